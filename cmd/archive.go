@@ -19,24 +19,34 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Folders of the archive holding the downloads and summaries the replay command rebuilds the outputs from
+const (
+	archiveDownloadFolder = "download"
+	archiveSummaryFolder  = "summary"
+)
+
 // archiveCmd represents the archive command
 var archiveCmd = &cobra.Command{
 	Use:   "archive",
 	Short: "Archive DNS toolkit data",
 	Long: `Archive DNS toolkit data from various folders specified in the config.
 Creates a compressed archive (tgz) containing all relevant data files and
-generates a summary of the archived contents.`,
-	Run: func(cmd *cobra.Command, args []string) {
+generates a summary of the archived contents.
+
+The archive also holds the downloaded files and the summary files, so that its
+outputs can be rebuilt offline with the replay command.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := u.EnsureDirectoryExists(Logger, constants.ArchiveDir); err != nil {
-			Logger.Errorf("Failed to create archive directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create archive directory: %w", err)
 		}
 
-		runArchive(Logger)
+		return runArchive(Logger)
 	},
 }
 
-func runArchive(logger *multilog.Logger) {
+// runArchive archives the data folders and summary files, and writes the archive summary.
+func runArchive(logger *multilog.Logger) error {
 	logger.Infof("Starting archive process")
 
 	// Create a timestamp for archive naming
@@ -46,8 +56,7 @@ func runArchive(logger *multilog.Logger) {
 
 	// Ensure the archive directory exists
 	if err := os.MkdirAll(constants.ArchiveDir, 0755); err != nil {
-		logger.Errorf("Failed to create archive directory: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to create archive directory: %w", err)
 	}
 
 	archiveSummary := &common.ArchiveSummary{
@@ -57,8 +66,7 @@ func runArchive(logger *multilog.Logger) {
 
 	archiveFile, err := os.Create(archivePath)
 	if err != nil {
-		logger.Errorf("Failed to create archive file: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to create archive file: %w", err)
 	}
 	defer func() {
 		if closeErr := archiveFile.Close(); closeErr != nil {
@@ -82,6 +90,7 @@ func runArchive(logger *multilog.Logger) {
 
 	summaryDir := AppConfig.DNSToolkit.Folders.Summary
 	processSummaryFiles(logger, summaryDir, archiveSummary, tarWriter)
+	addDownloadFiles(logger, summaryDir, archiveSummary, tarWriter, timestamp)
 
 	foldersToArchive := u.GetFoldersToArchive(logger, constants.Folders)
 
@@ -169,27 +178,27 @@ func runArchive(logger *multilog.Logger) {
 
 	summaryJSON, err := json.MarshalIndent(archiveSummary, "", "  ")
 	if err != nil {
-		logger.Errorf("Failed to marshal archive summary: %v", err)
-		return
+		return fmt.Errorf("failed to marshal archive summary: %w", err)
 	}
 
 	if err := os.WriteFile(summaryPath, summaryJSON, 0644); err != nil {
-		logger.Errorf("Failed to write archive summary to path %s: %v", summaryPath, err)
-		return
+		return fmt.Errorf("failed to write archive summary to path %s: %w", summaryPath, err)
 	}
 
 	logger.Infof("Archive process completed successfully; created archive %s", archivePath)
 	logger.Infof("Summary file created at %s", summaryPath)
 	logger.Infof("Total folders archived: %d", len(archiveSummary.Folders))
 	logger.Infof("Total summary files archived: %d", len(archiveSummary.SummaryFiles))
+	return nil
 }
 
-// processSummaryFiles processes the summary files and adds them to the archive summary
+// processSummaryFiles processes the summary files and adds them to the archive summary,
+// and to the archive itself if tarWriter is not nil
 func processSummaryFiles(
 	logger *multilog.Logger,
 	summaryDir string,
 	archiveSummary *common.ArchiveSummary,
-	_ *tar.Writer,
+	tarWriter *tar.Writer,
 ) {
 	entries, err := os.ReadDir(summaryDir)
 	if err != nil {
@@ -246,7 +255,67 @@ func processSummaryFiles(
 		}
 
 		archiveSummary.SummaryFiles = append(archiveSummary.SummaryFiles, archiveSummaryFile)
+
+		if tarWriter != nil {
+			targetPath := archiveSummaryFolder + "/" + filepath.Base(path)
+			if err := addFileToTar(logger, tarWriter, path, targetPath, fileInfo); err != nil {
+				logger.Warnf("Failed to add summary file %s to archive: %v", path, err)
+			}
+		}
+	}
+}
+
+// addDownloadFiles adds the downloaded files listed in the download summary to the archive
+func addDownloadFiles(
+	logger *multilog.Logger,
+	summaryDir string,
+	archiveSummary *common.ArchiveSummary,
+	tarWriter *tar.Writer,
+	timestamp string,
+) {
+	summaryFile := filepath.Join(summaryDir, constants.DefaultSummaryFiles[constants.SummaryTypeDownload])
+	archiveFolder := common.ArchiveFolder{
+		Name:      constants.SummaryTypeDownload + "(" + archiveDownloadFolder + ")",
+		Files:     []common.ArchiveFile{},
+		Timestamp: timestamp,
+	}
+	added := make(map[string]bool)
+	for _, summary := range readSummaries[common.DownloadSummary](logger, summaryFile) {
+		if summary.Filepath == "" || added[summary.Filepath] {
+			continue
+		}
+		added[summary.Filepath] = true
+		fileInfo, err := os.Stat(summary.Filepath)
+		if err != nil || !fileInfo.Mode().IsRegular() {
+			logger.Warnf("Skipping download file %s of %s: %v", summary.Filepath, summary.Name, err)
+			continue
+		}
+		targetPath := downloadArchiveName(summary.Filepath)
+		if err := addFileToTar(logger, tarWriter, summary.Filepath, targetPath, fileInfo); err != nil {
+			logger.Warnf("Failed to add download file %s to archive: %v", summary.Filepath, err)
+			continue
+		}
+		archiveFolder.Files = append(archiveFolder.Files, common.ArchiveFile{
+			Name:      filepath.Base(summary.Filepath),
+			Filepath:  summary.Filepath,
+			Checksum:  summary.Checksum,
+			Size:      fileInfo.Size(),
+			Timestamp: timestamp,
+		})
+	}
+	archiveFolder.Count = len(archiveFolder.Files)
+	if archiveFolder.Count > 0 {
+		archiveSummary.Folders = append(archiveSummary.Folders, archiveFolder)
+	}
+}
+
+// downloadArchiveName returns the name of a downloaded file in the archive, its path relative to the download folder
+func downloadArchiveName(filePath string) string {
+	name, err := filepath.Rel(constants.DownloadDir, filePath)
+	if err != nil || !filepath.IsLocal(name) {
+		name = filepath.Base(filePath)
 	}
+	return archiveDownloadFolder + "/" + filepath.ToSlash(name)
 }
 
 // getSummaryCount gets the count of items in the summary based on its type
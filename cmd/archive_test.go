@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"archive/tar"
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -302,7 +303,7 @@ func TestArchiveCommandIntegration(t *testing.T) {
 	assert.NotNil(t, archiveCmd, "Archive command should be defined")
 	assert.Equal(t, "archive", archiveCmd.Use, "Command should have correct usage")
 	assert.Contains(t, archiveCmd.Short, "Archive", "Command should have appropriate short description")
-	assert.NotNil(t, archiveCmd.Run, "Command should have a run function")
+	assert.NotNil(t, archiveCmd.RunE, "Command should have a run function")
 }
 
 // Helper function
@@ -354,3 +355,60 @@ func TestRunArchiveIntegration(t *testing.T) {
 	assert.NotPanics(t, func() {
 	}, "runArchive should not panic with valid configuration")
 }
+
+func TestAddDownloadFiles(t *testing.T) {
+	logger := multilog.NewLogger()
+	tmpDir := t.TempDir()
+	originalDownloadDir := constants.DownloadDir
+	constants.DownloadDir = filepath.Join(tmpDir, "download")
+	defer func() {
+		constants.DownloadDir = originalDownloadDir
+	}()
+
+	listPath := filepath.Join(constants.DownloadDir, "list.txt")
+	require.NoError(t, os.MkdirAll(constants.DownloadDir, 0755))
+	require.NoError(t, os.WriteFile(listPath, []byte("a.example\n"), 0644))
+	summaries := []common.DownloadSummary{
+		{Name: "list", Filepath: listPath, Checksum: "abc"},
+		{Name: "list-copy", Filepath: listPath},
+		{Name: "missing", Filepath: filepath.Join(constants.DownloadDir, "missing.txt")},
+		{Name: "failed", Error: "HTTP 404"},
+	}
+	summaryData, err := json.Marshal(summaries)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "download_summary.json"), summaryData, 0644))
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	archiveSummary := &common.ArchiveSummary{}
+	processSummaryFiles(logger, tmpDir, archiveSummary, tarWriter)
+	addDownloadFiles(logger, tmpDir, archiveSummary, tarWriter, "20261017")
+	require.NoError(t, tarWriter.Close())
+
+	require.Len(t, archiveSummary.Folders, 1)
+	assert.Equal(t, 1, archiveSummary.Folders[0].Count)
+	assert.Equal(t, "abc", archiveSummary.Folders[0].Files[0].Checksum)
+
+	var names []string
+	tarReader := tar.NewReader(&buf)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"summary/download_summary.json", "download/list.txt"}, names)
+}
+
+func TestDownloadArchiveName(t *testing.T) {
+	originalDownloadDir := constants.DownloadDir
+	constants.DownloadDir = "/data/download"
+	defer func() {
+		constants.DownloadDir = originalDownloadDir
+	}()
+
+	assert.Equal(t, "download/list.txt", downloadArchiveName("/data/download/list.txt"))
+	assert.Equal(t, "download/feed/list.txt", downloadArchiveName("/data/download/feed/list.txt"))
+	assert.Equal(t, "download/list.txt", downloadArchiveName("/elsewhere/list.txt"))
+}
@@ -1,7 +1,22 @@
 package cmd
 
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
 func appendSummary[T any](allSummaries *[]T, summary T, filterFunc func(T) bool) {
 	if filterFunc(summary) {
 		*allSummaries = append(*allSummaries, summary)
 	}
 }
+
+// commandContext returns the context of the command. Commands invoked directly through their
+// Run function (from tests or the run command) may have no context; context.Background is used then.
+func commandContext(cmd *cobra.Command) context.Context {
+	if cmd != nil && cmd.Context() != nil {
+		return cmd.Context()
+	}
+	return context.Background()
+}
@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
 	"sync"
@@ -27,24 +27,25 @@ var (
 )
 
 var consolidateCmd = &cobra.Command{
-	Use:   "consolidate",
-	Short: "Consolidate processed files",
-	Run: func(cmd *cobra.Command, args []string) {
-		consolidateAllCmd.Run(cmd, args)
+	Use:          "consolidate",
+	Short:        "Consolidate processed files",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return consolidateAllCmd.RunE(cmd, args)
 	},
 }
 
 var consolidateAllCmd = &cobra.Command{
-	Use:   "all",
-	Short: "Consolidate all processed files",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "all",
+	Short:        "Consolidate all processed files",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := commandContext(cmd)
 		if err := u.EnsureDirectoryExists(Logger, constants.ConsolidatedDir); err != nil {
-			Logger.Errorf("Failed to create consolidated directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create consolidated directory: %w", err)
 		}
 		if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
-			Logger.Errorf("Failed to create summary directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create summary directory: %w", err)
 		}
 
 		processedSummaries, genericSourceTypes, processedFiles := cfg.GetProcessedSummariesForConsolidation(
@@ -55,7 +56,7 @@ var consolidateAllCmd = &cobra.Command{
 		)
 		if len(processedSummaries) == 0 {
 			Logger.Errorf("No processed summaries found")
-			return
+			return ctx.Err()
 		}
 
 		var allConsolidatedSummaries []c.ConsolidatedSummary
@@ -65,6 +66,7 @@ var consolidateAllCmd = &cobra.Command{
 
 		allowlistEntriesByType := make(map[string]u.StringSet)
 		processAllowlists(
+			ctx,
 			genericSourceTypes,
 			processedFiles,
 			allowByType,
@@ -99,7 +101,7 @@ var consolidateAllCmd = &cobra.Command{
 		}
 		maxWorkers = max(maxWorkers, 1)
 		Logger.Infof("Using worker pool with %d worker(s) for consolidation", maxWorkers)
-		workerPool := c.NewDTWorkerPool(maxWorkers)
+		workerPool := c.NewDTWorkerPoolWithContext(ctx, maxWorkers)
 
 		for i := range blocklistTypes {
 			genericSourceType := blocklistTypes[i] // Local variable for this iteration
@@ -111,6 +113,7 @@ var consolidateAllCmd = &cobra.Command{
 				Logger.Debugf("Filtering %s blocklist with %d resolved allowlist entries", gst, allowlistEntries.Size())
 
 				_, blocklistSummary := consolidateFilesBasedOnSTLT(
+					ctx,
 					Logger,
 					gst,
 					constants.ListTypeBlocklist,
@@ -128,6 +131,7 @@ var consolidateAllCmd = &cobra.Command{
 
 				if includeInvalid {
 					_, invalidBlocklistSummary := consolidateFilesBasedOnSTLT(
+						ctx,
 						Logger,
 						gst,
 						constants.ListTypeBlocklist,
@@ -149,6 +153,11 @@ var consolidateAllCmd = &cobra.Command{
 		Logger.Debugf("Waiting for all blocklists to finish processing...")
 		workerPool.Wait()
 
+		if ctx.Err() != nil {
+			Logger.Warnf("Consolidation cancelled: %v; keeping previous summaries", ctx.Err())
+			return ctx.Err()
+		}
+
 		summaryFile := filepath.Join(
 			constants.SummaryDir,
 			constants.DefaultSummaryFiles["consolidated"],
@@ -173,10 +182,12 @@ var consolidateAllCmd = &cobra.Command{
 				}
 			}
 		}
+		return ctx.Err()
 	},
 }
 
 func processAllowlists(
+	ctx context.Context,
 	genericSourceTypes []string,
 	processedFiles []c.ProcessedFile,
 	_ map[string]u.StringSet,
@@ -186,8 +197,13 @@ func processAllowlists(
 ) {
 	Logger.Infof("Processing allowlists...")
 	for _, genericSourceType := range genericSourceTypes {
+		if ctx.Err() != nil {
+			Logger.Warnf("Processing allowlists cancelled: %v", ctx.Err())
+			return
+		}
 		// consolidate all allowlist source files (no filtering)
 		entries, allowlistSummary := consolidateFilesBasedOnSTLT(
+			ctx,
 			Logger,
 			genericSourceType,
 			constants.ListTypeAllowlist,
@@ -293,6 +309,7 @@ func processAllowlists(
 
 		if includeInvalid {
 			_, invalidAllowlistSummary := consolidateFilesBasedOnSTLT(
+				ctx,
 				Logger,
 				genericSourceType,
 				constants.ListTypeAllowlist,
@@ -320,6 +337,7 @@ func calculateOriginalCount(fileInfos []c.FileInfo) int {
 }
 
 func consolidateFilesBasedOnSTLT(
+	ctx context.Context,
 	logger *multilog.Logger,
 	genericSourceType, listType string,
 	valid bool,
@@ -344,7 +362,10 @@ func consolidateFilesBasedOnSTLT(
 		)
 		return u.NewStringSet([]string{}), c.ConsolidatedSummary{}
 	}
-	consolidatedEntries, fileInfos := consolidator.Consolidate(Logger, processedFiles)
+	consolidatedEntries, fileInfos := consolidator.Consolidate(ctx, Logger, processedFiles)
+	if ctx.Err() != nil {
+		return u.NewStringSet([]string{}), c.ConsolidatedSummary{}
+	}
 	consolidatedFileStrings := getFileStrings(fileInfos)
 	if len(consolidatedEntries) > 0 {
 		logger.Infof(
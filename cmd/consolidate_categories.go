@@ -1,7 +1,8 @@
 package cmd
 
 import (
-	"os"
+	"context"
+	"fmt"
 	"path/filepath"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
@@ -13,18 +14,18 @@ import (
 )
 
 var consolidateCategoriesCmd = &cobra.Command{
-	Use:   "categories",
-	Short: "Generate category-based consolidated lists (ads, malware, privacy, etc)",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "categories",
+	Short:        "Generate category-based consolidated lists (ads, malware, privacy, etc)",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := commandContext(cmd)
 		Logger.Infof("Generating category-based consolidated lists...")
 
 		if err := u.EnsureDirectoryExists(Logger, constants.ConsolidatedCategoriesDir); err != nil {
-			Logger.Errorf("Failed to create consolidated categories directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create consolidated categories directory: %w", err)
 		}
 		if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
-			Logger.Errorf("Failed to create summary directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create summary directory: %w", err)
 		}
 
 		processedSummaries, genericSourceTypes, processedFiles := cfg.GetProcessedSummariesForConsolidation(
@@ -35,7 +36,7 @@ var consolidateCategoriesCmd = &cobra.Command{
 		)
 		if len(processedSummaries) == 0 {
 			Logger.Errorf("No processed summaries found")
-			return
+			return nil
 		}
 
 		// Get unique categories from all processed files
@@ -50,7 +51,11 @@ var consolidateCategoriesCmd = &cobra.Command{
 
 		// Process each category and create consolidated lists
 		for _, category := range categories {
+			if ctx.Err() != nil {
+				break
+			}
 			categoryResults := processCategoryConsolidation(
+				ctx,
 				Logger,
 				category,
 				processedFiles,
@@ -64,6 +69,11 @@ var consolidateCategoriesCmd = &cobra.Command{
 			}
 		}
 
+		if ctx.Err() != nil {
+			Logger.Warnf("Consolidation of categories cancelled: %v; keeping previous summaries", ctx.Err())
+			return nil
+		}
+
 		// Create consolidated categories summaries
 		var consolidatedCategoriesSummaries []c.ConsolidatedCategoriesSummary
 		timestamp := u.GetTimestamp()
@@ -139,6 +149,7 @@ var consolidateCategoriesCmd = &cobra.Command{
 				}
 			}
 		}
+		return nil
 	},
 }
 
@@ -180,6 +191,7 @@ func getFilesForCategory(processedFiles []c.ProcessedFile, category string) []c.
 
 // processCategoryConsolidation processes consolidation for a specific category
 func processCategoryConsolidation(
+	ctx context.Context,
 	logger *multilog.Logger,
 	category string,
 	processedFiles []c.ProcessedFile,
@@ -196,11 +208,12 @@ func processCategoryConsolidation(
 		AllowFilterByType:  nil, // no cross-category filtering
 	}
 
-	return processConsolidationWithTransform(logger, config)
+	return processConsolidationWithTransform(ctx, logger, config)
 }
 
 // consolidateByCategory consolidates files for a specific category
 func consolidateByCategory(
+	ctx context.Context,
 	logger *multilog.Logger,
 	genericSourceType, listType, category string,
 	entriesToIgnore u.StringSet,
@@ -214,5 +227,5 @@ func consolidateByCategory(
 		IdentifierField:   "Category",
 	}
 
-	return consolidateGeneric(logger, params, entriesToIgnore, processedFiles)
+	return consolidateGeneric(ctx, logger, params, entriesToIgnore, processedFiles)
 }
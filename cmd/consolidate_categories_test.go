@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -151,6 +152,7 @@ func TestConsolidateByCategory(t *testing.T) {
 			defer func() { con.Consolidators = origRegistry }()
 
 			entries, summary := consolidateByCategory(
+				context.Background(),
 				logger,
 				tt.genericSourceType,
 				tt.listType,
@@ -170,7 +172,7 @@ func TestConsolidateCategoriesCommand(t *testing.T) {
 	assert.NotNil(t, consolidateCategoriesCmd)
 	assert.Equal(t, "categories", consolidateCategoriesCmd.Use)
 	assert.Contains(t, consolidateCategoriesCmd.Short, "category-based")
-	assert.NotNil(t, consolidateCategoriesCmd.Run)
+	assert.NotNil(t, consolidateCategoriesCmd.RunE)
 }
 
 func TestGetFilesForCategory(t *testing.T) {
@@ -310,6 +312,7 @@ func TestProcessCategoryConsolidation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := processCategoryConsolidation(
+				context.Background(),
 				logger,
 				tt.category,
 				tt.processedFiles,
@@ -12,7 +12,7 @@ func TestConsolidateCommand(t *testing.T) {
 	assert.NotNil(t, consolidateCmd)
 	assert.Equal(t, "consolidate", consolidateCmd.Use)
 	assert.Contains(t, consolidateCmd.Short, "Consolidate")
-	assert.NotNil(t, consolidateCmd.Run)
+	assert.NotNil(t, consolidateCmd.RunE)
 }
 
 func TestConsolidateAllCommand(t *testing.T) {
@@ -21,7 +21,7 @@ func TestConsolidateAllCommand(t *testing.T) {
 	assert.NotNil(t, consolidateAllCmd)
 	assert.Equal(t, "all", consolidateAllCmd.Use)
 	assert.Contains(t, consolidateAllCmd.Short, "Consolidate all")
-	assert.NotNil(t, consolidateAllCmd.Run)
+	assert.NotNil(t, consolidateAllCmd.RunE)
 }
 
 func TestConsolidateFlags(t *testing.T) {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -32,6 +33,7 @@ type ConsolidationParams struct {
 
 // consolidateGeneric is a generic consolidation function that can be used by both groups and categories
 func consolidateGeneric(
+	ctx context.Context,
 	logger *multilog.Logger,
 	params ConsolidationParams,
 	entriesToIgnore u.StringSet,
@@ -66,7 +68,10 @@ func consolidateGeneric(
 		return u.NewStringSet([]string{}), c.ConsolidatedSummary{}
 	}
 
-	consolidatedEntries, fileInfos := consolidator.Consolidate(logger, processedFiles)
+	consolidatedEntries, fileInfos := consolidator.Consolidate(ctx, logger, processedFiles)
+	if ctx.Err() != nil {
+		return u.NewStringSet([]string{}), c.ConsolidatedSummary{}
+	}
 	consolidatedFileStrings := getFileStrings(fileInfos)
 
 	allEntries, ignoredEntries := consolidator.FilterEntries(
@@ -216,7 +221,7 @@ func consolidateGeneric(
 // ProcessingConfig holds configuration for processing consolidation
 type ProcessingConfig struct {
 	GetFilesFunc       func([]c.ProcessedFile, string) []c.ProcessedFile
-	ConsolidateFunc    func(*multilog.Logger, string, string, string, u.StringSet, []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) // nolint:lll
+	ConsolidateFunc    func(context.Context, *multilog.Logger, string, string, string, u.StringSet, []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) // nolint:lll
 	AllowFilterByType  map[string]u.StringSet
 	Identifier         string
 	IdentifierField    string
@@ -226,6 +231,7 @@ type ProcessingConfig struct {
 
 // processIdentifierConsolidation is a generic function for processing consolidation by identifier (group or category)
 func processIdentifierConsolidation(
+	ctx context.Context,
 	logger *multilog.Logger,
 	config ProcessingConfig,
 ) map[string][]c.ConsolidatedSummary {
@@ -253,6 +259,9 @@ func processIdentifierConsolidation(
 
 	// First process allowlists for each identifier and source type
 	for _, gst := range config.GenericSourceTypes {
+		if ctx.Err() != nil {
+			return consolidatedSummariesByIdentifier
+		}
 		var allowlistFiles []c.ProcessedFile
 		for _, file := range identifierFiles {
 			if file.GenericSourceType == gst &&
@@ -263,6 +272,7 @@ func processIdentifierConsolidation(
 
 		if len(allowlistFiles) > 0 {
 			entries, allowlistSummary := config.ConsolidateFunc(
+				ctx,
 				logger,
 				gst,
 				constants.ListTypeAllowlist,
@@ -291,6 +301,9 @@ func processIdentifierConsolidation(
 
 	// Then process blocklists using the allowlists from above
 	for _, gst := range config.GenericSourceTypes {
+		if ctx.Err() != nil {
+			break
+		}
 		var blocklistFiles []c.ProcessedFile
 		for _, file := range identifierFiles {
 			if file.GenericSourceType == gst &&
@@ -312,6 +325,7 @@ func processIdentifierConsolidation(
 			}
 
 			_, blocklistSummary := config.ConsolidateFunc(
+				ctx,
 				logger,
 				gst,
 				constants.ListTypeBlocklist,
@@ -341,11 +355,12 @@ func processIdentifierConsolidation(
 // processConsolidationWithTransform is a generic function that handles both identifier-based consolidation
 // and transformation to source-type-based results
 func processConsolidationWithTransform(
+	ctx context.Context,
 	logger *multilog.Logger,
 	config ProcessingConfig,
 ) map[string][]c.ConsolidatedSummary {
 	// Get result keyed by identifier
-	resultByIdentifier := processIdentifierConsolidation(logger, config)
+	resultByIdentifier := processIdentifierConsolidation(ctx, logger, config)
 
 	// Transform to result keyed by source type
 	resultBySourceType := make(map[string][]c.ConsolidatedSummary)
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -27,7 +28,11 @@ type mockConsolidatorCommon struct {
 	shouldReturnValid bool
 }
 
-func (m *mockConsolidatorCommon) Consolidate(_ *multilog.Logger, _ []c.ProcessedFile) (u.StringSet, []c.FileInfo) {
+func (m *mockConsolidatorCommon) Consolidate(
+	_ context.Context,
+	_ *multilog.Logger,
+	_ []c.ProcessedFile,
+) (u.StringSet, []c.FileInfo) {
 	return m.mockEntries, m.mockFiles
 }
 
@@ -294,6 +299,7 @@ func TestConsolidateGeneric(t *testing.T) {
 
 			// Call the function under test
 			resultEntries, resultSummary := consolidateGeneric(
+				context.Background(),
 				logger,
 				tt.params,
 				tt.entriesToIgnore,
@@ -337,7 +343,7 @@ func TestProcessIdentifierConsolidation(t *testing.T) {
 	logger := multilog.NewLogger()
 
 	// Mock consolidate function
-	mockConsolidateFunc := func(logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
+	mockConsolidateFunc := func(_ context.Context, logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
 		entries := u.NewStringSet([]string{"example.com", "test.com"})
 		summary := c.ConsolidatedSummary{
 			Type:                      gst,
@@ -533,7 +539,7 @@ func TestProcessIdentifierConsolidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := processIdentifierConsolidation(logger, tt.config)
+			result := processIdentifierConsolidation(context.Background(), logger, tt.config)
 
 			// Verify the identifier exists in results
 			summaries, exists := result[tt.config.Identifier]
@@ -619,7 +625,7 @@ func TestProcessingConfig(t *testing.T) {
 		return files
 	}
 
-	mockConsolidateFunc := func(logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
+	mockConsolidateFunc := func(_ context.Context, logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
 		return u.NewStringSet([]string{}), c.ConsolidatedSummary{}
 	}
 
@@ -687,6 +693,7 @@ func TestConsolidateGeneric_FileSaveError(t *testing.T) {
 
 	// Call the function under test
 	resultEntries, resultSummary := consolidateGeneric(
+		context.Background(),
 		logger,
 		params,
 		entriesToIgnore,
@@ -786,6 +793,7 @@ func TestConsolidateGeneric_ChecksumCalculation(t *testing.T) {
 
 	// Call the function under test
 	_, resultSummary := consolidateGeneric(
+		context.Background(),
 		logger,
 		params,
 		u.NewStringSet([]string{}),
@@ -835,7 +843,7 @@ func TestConsolidateGeneric_EdgeCases(t *testing.T) {
 			},
 		}
 
-		entries, summary := consolidateGeneric(logger, params, u.NewStringSet([]string{}), processedFiles)
+		entries, summary := consolidateGeneric(context.Background(), logger, params, u.NewStringSet([]string{}), processedFiles)
 
 		assert.Equal(t, 1, len(entries), "Should handle empty identifier")
 		assert.Contains(t, summary.Filepath, "_domain_blocklist.txt", "Filepath should still be generated")
@@ -874,7 +882,7 @@ func TestConsolidateGeneric_EdgeCases(t *testing.T) {
 			},
 		}
 
-		entries, summary := consolidateGeneric(logger, params, u.NewStringSet([]string{}), processedFiles)
+		entries, summary := consolidateGeneric(context.Background(), logger, params, u.NewStringSet([]string{}), processedFiles)
 
 		assert.Equal(t, 1, len(entries), "Should process even with invalid identifier field")
 		assert.Empty(t, summary.Group, "Group should not be set for invalid field")
@@ -914,7 +922,7 @@ func TestConsolidateGeneric_EdgeCases(t *testing.T) {
 			},
 		}
 
-		entries, summary := consolidateGeneric(logger, params, u.NewStringSet([]string{}), processedFiles)
+		entries, summary := consolidateGeneric(context.Background(), logger, params, u.NewStringSet([]string{}), processedFiles)
 
 		assert.Equal(t, 0, len(entries), "Should handle nil entries gracefully")
 		assert.Empty(t, summary.Filepath, "Should not create filepath for empty result")
@@ -939,7 +947,7 @@ func TestProcessIdentifierConsolidation_EdgeCases(t *testing.T) {
 		// This should panic because the function expects valid function pointers
 		// This test demonstrates that the function requires proper validation
 		assert.Panics(t, func() {
-			processIdentifierConsolidation(logger, cfg)
+			processIdentifierConsolidation(context.Background(), logger, cfg)
 		}, "Should panic with nil function")
 	})
 
@@ -948,7 +956,7 @@ func TestProcessIdentifierConsolidation_EdgeCases(t *testing.T) {
 			return files
 		}
 
-		mockConsolidateFunc := func(logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
+		mockConsolidateFunc := func(_ context.Context, logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
 			return u.NewStringSet([]string{}), c.ConsolidatedSummary{}
 		}
 
@@ -961,7 +969,7 @@ func TestProcessIdentifierConsolidation_EdgeCases(t *testing.T) {
 			IdentifierField:    "Group",
 		}
 
-		result := processIdentifierConsolidation(logger, cfg)
+		result := processIdentifierConsolidation(context.Background(), logger, cfg)
 
 		summaries := result["test"]
 		assert.Equal(t, 0, len(summaries), "Should handle empty source types")
@@ -972,7 +980,7 @@ func TestProcessIdentifierConsolidation_EdgeCases(t *testing.T) {
 			return files
 		}
 
-		mockConsolidateFunc := func(logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
+		mockConsolidateFunc := func(_ context.Context, logger *multilog.Logger, gst, listType, identifier string, entriesToIgnore u.StringSet, processedFiles []c.ProcessedFile) (u.StringSet, c.ConsolidatedSummary) {
 			return u.NewStringSet([]string{"test.com"}), c.ConsolidatedSummary{
 				Type:     gst,
 				ListType: listType,
@@ -998,7 +1006,7 @@ func TestProcessIdentifierConsolidation_EdgeCases(t *testing.T) {
 			IdentifierField:    "UnknownField", // Unknown field
 		}
 
-		result := processIdentifierConsolidation(logger, cfg)
+		result := processIdentifierConsolidation(context.Background(), logger, cfg)
 
 		summaries := result["test"]
 		assert.Equal(t, 1, len(summaries), "Should process with unknown field")
@@ -1056,7 +1064,7 @@ func TestConsolidateGeneric_LargeDataset(t *testing.T) {
 		},
 	}
 
-	entries, summary := consolidateGeneric(logger, params, u.NewStringSet([]string{}), processedFiles)
+	entries, summary := consolidateGeneric(context.Background(), logger, params, u.NewStringSet([]string{}), processedFiles)
 
 	assert.Equal(t, 1000, len(entries), "Should handle large datasets")
 	assert.Equal(t, 1000, summary.Count, "Summary count should match")
@@ -1117,7 +1125,7 @@ func TestConsolidateGeneric_ConfigFlags(t *testing.T) {
 		calculateChecksum = false
 		defer func() { calculateChecksum = origCalculateChecksum }()
 
-		_, summary := consolidateGeneric(logger, params, u.NewStringSet([]string{}), processedFiles)
+		_, summary := consolidateGeneric(context.Background(), logger, params, u.NewStringSet([]string{}), processedFiles)
 
 		// When there are entries, filepath should be set regardless of calculateChecksum setting
 		if summary.Count > 0 {
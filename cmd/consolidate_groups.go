@@ -1,7 +1,8 @@
 package cmd
 
 import (
-	"os"
+	"context"
+	"fmt"
 	"path/filepath"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
@@ -13,18 +14,18 @@ import (
 )
 
 var consolidateGroupsCmd = &cobra.Command{
-	Use:   "groups",
-	Short: "Generate different sized consolidated lists (mini, lite, normal, big)",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "groups",
+	Short:        "Generate different sized consolidated lists (mini, lite, normal, big)",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := commandContext(cmd)
 		Logger.Infof("Generating sized consolidated lists...")
 
 		if err := u.EnsureDirectoryExists(Logger, constants.ConsolidatedGroupsDir); err != nil {
-			Logger.Errorf("Failed to create consolidated groups directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create consolidated groups directory: %w", err)
 		}
 		if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
-			Logger.Errorf("Failed to create summary directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create summary directory: %w", err)
 		}
 
 		processedSummaries, genericSourceTypes, processedFiles := cfg.GetProcessedSummariesForConsolidation(
@@ -36,7 +37,7 @@ var consolidateGroupsCmd = &cobra.Command{
 
 		if len(processedSummaries) == 0 {
 			Logger.Errorf("No processed summaries found")
-			return
+			return nil
 		}
 
 		// Maps to store consolidated summaries by group
@@ -47,7 +48,11 @@ var consolidateGroupsCmd = &cobra.Command{
 
 		// Process each size group and create consolidated lists
 		for _, group := range constants.SizeGroups {
+			if ctx.Err() != nil {
+				break
+			}
 			groupResults := processGroupConsolidationWithAllow(
+				ctx,
 				Logger,
 				group,
 				processedFiles,
@@ -61,6 +66,11 @@ var consolidateGroupsCmd = &cobra.Command{
 			}
 		}
 
+		if ctx.Err() != nil {
+			Logger.Warnf("Consolidation of groups cancelled: %v; keeping previous summaries", ctx.Err())
+			return nil
+		}
+
 		// Create consolidated groups summaries
 		var consolidatedGroupsSummaries []c.ConsolidatedGroupsSummary
 		timestamp := u.GetTimestamp()
@@ -138,6 +148,7 @@ var consolidateGroupsCmd = &cobra.Command{
 				}
 			}
 		}
+		return nil
 	},
 }
 
@@ -156,6 +167,7 @@ func getFilesForGroup(processedFiles []c.ProcessedFile, group string) []c.Proces
 }
 
 func processGroupConsolidationWithAllow(
+	ctx context.Context,
 	logger *multilog.Logger,
 	group string,
 	processedFiles []c.ProcessedFile,
@@ -172,11 +184,12 @@ func processGroupConsolidationWithAllow(
 		AllowFilterByType:  allowByType,
 	}
 
-	return processConsolidationWithTransform(logger, config)
+	return processConsolidationWithTransform(ctx, logger, config)
 }
 
 // consolidateByGroup consolidates files for a specific size group
 func consolidateByGroup(
+	ctx context.Context,
 	logger *multilog.Logger,
 	genericSourceType, listType, group string,
 	entriesToIgnore u.StringSet,
@@ -190,5 +203,5 @@ func consolidateByGroup(
 		IdentifierField:   "Group",
 	}
 
-	return consolidateGeneric(logger, params, entriesToIgnore, processedFiles)
+	return consolidateGeneric(ctx, logger, params, entriesToIgnore, processedFiles)
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -30,14 +31,14 @@ func processGroupConsolidation(
 	processedFiles []c.ProcessedFile,
 	genericSourceTypes []string,
 ) map[string][]c.ConsolidatedSummary {
-	return processGroupConsolidationWithAllow(logger, group, processedFiles, genericSourceTypes)
+	return processGroupConsolidationWithAllow(context.Background(), logger, group, processedFiles, genericSourceTypes)
 }
 
 func TestConsolidateGroupsCommand(t *testing.T) {
 	assert.NotNil(t, consolidateGroupsCmd)
 	assert.Equal(t, "groups", consolidateGroupsCmd.Use)
 	assert.Contains(t, consolidateGroupsCmd.Short, "sized consolidated lists")
-	assert.NotNil(t, consolidateGroupsCmd.Run)
+	assert.NotNil(t, consolidateGroupsCmd.RunE)
 }
 
 func TestConsolidateByGroup(t *testing.T) {
@@ -107,6 +108,7 @@ func TestConsolidateByGroup(t *testing.T) {
 			defer func() { con.Consolidators = origRegistry }()
 
 			entries, summary := consolidateByGroup(
+				context.Background(),
 				logger,
 				tt.genericSourceType,
 				tt.listType,
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -200,7 +201,11 @@ type mockConsolidator struct {
 	mockFiles   []c.FileInfo
 }
 
-func (m *mockConsolidator) Consolidate(_ *multilog.Logger, _ []c.ProcessedFile) (u.StringSet, []c.FileInfo) {
+func (m *mockConsolidator) Consolidate(
+	_ context.Context,
+	_ *multilog.Logger,
+	_ []c.ProcessedFile,
+) (u.StringSet, []c.FileInfo) {
 	return m.mockEntries, m.mockFiles
 }
 
@@ -271,6 +276,7 @@ func TestConsolidateFilesBasedOnSTLT(t *testing.T) {
 	entriesToIgnore := u.NewStringSet([]string{})
 
 	gotEntries, gotSummary := consolidateFilesBasedOnSTLT(
+		context.Background(),
 		logger,
 		"domain",
 		"blocklist",
@@ -333,6 +339,7 @@ func TestProcessAllowlists(t *testing.T) {
 
 	// Execute the function
 	processAllowlists(
+		context.Background(),
 		genericSourceTypes,
 		processedFiles,
 		resolvedAllowByType,
@@ -395,6 +402,7 @@ func TestIgnoredEntriesAnnotation(t *testing.T) {
 	processedFiles := []c.ProcessedFile{{Valid: true}}
 
 	gotEntries, gotSummary := consolidateFilesBasedOnSTLT(
+		context.Background(),
 		logger,
 		"domain",
 		"blocklist",
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,9 +26,10 @@ import (
 const defaultMaxRetries = constants.DefaultMaxRetries
 
 var downloadCmd = &cobra.Command{
-	Use:   "download",
-	Short: "Download enabled sources",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "download",
+	Short:        "Download enabled sources",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		forceFlag, getBoolErr := cmd.Flags().GetBool("force")
 		if getBoolErr != nil {
 			Logger.Warnf("Failed to parse --force flag (defaulting to false): %v", getBoolErr)
@@ -35,12 +39,10 @@ var downloadCmd = &cobra.Command{
 		forceDownload := forceFlag || forceEnv
 
 		if err := u.EnsureDirectoryExists(Logger, constants.DownloadDir); err != nil {
-			Logger.Errorf("Failed to create download directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create download directory: %w", err)
 		}
 		if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
-			Logger.Errorf("Failed to create summary directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create summary directory: %w", err)
 		}
 
 		summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles["download"])
@@ -79,6 +81,11 @@ var downloadCmd = &cobra.Command{
 			Logger.Warnf("Failed to register domain top downloader: %v", domainTopErr)
 		}
 
+		execDownloader := d.NewExecDownloaderWithRetries(maxRetries)
+		if execErr := d.RegisterDownloader(execDownloader); execErr != nil {
+			Logger.Warnf("Failed to register exec downloader: %v", execErr)
+		}
+
 		summaries := make([]c.DownloadSummary, 0)
 		var mu sync.Mutex
 
@@ -91,10 +98,18 @@ var downloadCmd = &cobra.Command{
 		maxWorkers = max(maxWorkers, 1)
 		Logger.Infof("Using worker pool with %d worker(s) for downloads", maxWorkers)
 		limiter := createDownloadRateLimiter(maxWorkers)
-		workerPool := c.NewDTWorkerPoolWithLimiter(maxWorkers, limiter)
+		ctx := commandContext(cmd)
+		workerPool := c.NewDTWorkerPoolWithLimiter(ctx, maxWorkers, limiter)
+		hostLimiter := newHostLimiter()
+		scheduler := c.NewFairScheduler(workerPool, func(host string) int {
+			if host == "" {
+				return 0
+			}
+			return hostLimiter.MaxConcurrent(host)
+		})
 
 		// Stats to track a download process
-		var totalSources, successCount, failCount, downloadedCount int
+		var totalSources, successCount, failCount, downloadedCount, quarantinedCount int
 		var statsMutex sync.Mutex
 
 		for _, sourcesConfig := range SourcesConfigs {
@@ -105,7 +120,7 @@ var downloadCmd = &cobra.Command{
 			for _, source := range sourcesConfig.GetEnabledSources(sourceFilters) {
 				totalSources++
 				source := source // local copy for goroutine
-				workerPool.Submit(func() {
+				scheduler.Add(sourceHost(source.URL), func() {
 					if forceDownload {
 						Logger.Debugf("Force downloading source: %s", source.Name)
 					}
@@ -129,32 +144,48 @@ var downloadCmd = &cobra.Command{
 						return
 					}
 
-					var downloader d.Downloader
-					if specificDownloader, exists := d.GetDownloader(source.Name); exists {
-						downloader = specificDownloader
-						Logger.Debugf("Using registered downloader for %s", source.Name)
-					} else {
-						downloader, _ = d.GetDownloader(d.DefaultDownloaderName())
-						Logger.Debugf("Using default downloader with %d retries for %s", maxRetries, source.Name)
+					downloader, err := selectDownloader(source)
+					if err != nil {
+						Logger.Errorf("Selecting downloader error: %v", err)
+						statsMutex.Lock()
+						failCount++
+						statsMutex.Unlock()
+
+						mu.Lock()
+						summaries = append(summaries, c.DownloadSummary{
+							Name:                 source.Name,
+							URL:                  source.URL,
+							Error:                err.Error(),
+							LastCheckedTimestamp: u.GetTimestamp(),
+						})
+						mu.Unlock()
+
+						return
 					}
+					Logger.Debugf("Using %s downloader for %s", downloader.Name(), source.Name)
 
-					var skipCertVerification bool
-					var skipCertVerificationHosts []string
 					var applicationConfig cfg.ApplicationConfig
-
 					if AppConfig != nil {
-						skipCertVerification = AppConfig.DNSToolkit.SkipCertVerification
-						skipCertVerificationHosts = AppConfig.DNSToolkit.SkipCertVerificationHosts
 						applicationConfig = AppConfig.Application
+						downloadFile.Proxy = AppConfig.DNSToolkit.GetProxy(source)
+						downloadFile.HTTP = AppConfig.DNSToolkit.GetHTTP(source)
 					}
+					downloadFile.Hosts = hostLimiter
+
+					applyPreviousValidators(Logger, summaryFile, &downloadFile)
+					downloadFile.Guard = newAnomalyGuard(Logger, summaryFile, source)
 
 					filePath, fetchSkipped, err := downloader.Download(
+						ctx,
 						Logger,
-						downloadFile,
-						skipCertVerification,
-						skipCertVerificationHosts,
+						&downloadFile,
 						applicationConfig,
 					)
+					if err != nil && ctx.Err() != nil {
+						// Interrupted: keep the previous summary of this source instead of recording an error
+						Logger.Warnf("Download cancelled for %s: %v", source.Name, ctx.Err())
+						return
+					}
 
 					for _, target := range downloadFile.Targets {
 						targetFilePath := filepath.Join(target.TargetFolder, target.TargetFile)
@@ -172,7 +203,17 @@ var downloadCmd = &cobra.Command{
 							SkipCategoriesConsolidation: source.SkipCategoriesConsolidation,
 						}
 
-						if err != nil {
+						var anomalyErr *d.AnomalyError
+						if errors.As(err, &anomalyErr) {
+							summary = quarantineDownloadSummary(Logger, summaryFile, summary, anomalyErr)
+
+							statsMutex.Lock()
+							quarantinedCount++
+							statsMutex.Unlock()
+						} else if err != nil {
+							if downloadFile.Guard != nil {
+								summary.EntryCount = downloadFile.Guard.PreviousEntries
+							}
 
 							switch e := err.(type) { // wrapped errors handling
 							case *d.HTTPStatusError:
@@ -181,6 +222,12 @@ var downloadCmd = &cobra.Command{
 							case *d.CertVerificationError:
 								Logger.Errorf("Downloading source %s error: Certificate verification failed for %s", source.Name, e.Host)
 								summary.Error = e.Error()
+							case *d.IntegrityError:
+								Logger.Errorf("Downloading source %s error: Integrity verification failed for %s", source.Name, e.URL)
+								summary.Error = e.Error()
+							case *d.ExecError:
+								Logger.Errorf("Downloading source %s error: %s %s", source.Name, e.Command, e.Reason)
+								summary.Error = e.Error()
 							default:
 								Logger.Errorf("Downloading source %s error: %v", source.Name, err)
 								summary.Error = err.Error()
@@ -197,6 +244,20 @@ var downloadCmd = &cobra.Command{
 							}
 							statsMutex.Unlock()
 
+							summary.ETag = downloadFile.ETag
+							summary.LastModified = downloadFile.LastModified
+							if count, countErr := u.CountFileEntries(Logger, targetFilePath); countErr == nil {
+								summary.EntryCount = count
+							} else {
+								Logger.Debugf("Counting entries of %s error: %v", targetFilePath, countErr)
+							}
+							summary.ServedURL = downloadFile.ServedURL
+							if len(downloadFile.MirrorChecksums) > 0 {
+								consistent := downloadFile.MirrorsConsistent()
+								summary.MirrorChecksums = downloadFile.MirrorChecksums
+								summary.MirrorsConsistent = &consistent
+							}
+
 							if fetchSkipped {
 								summary.LastCheckedTimestamp = u.GetTimestamp()
 								if info, err := os.Stat(filePath); err == nil {
@@ -259,18 +320,110 @@ var downloadCmd = &cobra.Command{
 			}
 		}
 
-		workerPool.Wait()
+		scheduler.Run()
+
+		if ctx.Err() != nil {
+			Logger.Warnf("Download cancelled: %v; keeping previous summaries of unfinished sources", ctx.Err())
+			summaries = u.CarryOverSummaries(Logger, summaries, summaryFile, func(s c.DownloadSummary) string {
+				return s.Name
+			})
+		}
 
-		Logger.Infof("Download complete: %d sources processed, %d successful (%d downloaded, %d skipped), %d failed",
-			totalSources, successCount, downloadedCount, successCount-downloadedCount, failCount)
+		Logger.Infof(
+			"Download complete: %d sources processed, %d successful (%d downloaded, %d skipped), %d failed, %d quarantined",
+			totalSources, successCount, downloadedCount, successCount-downloadedCount, failCount, quarantinedCount)
 
 		_, err := u.SaveSummaries(Logger, summaries, summaryFile, c.DownloadSummaryLessFunc)
 		if err != nil {
 			Logger.Errorf("Saving summaries error: %v", err)
 		}
+		return ctx.Err()
 	},
 }
 
+// selectDownloader returns the downloader named by the downloader option of source,
+// else the one registered under the source name, else the default downloader.
+func selectDownloader(source cfg.Source) (d.Downloader, error) {
+	if source.Downloader != "" {
+		downloader, exists := d.GetDownloader(source.Downloader)
+		if !exists {
+			return nil, fmt.Errorf("unknown downloader %s for %s", source.Downloader, source.Name)
+		}
+		return downloader, nil
+	}
+	if downloader, exists := d.GetDownloader(source.Name); exists {
+		return downloader, nil
+	}
+	downloader, exists := d.GetDownloader(d.DefaultDownloaderName())
+	if !exists {
+		return nil, fmt.Errorf("default downloader is not registered")
+	}
+	return downloader, nil
+}
+
+// applyPreviousValidators copies the ETag and Last-Modified validators of the last successful
+// download of the same URL into file, so the download can be made conditional.
+func applyPreviousValidators(logger *multilog.Logger, summaryFile string, file *c.DownloadFile) {
+	prevSummary, err := loadPreviousDownloadSummary(logger, summaryFile, file.Name)
+	if err != nil || prevSummary == nil {
+		return
+	}
+	if prevSummary.URL != file.URL || prevSummary.Error != "" {
+		return
+	}
+	file.ETag = prevSummary.ETag
+	file.LastModified = prevSummary.LastModified
+}
+
+// newAnomalyGuard returns the anomaly guard for a download of source, with the entry count of its previous
+// download of the same URL to compare against, or nil if the guard is disabled.
+func newAnomalyGuard(logger *multilog.Logger, summaryFile string, source cfg.Source) *c.AnomalyGuard {
+	guard := &c.AnomalyGuard{ExpectHTML: expectsHTML(source.Types)}
+	if AppConfig != nil {
+		guard.AnomalyGuardConfig = AppConfig.DNSToolkit.AnomalyGuard
+	}
+	if guard.Disabled {
+		return nil
+	}
+	prevSummary, err := loadPreviousDownloadSummary(logger, summaryFile, source.Name)
+	if err == nil && prevSummary != nil && prevSummary.URL == source.URL {
+		guard.PreviousEntries = prevSummary.EntryCount
+	}
+	return guard
+}
+
+// expectsHTML reports whether any of the types of a source is scraped from an HTML page.
+func expectsHTML(types []c.SourceType) bool {
+	for _, sourceType := range types {
+		if strings.Contains(sourceType.Name, "html") {
+			return true
+		}
+	}
+	return false
+}
+
+// quarantineDownloadSummary returns the summary of a download rejected by the anomaly guard:
+// the previous summary of the source, which describes the last-known-good copy that was kept, flagged as quarantined.
+// Without a previous successful download, the rejection is recorded as an error.
+func quarantineDownloadSummary(
+	logger *multilog.Logger,
+	summaryFile string,
+	summary c.DownloadSummary,
+	anomalyErr *d.AnomalyError,
+) c.DownloadSummary {
+	logger.Warnf("Quarantining source %s: %s", summary.Name, anomalyErr.Reason)
+	prevSummary, err := loadPreviousDownloadSummary(logger, summaryFile, summary.Name)
+	if err == nil && prevSummary != nil && prevSummary.Error == "" {
+		summary = *prevSummary
+		summary.LastCheckedTimestamp = u.GetTimestamp()
+	} else {
+		summary.Error = anomalyErr.Error()
+	}
+	summary.Quarantined = true
+	summary.QuarantineReason = anomalyErr.Reason
+	return summary
+}
+
 // loadPreviousDownloadSummaries loads the existing download summaries from the summary file
 func loadPreviousDownloadSummary(
 	logger *multilog.Logger,
@@ -287,6 +440,23 @@ func loadPreviousDownloadSummary(
 	return &summary, nil
 }
 
+// newHostLimiter creates the per-host limits and circuit breaker shared by the downloads of a run.
+func newHostLimiter() *c.HostLimiter {
+	if AppConfig == nil {
+		return c.NewHostLimiter(nil, constants.DefaultCircuitBreakerFailures)
+	}
+	return c.NewHostLimiter(AppConfig.DNSToolkit.HostLimits, AppConfig.DNSToolkit.GetCircuitBreakerFailures())
+}
+
+// sourceHost returns the host a source is downloaded from, or an empty string for local sources.
+func sourceHost(rawURL string) string {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsedURL.Scheme == "file" {
+		return ""
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
+
 func init() {
 	downloadCmd.Flags().Bool("force", false, "Force re-download of all sources (ignores existing summaries)")
 }
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/config"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
 	d "github.com/phani-kb/dns-toolkit/internal/downloaders"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestDownloadCommand tests the download command's Run function
@@ -22,8 +27,8 @@ func TestDownloadCommand(t *testing.T) {
 
 	cmd := downloadCmd
 
-	runFunc := downloadCmd.Run
-	runFunc(cmd, []string{})
+	runFunc := downloadCmd.RunE
+	assert.NoError(t, runFunc(cmd, []string{}))
 
 	_, err := os.Stat(constants.DownloadDir)
 	assert.NoError(t, err, "Download directory should exist")
@@ -51,8 +56,24 @@ func TestDownloadCommand_WithSources(t *testing.T) {
 	SourcesConfigs = []config.SourcesConfig{sourceConfig}
 	defer func() { SourcesConfigs = oldSources }()
 
-	runFunc := downloadCmd.Run
-	runFunc(downloadCmd, []string{})
+	runFunc := downloadCmd.RunE
+	assert.NoError(t, runFunc(downloadCmd, []string{}))
+}
+
+// TestDownloadCommand_Cancelled tests that an interrupted download fails with the cancellation error
+func TestDownloadCommand_Cancelled(t *testing.T) {
+	InitForTesting()
+
+	oldSources := SourcesConfigs
+	SourcesConfigs = []config.SourcesConfig{}
+	defer func() { SourcesConfigs = oldSources }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	downloadCmd.SetContext(ctx)
+	defer downloadCmd.SetContext(context.Background())
+
+	assert.ErrorIs(t, downloadCmd.RunE(downloadCmd, []string{}), context.Canceled)
 }
 
 // TestValidateAndInitDownloader tests downloader initialization logic
@@ -62,8 +83,8 @@ func TestValidateAndInitDownloader(t *testing.T) {
 	oldConfig := AppConfig
 	AppConfig = nil
 
-	runFunc := downloadCmd.Run
-	runFunc(downloadCmd, []string{})
+	runFunc := downloadCmd.RunE
+	assert.NoError(t, runFunc(downloadCmd, []string{}))
 
 	AppConfig = oldConfig
 }
@@ -76,8 +97,8 @@ func TestDownloadCommand_DownloaderRegistration(t *testing.T) {
 	SourcesConfigs = []config.SourcesConfig{}
 	defer func() { SourcesConfigs = oldSources }()
 
-	runFunc := downloadCmd.Run
-	runFunc(downloadCmd, []string{})
+	runFunc := downloadCmd.RunE
+	assert.NoError(t, runFunc(downloadCmd, []string{}))
 
 	defaultDownloader, exists := d.GetDownloader("default")
 	assert.True(t, exists, "Default downloader should be registered")
@@ -87,6 +108,41 @@ func TestDownloadCommand_DownloaderRegistration(t *testing.T) {
 	assert.True(t, exists, "Domain top downloader should be registered")
 	assert.NotNil(t, domainTopDownloader, "Domain top downloader should not be nil")
 	assert.Equal(t, "tranco", domainTopDownloader.Name(), "Domain top downloader should have correct name")
+
+	execDownloader, exists := d.GetDownloader("exec")
+	assert.True(t, exists, "Exec downloader should be registered")
+	assert.Equal(t, "exec", execDownloader.Name(), "Exec downloader should have correct name")
+}
+
+// TestSelectDownloader tests that the downloader option of a source takes precedence over its name
+func TestSelectDownloader(t *testing.T) {
+	InitForTesting()
+
+	oldSources := SourcesConfigs
+	SourcesConfigs = []config.SourcesConfig{}
+	defer func() { SourcesConfigs = oldSources }()
+	assert.NoError(t, downloadCmd.RunE(downloadCmd, []string{}))
+
+	tests := []struct {
+		source  config.Source
+		want    string
+		wantErr bool
+	}{
+		{source: config.Source{Name: "list"}, want: "default"},
+		{source: config.Source{Name: "tranco"}, want: "tranco"},
+		{source: config.Source{Name: "portal", Downloader: "exec"}, want: "exec"},
+		{source: config.Source{Name: "tranco", Downloader: "default"}, want: "default"},
+		{source: config.Source{Name: "portal", Downloader: "ftp"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		downloader, err := selectDownloader(tt.source)
+		if tt.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, downloader.Name(), tt.source.Name)
+	}
 }
 
 // TestDownloadCommand_WithRetryConfiguration tests downloader initialization with retry settings
@@ -107,8 +163,8 @@ func TestDownloadCommand_WithRetryConfiguration(t *testing.T) {
 	defer func() { SourcesConfigs = oldSources }()
 
 	// Run the download command
-	runFunc := downloadCmd.Run
-	runFunc(downloadCmd, []string{})
+	runFunc := downloadCmd.RunE
+	assert.NoError(t, runFunc(downloadCmd, []string{}))
 
 	// Verify directories are created
 	_, err := os.Stat(constants.DownloadDir)
@@ -117,3 +173,95 @@ func TestDownloadCommand_WithRetryConfiguration(t *testing.T) {
 	_, err = os.Stat(constants.SummaryDir)
 	assert.NoError(t, err, "Summary directory should exist")
 }
+
+// TestApplyPreviousValidators tests that validators are only reused for the same URL after a successful download
+func TestApplyPreviousValidators(t *testing.T) {
+	logger := multilog.NewLogger()
+	summaryFile := filepath.Join(t.TempDir(), "download_summary.json")
+
+	summaries := []common.DownloadSummary{
+		{Name: "ok", URL: "http://example.com/ok.txt", ETag: `"abc"`, LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{Name: "failed", URL: "http://example.com/failed.txt", ETag: `"def"`, Error: "HTTP 500"},
+	}
+	_, err := u.SaveSummaries(logger, summaries, summaryFile, common.DownloadSummaryLessFunc)
+	require.NoError(t, err)
+
+	file := common.DownloadFile{Name: "ok", URL: "http://example.com/ok.txt"}
+	applyPreviousValidators(logger, summaryFile, &file)
+	assert.Equal(t, `"abc"`, file.ETag)
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", file.LastModified)
+
+	changed := common.DownloadFile{Name: "ok", URL: "http://example.com/moved.txt"}
+	applyPreviousValidators(logger, summaryFile, &changed)
+	assert.Empty(t, changed.ETag, "validators should not be reused when the URL changed")
+
+	failed := common.DownloadFile{Name: "failed", URL: "http://example.com/failed.txt"}
+	applyPreviousValidators(logger, summaryFile, &failed)
+	assert.Empty(t, failed.ETag, "validators should not be reused after a failed download")
+
+	missing := common.DownloadFile{Name: "missing", URL: "http://example.com/missing.txt"}
+	applyPreviousValidators(logger, summaryFile, &missing)
+	assert.Empty(t, missing.ETag)
+}
+
+func TestSourceHost(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "raw.githubusercontent.com", sourceHost("https://Raw.GitHubUserContent.com/a/list.txt"))
+	assert.Equal(t, "example.com", sourceHost(" http://example.com:8080/list.txt "))
+	assert.Equal(t, "", sourceHost("file:///tmp/list.txt"))
+	assert.Equal(t, "", sourceHost(""))
+}
+
+func TestNewAnomalyGuard(t *testing.T) {
+	logger := multilog.NewLogger()
+	summaryFile := filepath.Join(t.TempDir(), "download_summary.json")
+
+	summaries := []common.DownloadSummary{
+		{Name: "list", URL: "http://example.com/list.txt", EntryCount: 5000},
+	}
+	_, err := u.SaveSummaries(logger, summaries, summaryFile, common.DownloadSummaryLessFunc)
+	require.NoError(t, err)
+
+	source := config.Source{
+		Name:  "list",
+		URL:   "http://example.com/list.txt",
+		Types: []common.SourceType{{Name: constants.SourceTypeDomain}},
+	}
+	guard := newAnomalyGuard(logger, summaryFile, source)
+	require.NotNil(t, guard)
+	assert.Equal(t, 5000, guard.PreviousEntries)
+	assert.False(t, guard.ExpectHTML)
+
+	source.URL = "http://example.com/moved.txt"
+	guard = newAnomalyGuard(logger, summaryFile, source)
+	require.NotNil(t, guard)
+	assert.Zero(t, guard.PreviousEntries, "counts should not be compared when the URL changed")
+
+	source.Types = []common.SourceType{{Name: constants.SourceTypeDomainCustomHtmlCcam}}
+	assert.True(t, newAnomalyGuard(logger, summaryFile, source).ExpectHTML)
+}
+
+func TestQuarantineDownloadSummary(t *testing.T) {
+	logger := multilog.NewLogger()
+	summaryFile := filepath.Join(t.TempDir(), "download_summary.json")
+
+	summaries := []common.DownloadSummary{
+		{Name: "list", URL: "http://example.com/list.txt", Filepath: "data/download/list.txt", EntryCount: 5000},
+	}
+	_, err := u.SaveSummaries(logger, summaries, summaryFile, common.DownloadSummaryLessFunc)
+	require.NoError(t, err)
+	anomalyErr := &d.AnomalyError{URL: "http://example.com/list.txt", Reason: "entry count dropped by 99% from 5000 to 3"}
+
+	summary := quarantineDownloadSummary(logger, summaryFile, common.DownloadSummary{Name: "list"}, anomalyErr)
+	assert.True(t, summary.Quarantined)
+	assert.Equal(t, anomalyErr.Reason, summary.QuarantineReason)
+	assert.Empty(t, summary.Error)
+	assert.Equal(t, "data/download/list.txt", summary.Filepath, "the last-known-good copy should be described")
+	assert.Equal(t, 5000, summary.EntryCount)
+	assert.NotEmpty(t, summary.LastCheckedTimestamp)
+
+	summary = quarantineDownloadSummary(logger, summaryFile, common.DownloadSummary{Name: "new"}, anomalyErr)
+	assert.True(t, summary.Quarantined)
+	assert.Equal(t, anomalyErr.Error(), summary.Error, "without a previous copy the rejection is an error")
+}
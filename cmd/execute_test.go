@@ -54,14 +54,14 @@ func TestExecuteCommands(t *testing.T) {
 		versionCmd.Run(versionCmd, []string{})
 	}
 
-	assert.NotNil(t, downloadCmd.Run)
+	assert.NotNil(t, downloadCmd.RunE)
 
-	if downloadCmd.Run != nil {
-		downloadCmd.Run(downloadCmd, []string{})
+	if downloadCmd.RunE != nil {
+		_ = downloadCmd.RunE(downloadCmd, []string{})
 	}
 
-	if processCmd.Run != nil {
-		processCmd.Run(processCmd, []string{})
+	if processCmd.RunE != nil {
+		_ = processCmd.RunE(processCmd, []string{})
 	}
 
 	if sourceTypesSummaryCmd.Run != nil {
@@ -72,20 +72,20 @@ func TestExecuteCommands(t *testing.T) {
 		validateSourcesCmd.Run(validateSourcesCmd, []string{})
 	}
 
-	if consolidateCmd.Run != nil {
-		consolidateCmd.Run(consolidateCmd, []string{})
+	if consolidateCmd.RunE != nil {
+		_ = consolidateCmd.RunE(consolidateCmd, []string{})
 	}
 
-	if overlapCmd.Run != nil {
-		overlapCmd.Run(overlapCmd, []string{})
+	if overlapCmd.RunE != nil {
+		_ = overlapCmd.RunE(overlapCmd, []string{})
 	}
 
-	if topEntriesCmd.Run != nil {
-		topEntriesCmd.Run(topEntriesCmd, []string{})
+	if topEntriesCmd.RunE != nil {
+		_ = topEntriesCmd.RunE(topEntriesCmd, []string{})
 	}
 
-	if archiveCmd.Run != nil {
-		archiveCmd.Run(archiveCmd, []string{})
+	if archiveCmd.RunE != nil {
+		_ = archiveCmd.RunE(archiveCmd, []string{})
 	}
 
 	if generateCmd.Run != nil {
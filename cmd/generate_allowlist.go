@@ -52,6 +52,7 @@ func strFormat(s string) string {
 func generateAllowlist(logger *multilog.Logger) {
 	domainsFile := constants.AllowlistFilesMap[constants.SourceTypeDomain]
 	ipv4File := constants.AllowlistFilesMap[constants.SourceTypeIpv4]
+	ipv6File := constants.AllowlistFilesMap[constants.SourceTypeIpv6]
 	adguardFile := constants.AllowlistFilesMap[constants.SourceTypeAdguard]
 	customALDomainsFile := constants.CustomAllowlistFilesMap[constants.SourceTypeDomain]
 
@@ -80,6 +81,14 @@ func generateAllowlist(logger *multilog.Logger) {
 	}
 	logger.Info("IPv4 addresses written to file", "file", ipv4File)
 
+	customIPv6File := constants.CustomAllowlistFilesMap[constants.SourceTypeIpv6]
+	customIPv6 := loadCustomElements(logger, customIPv6File)
+	if err := generateIPv6Addresses(logger, ipv6File, customIPv6, customDomains, sourceDomains); err != nil {
+		logger.Error("Failed to generate IPv6 addresses", "error", err)
+		return
+	}
+	logger.Info("IPv6 addresses written to file", "file", ipv6File)
+
 	backupExistingFiles(logger, overwrite)
 }
 
@@ -101,6 +110,7 @@ func backupExistingFiles(logger *multilog.Logger, overwrite bool) {
 	filesToBackup := []string{
 		constants.CustomAllowlistFilesMap[constants.SourceTypeDomain],
 		constants.CustomAllowlistFilesMap[constants.SourceTypeIpv4],
+		constants.CustomAllowlistFilesMap[constants.SourceTypeIpv6],
 		constants.CustomAllowlistFilesMap[constants.SourceTypeAdguard],
 	}
 
@@ -285,31 +295,61 @@ func generateIPv4Addresses(
 	logger *multilog.Logger,
 	filename string,
 	customIPs, customDomains, sourceDomains []string,
+) error {
+	return generateIPAddresses(logger, filename, customIPs, customDomains, sourceDomains, getResolvedIPs)
+}
+
+func generateIPv6Addresses(
+	logger *multilog.Logger,
+	filename string,
+	customIPs, customDomains, sourceDomains []string,
+) error {
+	return generateIPAddresses(logger, filename, customIPs, customDomains, sourceDomains, getResolvedIPv6s)
+}
+
+func generateIPAddresses(
+	logger *multilog.Logger,
+	filename string,
+	customIPs, customDomains, sourceDomains []string,
+	resolve func(*multilog.Logger, []string) []string,
 ) error {
 	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	resolvedCustomIPs := getResolvedIPs(logger, customDomains)
+	resolvedCustomIPs := resolve(logger, customDomains)
 
 	logger.Info("Existing custom IPs", "count", len(customIPs))
 	customIPs = append(customIPs, resolvedCustomIPs...)
 	customIPs = utils.RemoveDuplicates(customIPs)
 
-	resolvedIPs := getResolvedIPs(logger, sourceDomains)
+	resolvedIPs := resolve(logger, sourceDomains)
 	uniqueIPs := utils.RemoveDuplicates(resolvedIPs)
 
 	return writeAllowlistWithStructure(logger, filename, customIPs, uniqueIPs, strFormat)
 }
 
 func getResolvedIPs(logger *multilog.Logger, domains []string) []string {
+	return resolveIPs(logger, domains, "IPv4", utils.ResolveDomainsToIPv4)
+}
+
+func getResolvedIPv6s(logger *multilog.Logger, domains []string) []string {
+	return resolveIPs(logger, domains, "IPv6", utils.ResolveDomainsToIPv6)
+}
+
+func resolveIPs(
+	logger *multilog.Logger,
+	domains []string,
+	ipVersion string,
+	resolve func(*multilog.Logger, []string) ([]string, []string),
+) []string {
 	totalDomains := len(domains)
-	logger.Infof("Resolving IPv4 addresses for %v domains...", totalDomains)
+	logger.Infof("Resolving %s addresses for %v domains...", ipVersion, totalDomains)
 
-	resolvedIPs, failedDomains := utils.ResolveDomainsToIPv4(logger, domains)
+	resolvedIPs, failedDomains := resolve(logger, domains)
 
-	logger.Infof("Resolved IPv4 addresses count: %v", len(resolvedIPs))
+	logger.Infof("Resolved %s addresses count: %v", ipVersion, len(resolvedIPs))
 	if len(failedDomains) > 0 {
 		logger.Warnf("Failed to resolve %v domains", len(failedDomains))
 		for _, domain := range failedDomains {
@@ -96,6 +96,16 @@ func TestGenerateIPv4Addresses(t *testing.T) {
 	}
 }
 
+func TestGenerateIPv6Addresses(t *testing.T) {
+	logger, _ := multilog.NewTestLogger(t)
+	file := "test_ipv6.txt"
+	defer os.Remove(file)
+	err := generateIPv6Addresses(logger, file, []string{"2001:db8::1"}, []string{"a.com"}, []string{"b.com"})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestGetResolvedIPs(t *testing.T) {
 	logger, _ := multilog.NewTestLogger(t)
 	ips := getResolvedIPs(logger, []string{"localhost"})
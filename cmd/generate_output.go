@@ -3,10 +3,12 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,6 +27,7 @@ import (
 var (
 	includeIgnored bool
 	deleteFolders  bool
+	domainFormats  []string
 )
 
 // prepareDirectories creates necessary output directories
@@ -420,9 +423,145 @@ func processRegularFiles(
 		}
 
 		Logger.Debug("Successfully generated output file", "path", outputFilePath, "from", filePath)
+
+		if listType == constants.ListTypeBlocklist && isDomainFileName(fileName) {
+			for _, format := range domainFormats {
+				err := createDomainFormatOutput(
+					tmpl,
+					staticTemplate,
+					filePath,
+					format,
+					description,
+					fileCount[filePath],
+					originalCount,
+					filteredCount,
+					outputFilePath,
+					files,
+				)
+				if err != nil {
+					Logger.Error("Failed to create domain format output file", "format", format, "error", err)
+				}
+			}
+		}
 	}
 }
 
+// isDomainFileName checks if a consolidated or top file holds domain entries, by its name
+func isDomainFileName(fileName string) bool {
+	return strings.HasPrefix(fileName, constants.SourceTypeDomain+"_") ||
+		strings.Contains(fileName, "_"+constants.SourceTypeDomain+"_")
+}
+
+// createDomainFormatOutput creates the output file of a domain blocklist rendered as the rules of a format,
+// next to the output file of the list, with the format appended to its name
+func createDomainFormatOutput(
+	tmpl *template.Template,
+	staticTemplate []byte,
+	filePath string,
+	format string,
+	description string,
+	count int,
+	originalCount int,
+	filteredCount int,
+	outputPath string,
+	files string,
+) error {
+	renderedPath, err := renderDomainRulesFile(filePath, format)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.Remove(renderedPath); err != nil {
+			Logger.Error("Failed to remove rendered file", "file", renderedPath, "error", err)
+		}
+	}()
+
+	ext := filepath.Ext(outputPath)
+	formatOutputPath := strings.TrimSuffix(outputPath, ext) + "_" + format + ext
+	return createOutputFromFile(
+		tmpl,
+		staticTemplate,
+		renderedPath,
+		filepath.Base(formatOutputPath),
+		fmt.Sprintf("%s (%s rules)", description, format),
+		count,
+		originalCount,
+		filteredCount,
+		formatOutputPath,
+		files,
+	)
+}
+
+// renderDomainRulesFile writes the rules of the domains of a file in a format to a temporary file,
+// with the modification time of the file, and returns its path.
+// The domains listed in the subdomains file of the file, and the *. processed entries of the top files,
+// are rendered as rules matching the subdomains as well.
+func renderDomainRulesFile(filePath, format string) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	subdomains, err := readSubdomains(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	var rules []string
+	for _, entry := range strings.Split(string(content), "\n") {
+		if entry = strings.TrimSpace(entry); entry == "" || u.IsComment(entry) {
+			continue
+		}
+		domain, semantics, _ := u.ParseDomainEntry(entry)
+		if subdomains.Contains(domain) {
+			semantics = constants.MatchSemanticsSubdomains
+		}
+		rule, err := u.RenderDomainRule(format, domain, semantics)
+		if err != nil {
+			if errors.Is(err, u.ErrUnsupportedDomainFormat) {
+				return "", err
+			}
+			Logger.Debug("Skipping entry", "entry", entry, "error", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	renderedFile, err := os.CreateTemp("", "rendered-"+format+"-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create rendered file: %w", err)
+	}
+	renderedPath := renderedFile.Name()
+	_, err = renderedFile.WriteString(strings.Join(rules, "\n") + "\n")
+	if closeErr := renderedFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chtimes(renderedPath, info.ModTime(), info.ModTime())
+	}
+	if err != nil {
+		_ = os.Remove(renderedPath)
+		return "", fmt.Errorf("failed to write rendered file: %w", err)
+	}
+	return renderedPath, nil
+}
+
+// readSubdomains reads the domains of a domain file that also match their subdomains,
+// an empty set if the file has no subdomains file
+func readSubdomains(filePath string) (u.StringSet, error) {
+	entries, _, err := u.ReadEntriesFromFile(Logger, u.SubdomainsFilepath(filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return u.NewStringSet([]string{}), nil
+		}
+		return nil, fmt.Errorf("failed to read subdomains file: %w", err)
+	}
+	return u.NewStringSet(entries), nil
+}
+
 // processIgnoredFiles processes and generates output for ignored files
 func processIgnoredFiles(
 	tmpl *template.Template,
@@ -485,32 +624,37 @@ var generateCmd = &cobra.Command{
 }
 
 var generateOutputCmd = &cobra.Command{
-	Use:   "output",
-	Short: "Generate output files with templates prefixed to them",
-	Long:  "Generate output files with static and dynamic templates prefixed to the summary types defined in SummaryTypesWithTemplateMap", // nolint:lll
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "output",
+	Short:        "Generate output files with templates prefixed to them",
+	Long:         "Generate output files with static and dynamic templates prefixed to the summary types defined in SummaryTypesWithTemplateMap", // nolint:lll
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if os.Getenv("DNS_TOOLKIT_TEST_MODE") == "true" {
-			return
+			return nil
 		}
 
 		Logger.Info("Starting generate prefixes command...")
 
+		for _, format := range domainFormats {
+			if !slices.Contains(constants.DomainFormats, format) {
+				return fmt.Errorf("unsupported domain format: %s, supported formats: %s",
+					format, strings.Join(constants.DomainFormats, ", "))
+			}
+		}
+
 		if err := u.EnsureDirectoryExists(Logger, constants.OutputDir); err != nil {
-			Logger.Errorf("Failed to create output directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create output directory: %w", err)
 		}
 
 		// Prepare directories
 		if err := prepareDirectories(); err != nil {
-			Logger.Error("Failed to prepare directories", "error", err)
-			return
+			return fmt.Errorf("failed to prepare directories: %w", err)
 		}
 
 		// Load templates
 		tmpl, staticTemplate, err := loadTemplates()
 		if err != nil {
-			Logger.Error("Failed to load templates", "error", err)
-			return
+			return fmt.Errorf("failed to load templates: %w", err)
 		}
 
 		processedSummaryFiles := make(map[string]string)
@@ -568,6 +712,7 @@ var generateOutputCmd = &cobra.Command{
 		deleteFilesAndFoldersAfterGeneration()
 
 		Logger.Info("Finished generate prefixes command.")
+		return nil
 	},
 }
 
@@ -763,4 +908,8 @@ func init() {
 	// Add the deleteFolders flag
 	generateOutputCmd.Flags().BoolVarP(&deleteFolders, "delete-folders", "d", false,
 		"Delete source folders after output generation")
+
+	// Add the domainFormats flag
+	generateOutputCmd.Flags().StringSliceVar(&domainFormats, "domain-formats", nil,
+		"Also render the domain blocklists as the rules of these formats: "+strings.Join(constants.DomainFormats, ", "))
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,7 +31,7 @@ func TestGenerateOutputCmdRun(t *testing.T) {
 		}
 	}()
 
-	generateOutputCmd.Run(generateOutputCmd, []string{})
+	assert.NoError(t, generateOutputCmd.RunE(generateOutputCmd, []string{}))
 }
 
 func TestGenerateDescription(t *testing.T) {
@@ -306,6 +307,56 @@ func TestProcessRegularFiles(t *testing.T) {
 	assert.Contains(t, contentStr, inputContent)
 }
 
+func TestProcessRegularFiles_DomainFormats(t *testing.T) {
+	origDomainFormats := domainFormats
+	domainFormats = []string{constants.DomainFormatAdguard, constants.DomainFormatUnbound}
+	defer func() { domainFormats = origDomainFormats }()
+
+	tempDir := t.TempDir()
+	origOutputDir := constants.SummaryTypesOutputDirMap["domainformats"]
+	constants.SummaryTypesOutputDirMap["domainformats"] = tempDir
+	defer func() { constants.SummaryTypesOutputDirMap["domainformats"] = origOutputDir }()
+
+	consolidatedDir := t.TempDir()
+	blocklistFile := filepath.Join(consolidatedDir, "domain_blocklist.txt")
+	allowlistFile := filepath.Join(consolidatedDir, "domain_allowlist.txt")
+	require.NoError(t, os.WriteFile(blocklistFile, []byte("ads.example.com\ntracker.example.com\n"), 0644))
+	require.NoError(t, os.WriteFile(u.SubdomainsFilepath(blocklistFile), []byte("tracker.example.com\n"), 0644))
+	require.NoError(t, os.WriteFile(allowlistFile, []byte("example.org\n"), 0644))
+	require.NoError(t, os.WriteFile(u.SubdomainsFilepath(allowlistFile), []byte("example.org\n"), 0644))
+
+	dynTmpl, err := template.New("dynamic").Parse("Header: {{.FileName}} - {{.Description}} - {{.Count}}")
+	require.NoError(t, err)
+
+	processRegularFiles(
+		dynTmpl,
+		[]byte("STATIC HEADER"),
+		"domainformats",
+		map[string]string{blocklistFile: "blocklist", allowlistFile: "allowlist"},
+		map[string]int{blocklistFile: 2, allowlistFile: 1},
+		map[string][]common.FileInfo{},
+		map[string]int{},
+		map[string]int{},
+	)
+
+	adguardContent, err := os.ReadFile(filepath.Join(tempDir, "domain_blocklist_adguard.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(adguardContent), "Header: domain_blocklist_adguard.txt")
+	assert.Contains(t, string(adguardContent), "(adguard rules) - 2")
+	assert.Contains(t, string(adguardContent), "|ads.example.com^\n||tracker.example.com^\n")
+
+	unboundContent, err := os.ReadFile(filepath.Join(tempDir, "domain_blocklist_unbound.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(unboundContent), `local-data: "ads.example.com. A 0.0.0.0"`)
+	assert.Contains(t, string(unboundContent), `local-zone: "tracker.example.com." always_nxdomain`)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "domain_blocklist.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ads.example.com\ntracker.example.com\n")
+	assert.NotContains(t, string(content), constants.WildcardDomainPrefix, "the default output lists plain domains")
+	assert.NoFileExists(t, filepath.Join(tempDir, "domain_allowlist_adguard.txt"), "allowlists are not rendered")
+}
+
 func TestProcessIgnoredFiles(t *testing.T) {
 	origIncludeIgnored := includeIgnored
 	defer func() { includeIgnored = origIncludeIgnored }()
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	invalidReportSource  string
+	invalidReportSamples int
+)
+
+// invalidReasonReport is the number of invalid entries of a source with a reason code.
+type invalidReasonReport struct {
+	reason  string
+	count   int
+	samples []string // first invalid lines with the reason
+}
+
+// invalidSourceReport is the analysis of the invalid entries of a source.
+type invalidSourceReport struct {
+	name    string
+	total   int
+	reasons []invalidReasonReport // by descending count
+}
+
+var invalidReportCmd = &cobra.Command{
+	Use:   "invalid-report",
+	Short: "Report why the entries of the sources were rejected by processing",
+	Long: `Report the invalid entries of the last processing by source and reason, to find the sources
+and processors needing a fix.
+
+The reasons are the codes counted when the entries were processed, such as single_label, port or
+non_sinkhole: the code given by the processor that rejected an entry, or the diagnosis of the entry
+as an entry of its type. The sample lines of each reason are kept with the processed summary, the
+invalid files of an older summary without them are diagnosed again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles["processed"])
+		summaries := readSummaries[c.ProcessedSummary](Logger, summaryFile)
+		if len(summaries) == 0 {
+			Logger.Errorf("No processed summaries found in %s", summaryFile)
+			os.Exit(1)
+		}
+		reports := buildInvalidReport(Logger, summaries, invalidReportSource, invalidReportSamples)
+		logInvalidReport(Logger, reports)
+	},
+}
+
+func init() {
+	invalidReportCmd.Flags().StringVar(&invalidReportSource, "source", "", "Only report the source with this name")
+	invalidReportCmd.Flags().IntVar(&invalidReportSamples, "samples", 3, "Number of sample lines shown per reason")
+}
+
+// buildInvalidReport aggregates the invalid entries of the processed summaries by source and reason code.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - summaries: The processed summaries
+//   - sourceName: Name of the only source to report, all sources if empty
+//   - maxSamples: Number of sample lines kept per reason
+//
+// Returns:
+//   - The reports of the sources with invalid entries, by descending number of invalid entries
+func buildInvalidReport(
+	logger *multilog.Logger,
+	summaries []c.ProcessedSummary,
+	sourceName string,
+	maxSamples int,
+) []invalidSourceReport {
+	counts := make(map[string]map[string]int)
+	samples := make(map[string]map[string][]string)
+
+	for _, summary := range summaries {
+		if sourceName != "" && summary.Name != sourceName {
+			continue
+		}
+		for _, file := range summary.InvalidFiles {
+			if counts[summary.Name] == nil {
+				counts[summary.Name] = make(map[string]int)
+				samples[summary.Name] = make(map[string][]string)
+			}
+			for reason, count := range file.InvalidReasons {
+				counts[summary.Name][reason] += count
+			}
+			if len(file.InvalidReasons) > 0 && (maxSamples <= 0 || file.InvalidSamples != nil) {
+				for reason, lines := range file.InvalidSamples {
+					for _, line := range lines {
+						if len(samples[summary.Name][reason]) < maxSamples {
+							samples[summary.Name][reason] = append(samples[summary.Name][reason], line)
+						}
+					}
+				}
+				continue
+			}
+			lines, err := readInvalidLines(logger, file.Filepath)
+			if err != nil {
+				logger.Warnf("Reading invalid file error: %v (file: %s)", err, file.Filepath)
+				continue
+			}
+			for _, line := range lines {
+				reason := u.InvalidEntryReason(file.GenericSourceType, line)
+				if len(file.InvalidReasons) == 0 {
+					counts[summary.Name][reason]++
+				}
+				if len(samples[summary.Name][reason]) < maxSamples {
+					samples[summary.Name][reason] = append(samples[summary.Name][reason], line)
+				}
+			}
+		}
+	}
+
+	reports := make([]invalidSourceReport, 0, len(counts))
+	for name, reasonCounts := range counts {
+		report := invalidSourceReport{name: name}
+		for reason, count := range reasonCounts {
+			report.total += count
+			report.reasons = append(report.reasons, invalidReasonReport{
+				reason:  reason,
+				count:   count,
+				samples: samples[name][reason],
+			})
+		}
+		if report.total == 0 {
+			continue
+		}
+		sort.Slice(report.reasons, func(i, j int) bool {
+			if report.reasons[i].count != report.reasons[j].count {
+				return report.reasons[i].count > report.reasons[j].count
+			}
+			return report.reasons[i].reason < report.reasons[j].reason
+		})
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].total != reports[j].total {
+			return reports[i].total > reports[j].total
+		}
+		return reports[i].name < reports[j].name
+	})
+	return reports
+}
+
+// readInvalidLines returns the lines of an invalid file in file order.
+func readInvalidLines(logger *multilog.Logger, filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer u.CloseFile(logger, file)
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// logInvalidReport logs the invalid entries of each source by reason, with their sample lines.
+func logInvalidReport(logger *multilog.Logger, reports []invalidSourceReport) {
+	total := 0
+	for _, report := range reports {
+		total += report.total
+		logger.Infof("%s: %d invalid", report.name, report.total)
+		for _, reason := range report.reasons {
+			logger.Infof("  %s: %d", reason.reason, reason.count)
+			for _, sample := range reason.samples {
+				logger.Infof("    %s", sample)
+			}
+		}
+	}
+	logger.Infof("Invalid entries: %d in %d source(s)", total, len(reports))
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInvalidReport(t *testing.T) {
+	logger, _ := multilog.NewTestLogger(t)
+	tempDir := t.TempDir()
+
+	domainInvalid := filepath.Join(tempDir, "domains_invalid.txt")
+	require.NoError(t, os.WriteFile(domainInvalid, []byte("localhost\nintranet\n192.0.2.1\nexample.com:80\n"), 0644))
+	ipv4Invalid := filepath.Join(tempDir, "ipv4_invalid.txt")
+	require.NoError(t, os.WriteFile(ipv4Invalid, []byte("example.com\n"), 0644))
+
+	summaries := []c.ProcessedSummary{
+		{
+			Name: "domains",
+			InvalidFiles: []c.ProcessedFile{{
+				GenericSourceType: constants.SourceTypeDomain,
+				Filepath:          domainInvalid,
+				InvalidReasons: map[string]int{
+					constants.InvalidReasonSingleLabel:    2,
+					constants.InvalidReasonIPInDomainList: 1,
+					constants.InvalidReasonPort:           1,
+				},
+			}},
+		},
+		{
+			// a summary of a previous version, whose reasons are diagnosed again
+			Name: "ips",
+			InvalidFiles: []c.ProcessedFile{{
+				GenericSourceType: constants.SourceTypeIpv4,
+				Filepath:          ipv4Invalid,
+			}},
+		},
+		{
+			// the samples kept with the summary are used, the invalid file is not read
+			Name: "hosts",
+			InvalidFiles: []c.ProcessedFile{{
+				GenericSourceType: constants.SourceTypeDomain,
+				Filepath:          filepath.Join(tempDir, "missing_invalid.txt"),
+				InvalidReasons:    map[string]int{constants.InvalidReasonNonSinkhole: 2},
+				InvalidSamples: map[string][]string{
+					constants.InvalidReasonNonSinkhole: {"10.0.0.1 a.example.com", "10.0.0.2 b.example.com"},
+				},
+			}},
+		},
+		{Name: "clean"},
+	}
+
+	reports := buildInvalidReport(logger, summaries, "", 1)
+	require.Len(t, reports, 3)
+	assert.Equal(t, "domains", reports[0].name)
+	assert.Equal(t, 4, reports[0].total)
+	assert.Equal(t, []invalidReasonReport{
+		{reason: constants.InvalidReasonSingleLabel, count: 2, samples: []string{"localhost"}},
+		{reason: constants.InvalidReasonIPInDomainList, count: 1, samples: []string{"192.0.2.1"}},
+		{reason: constants.InvalidReasonPort, count: 1, samples: []string{"example.com:80"}},
+	}, reports[0].reasons)
+	assert.Equal(t, invalidSourceReport{
+		name:    "hosts",
+		total:   2,
+		reasons: []invalidReasonReport{{reason: constants.InvalidReasonNonSinkhole, count: 2, samples: []string{"10.0.0.1 a.example.com"}}},
+	}, reports[1])
+	assert.Equal(t, invalidSourceReport{
+		name:    "ips",
+		total:   1,
+		reasons: []invalidReasonReport{{reason: constants.InvalidReasonDomainInIPList, count: 1, samples: []string{"example.com"}}},
+	}, reports[2])
+
+	reports = buildInvalidReport(logger, summaries, "ips", 0)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "ips", reports[0].name)
+	assert.Empty(t, reports[0].reasons[0].samples)
+
+	logInvalidReport(logger, reports)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"runtime"
 
@@ -23,9 +24,10 @@ var (
 )
 
 var overlapCmd = &cobra.Command{
-	Use:   "overlap",
-	Short: "Find overlap between source files",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "overlap",
+	Short:        "Find overlap between source files",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		overlapMaxWorkers = AppConfig.DNSToolkit.MaxWorkers
 		if overlapMaxWorkers <= 0 {
 			overlapMaxWorkers = runtime.GOMAXPROCS(0)
@@ -62,16 +64,14 @@ var overlapCmd = &cobra.Command{
 		var processedFiles []c.ProcessedFile
 		var genericSourceTypes []string
 		var processedSummaries []c.ProcessedSummary
-		func() {
+		runErr := func() error {
 			defer stopProfiling()
 
 			if err := u.EnsureDirectoryExists(Logger, constants.OverlapDir); err != nil {
-				Logger.Errorf("Failed to create overlap directory: %v", err)
-				os.Exit(1)
+				return fmt.Errorf("failed to create overlap directory: %w", err)
 			}
 			if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
-				Logger.Errorf("Failed to create summary directory: %v", err)
-				os.Exit(1)
+				return fmt.Errorf("failed to create summary directory: %w", err)
 			}
 
 			processedSummaries, genericSourceTypes, processedFiles = cfg.GetProcessedSummaries(
@@ -82,12 +82,12 @@ var overlapCmd = &cobra.Command{
 
 			if len(processedSummaries) == 0 {
 				Logger.Errorf("No processed files found")
-				return
+				return nil
 			}
 
 			if len(genericSourceTypes) == 0 {
 				Logger.Errorf("No generic source types found")
-				return
+				return nil
 			}
 
 			// Create an instance of the overlap service
@@ -95,6 +95,7 @@ var overlapCmd = &cobra.Command{
 
 			// Use the service to write compact overlap summaries
 			_, err := overlapService.WriteCompactOverlapSummaries(
+				commandContext(cmd),
 				Logger,
 				processedFiles,
 				genericSourceTypes,
@@ -103,6 +104,7 @@ var overlapCmd = &cobra.Command{
 			if err != nil {
 				Logger.Errorf("Error writing overlap summaries: %v", err)
 			}
+			return nil
 		}()
 
 		// Now analyze profiles after profiling has stopped
@@ -112,6 +114,7 @@ var overlapCmd = &cobra.Command{
 				OutputDir:       overlapProfileDir,
 			})
 		}
+		return runErr
 	},
 }
 
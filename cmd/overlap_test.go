@@ -12,7 +12,7 @@ func TestOverlapCommand(t *testing.T) {
 	assert.NotNil(t, overlapCmd)
 	assert.Equal(t, "overlap", overlapCmd.Use)
 	assert.Contains(t, overlapCmd.Short, "overlap")
-	assert.NotNil(t, overlapCmd.Run)
+	assert.NotNil(t, overlapCmd.RunE)
 }
 
 func TestOverlapCommandFlags(t *testing.T) {
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
 	"sync"
 
@@ -24,30 +23,41 @@ import (
 // processCmd is the cobra command for processing downloaded files.
 // It extracts and validates content from the files, categorizing them into valid and invalid entries.
 var processCmd = &cobra.Command{
-	Use:   "process",
-	Short: "Process downloaded files",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "process",
+	Short:        "Process downloaded files",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := u.EnsureDirectoryExists(Logger, constants.ProcessedDir); err != nil {
-			Logger.Errorf("Failed to create processed directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create processed directory: %w", err)
 		}
 		if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
-			Logger.Errorf("Failed to create summary directory: %v", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create summary directory: %w", err)
 		}
 
-		ctx := context.Background()
-		processAllSources(ctx, Logger, constants.ProcessedDir)
+		forceProcess, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			Logger.Warnf("Failed to parse --force flag (defaulting to false): %v", err)
+			forceProcess = false
+		}
+
+		ctx := commandContext(cmd)
+		processAllSources(ctx, Logger, constants.ProcessedDir, forceProcess)
+		return ctx.Err()
 	},
 }
 
+func init() {
+	processCmd.Flags().Bool("force", false, "Reprocess all sources even if their downloads have not changed")
+}
+
 // processAllSources processes all downloaded source files.
 //
 // Parameters:
 //   - ctx: Context for cancellation
 //   - logger: Logger for recording operations and errors
 //   - processedDir: Directory to save processed results
-func processAllSources(ctx context.Context, logger *multilog.Logger, processedDir string) {
+//   - force: Reprocess every source, even if it has not changed since the last run
+func processAllSources(ctx context.Context, logger *multilog.Logger, processedDir string, force bool) {
 	dsf := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles["download"])
 	content, err := os.ReadFile(dsf)
 	if err != nil {
@@ -62,13 +72,17 @@ func processAllSources(ctx context.Context, logger *multilog.Logger, processedDi
 		return
 	}
 
+	summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles["processed"])
+	// Previous summaries are loaded even when forced, the anomaly guard compares against them
+	previousSummaries := loadPreviousProcessedSummaries(logger, summaryFile)
+
 	processedSummariesMap := make(map[string]c.ProcessedSummary)
 	var mu sync.Mutex
 
 	// Create a worker pool for controlled concurrency
 	maxWorkers := AppConfig.DNSToolkit.MaxWorkers
 	logger.Infof("Using worker pool with %d worker(s) for processing", maxWorkers)
-	workerPool := c.NewDTWorkerPool(maxWorkers)
+	workerPool := c.NewDTWorkerPoolWithContext(ctx, maxWorkers)
 
 	for _, summary := range downloadSummaries {
 		if summary.Error != "" {
@@ -91,7 +105,28 @@ func processAllSources(ctx context.Context, logger *multilog.Logger, processedDi
 				// Continue processing
 			}
 
-			processedSummaries := processSourceFile(ctx, logger, summary, processedDir)
+			downloadChecksum := getDownloadChecksum(logger, summary)
+			configFingerprint := getConfigFingerprint(summary)
+
+			var processedSummaries []c.ProcessedSummary
+			previous, exists := previousSummaries[summary.Name]
+			// the previous summary is merged across the targets of the source, only those of this target are reused
+			previousTarget := targetProcessedSummary(previous, summary)
+			if !force && exists && isProcessedSummaryReusable(logger, previousTarget, downloadChecksum, configFingerprint) {
+				logger.Infof("Skipping processing for %s: download and configuration unchanged", summary.Name)
+				processedSummaries = []c.ProcessedSummary{previousTarget}
+			} else if exists {
+				processedSummaries = processSourceFileWithGuard(ctx, logger, summary, processedDir, previous)
+			} else {
+				processedSummaries = processSourceFile(ctx, logger, summary, processedDir)
+			}
+
+			for _, processedSummary := range processedSummaries {
+				// A quarantined summary keeps describing the download its files were processed from
+				if !processedSummary.Quarantined {
+					setDownloadChecksum(processedSummary, downloadChecksum)
+				}
+			}
 
 			mu.Lock()
 			for _, processedSummary := range processedSummaries {
@@ -102,6 +137,11 @@ func processAllSources(ctx context.Context, logger *multilog.Logger, processedDi
 					processedSummariesMap[summary.Name] = processedSummary
 				}
 			}
+			if processedSummary, exists := processedSummariesMap[summary.Name]; exists && !processedSummary.Quarantined {
+				processedSummary.ConfigFingerprint = configFingerprint
+				processedSummary.ProcessorVersion = constants.ProcessorVersion
+				processedSummariesMap[summary.Name] = processedSummary
+			}
 			mu.Unlock()
 		})
 	}
@@ -113,13 +153,147 @@ func processAllSources(ctx context.Context, logger *multilog.Logger, processedDi
 		processedSummaries = append(processedSummaries, summary)
 	}
 
-	summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles["processed"])
+	if ctx.Err() != nil {
+		logger.Warnf("Processing cancelled: %v; keeping previous summaries of unfinished sources", ctx.Err())
+		processedSummaries = u.CarryOverSummaries(logger, processedSummaries, summaryFile, func(s c.ProcessedSummary) string {
+			return s.Name
+		})
+	}
+
 	_, err = u.SaveSummaries(logger, processedSummaries, summaryFile, c.ProcessedSummaryLessFunc)
 	if err != nil {
 		logger.Errorf("Saving processed summaries error: %v", err)
 	}
 }
 
+// loadPreviousProcessedSummaries reads the processed summaries of the last run, keyed by source name.
+// A missing or unreadable summary file results in an empty map, which forces full processing.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - summaryFile: Path to the processed summary file
+//
+// Returns:
+//   - A map of source names to their previous ProcessedSummary
+func loadPreviousProcessedSummaries(logger *multilog.Logger, summaryFile string) map[string]c.ProcessedSummary {
+	previous := make(map[string]c.ProcessedSummary)
+	content, err := os.ReadFile(summaryFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Reading previous processed summary error: %v (file: %s)", err, summaryFile)
+		}
+		return previous
+	}
+
+	var summaries []c.ProcessedSummary
+	if err := json.Unmarshal(content, &summaries); err != nil {
+		logger.Warnf("Parsing previous processed summary error: %v", err)
+		return previous
+	}
+	for _, summary := range summaries {
+		previous[summary.Name] = summary
+	}
+	return previous
+}
+
+// getDownloadChecksum returns the checksum of the downloaded file. The checksum recorded in the
+// download summary is used when present; otherwise it is calculated from the file.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - summary: Download summary of the source
+//
+// Returns:
+//   - The checksum of the downloaded file, or empty if it could not be calculated
+func getDownloadChecksum(logger *multilog.Logger, summary c.DownloadSummary) string {
+	if summary.Checksum != "" {
+		return summary.Checksum
+	}
+	if _, err := os.Stat(summary.Filepath); err != nil {
+		return ""
+	}
+	return u.CalculateChecksum(logger, summary.Filepath, AppConfig.DNSToolkit.FilesChecksum.Algorithm)
+}
+
+// getConfigFingerprint returns a fingerprint of the source configuration that affects processing:
+// the enabled types and list types, the categories and the consolidation flags.
+//
+// Parameters:
+//   - summary: Download summary of the source
+//
+// Returns:
+//   - A hex-encoded MD5 hash of the configuration
+func getConfigFingerprint(summary c.DownloadSummary) string {
+	data, err := json.Marshal(struct {
+		Types                       []c.SourceType `json:"types"`
+		Categories                  []string       `json:"categories"`
+		SkipGeneralConsolidation    bool           `json:"skip_general_consolidation"`
+		SkipGroupsConsolidation     bool           `json:"skip_groups_consolidation"`
+		SkipCategoriesConsolidation bool           `json:"skip_categories_consolidation"`
+	}{
+		Types:                       summary.GetSourceTypes(),
+		Categories:                  summary.Categories,
+		SkipGeneralConsolidation:    summary.SkipGeneralConsolidation,
+		SkipGroupsConsolidation:     summary.SkipGroupsConsolidation,
+		SkipCategoriesConsolidation: summary.SkipCategoriesConsolidation,
+	})
+	if err != nil {
+		return ""
+	}
+	hash := md5.Sum(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// isProcessedSummaryReusable checks whether the previous processing result of a download target is still
+// valid: the download checksum of every processed file, the configuration fingerprint and the processor
+// version must match, and every processed file it references must still exist.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - previous: ProcessedSummary recorded by the last run, with only the processed files of the target
+//   - downloadChecksum: Checksum of the current download of the target
+//   - configFingerprint: Fingerprint of the current source configuration
+//
+// Returns:
+//   - true if the previous processed files can be reused, false otherwise
+func isProcessedSummaryReusable(
+	logger *multilog.Logger,
+	previous c.ProcessedSummary,
+	downloadChecksum, configFingerprint string,
+) bool {
+	if downloadChecksum == "" {
+		return false
+	}
+	if previous.ConfigFingerprint != configFingerprint || previous.ProcessorVersion != constants.ProcessorVersion {
+		return false
+	}
+	if len(previous.ValidFiles) == 0 && len(previous.InvalidFiles) == 0 {
+		return false
+	}
+	for _, files := range [][]c.ProcessedFile{previous.ValidFiles, previous.InvalidFiles} {
+		for _, file := range files {
+			if file.DownloadChecksum != downloadChecksum {
+				return false
+			}
+			if _, err := os.Stat(file.Filepath); err != nil {
+				logger.Debugf("Processed file %s of %s is missing; reprocessing", file.Filepath, previous.Name)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// setDownloadChecksum records the checksum of the download the processed files of summary were processed from.
+func setDownloadChecksum(summary c.ProcessedSummary, downloadChecksum string) {
+	for i := range summary.ValidFiles {
+		summary.ValidFiles[i].DownloadChecksum = downloadChecksum
+	}
+	for i := range summary.InvalidFiles {
+		summary.InvalidFiles[i].DownloadChecksum = downloadChecksum
+	}
+}
+
 // processSourceFile processes a single source file.
 // It reads the file content and processes it according to its source types.
 //
@@ -132,71 +306,130 @@ func processAllSources(ctx context.Context, logger *multilog.Logger, processedDi
 // Returns:
 //   - A ProcessedSummary containing information about the processing results
 func processSourceFile(
-	_ context.Context,
+	ctx context.Context,
 	logger *multilog.Logger,
 	summary c.DownloadSummary,
 	processedDir string,
 ) []c.ProcessedSummary {
 	processedSummaries := make([]c.ProcessedSummary, 0)
-	content, err := os.ReadFile(summary.Filepath)
-	if err != nil {
-		logger.Errorf("Reading file error: %v (file: %s)", err, summary.Filepath)
-		return processedSummaries
-	}
-
 	validFiles := make(map[string]c.ProcessedFile)
 	invalidFiles := make(map[string]c.ProcessedFile)
+	fileName := processedFileName(summary)
 
 	for _, sourceTypeObj := range summary.GetSourceTypes() {
 		sourceTypeName := sourceTypeObj.Name
 		for _, listTypeObj := range sourceTypeObj.GetListTypes() {
+			if ctx.Err() != nil {
+				logger.Warnf("Processing cancelled for %s: %v", summary.Name, ctx.Err())
+				return make([]c.ProcessedSummary, 0)
+			}
+
 			listTypeName := listTypeObj.Name
 			mustConsider := listTypeObj.MustConsider
-			validEntries, invalidEntries := extractEntriesByType(logger, string(content), sourceTypeName, listTypeName)
-			validFilePath, invalidFilePath := saveEntries(
-				logger,
+			processorType := sourceTypeName
+			if sourceTypeObj.Processor != nil {
+				// the processor declared by the source is registered for the source only
+				processorType = r.DeclaredProcessorKey(summary.Name, sourceTypeName)
+			}
+			processor, exists := streamProcessorFor(processorType, listTypeName)
+			if !exists {
+				logger.Warnf("Unsupported source type: %s", sourceTypeName)
+				continue
+			}
+			var matchSemantics string
+			if semanticsProcessor, ok := processor.(r.MatchSemanticsProcessor); ok {
+				matchSemantics = semanticsProcessor.MatchSemantics()
+			}
+			validFilePaths := map[string]string{
+				sourceTypeName: filepath.Join(
+					processedDir,
+					generateFileName(logger, fileName, sourceTypeName, listTypeName, "valid"),
+				),
+			}
+			multiTypeProcessor, isMultiType := processor.(r.MultiTypeProcessor)
+			if isMultiType {
+				validFilePaths = make(map[string]string)
+				for _, outputType := range multiTypeProcessor.OutputTypes() {
+					validFilePaths[outputType] = filepath.Join(
+						processedDir,
+						generateFileName(logger, fileName, sourceTypeName+"_"+outputType, listTypeName, "valid"),
+					)
+				}
+			}
+			invalidFilePath := filepath.Join(
 				processedDir,
-				summary.Name,
-				sourceTypeName,
-				listTypeName,
-				validEntries,
-				invalidEntries,
+				generateFileName(logger, fileName, sourceTypeName, listTypeName, "invalid"),
+			)
+			validCounts, invalidCount, invalidReasons, invalidSamples, err := streamEntries(
+				ctx,
+				logger,
+				summary.Filepath,
+				processor,
+				validFilePaths,
+				invalidFilePath,
 			)
+			if err != nil {
+				if ctx.Err() != nil {
+					logger.Warnf("Processing cancelled for %s: %v", summary.Name, ctx.Err())
+				} else {
+					logger.Errorf("Processing file error: %v (file: %s)", err, summary.Filepath)
+				}
+				return make([]c.ProcessedSummary, 0)
+			}
 
 			key := fmt.Sprintf("%s_%s", sourceTypeName, listTypeName)
-			if validFilePath != "" {
-				validFiles[key] = createProcessedFile(
+			validCount := 0
+			for fileType, count := range validCounts {
+				validCount += count
+				if count == 0 {
+					continue
+				}
+				file := createProcessedFile(
 					logger,
 					summary.Name,
-					validFilePath,
+					validFilePaths[fileType],
 					sourceTypeName,
 					listTypeName,
-					validEntries,
+					count,
 					mustConsider,
 					true,
+					matchSemantics,
 					listTypeObj.Groups,
 					summary.Categories,
 					summary.SkipGeneralConsolidation,
 					summary.SkipGroupsConsolidation,
 					summary.SkipCategoriesConsolidation,
 				)
+				file.DownloadFilepath = summary.Filepath
+				fileKey := key
+				if isMultiType {
+					// the entries of a multi-type processor are consolidated with those of their own type
+					file.GenericSourceType = fileType
+					fileKey = key + "_" + fileType
+				}
+				validFiles[fileKey] = file
 			}
-			if invalidFilePath != "" {
-				invalidFiles[key] = createProcessedFile(
+			if invalidCount > 0 {
+				invalidFile := createProcessedFile(
 					logger,
 					summary.Name,
 					invalidFilePath,
 					sourceTypeName,
 					listTypeName,
-					invalidEntries,
+					invalidCount,
 					mustConsider,
 					false,
+					"",
 					listTypeObj.Groups,
 					summary.Categories,
 					summary.SkipGeneralConsolidation,
 					summary.SkipGroupsConsolidation,
 					summary.SkipCategoriesConsolidation,
 				)
+				invalidFile.DownloadFilepath = summary.Filepath
+				invalidFile.InvalidReasons = invalidReasons
+				invalidFile.InvalidSamples = invalidSamples
+				invalidFiles[key] = invalidFile
 			}
 
 			logger.Infof(
@@ -204,8 +437,8 @@ func processSourceFile(
 				summary.Name,
 				sourceTypeName,
 				listTypeName,
-				len(validEntries),
-				len(invalidEntries),
+				validCount,
+				invalidCount,
 			)
 		}
 
@@ -225,6 +458,133 @@ func processSourceFile(
 	return processedSummaries
 }
 
+// processedFileName returns the name the processed files of a download are named after.
+// The targets of a source downloading several files are processed into files of their own.
+func processedFileName(summary c.DownloadSummary) string {
+	target := filepath.Base(summary.Filepath)
+	if !strings.HasPrefix(target, summary.Name+"-") {
+		return summary.Name
+	}
+	return strings.TrimSuffix(target, filepath.Ext(target))
+}
+
+// processSourceFileWithGuard processes a source file into a staging folder and moves the processed files
+// into place, unless the anomaly guard rejects the new valid-entry count compared to the previous result
+// of the same download target. A rejected result is discarded and the previous one is kept, flagged as
+// quarantined.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - logger: Logger for recording operations and errors
+//   - summary: Download summary containing file information
+//   - processedDir: Directory to save processed results
+//   - previous: ProcessedSummary recorded by the last run
+//
+// Returns:
+//   - The ProcessedSummary objects of the new result, or the previous one if it was rejected
+func processSourceFileWithGuard(
+	ctx context.Context,
+	logger *multilog.Logger,
+	summary c.DownloadSummary,
+	processedDir string,
+	previous c.ProcessedSummary,
+) []c.ProcessedSummary {
+	var guard c.AnomalyGuardConfig
+	if AppConfig != nil {
+		guard = AppConfig.DNSToolkit.AnomalyGuard
+	}
+	if guard.Disabled {
+		return processSourceFile(ctx, logger, summary, processedDir)
+	}
+
+	stagingDir, err := os.MkdirTemp(processedDir, ".staging-"+summary.Name+"-")
+	if err != nil {
+		logger.Warnf("Creating staging folder error: %v; processing %s without the anomaly guard", err, summary.Name)
+		return processSourceFile(ctx, logger, summary, processedDir)
+	}
+	defer func() {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			logger.Warnf("Removing staging folder error: %v (folder: %s)", err, stagingDir)
+		}
+	}()
+
+	processedSummaries := processSourceFile(ctx, logger, summary, stagingDir)
+	if len(processedSummaries) == 0 {
+		return processedSummaries
+	}
+	// the previous summary is merged across the targets of the source, only those of this target compare
+	previous = targetProcessedSummary(previous, summary)
+	previousEntries := countValidEntries([]c.ProcessedSummary{previous})
+	reason := guard.CheckEntries(previousEntries, countValidEntries(processedSummaries))
+	if reason != "" && processedFilesExist(previous) {
+		logger.Warnf("Quarantining source %s: processed %s, keeping the previous result", summary.Name, reason)
+		previous.Quarantined = true
+		previous.QuarantineReason = "processed " + reason
+		return []c.ProcessedSummary{previous}
+	}
+
+	for i := range processedSummaries {
+		for _, files := range [][]c.ProcessedFile{processedSummaries[i].ValidFiles, processedSummaries[i].InvalidFiles} {
+			for j := range files {
+				finalPath := filepath.Join(processedDir, filepath.Base(files[j].Filepath))
+				if files[j].Filepath == finalPath {
+					continue
+				}
+				if err := os.Rename(files[j].Filepath, finalPath); err != nil && !os.IsNotExist(err) {
+					logger.Errorf("Moving processed file error: %v (file: %s)", err, files[j].Filepath)
+				}
+				files[j].Filepath = finalPath
+			}
+		}
+	}
+	return processedSummaries
+}
+
+// targetProcessedSummary returns the summary with only the processed files of the download target.
+// Files recorded without their download are those of a source with a single target.
+func targetProcessedSummary(summary c.ProcessedSummary, download c.DownloadSummary) c.ProcessedSummary {
+	singleTarget := processedFileName(download) == download.Name
+	ofTarget := func(files []c.ProcessedFile) []c.ProcessedFile {
+		var kept []c.ProcessedFile
+		for _, file := range files {
+			if file.DownloadFilepath == download.Filepath || file.DownloadFilepath == "" && singleTarget {
+				kept = append(kept, file)
+			}
+		}
+		return kept
+	}
+	summary.ValidFiles = ofTarget(summary.ValidFiles)
+	summary.InvalidFiles = ofTarget(summary.InvalidFiles)
+	return summary
+}
+
+// countValidEntries returns the number of valid entries in the distinct valid files of the summaries.
+func countValidEntries(summaries []c.ProcessedSummary) int {
+	seen := make(map[string]bool)
+	count := 0
+	for _, summary := range summaries {
+		for _, file := range summary.ValidFiles {
+			if !seen[file.Filepath] {
+				seen[file.Filepath] = true
+				count += file.NumberOfEntries
+			}
+		}
+	}
+	return count
+}
+
+// processedFilesExist reports whether every processed file of a summary is still on disk.
+func processedFilesExist(summary c.ProcessedSummary) bool {
+	for _, files := range [][]c.ProcessedFile{summary.ValidFiles, summary.InvalidFiles} {
+		for _, file := range files {
+			if _, err := os.Stat(file.Filepath); err != nil {
+				return false
+			}
+		}
+	}
+	return len(summary.ValidFiles) > 0
+}
+
 // createProcessedFile creates a ProcessedFile object for the given file.
 // It includes metadata about the file and calculates a checksum if enabled.
 //
@@ -234,9 +594,10 @@ func processSourceFile(
 //   - filePath: Path to the file
 //   - sourceType: Type of the source
 //   - listType: Type of the list (blocklist, allowlist, etc.)
-//   - entries: List of entries in the file
+//   - numberOfEntries: Number of entries in the file
 //   - mustConsider: Whether the file must be considered for processing
 //   - valid: Whether the file contains valid entries
+//   - matchSemantics: How the entries match hostnames, empty if unknown
 //   - groups: Groups associated with the source type
 //   - categories: Categories associated with the source type
 //   - skipGeneralConsolidation: Whether to skip general consolidation
@@ -248,9 +609,10 @@ func processSourceFile(
 func createProcessedFile(
 	logger *multilog.Logger,
 	name, filePath, sourceType, listType string,
-	entries []string,
+	numberOfEntries int,
 	mustConsider bool,
 	valid bool,
+	matchSemantics string,
 	groups []string,
 	categories []string,
 	skipGeneralConsolidation bool,
@@ -271,10 +633,11 @@ func createProcessedFile(
 		ActualSourceType:            sourceType,
 		ListType:                    listType,
 		Filepath:                    filePath,
-		NumberOfEntries:             len(entries),
+		NumberOfEntries:             numberOfEntries,
 		Checksum:                    checksum,
 		MustConsider:                mustConsider,
 		Valid:                       valid,
+		MatchSemantics:              matchSemantics,
 		Groups:                      groups,
 		Categories:                  categories,
 		SkipGeneralConsolidation:    skipGeneralConsolidation,
@@ -283,10 +646,102 @@ func createProcessedFile(
 	}
 }
 
-// extractEntriesByType extracts entries from content based on the source type.
-// It uses either built-in regex patterns or custom processors to extract entries.
+// streamProcessorFor returns the streaming processor of a source type and list type.
+// Domains and the source types with a built-in regex pattern are classified line by line,
+// other source types use their registered processor.
 //
 // Parameters:
+//   - sourceType: Type of source (domain, ipv4, etc.)
+//   - listType: Type of list (allowlist, blocklist, etc.)
+//
+// Returns:
+//   - The StreamProcessor for the source type
+//   - A boolean indicating whether the source type is supported
+func streamProcessorFor(sourceType, listType string) (r.StreamProcessor, bool) {
+	if sourceType == constants.SourceTypeDomain {
+		return r.NewLineProcessor(sourceType, listType, func(line string) (string, string) {
+			// *.example.com lines are kept as entries matching the subdomains as well
+			if u.IsDomainEntry(line) {
+				return line, ""
+			}
+			return "", line
+		}), true
+	}
+	if regex, exists := constants.SourceTypeRegexMap[sourceType]; exists {
+		return r.NewLineProcessor(sourceType, listType, func(line string) (string, string) {
+			if matchedString := regex.FindString(line); matchedString != "" {
+				return matchedString, ""
+			}
+			return "", line
+		}), true
+	}
+	if processor, exists := r.Processors.GetProcessor(sourceType, listType); exists {
+		return r.AsStreamProcessor(processor), true
+	}
+	return nil, false
+}
+
+// streamEntries streams a downloaded file through a processor into its valid and invalid processed files.
+// The valid entries of a MultiTypeProcessor are written to one file per generic source type.
+// The processed files are only replaced when the whole file has been processed.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//   - logger: Logger for recording operations and errors
+//   - filePath: Path of the downloaded file
+//   - processor: The StreamProcessor for the source type
+//   - validFilePaths: Paths of the valid entries files, by the source type of the processor
+//     or by the output types of a MultiTypeProcessor
+//   - invalidFilePath: Path of the invalid entries file
+//
+// Returns:
+//   - The number of valid entries by source type, a valid file is not created if there are none
+//   - The number of invalid entries, the invalid file is not created if there are none
+//   - The number of invalid entries by reason code, see constants.InvalidReasonNoMatch
+//   - The first invalid entries of each reason code
+//   - An error if the file could not be read or processed
+func streamEntries(
+	ctx context.Context,
+	logger *multilog.Logger,
+	filePath string,
+	processor r.StreamProcessor,
+	validFilePaths map[string]string,
+	invalidFilePath string,
+) (map[string]int, int, map[string]int, map[string][]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	defer u.CloseFile(logger, file)
+
+	sourceType := processor.GetSourceType()
+	genericSourceType := cfg.GetGenericSourceType(sourceType)
+	if _, ok := processor.(r.MultiTypeProcessor); ok {
+		sink := r.NewTypedFileSink(validFilePaths, invalidFilePath, genericSourceType)
+		if err := processor.ProcessStream(ctx, logger, file, sink); err != nil {
+			sink.Discard(logger)
+			return nil, 0, nil, nil, err
+		}
+		validCounts, invalidCount, err := sink.Commit(logger)
+		reasons, samples := sink.InvalidReasons()
+		return validCounts, invalidCount, reasons, samples, err
+	}
+
+	sink := r.NewFileSink(validFilePaths[sourceType], invalidFilePath, genericSourceType)
+	if err := processor.ProcessStream(ctx, logger, file, sink); err != nil {
+		sink.Discard(logger)
+		return nil, 0, nil, nil, err
+	}
+	validCount, invalidCount, err := sink.Commit(logger)
+	reasons, samples := sink.InvalidReasons()
+	return map[string]int{sourceType: validCount}, invalidCount, reasons, samples, err
+}
+
+// extractEntriesByType extracts entries from content held in memory based on the source type,
+// using the same processors as the processing of downloaded files.
+//
+// Parameters:
+//   - ctx: Context for cancellation, passed on to the processor
 //   - logger: Logger for recording operations and errors
 //   - content: The content to process
 //   - sourceType: Type of source (domain, ipv4, etc.)
@@ -296,29 +751,18 @@ func createProcessedFile(
 //   - A slice of valid entries
 //   - A slice of invalid entries
 func extractEntriesByType(
+	ctx context.Context,
 	logger *multilog.Logger,
 	content string,
 	sourceType string,
 	listType string,
 ) ([]string, []string) {
-	var validEntries, invalidEntries []string
-
-	if sourceType == constants.SourceTypeDomain {
-		vEntries, iEntries := u.ExtractDomains(content)
-		validEntries = append(validEntries, vEntries...)
-		invalidEntries = append(invalidEntries, iEntries...)
-	} else if regex, exists := constants.SourceTypeRegexMap[sourceType]; exists {
-		vEntries, iEntries := u.ExtractEntriesWithRegex(content, regex)
-		validEntries = append(validEntries, vEntries...)
-		invalidEntries = append(invalidEntries, iEntries...)
-	} else if processor, exists := r.Processors.GetProcessor(sourceType, listType); exists {
-		vEntries, iEntries := processor.Process(logger, content)
-		validEntries = append(validEntries, vEntries...)
-		invalidEntries = append(invalidEntries, iEntries...)
-	} else {
+	processor, exists := streamProcessorFor(sourceType, listType)
+	if !exists {
 		logger.Warnf("Unsupported source type: %s", sourceType)
+		return nil, nil
 	}
-
+	validEntries, invalidEntries := r.ProcessContent(ctx, logger, processor, content)
 	return u.RemoveDuplicates(validEntries), u.RemoveDuplicates(invalidEntries)
 }
 
@@ -365,66 +809,6 @@ func createSummary(
 	}
 }
 
-// saveEntries saves valid and invalid entries to separate files.
-// It generates filenames based on the source and entry type.
-//
-// Parameters:
-//   - logger: Logger for recording operations and errors
-//   - processedDir: Directory to save processed files
-//   - name: Name of the source
-//   - sourceType: Type of source (domain, ipv4, etc.)
-//   - validEntries: The valid entries to save
-//   - invalidEntries: The invalid entries to save
-//
-// Returns:
-//   - The name of the valid entries file, or empty if none
-//   - The name of the invalid entries file, or empty if none
-func saveEntries(
-	logger *multilog.Logger,
-	processedDir, name, sourceType, listType string,
-	validEntries, invalidEntries []string,
-) (string, string) {
-	var validFilePath, invalidFilePath string
-
-	save := func(entries []string, entryType string) string {
-		if len(entries) == 0 {
-			return ""
-		}
-		fileName := generateFileName(logger, name, sourceType, listType, entryType)
-		filePath := filepath.Join(processedDir, fileName)
-		saveToFile(logger, filePath, entries)
-		return filePath
-	}
-
-	validFilePath = save(validEntries, "valid")
-	invalidFilePath = save(invalidEntries, "invalid")
-
-	return validFilePath, invalidFilePath
-}
-
-// saveToFile saves entries to a file in the processed directory.
-// It deduplicates and sorts the entries before saving.
-//
-// Parameters:
-//   - logger: Logger for recording operations and errors
-//   - filePath: Path of the file to create
-//   - entries: Entries to write to the file
-func saveToFile(logger *multilog.Logger, filePath string, entries []string) {
-	uniqueEntries := u.RemoveDuplicates(entries)
-	if len(uniqueEntries) == 0 {
-		return
-	}
-
-	sorted := slices.Clone(uniqueEntries)
-	slices.Sort(sorted)
-
-	content := strings.Join(sorted, "\n") + "\n"
-	err := os.WriteFile(filePath, []byte(content), 0644)
-	if err != nil {
-		logger.Errorf("Error writing file: %v (path: %s)", err, filePath)
-	}
-}
-
 // generateFileName generates a unique filename for a processed file.
 // The filename includes the source name, source type, entry type (valid/invalid),
 // and an MD5 hash to ensure uniqueness.
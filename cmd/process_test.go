@@ -3,18 +3,21 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/phani-kb/dns-toolkit/internal/config"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
 	"github.com/phani-kb/multilog"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
+	r "github.com/phani-kb/dns-toolkit/internal/processors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMergeSummaries(t *testing.T) {
@@ -281,354 +284,293 @@ func TestCreateSummary(t *testing.T) {
 	}
 }
 
-func TestSaveEntries(t *testing.T) {
+func TestProcessSourceFile(t *testing.T) {
 	logger, _ := multilog.NewTestLogger(t)
+	tempDir := t.TempDir()
 
-	tempDir, err := os.MkdirTemp("", "save_entries_test")
+	// Fake download summary and file
+	fileContent := "example.com\ninvalid_domain\n"
+	filePath := filepath.Join(tempDir, "test.txt")
+	err := os.WriteFile(filePath, []byte(fileContent), 0644)
 	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
+		t.Fatalf("Failed to write to file: %v", err)
 	}
-	defer func(path string) {
-		err := os.RemoveAll(path)
-		if err != nil {
-			t.Logf("Failed to remove temp directory: %v", err)
-		}
-	}(tempDir)
 
-	tests := []struct {
-		name              string
-		processedDir      string
-		sourceName        string
-		sourceType        string
-		listType          string
-		validEntries      []string
-		invalidEntries    []string
-		expectValidFile   bool
-		expectInvalidFile bool
-	}{
-		{
-			name:              "Save valid and invalid entries",
-			validEntries:      []string{"example.com", "test.org", "sample.net"},
-			invalidEntries:    []string{"invalid1", "invalid2"},
-			processedDir:      tempDir,
-			sourceName:        "test-source",
-			sourceType:        "domain",
-			listType:          "blocklist",
-			expectValidFile:   true,
-			expectInvalidFile: true,
-		},
-		{
-			name:              "Save only valid entries",
-			validEntries:      []string{"valid.com"},
-			invalidEntries:    []string{},
-			processedDir:      tempDir,
-			sourceName:        "valid-source",
-			sourceType:        "domain",
-			listType:          "blocklist",
-			expectValidFile:   true,
-			expectInvalidFile: false,
-		},
-		{
-			name:              "Save only invalid entries",
-			validEntries:      []string{},
-			invalidEntries:    []string{"invalid.com"},
-			processedDir:      tempDir,
-			sourceName:        "invalid-source",
-			sourceType:        "domain",
-			listType:          "blocklist",
-			expectValidFile:   false,
-			expectInvalidFile: true,
-		},
-		{
-			name:              "Save empty entries",
-			validEntries:      []string{},
-			invalidEntries:    []string{},
-			processedDir:      tempDir,
-			sourceName:        "empty-source",
-			sourceType:        "domain",
-			listType:          "blocklist",
-			expectValidFile:   false,
-			expectInvalidFile: false,
+	summary := c.DownloadSummary{
+		Name:     "test-source",
+		Filepath: filePath,
+		Types: []c.SourceType{
+			{
+				Name: "domain",
+				ListTypes: []c.ListType{
+					{Name: "blocklist", MustConsider: true},
+				},
+			},
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			validFilePath, invalidFilePath := saveEntries(logger, tt.processedDir, tt.sourceName,
-				tt.sourceType, tt.listType, tt.validEntries, tt.invalidEntries)
-
-			if tt.expectValidFile {
-				if validFilePath == "" {
-					t.Error("Expected valid file path but got empty string")
-				} else if _, err := os.Stat(validFilePath); os.IsNotExist(err) {
-					t.Errorf("Expected valid file to exist at %s but it doesn't", validFilePath)
-				}
-			} else {
-				if validFilePath != "" {
-					t.Errorf("Expected no valid file but got path: %s", validFilePath)
-				}
-			}
-
-			if tt.expectInvalidFile {
-				if invalidFilePath == "" {
-					t.Error("Expected invalid file path but got empty string")
-				} else if _, err := os.Stat(invalidFilePath); os.IsNotExist(err) {
-					t.Errorf("Expected invalid file to exist at %s but it doesn't", invalidFilePath)
-				}
-			} else {
-				if invalidFilePath != "" {
-					t.Errorf("Expected no invalid file but got path: %s", invalidFilePath)
-				}
-			}
-
-			if tt.expectValidFile && validFilePath != "" {
-				content, err := os.ReadFile(validFilePath)
-				if err != nil {
-					t.Errorf("Failed to read valid file: %v", err)
-				} else {
-					lines := strings.Split(strings.TrimSpace(string(content)), "\n")
-					// Remove empty lines
-					var nonEmptyLines []string
-					for _, line := range lines {
-						if strings.TrimSpace(line) != "" {
-							nonEmptyLines = append(nonEmptyLines, line)
-						}
-					}
-					if len(nonEmptyLines) != len(tt.validEntries) {
-						t.Errorf("Expected %d valid entries in file, got %d", len(tt.validEntries), len(nonEmptyLines))
-					}
-				}
-			}
-
-			if tt.expectInvalidFile && invalidFilePath != "" {
-				content, err := os.ReadFile(invalidFilePath)
-				if err != nil {
-					t.Errorf("Failed to read invalid file: %v", err)
-				} else {
-					lines := strings.Split(strings.TrimSpace(string(content)), "\n")
-					// Remove empty lines
-					var nonEmptyLines []string
-					for _, line := range lines {
-						if strings.TrimSpace(line) != "" {
-							nonEmptyLines = append(nonEmptyLines, line)
-						}
-					}
-					if len(nonEmptyLines) != len(tt.invalidEntries) {
-						t.Errorf("Expected %d invalid entries in file, got %d", len(tt.invalidEntries), len(nonEmptyLines))
-					}
-				}
-			}
-		})
+	processed := processSourceFile(context.Background(), logger, summary, tempDir)
+	if len(processed) == 0 {
+		t.Fatal("Expected at least one processed summary")
+	}
+	ps := processed[0]
+	if ps.Name != "test-source" {
+		t.Errorf("Expected summary name 'test-source', got %s", ps.Name)
+	}
+	if len(ps.ValidFiles) != 1 {
+		t.Errorf("Expected 1 valid file, got %d", len(ps.ValidFiles))
+	}
+	if len(ps.InvalidFiles) != 1 {
+		t.Errorf("Expected 1 invalid file, got %d", len(ps.InvalidFiles))
 	}
 }
 
-func TestSaveToFile(t *testing.T) {
-	logger, _ := multilog.NewTestLogger(t)
-
-	tempDir, err := os.MkdirTemp("", "save_to_file_test")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer func(path string) {
-		err := os.RemoveAll(path)
-		if err != nil {
-			t.Logf("Failed to remove temp directory: %v", err)
-		}
-	}(tempDir)
+func TestProcessSourceFile_Formats(t *testing.T) {
+	csvSpec := c.ProcessorSpec{Kind: constants.ProcessorKindCsv, Column: 1, Delimiter: ";", SkipHeader: true}
+	htmlSpec := c.ProcessorSpec{Kind: constants.ProcessorKindHtml, Selector: "tr td:first-child", Extract: "domain"}
 
 	tests := []struct {
-		setupFunc       func()
-		name            string
-		filePath        string
-		entries         []string
-		expectedContent []string
-		expectError     bool
+		name              string
+		fileName          string
+		content           string
+		sourceType        string
+		spec              *c.ProcessorSpec
+		expectedValid     map[string]string // content of the valid files by generic source type
+		expectedSemantics string
+		expectedInvalid   int
+		expectedReasons   map[string]int // number of invalid entries by reason code
 	}{
 		{
-			name:            "Save valid entries to file",
-			entries:         []string{"domain1.com", "domain2.org", "domain3.net"},
-			filePath:        filepath.Join(tempDir, "valid_domains.txt"),
-			expectError:     false,
-			expectedContent: []string{"domain1.com", "domain2.org", "domain3.net"},
-		},
-		{
-			name:            "Save empty entries",
-			entries:         []string{},
-			filePath:        filepath.Join(tempDir, "empty.txt"),
-			expectError:     false,
-			expectedContent: []string{},
-		},
-		{
-			name:            "Save single entry",
-			entries:         []string{"single.com"},
-			filePath:        filepath.Join(tempDir, "single.txt"),
-			expectError:     false,
-			expectedContent: []string{"single.com"},
+			name:       "dnsmasq entries match subdomains",
+			fileName:   "dnsmasq.conf",
+			content:    "address=/ads.example.com/0.0.0.0\nserver=/tracker.example.com/\nserver=/corp.example.com/10.0.0.1\n",
+			sourceType: constants.SourceTypeDomainDnsmasq,
+			expectedValid: map[string]string{
+				constants.SourceTypeDomain: "ads.example.com\ntracker.example.com\n",
+			},
+			expectedSemantics: constants.MatchSemanticsSubdomains,
+			expectedInvalid:   1,
+			expectedReasons:   map[string]int{constants.InvalidReasonNonSinkhole: 1},
 		},
 		{
-			name:            "Save entries with duplicates (should be sorted and deduplicated)",
-			entries:         []string{"zebra.com", "alpha.com", "beta.org", "alpha.com"},
-			filePath:        filepath.Join(tempDir, "sorted.txt"),
-			expectError:     false,
-			expectedContent: []string{"alpha.com", "beta.org", "zebra.com"}, // Sorted and deduplicated
+			name:     "rpz triggers are saved by type",
+			fileName: "policy.rpz",
+			content: "$ORIGIN rpz.example.\nads.example.com CNAME .\n*.ads.example.com CNAME *.\n" +
+				"32.1.2.0.192.rpz-ip CNAME .\n24.0.2.0.192.rpz-ip CNAME .\nads.example.com.rpz-nsdname CNAME .\n",
+			sourceType: constants.SourceTypeRpz,
+			expectedValid: map[string]string{
+				constants.SourceTypeDomain:   "ads.example.com\n",
+				constants.SourceTypeAdguard:  "|ads.example.com^\n||*.ads.example.com^\n",
+				constants.SourceTypeIpv4:     "192.0.2.1\n",
+				constants.SourceTypeCidrIpv4: "192.0.2.0/24\n",
+			},
+			expectedInvalid: 1,
+			expectedReasons: map[string]int{constants.InvalidReasonUnsupported: 1},
 		},
 		{
-			name:     "Save to read-only directory",
-			entries:  []string{"test.com"},
-			filePath: filepath.Join(tempDir, "readonly", "test.txt"),
-			setupFunc: func() {
-				roDir := filepath.Join(tempDir, "readonly")
-				err := os.MkdirAll(roDir, 0755)
-				if err != nil {
-					t.Fatalf("Failed to create readonly directory: %v", err)
-				}
-				err = os.Chmod(roDir, 0444) // Read-only
-				if err != nil {
-					t.Fatalf("Failed to change directory permissions: %v", err)
-				}
+			name:       "declared csv processor",
+			fileName:   "feed.csv",
+			content:    "id;host\n1;ads.example.com\n2;tracker.example.com\n3;not a domain\n",
+			sourceType: constants.SourceTypeDomain,
+			spec:       &csvSpec,
+			expectedValid: map[string]string{
+				constants.SourceTypeDomain: "ads.example.com\ntracker.example.com\n",
 			},
-			expectError: true,
+			expectedInvalid: 1,
+			expectedReasons: map[string]int{constants.InvalidReasonSingleLabel: 1},
 		},
 		{
-			name:            "Save to file in nested directory",
-			entries:         []string{"nested.com"},
-			filePath:        filepath.Join(tempDir, "level1", "level2", "nested.txt"),
-			expectError:     false,
-			expectedContent: []string{"nested.com"},
-			setupFunc: func() {
-				nestedDir := filepath.Join(tempDir, "level1", "level2")
-				err := os.MkdirAll(nestedDir, 0755)
-				if err != nil {
-					t.Fatalf("Failed to create nested directory: %v", err)
-				}
+			name:     "html selector",
+			fileName: "scams.html",
+			content: "<table><tr><td>scam.example.com</td><td>reported</td></tr>" +
+				"<tr><td>fraud.example.net</td><td>reported</td></tr></table>",
+			sourceType: constants.SourceTypeHtmlSelector,
+			spec:       &htmlSpec,
+			expectedValid: map[string]string{
+				constants.SourceTypeDomain: "fraud.example.net\nscam.example.com\n",
 			},
 		},
 		{
-			name:            "Overwrite existing file",
-			entries:         []string{"new.com"},
-			filePath:        filepath.Join(tempDir, "overwrite.txt"),
-			expectError:     false,
-			expectedContent: []string{"new.com"},
-			setupFunc: func() {
-				existingPath := filepath.Join(tempDir, "overwrite.txt")
-				err := os.WriteFile(existingPath, []byte("old.com\n"), 0644)
-				if err != nil {
-					t.Fatalf("Failed to write to existing file: %v", err)
-				}
+			name:       "mixed entries are saved by detected type",
+			fileName:   "iocs.txt",
+			content:    "hxxp://c2.example[.]com/gate.php\n192.0.2.1:4444\n198.51.100.0/24\nphish.example.net\nnot_an_ioc\n",
+			sourceType: constants.SourceTypeMixed,
+			expectedValid: map[string]string{
+				constants.SourceTypeDomain:   "c2.example.com\nphish.example.net\n",
+				constants.SourceTypeIpv4:     "192.0.2.1\n",
+				constants.SourceTypeCidrIpv4: "198.51.100.0/24\n",
 			},
+			expectedInvalid: 1,
+			expectedReasons: map[string]int{constants.InvalidReasonSingleLabel: 1},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setupFunc != nil {
-				tt.setupFunc()
+			logger, _ := multilog.NewTestLogger(t)
+			tempDir := t.TempDir()
+			sourceName := strings.ReplaceAll(tt.name, " ", "-")
+
+			filePath := filepath.Join(tempDir, tt.fileName)
+			require.NoError(t, os.WriteFile(filePath, []byte(tt.content), 0644))
+			if tt.spec != nil {
+				listTypes := []string{constants.ListTypeBlocklist}
+				require.NoError(t, r.RegisterDeclaredProcessor(sourceName, tt.sourceType, listTypes, *tt.spec))
+				t.Cleanup(func() {
+					r.Processors.UnregisterProcessor(
+						r.DeclaredProcessorKey(sourceName, tt.sourceType),
+						constants.ListTypeBlocklist,
+					)
+				})
 			}
-
-			saveToFile(logger, tt.filePath, tt.entries)
-
-			fileExists := false
-			if _, err := os.Stat(tt.filePath); err == nil {
-				fileExists = true
+			summary := c.DownloadSummary{
+				Name:     sourceName,
+				Filepath: filePath,
+				Types: []c.SourceType{{
+					Name:      tt.sourceType,
+					ListTypes: []c.ListType{{Name: constants.ListTypeBlocklist}},
+					Processor: tt.spec,
+				}},
 			}
 
-			if !tt.expectError && len(tt.entries) > 0 && !fileExists {
-				t.Errorf("Expected file %s to exist but it doesn't", tt.filePath)
-				return
+			processed := processSourceFile(context.Background(), logger, summary, tempDir)
+			require.Len(t, processed, 1)
+
+			validContents := make(map[string]string)
+			for _, validFile := range processed[0].ValidFiles {
+				assert.Equal(t, tt.sourceType, validFile.ActualSourceType)
+				assert.Equal(t, tt.expectedSemantics, validFile.MatchSemantics)
+				fileContent, err := os.ReadFile(validFile.Filepath)
+				require.NoError(t, err)
+				assert.Len(t, strings.Split(strings.TrimSpace(string(fileContent)), "\n"), validFile.NumberOfEntries)
+				validContents[validFile.GenericSourceType] = string(fileContent)
 			}
+			assert.Equal(t, tt.expectedValid, validContents, "each type should have its own processed file")
 
-			if tt.expectError {
+			if tt.expectedInvalid == 0 {
+				assert.Empty(t, processed[0].InvalidFiles)
 				return
 			}
-
-			if len(tt.entries) > 0 && !tt.expectError {
-				content, err := os.ReadFile(tt.filePath)
-				if err != nil {
-					t.Errorf("Failed to read file: %v", err)
-					return
-				}
-
-				lines := strings.Split(strings.TrimSpace(string(content)), "\n")
-
-				var nonEmptyLines []string
-				for _, line := range lines {
-					if strings.TrimSpace(line) != "" {
-						nonEmptyLines = append(nonEmptyLines, line)
-					}
-				}
-
-				expectedSorted := make([]string, len(tt.expectedContent))
-				copy(expectedSorted, tt.expectedContent)
-				sort.Strings(expectedSorted)
-
-				actualSorted := make([]string, len(nonEmptyLines))
-				copy(actualSorted, nonEmptyLines)
-				sort.Strings(actualSorted)
-				sort.Strings(actualSorted)
-
-				if len(expectedSorted) != len(actualSorted) {
-					t.Errorf("Expected %d lines, got %d", len(expectedSorted), len(actualSorted))
-					return
-				}
-
-				for i, expected := range expectedSorted {
-					if i < len(actualSorted) && actualSorted[i] != expected {
-						t.Errorf("Line %d: expected %q, got %q", i, expected, actualSorted[i])
-					}
-				}
-			}
-
-			if tt.setupFunc != nil {
-				roDir := filepath.Join(tempDir, "readonly")
-				if _, err := os.Stat(roDir); err == nil {
-					err := os.Chmod(roDir, 0755)
-					if err != nil {
-						t.Logf("Failed to restore directory permissions: %v", err)
-					}
-				}
-			}
+			require.Len(t, processed[0].InvalidFiles, 1)
+			invalidFile := processed[0].InvalidFiles[0]
+			assert.Equal(t, tt.expectedInvalid, invalidFile.NumberOfEntries)
+			assert.Empty(t, invalidFile.MatchSemantics)
+			assert.Equal(t, tt.expectedReasons, invalidFile.InvalidReasons)
 		})
 	}
 }
 
-func TestProcessSourceFile(t *testing.T) {
+func TestProcessSourceFileWithGuard(t *testing.T) {
 	logger, _ := multilog.NewTestLogger(t)
 	tempDir := t.TempDir()
+	processedDir := filepath.Join(tempDir, "processed")
+	require.NoError(t, os.MkdirAll(processedDir, 0755))
 
-	// Fake download summary and file
-	fileContent := "example.com\ninvalid_domain\n"
-	filePath := filepath.Join(tempDir, "test.txt")
-	err := os.WriteFile(filePath, []byte(fileContent), 0644)
-	if err != nil {
-		t.Fatalf("Failed to write to file: %v", err)
+	writeDomains := func(count int) string {
+		var lines []string
+		for i := 0; i < count; i++ {
+			lines = append(lines, fmt.Sprintf("host%d.example.com", i))
+		}
+		filePath := filepath.Join(tempDir, "list.txt")
+		require.NoError(t, os.WriteFile(filePath, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+		return filePath
 	}
-
 	summary := c.DownloadSummary{
-		Name:     "test-source",
-		Filepath: filePath,
-		Types: []c.SourceType{
-			{
-				Name: "domain",
-				ListTypes: []c.ListType{
-					{Name: "blocklist", MustConsider: true},
-				},
-			},
-		},
+		Name:     "guarded",
+		Filepath: writeDomains(500),
+		Types:    []c.SourceType{{Name: "domain", ListTypes: []c.ListType{{Name: "blocklist"}}}},
 	}
-
-	processed := processSourceFile(context.Background(), logger, summary, tempDir)
-	if len(processed) == 0 {
-		t.Fatal("Expected at least one processed summary")
+	processed := processSourceFile(context.Background(), logger, summary, processedDir)
+	require.NotEmpty(t, processed)
+	previous := processed[len(processed)-1]
+	require.Len(t, previous.ValidFiles, 1)
+	validPath := previous.ValidFiles[0].Filepath
+
+	summary.Filepath = writeDomains(20)
+	kept := processSourceFileWithGuard(context.Background(), logger, summary, processedDir, previous)
+	require.Len(t, kept, 1)
+	assert.True(t, kept[0].Quarantined)
+	assert.Contains(t, kept[0].QuarantineReason, "from 500 to 20")
+	content, err := os.ReadFile(validPath)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 500, "the previous result should be kept")
+
+	summary.Filepath = writeDomains(300)
+	accepted := processSourceFileWithGuard(context.Background(), logger, summary, processedDir, previous)
+	require.NotEmpty(t, accepted)
+	assert.False(t, accepted[0].Quarantined)
+	require.Len(t, accepted[0].ValidFiles, 1)
+	assert.Equal(t, validPath, accepted[0].ValidFiles[0].Filepath)
+	content, err = os.ReadFile(validPath)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 300)
+
+	entries, err := os.ReadDir(processedDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.False(t, strings.HasPrefix(entry.Name(), ".staging-"), "staging folders should be removed")
 	}
-	ps := processed[0]
-	if ps.Name != "test-source" {
-		t.Errorf("Expected summary name 'test-source', got %s", ps.Name)
+}
+
+func TestProcessSourceFileWithGuard_MultipleTargets(t *testing.T) {
+	logger, _ := multilog.NewTestLogger(t)
+	tempDir := t.TempDir()
+	processedDir := filepath.Join(tempDir, "processed")
+	require.NoError(t, os.MkdirAll(processedDir, 0755))
+
+	writeDomains := func(target string, count int) c.DownloadSummary {
+		var lines []string
+		for i := 0; i < count; i++ {
+			lines = append(lines, fmt.Sprintf("%s-host%d.example.com", target, i))
+		}
+		filePath := filepath.Join(tempDir, "archived-"+target+".txt")
+		require.NoError(t, os.WriteFile(filePath, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+		return c.DownloadSummary{
+			Name:     "archived",
+			Filepath: filePath,
+			Types:    []c.SourceType{{Name: "domain", ListTypes: []c.ListType{{Name: "blocklist"}}}},
+		}
 	}
-	if len(ps.ValidFiles) != 1 {
-		t.Errorf("Expected 1 valid file, got %d", len(ps.ValidFiles))
+
+	// the previous summary of the source is merged across its targets
+	large := processSourceFile(context.Background(), logger, writeDomains("large", 500), processedDir)
+	small := processSourceFile(context.Background(), logger, writeDomains("small", 20), processedDir)
+	require.NotEmpty(t, large)
+	require.NotEmpty(t, small)
+	previous := large[len(large)-1]
+	mergeSummaries(&previous, &small[len(small)-1])
+	require.Len(t, previous.ValidFiles, 2, "each target should have processed files of its own")
+
+	kept := processSourceFileWithGuard(context.Background(), logger, writeDomains("small", 19), processedDir, previous)
+	require.NotEmpty(t, kept)
+	assert.False(t, kept[0].Quarantined, "a target should not be compared with the entries of the other targets")
+	require.Len(t, kept[0].ValidFiles, 1)
+	assert.Equal(t, 19, kept[0].ValidFiles[0].NumberOfEntries)
+
+	quarantined := processSourceFileWithGuard(
+		context.Background(), logger, writeDomains("large", 20), processedDir, previous,
+	)
+	require.Len(t, quarantined, 1)
+	assert.True(t, quarantined[0].Quarantined)
+	assert.Contains(t, quarantined[0].QuarantineReason, "from 500 to 20")
+	require.Len(t, quarantined[0].ValidFiles, 1, "only the files of the quarantined target should be kept")
+	assert.Equal(t, 500, quarantined[0].ValidFiles[0].NumberOfEntries)
+}
+
+func TestProcessedFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		summary  c.DownloadSummary
+		expected string
+	}{
+		{"single target", c.DownloadSummary{Name: "src", Filepath: "/data/download/src.txt"}, "src"},
+		{"archive target", c.DownloadSummary{Name: "src", Filepath: "/data/download/src-hosts.txt"}, "src-hosts"},
+		{"other file", c.DownloadSummary{Name: "src", Filepath: "/tmp/list.txt"}, "src"},
 	}
-	if len(ps.InvalidFiles) != 1 {
-		t.Errorf("Expected 1 invalid file, got %d", len(ps.InvalidFiles))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, processedFileName(tt.summary))
+		})
 	}
 }
 
@@ -648,9 +590,10 @@ func TestCreateProcessedFile(t *testing.T) {
 		filePath,
 		sourceType,
 		listType,
-		entries,
+		len(entries),
 		true,
 		true,
+		"",
 		groups,
 		categories,
 		false, // skipGeneralConsolidation
@@ -683,7 +626,7 @@ func TestExtractEntriesByType(t *testing.T) {
 	sourceType := "domain"
 	listType := "blocklist"
 
-	valid, invalid := extractEntriesByType(logger, content, sourceType, listType)
+	valid, invalid := extractEntriesByType(context.Background(), logger, content, sourceType, listType)
 	if len(valid) != 1 || valid[0] != "example.com" {
 		t.Errorf("Expected valid entry 'example.com', got %v", valid)
 	}
@@ -692,6 +635,17 @@ func TestExtractEntriesByType(t *testing.T) {
 	}
 }
 
+func TestProcessCommand_Cancelled(t *testing.T) {
+	InitForTesting()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	processCmd.SetContext(ctx)
+	defer processCmd.SetContext(context.Background())
+
+	assert.ErrorIs(t, processCmd.RunE(processCmd, []string{}), context.Canceled)
+}
+
 func TestProcessAllSources(t *testing.T) {
 	logger, _ := multilog.NewTestLogger(t)
 
@@ -910,7 +864,7 @@ func TestProcessAllSources(t *testing.T) {
 			}
 
 			// Run the function
-			processAllSources(ctx, logger, processedDir)
+			processAllSources(ctx, logger, processedDir, false)
 
 			// Verify results
 			if !tt.expectError {
@@ -976,6 +930,14 @@ func TestExtractEntriesByTypeComprehensive(t *testing.T) {
 			expectedValid:   []string{"example.com", "test.org"},
 			expectedInvalid: []string{"invalid_domain_123"},
 		},
+		{
+			name:            "Domain extraction with wildcards",
+			content:         "*.example.com\ntest.org\n* block comment\n*.*.example.net\ninvalid_domain_123\n",
+			sourceType:      "domain",
+			listType:        "blocklist",
+			expectedValid:   []string{"*.example.com", "test.org"},
+			expectedInvalid: []string{"invalid_domain_123"},
+		},
 		{
 			name:       "IPv4 extraction with regex",
 			content:    "192.168.1.1\n10.0.0.1\n999.999.999.999\n127.0.0.1\n",
@@ -990,12 +952,20 @@ func TestExtractEntriesByTypeComprehensive(t *testing.T) {
 			expectedInvalid: []string{},
 		},
 		{
-			name:            "IPv6 extraction with regex - simple format",
-			content:         "2001:0db8:85a3:0000:0000:8a2e:0370:7334\ninvalid_ipv6\n",
+			name:            "IPv6 extraction - canonical form",
+			content:         "2001:0db8:85a3:0000:0000:8a2e:0370:7334\n2001:DB8::1\n2001:db8::/32\ninvalid_ipv6\n",
 			sourceType:      "ipv6",
 			listType:        "blocklist",
-			expectedValid:   []string{"2001:0db8:85a3:0000:0000:8a2e:0370:7334"},
-			expectedInvalid: []string{"invalid_ipv6"},
+			expectedValid:   []string{"2001:db8:85a3::8a2e:370:7334", "2001:db8::1"},
+			expectedInvalid: []string{"2001:db8::/32", "invalid_ipv6"},
+		},
+		{
+			name:            "CIDR IPv6 extraction",
+			content:         "2001:db8::/32\n2001:DB8:0:1::5/64\n2001:db8::1\n",
+			sourceType:      "cidr_ipv6",
+			listType:        "allowlist",
+			expectedValid:   []string{"2001:db8::/32", "2001:db8:0:1::/64"},
+			expectedInvalid: []string{"2001:db8::1"},
 		},
 		{
 			name:            "IPv4 Hostname extraction",
@@ -1066,7 +1036,7 @@ func TestExtractEntriesByTypeComprehensive(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := extractEntriesByType(logger, tt.content, tt.sourceType, tt.listType)
+			valid, invalid := extractEntriesByType(context.Background(), logger, tt.content, tt.sourceType, tt.listType)
 
 			// Check valid entries
 			if len(valid) != len(tt.expectedValid) {
@@ -1189,9 +1159,10 @@ func TestCreateProcessedFileWithChecksum(t *testing.T) {
 				testFile,
 				"domain",
 				"blocklist",
-				entries,
+				len(entries),
 				true,
 				true,
+				constants.MatchSemanticsSubdomains,
 				[]string{"group1"},
 				[]string{"category1"},
 				false, // skipGeneralConsolidation
@@ -1218,6 +1189,7 @@ func TestCreateProcessedFileWithChecksum(t *testing.T) {
 			assert.Equal(t, len(entries), pf.NumberOfEntries)
 			assert.True(t, pf.MustConsider)
 			assert.True(t, pf.Valid)
+			assert.Equal(t, constants.MatchSemanticsSubdomains, pf.MatchSemantics)
 			assert.Equal(t, []string{"group1"}, pf.Groups)
 			assert.Equal(t, []string{"category1"}, pf.Categories)
 		})
@@ -1432,7 +1404,7 @@ func TestProcessAllSourcesEdgeCases(t *testing.T) {
 
 			ctx := context.Background()
 			// This should not panic and should handle the edge cases gracefully
-			processAllSources(ctx, logger, processedDir)
+			processAllSources(ctx, logger, processedDir, false)
 
 			// Cleanup for next test
 			err := os.RemoveAll(filepath.Join(summaryDir, constants.DefaultSummaryFiles["download"]))
@@ -1582,3 +1554,343 @@ func TestCreateSummaryWithDisabledListTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConfigFingerprint(t *testing.T) {
+	t.Parallel()
+
+	base := c.DownloadSummary{
+		Name: "source",
+		Types: []c.SourceType{
+			{Name: "domain", ListTypes: []c.ListType{{Name: "blocklist"}}},
+		},
+		Categories: []string{"ads"},
+	}
+	fingerprint := getConfigFingerprint(base)
+	assert.NotEmpty(t, fingerprint)
+
+	same := base
+	same.Checksum = "different-checksum"
+	assert.Equal(t, fingerprint, getConfigFingerprint(same), "checksum should not affect the fingerprint")
+
+	listType := base
+	listType.Types = []c.SourceType{
+		{Name: "domain", ListTypes: []c.ListType{{Name: "allowlist"}}},
+	}
+	assert.NotEqual(t, fingerprint, getConfigFingerprint(listType))
+
+	categories := base
+	categories.Categories = []string{"malware"}
+	assert.NotEqual(t, fingerprint, getConfigFingerprint(categories))
+
+	skip := base
+	skip.SkipGroupsConsolidation = true
+	assert.NotEqual(t, fingerprint, getConfigFingerprint(skip))
+}
+
+func TestIsProcessedSummaryReusable(t *testing.T) {
+	t.Parallel()
+
+	logger, _ := multilog.NewTestLogger(t)
+	existingFile := filepath.Join(t.TempDir(), "valid.txt")
+	require.NoError(t, os.WriteFile(existingFile, []byte("example.com\n"), 0644))
+
+	previous := c.ProcessedSummary{
+		Name:              "source",
+		ValidFiles:        []c.ProcessedFile{{Filepath: existingFile, DownloadChecksum: "abc", Valid: true}},
+		ConfigFingerprint: "fp",
+		ProcessorVersion:  constants.ProcessorVersion,
+	}
+
+	assert.True(t, isProcessedSummaryReusable(logger, previous, "abc", "fp"))
+	assert.False(t, isProcessedSummaryReusable(logger, previous, "", "fp"), "empty checksum")
+	assert.False(t, isProcessedSummaryReusable(logger, previous, "def", "fp"), "changed checksum")
+	assert.False(t, isProcessedSummaryReusable(logger, previous, "abc", "other"), "changed config")
+
+	oldVersion := previous
+	oldVersion.ProcessorVersion = "0"
+	assert.False(t, isProcessedSummaryReusable(logger, oldVersion, "abc", "fp"), "changed processor version")
+
+	missingFile := previous
+	missingFile.InvalidFiles = []c.ProcessedFile{
+		{Filepath: filepath.Join(t.TempDir(), "missing.txt"), DownloadChecksum: "abc"},
+	}
+	assert.False(t, isProcessedSummaryReusable(logger, missingFile, "abc", "fp"), "missing processed file")
+
+	otherDownload := previous
+	otherDownload.InvalidFiles = []c.ProcessedFile{{Filepath: existingFile, DownloadChecksum: "def"}}
+	assert.False(t, isProcessedSummaryReusable(logger, otherDownload, "abc", "fp"), "file of another download")
+
+	noFiles := previous
+	noFiles.ValidFiles = nil
+	assert.False(t, isProcessedSummaryReusable(logger, noFiles, "abc", "fp"), "no processed files")
+}
+
+func TestProcessAllSources_Incremental(t *testing.T) {
+	logger, _ := multilog.NewTestLogger(t)
+	tempDir := t.TempDir()
+	downloadDir := filepath.Join(tempDir, "download")
+	processedDir := filepath.Join(tempDir, "processed")
+	require.NoError(t, os.MkdirAll(downloadDir, 0755))
+	require.NoError(t, os.MkdirAll(processedDir, 0755))
+
+	originalSummaryDir := constants.SummaryDir
+	originalBackupDir := constants.BackupDir
+	originalSources := SourcesConfigs
+	defer func() {
+		constants.SummaryDir = originalSummaryDir
+		constants.BackupDir = originalBackupDir
+		SourcesConfigs = originalSources
+	}()
+	constants.SummaryDir = tempDir
+	constants.BackupDir = filepath.Join(tempDir, "backup")
+	SourcesConfigs = []config.SourcesConfig{{Sources: []config.Source{{Name: "incremental-source"}}}}
+
+	downloadFile := filepath.Join(downloadDir, "incremental.txt")
+	writeDownload := func(content string) {
+		require.NoError(t, os.WriteFile(downloadFile, []byte(content), 0644))
+		summaries := []c.DownloadSummary{
+			{
+				Name:     "incremental-source",
+				Filepath: downloadFile,
+				Types: []c.SourceType{
+					{Name: "domain", ListTypes: []c.ListType{{Name: "blocklist"}}},
+				},
+			},
+		}
+		data, err := json.Marshal(summaries)
+		require.NoError(t, err)
+		require.NoError(
+			t,
+			os.WriteFile(filepath.Join(tempDir, constants.DefaultSummaryFiles["download"]), data, 0644),
+		)
+	}
+	readProcessed := func() c.ProcessedSummary {
+		content, err := os.ReadFile(filepath.Join(tempDir, constants.DefaultSummaryFiles["processed"]))
+		require.NoError(t, err)
+		var summaries []c.ProcessedSummary
+		require.NoError(t, json.Unmarshal(content, &summaries))
+		require.Len(t, summaries, 1)
+		return summaries[0]
+	}
+
+	writeDownload("example.com\n")
+	processAllSources(context.Background(), logger, processedDir, false)
+	first := readProcessed()
+	require.Len(t, first.ValidFiles, 1)
+	assert.NotEmpty(t, first.ValidFiles[0].DownloadChecksum)
+	assert.NotEmpty(t, first.ConfigFingerprint)
+	assert.Equal(t, constants.ProcessorVersion, first.ProcessorVersion)
+
+	// Mark the processed file so a reprocess would be detected.
+	validFile := first.ValidFiles[0].Filepath
+	require.NoError(t, os.WriteFile(validFile, []byte("marker.example\n"), 0644))
+
+	processAllSources(context.Background(), logger, processedDir, false)
+	second := readProcessed()
+	assert.Equal(t, first.LastProcessedTimestamp, second.LastProcessedTimestamp)
+	content, err := os.ReadFile(validFile)
+	require.NoError(t, err)
+	assert.Equal(t, "marker.example\n", string(content), "unchanged source should not be reprocessed")
+
+	processAllSources(context.Background(), logger, processedDir, true)
+	content, err = os.ReadFile(validFile)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com\n", string(content), "force should reprocess the source")
+
+	writeDownload("example.com\nexample.org\n")
+	processAllSources(context.Background(), logger, processedDir, false)
+	third := readProcessed()
+	assert.NotEqual(t, first.ValidFiles[0].DownloadChecksum, third.ValidFiles[0].DownloadChecksum)
+	assert.Equal(t, 2, third.ValidFiles[0].NumberOfEntries)
+}
+
+func TestProcessAllSources_IncrementalMultipleTargets(t *testing.T) {
+	logger, _ := multilog.NewTestLogger(t)
+	tempDir := t.TempDir()
+	downloadDir := filepath.Join(tempDir, "download")
+	processedDir := filepath.Join(tempDir, "processed")
+	require.NoError(t, os.MkdirAll(downloadDir, 0755))
+	require.NoError(t, os.MkdirAll(processedDir, 0755))
+
+	originalSummaryDir := constants.SummaryDir
+	originalBackupDir := constants.BackupDir
+	originalSources := SourcesConfigs
+	defer func() {
+		constants.SummaryDir = originalSummaryDir
+		constants.BackupDir = originalBackupDir
+		SourcesConfigs = originalSources
+	}()
+	constants.SummaryDir = tempDir
+	constants.BackupDir = filepath.Join(tempDir, "backup")
+	SourcesConfigs = []config.SourcesConfig{{Sources: []config.Source{{Name: "archived"}}}}
+
+	targets := map[string]string{
+		"hosts":   filepath.Join(downloadDir, "archived-hosts.txt"),
+		"domains": filepath.Join(downloadDir, "archived-domains.txt"),
+	}
+	writeDownloads := func(contents map[string]string) {
+		var summaries []c.DownloadSummary
+		for target, content := range contents {
+			require.NoError(t, os.WriteFile(targets[target], []byte(content), 0644))
+			summaries = append(summaries, c.DownloadSummary{
+				Name:     "archived",
+				Filepath: targets[target],
+				Types: []c.SourceType{
+					{Name: "domain", ListTypes: []c.ListType{{Name: "blocklist"}}},
+				},
+			})
+		}
+		data, err := json.Marshal(summaries)
+		require.NoError(t, err)
+		require.NoError(
+			t,
+			os.WriteFile(filepath.Join(tempDir, constants.DefaultSummaryFiles["download"]), data, 0644),
+		)
+	}
+	readValidFiles := func() map[string]c.ProcessedFile {
+		content, err := os.ReadFile(filepath.Join(tempDir, constants.DefaultSummaryFiles["processed"]))
+		require.NoError(t, err)
+		var summaries []c.ProcessedSummary
+		require.NoError(t, json.Unmarshal(content, &summaries))
+		require.Len(t, summaries, 1)
+		files := make(map[string]c.ProcessedFile)
+		for _, file := range summaries[0].ValidFiles {
+			files[file.DownloadFilepath] = file
+		}
+		require.Len(t, files, 2, "each target should have processed files of its own")
+		return files
+	}
+
+	writeDownloads(map[string]string{"hosts": "hosts.example.com\n", "domains": "domains.example.com\n"})
+	processAllSources(context.Background(), logger, processedDir, false)
+	first := readValidFiles()
+	assert.NotEqual(t, first[targets["hosts"]].DownloadChecksum, first[targets["domains"]].DownloadChecksum)
+
+	// Mark the processed files so a reprocess would be detected.
+	for _, file := range first {
+		require.NoError(t, os.WriteFile(file.Filepath, []byte("marker.example\n"), 0644))
+	}
+
+	writeDownloads(map[string]string{"hosts": "hosts.example.com\n", "domains": "changed.example.com\n"})
+	processAllSources(context.Background(), logger, processedDir, false)
+	second := readValidFiles()
+	assert.Equal(t, first[targets["hosts"]].DownloadChecksum, second[targets["hosts"]].DownloadChecksum)
+	assert.NotEqual(t, first[targets["domains"]].DownloadChecksum, second[targets["domains"]].DownloadChecksum)
+
+	content, err := os.ReadFile(second[targets["hosts"]].Filepath)
+	require.NoError(t, err)
+	assert.Equal(t, "marker.example\n", string(content), "unchanged target should not be reprocessed")
+	content, err = os.ReadFile(second[targets["domains"]].Filepath)
+	require.NoError(t, err)
+	assert.Equal(t, "changed.example.com\n", string(content), "changed target should be reprocessed")
+}
+
+func TestProcessAllSources_DeclaredProcessorRoundTrip(t *testing.T) {
+	logger, _ := multilog.NewTestLogger(t)
+	tempDir := t.TempDir()
+	downloadDir := filepath.Join(tempDir, "download")
+	processedDir := filepath.Join(tempDir, "processed")
+	require.NoError(t, os.MkdirAll(downloadDir, 0755))
+	require.NoError(t, os.MkdirAll(processedDir, 0755))
+
+	originalSummaryDir := constants.SummaryDir
+	originalBackupDir := constants.BackupDir
+	originalSources := SourcesConfigs
+	defer func() {
+		constants.SummaryDir = originalSummaryDir
+		constants.BackupDir = originalBackupDir
+		SourcesConfigs = originalSources
+	}()
+	constants.SummaryDir = tempDir
+	constants.BackupDir = filepath.Join(tempDir, "backup")
+
+	sources := []struct {
+		name          string
+		sourceType    string
+		content       string
+		spec          c.ProcessorSpec
+		expectedValid string
+	}{
+		{
+			name:          "declared-csv",
+			sourceType:    constants.SourceTypeDomain,
+			content:       "id;host\n1;ads.example.com\n2;tracker.example.com\n",
+			spec:          c.ProcessorSpec{Kind: constants.ProcessorKindCsv, Column: 1, Delimiter: ";", SkipHeader: true},
+			expectedValid: "ads.example.com\ntracker.example.com\n",
+		},
+		{
+			name:       "declared-html",
+			sourceType: constants.SourceTypeHtmlSelector,
+			content: "<table><tr><td>scam.example.com</td><td>reported</td></tr>" +
+				"<tr><td>fraud.example.net</td><td>reported</td></tr></table>",
+			spec:          c.ProcessorSpec{Kind: constants.ProcessorKindHtml, Selector: "tr td:first-child", Extract: "domain"},
+			expectedValid: "fraud.example.net\nscam.example.com\n",
+		},
+	}
+
+	var summaries []c.DownloadSummary
+	var sourceConfigs []config.Source
+	for _, source := range sources {
+		filePath := filepath.Join(downloadDir, source.name+".txt")
+		require.NoError(t, os.WriteFile(filePath, []byte(source.content), 0644))
+		sourceTypes := []c.SourceType{{
+			Name:      source.sourceType,
+			ListTypes: []c.ListType{{Name: constants.ListTypeBlocklist}},
+			Processor: &source.spec,
+		}}
+		summaries = append(summaries, c.DownloadSummary{Name: source.name, Filepath: filePath, Types: sourceTypes})
+		sourceConfigs = append(sourceConfigs, config.Source{Name: source.name, Types: sourceTypes})
+	}
+	SourcesConfigs = []config.SourcesConfig{{Sources: sourceConfigs}}
+	require.NoError(t, SourcesConfigs[0].RegisterDeclaredProcessors())
+	t.Cleanup(func() {
+		for _, source := range sources {
+			r.Processors.UnregisterProcessor(
+				r.DeclaredProcessorKey(source.name, source.sourceType),
+				constants.ListTypeBlocklist,
+			)
+		}
+	})
+
+	data, err := json.Marshal(summaries)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, constants.DefaultSummaryFiles["download"]), data, 0644))
+
+	var readBack []c.DownloadSummary
+	require.NoError(t, json.Unmarshal(data, &readBack))
+	require.Len(t, readBack, len(sources))
+	for i, summary := range readBack {
+		require.NotNil(t, summary.Types[0].Processor, "the declared processor should be read back")
+		assert.Equal(t, sources[i].spec, *summary.Types[0].Processor)
+
+		changed := summary
+		changedSpec := *summary.Types[0].Processor
+		changedSpec.Attribute = "data-host"
+		changed.Types = []c.SourceType{summary.Types[0]}
+		changed.Types[0].Processor = &changedSpec
+		assert.NotEqual(t, getConfigFingerprint(summary), getConfigFingerprint(changed),
+			"the fingerprint should change with the declared processor")
+	}
+
+	processAllSources(context.Background(), logger, processedDir, false)
+
+	content, err := os.ReadFile(filepath.Join(tempDir, constants.DefaultSummaryFiles["processed"]))
+	require.NoError(t, err)
+	var processed []c.ProcessedSummary
+	require.NoError(t, json.Unmarshal(content, &processed))
+	require.Len(t, processed, len(sources))
+	for _, source := range sources {
+		var found bool
+		for _, summary := range processed {
+			if summary.Name != source.name {
+				continue
+			}
+			found = true
+			require.Len(t, summary.ValidFiles, 1, source.name)
+			validContent, err := os.ReadFile(summary.ValidFiles[0].Filepath)
+			require.NoError(t, err)
+			assert.Equal(t, source.expectedValid, string(validContent), source.name)
+		}
+		assert.True(t, found, "%s should be processed", source.name)
+	}
+}
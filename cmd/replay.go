@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayArchive   string
+	replayWorkspace string
+)
+
+// Statuses of an output compared by the replay command
+const (
+	replayIdentical = "identical"
+	replayChanged   = "changed"
+	replayMissing   = "missing" // archived but not rebuilt
+	replayExtra     = "extra"   // rebuilt but not archived
+)
+
+// maxReplaySamples is the number of added and removed entries logged for a changed output.
+const maxReplaySamples = 5
+
+// replayDiff is the comparison of an archived output with its rebuilt copy.
+type replayDiff struct {
+	name    string
+	status  string
+	added   []string // entries only in the rebuilt output
+	removed []string // entries only in the archived output
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Rebuild the outputs of an archive offline and compare them",
+	Long: `Rebuild the outputs of an archive created by the archive command, to check whether the same outputs come back.
+
+The downloaded files and the download summary of the archive are restored into a scratch workspace,
+where the process, consolidate and output stages run without network access. The rebuilt outputs are
+then compared with the archived ones entry by entry, ignoring the generated headers.
+
+The current configuration is used, so configuration changes made since the archive was created show
+up as differences, as do sources that need to resolve domains. The workspace is removed afterwards
+unless one is given with --workspace. Exits with status 1 if the outputs differ.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		workspace := replayWorkspace
+		if workspace == "" {
+			tempDir, err := os.MkdirTemp("", "dns-toolkit-replay-")
+			if err != nil {
+				Logger.Errorf("Failed to create replay workspace: %v", err)
+				os.Exit(1)
+			}
+			workspace = tempDir
+		} else if err := u.EnsureDirectoryExists(Logger, workspace); err != nil {
+			Logger.Errorf("Failed to create replay workspace: %v", err)
+			os.Exit(1)
+		}
+
+		diffs, err := runReplay(commandContext(cmd), Logger, replayArchive, workspace)
+		if replayWorkspace == "" {
+			if removeErr := os.RemoveAll(workspace); removeErr != nil {
+				Logger.Warnf("Failed to remove replay workspace %s: %v", workspace, removeErr)
+			}
+		}
+		if err != nil {
+			Logger.Errorf("Replay of %s failed: %v", replayArchive, err)
+			os.Exit(1)
+		}
+		if !logReplayDiffs(Logger, diffs) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayArchive, "archive", "", "Archive (tgz) created by the archive command")
+	replayCmd.Flags().StringVar(&replayWorkspace, "workspace", "", "Folder to rebuild the outputs in, kept afterwards")
+	_ = replayCmd.MarkFlagRequired("archive")
+}
+
+// runReplay restores archivePath into workspace, rebuilds its outputs offline and compares them with the archived ones.
+// The data folders point into the workspace while the stages run.
+func runReplay(ctx context.Context, logger *multilog.Logger, archivePath, workspace string) ([]replayDiff, error) {
+	snapshotDir := filepath.Join(workspace, "snapshot")
+	names, err := extractSnapshot(logger, archivePath, snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Extracted %d file(s) from %s", len(names), archivePath)
+
+	restore := useWorkspaceDirs(workspace, filepath.Join(snapshotDir, archiveDownloadFolder))
+	defer restore()
+
+	if err := restoreDownloadSummary(logger, snapshotDir, names); err != nil {
+		return nil, err
+	}
+
+	stages := defaultPipelineStages()
+	statePath := filepath.Join(constants.SummaryDir, constants.RunStateFile)
+	state, selected, err := prepareRun(logger, stages, statePath, false, "process", "output")
+	if err != nil {
+		return nil, err
+	}
+	// Overlaps are not part of the outputs
+	delete(selected, "overlap")
+	if stage := state.GetStage("overlap"); stage != nil {
+		stage.Status, stage.Error = constants.StageStatusSkipped, "not needed for replay"
+	}
+	if !runPipeline(u.WithOffline(ctx), logger, stages, selected, state, statePath) {
+		return nil, fmt.Errorf("rebuilding the outputs failed; see %s", statePath)
+	}
+
+	return compareOutputs(logger, snapshotDir, names, constants.OutputDir)
+}
+
+// extractSnapshot extracts the regular files of a tgz archive into dir and returns their names.
+func extractSnapshot(logger *multilog.Logger, archivePath, dir string) ([]string, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer u.CloseFile(logger, file)
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive %s: %w", archivePath, err)
+	}
+	defer func() {
+		if err := gzipReader.Close(); err != nil {
+			logger.Errorf("Closing gzip reader error: %v", err)
+		}
+	}()
+
+	var names []string
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !filepath.IsLocal(header.Name) {
+			return nil, fmt.Errorf("invalid file path in archive: %s", header.Name)
+		}
+		filePath := filepath.Join(dir, header.Name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, err
+		}
+		outFile, err := os.Create(filePath)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(outFile, tarReader)
+		u.CloseFile(logger, outFile)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, filepath.ToSlash(header.Name))
+	}
+	return names, nil
+}
+
+// useWorkspaceDirs points the data folders into workspace, with the downloads in downloadDir,
+// and returns a function restoring them.
+func useWorkspaceDirs(workspace, downloadDir string) func() {
+	outputDir := filepath.Join(workspace, "output")
+	dirs := map[*string]string{
+		&constants.DownloadDir:               downloadDir,
+		&constants.ProcessedDir:              filepath.Join(workspace, "processed"),
+		&constants.ConsolidatedDir:           filepath.Join(workspace, "consolidated"),
+		&constants.ConsolidatedGroupsDir:     filepath.Join(workspace, "consolidated_groups"),
+		&constants.ConsolidatedCategoriesDir: filepath.Join(workspace, "consolidated_categories"),
+		&constants.SummaryDir:                workspace,
+		&constants.OverlapDir:                filepath.Join(workspace, "overlap"),
+		&constants.TopDir:                    filepath.Join(workspace, "top"),
+		&constants.ArchiveDir:                filepath.Join(workspace, "archive"),
+		&constants.BackupDir:                 filepath.Join(workspace, "backup"),
+		&constants.ProfilesDir:               filepath.Join(workspace, "profiles"),
+		&constants.OutputDir:                 outputDir,
+		&constants.OutputGroupsDir:           outputDir + "/groups",
+		&constants.OutputCategoriesDir:       outputDir + "/categories",
+		&constants.OutputIgnoredDir:          outputDir + "/ignored",
+		&constants.OutputTopDir:              outputDir + "/top",
+		&constants.OutputSummariesDir:        outputDir + "/summaries",
+	}
+	previous := make(map[*string]string, len(dirs))
+	for dir, path := range dirs {
+		previous[dir] = *dir
+		*dir = path
+	}
+
+	// Output files are written to the folders of their summary type
+	previousOutputDirs := make(map[string]string, len(constants.SummaryTypesOutputDirMap))
+	for summaryType, dir := range constants.SummaryTypesOutputDirMap {
+		previousOutputDirs[summaryType] = dir
+	}
+	constants.SummaryTypesOutputDirMap[constants.SummaryTypeConsolidated] = constants.OutputDir
+	constants.SummaryTypesOutputDirMap[constants.SummaryTypeConsolidatedGroups] = constants.OutputGroupsDir
+	constants.SummaryTypesOutputDirMap[constants.SummaryTypeConsolidatedCategories] = constants.OutputCategoriesDir
+	constants.SummaryTypesOutputDirMap[constants.SummaryTypeTop] = constants.OutputTopDir
+	constants.SummaryTypesOutputDirMap[constants.SummaryTypeOutput] = constants.OutputDir
+
+	return func() {
+		for dir, path := range previous {
+			*dir = path
+		}
+		for summaryType, dir := range previousOutputDirs {
+			constants.SummaryTypesOutputDirMap[summaryType] = dir
+		}
+	}
+}
+
+// restoreDownloadSummary writes the download summary of the archive extracted in snapshotDir
+// to the summary folder, pointing every source to its archived download file.
+func restoreDownloadSummary(logger *multilog.Logger, snapshotDir string, names []string) error {
+	summaryName := constants.DefaultSummaryFiles[constants.SummaryTypeDownload]
+	archivedFile := filepath.Join(snapshotDir, archiveSummaryFolder, summaryName)
+	if _, err := os.Stat(archivedFile); err != nil {
+		return fmt.Errorf("archive has no %s, it was created before archives held their downloads", summaryName)
+	}
+	summaries := readSummaries[c.DownloadSummary](logger, archivedFile)
+	if len(summaries) == 0 {
+		return fmt.Errorf("archived %s lists no sources", summaryName)
+	}
+
+	restored := 0
+	for i := range summaries {
+		summary := &summaries[i]
+		if summary.Filepath == "" {
+			continue
+		}
+		name := matchArchivedDownload(summary.Filepath, names)
+		if name == "" {
+			logger.Warnf("Download file %s of %s is not in the archive", summary.Filepath, summary.Name)
+			if summary.Error == "" {
+				summary.Error = "download file not in the archive"
+			}
+			continue
+		}
+		summary.Filepath = filepath.Join(snapshotDir, filepath.FromSlash(name))
+		restored++
+	}
+
+	summaryFile := filepath.Join(constants.SummaryDir, summaryName)
+	if _, err := u.SaveSummaries(logger, summaries, summaryFile, c.DownloadSummaryLessFunc); err != nil {
+		return fmt.Errorf("failed to write %s: %w", summaryFile, err)
+	}
+	logger.Infof("Restored the download files of %d of %d source(s)", restored, len(summaries))
+	return nil
+}
+
+// matchArchivedDownload returns the archived download file stored for filePath, the longest name
+// under the download folder that ends filePath, or an empty string if there is none.
+func matchArchivedDownload(filePath string, names []string) string {
+	slashPath := filepath.ToSlash(filePath)
+	best := ""
+	for _, name := range names {
+		rel, found := strings.CutPrefix(name, archiveDownloadFolder+"/")
+		if !found || len(name) <= len(best) {
+			continue
+		}
+		if slashPath == rel || strings.HasSuffix(slashPath, "/"+rel) {
+			best = name
+		}
+	}
+	return best
+}
+
+// compareOutputs compares the outputs of the archive extracted in snapshotDir, whose files are names,
+// with the outputs rebuilt in outputDir. Summary files are not compared, they hold timestamps.
+func compareOutputs(
+	logger *multilog.Logger,
+	snapshotDir string,
+	names []string,
+	outputDir string,
+) ([]replayDiff, error) {
+	archived := make(map[string]string)
+	for _, name := range names {
+		if strings.HasPrefix(name, archiveDownloadFolder+"/") || strings.HasPrefix(name, archiveSummaryFolder+"/") ||
+			filepath.Ext(name) == ".json" {
+			continue
+		}
+		// Outputs are stored at the root, or under the output folder when it is configured with an absolute path
+		archived[strings.TrimPrefix(name, filepath.Base(constants.Folders["output"])+"/")] = name
+	}
+
+	rebuilt, err := listOutputFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []replayDiff
+	for outputName, name := range archived {
+		if !rebuilt[outputName] {
+			diffs = append(diffs, replayDiff{name: outputName, status: replayMissing})
+			continue
+		}
+		archivedEntries, err := outputEntries(filepath.Join(snapshotDir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		rebuiltEntries, err := outputEntries(filepath.Join(outputDir, filepath.FromSlash(outputName)))
+		if err != nil {
+			return nil, err
+		}
+		diff := replayDiff{name: outputName, status: replayIdentical}
+		for entry := range rebuiltEntries {
+			if !archivedEntries.Contains(entry) {
+				diff.added = append(diff.added, entry)
+			}
+		}
+		for entry := range archivedEntries {
+			if !rebuiltEntries.Contains(entry) {
+				diff.removed = append(diff.removed, entry)
+			}
+		}
+		if len(diff.added) > 0 || len(diff.removed) > 0 {
+			diff.status = replayChanged
+			sort.Strings(diff.added)
+			sort.Strings(diff.removed)
+		}
+		diffs = append(diffs, diff)
+	}
+	for outputName := range rebuilt {
+		if _, found := archived[outputName]; !found {
+			diffs = append(diffs, replayDiff{name: outputName, status: replayExtra})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].name < diffs[j].name })
+	logger.Debugf("Compared %d archived and %d rebuilt output(s)", len(archived), len(rebuilt))
+	return diffs, nil
+}
+
+// listOutputFiles returns the non-summary files of outputDir and of its direct subfolders, like the archive holds them.
+func listOutputFiles(outputDir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	entries, err := os.ReadDir(outputDir)
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if filepath.Ext(entry.Name()) != ".json" {
+				files[entry.Name()] = true
+			}
+			continue
+		}
+		subEntries, err := os.ReadDir(filepath.Join(outputDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, subEntry := range subEntries {
+			if !subEntry.IsDir() && filepath.Ext(subEntry.Name()) != ".json" {
+				files[entry.Name()+"/"+subEntry.Name()] = true
+			}
+		}
+	}
+	return files, nil
+}
+
+// outputEntries returns the entries of an output file, skipping its header and comments.
+func outputEntries(filePath string) (u.StringSet, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	entries := u.NewStringSet(nil)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !u.IsComment(line) {
+			entries.Add(line)
+		}
+	}
+	return entries, nil
+}
+
+// logReplayDiffs logs the comparison of the outputs and returns true if they are all identical.
+func logReplayDiffs(logger *multilog.Logger, diffs []replayDiff) bool {
+	identical := 0
+	for _, diff := range diffs {
+		switch diff.status {
+		case replayIdentical:
+			identical++
+			logger.Debugf("Output %s is identical", diff.name)
+		case replayChanged:
+			logger.Warnf("Output %s differs: %d entries added %v, %d removed %v",
+				diff.name,
+				len(diff.added), diff.added[:min(len(diff.added), maxReplaySamples)],
+				len(diff.removed), diff.removed[:min(len(diff.removed), maxReplaySamples)],
+			)
+		case replayMissing:
+			logger.Warnf("Output %s was not rebuilt", diff.name)
+		case replayExtra:
+			logger.Warnf("Output %s was rebuilt but is not in the archive", diff.name)
+		}
+	}
+	logger.Infof("Replay compared %d output(s): %d identical, %d differing", len(diffs), identical, len(diffs)-identical)
+	return identical == len(diffs)
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestArchive writes a tgz archive holding files, keyed by name, to archivePath.
+func writeTestArchive(t *testing.T, archivePath string, files map[string]string) {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	require.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0644))
+}
+
+func TestExtractSnapshot(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	tempDir := t.TempDir()
+	archivePath := filepath.Join(tempDir, "archive.tgz")
+	writeTestArchive(t, archivePath, map[string]string{
+		"download/list.txt":                  "a.example\n",
+		"summary/download_summary.json":      "[]",
+		"output/domain_blocklist.txt":        "a.example\n",
+		"groups/normal_domain_blocklist.txt": "a.example\n",
+	})
+
+	snapshotDir := filepath.Join(tempDir, "snapshot")
+	names, err := extractSnapshot(logger, archivePath, snapshotDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"download/list.txt",
+		"summary/download_summary.json",
+		"output/domain_blocklist.txt",
+		"groups/normal_domain_blocklist.txt",
+	}, names)
+	content, err := os.ReadFile(filepath.Join(snapshotDir, "download", "list.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a.example\n", string(content))
+
+	evilPath := filepath.Join(tempDir, "evil.tgz")
+	writeTestArchive(t, evilPath, map[string]string{"../evil.txt": "x"})
+	_, err = extractSnapshot(logger, evilPath, snapshotDir)
+	assert.Error(t, err)
+
+	notArchive := filepath.Join(tempDir, "list.txt")
+	require.NoError(t, os.WriteFile(notArchive, []byte("a.example\n"), 0644))
+	_, err = extractSnapshot(logger, notArchive, snapshotDir)
+	assert.Error(t, err)
+}
+
+func TestMatchArchivedDownload(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"download/list.txt", "download/feed/list.txt", "output/list.txt", "download/other.txt"}
+	assert.Equal(t, "download/list.txt", matchArchivedDownload("/home/user/data/download/list.txt", names))
+	assert.Equal(t, "download/feed/list.txt", matchArchivedDownload("data/download/feed/list.txt", names))
+	assert.Equal(t, "download/other.txt", matchArchivedDownload("other.txt", names))
+	assert.Equal(t, "", matchArchivedDownload("/data/download/missing.txt", names))
+	assert.Equal(t, "", matchArchivedDownload("/data/download/xlist.txt", []string{"download/list.txt"}))
+}
+
+func TestUseWorkspaceDirs(t *testing.T) {
+	downloadDir, outputDir := constants.DownloadDir, constants.OutputDir
+	groupsDir := constants.SummaryTypesOutputDirMap[constants.SummaryTypeConsolidatedGroups]
+	workspace := t.TempDir()
+
+	restore := useWorkspaceDirs(workspace, filepath.Join(workspace, "snapshot", "download"))
+	assert.Equal(t, filepath.Join(workspace, "snapshot", "download"), constants.DownloadDir)
+	assert.Equal(t, workspace, constants.SummaryDir)
+	assert.Equal(t, filepath.Join(workspace, "output"), constants.OutputDir)
+	assert.Equal(t, constants.OutputGroupsDir, constants.SummaryTypesOutputDirMap[constants.SummaryTypeConsolidatedGroups])
+	restore()
+
+	assert.Equal(t, downloadDir, constants.DownloadDir)
+	assert.Equal(t, outputDir, constants.OutputDir)
+	assert.Equal(t, groupsDir, constants.SummaryTypesOutputDirMap[constants.SummaryTypeConsolidatedGroups])
+}
+
+func TestRestoreDownloadSummary(t *testing.T) {
+	logger := multilog.NewLogger()
+	workspace := t.TempDir()
+	snapshotDir := filepath.Join(workspace, "snapshot")
+	restore := useWorkspaceDirs(workspace, filepath.Join(snapshotDir, archiveDownloadFolder))
+	defer restore()
+
+	assert.Error(t, restoreDownloadSummary(logger, snapshotDir, nil), "archives without downloads cannot be replayed")
+
+	summaries := []c.DownloadSummary{
+		{Name: "kept", Filepath: "/home/user/data/download/kept.txt"},
+		{Name: "lost", Filepath: "/home/user/data/download/lost.txt"},
+		{Name: "failed", Error: "HTTP 404"},
+	}
+	summaryName := constants.DefaultSummaryFiles[constants.SummaryTypeDownload]
+	archivedFile := filepath.Join(snapshotDir, archiveSummaryFolder, summaryName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(archivedFile), 0755))
+	_, err := u.SaveSummaries(logger, summaries, archivedFile, c.DownloadSummaryLessFunc)
+	require.NoError(t, err)
+
+	require.NoError(t, restoreDownloadSummary(logger, snapshotDir, []string{"download/kept.txt"}))
+
+	restoredFile := filepath.Join(workspace, summaryName)
+	restored := readSummaries[c.DownloadSummary](logger, restoredFile)
+	require.Len(t, restored, 3)
+	byName := make(map[string]c.DownloadSummary)
+	for _, summary := range restored {
+		byName[summary.Name] = summary
+	}
+	assert.Equal(t, filepath.Join(snapshotDir, "download", "kept.txt"), byName["kept"].Filepath)
+	assert.Empty(t, byName["kept"].Error)
+	assert.NotEmpty(t, byName["lost"].Error)
+	assert.Equal(t, "HTTP 404", byName["failed"].Error)
+}
+
+func TestCompareOutputs(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	snapshotDir := filepath.Join(t.TempDir(), "snapshot")
+	outputDir := filepath.Join(t.TempDir(), "output")
+	write := func(dir, name, content string) {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	write(snapshotDir, "output/domain_blocklist.txt", "# Last updated: 20261016\n###\na.example\nb.example\n")
+	write(outputDir, "domain_blocklist.txt", "# Last updated: 20261017\n###\nb.example\na.example\n")
+	write(snapshotDir, "groups/normal_domain_blocklist.txt", "a.example\nfalse-positive.example\n")
+	write(outputDir, "groups/normal_domain_blocklist.txt", "a.example\nnew.example\n")
+	write(snapshotDir, "top/top_domain_blocklist_min3.txt", "a.example\n")
+	write(outputDir, "categories/ads_domain_blocklist.txt", "a.example\n")
+	write(snapshotDir, "summaries/consolidated_summary.json", "[]")
+	write(outputDir, "summaries/consolidated_summary.json", "[{}]")
+	write(snapshotDir, "download/list.txt", "a.example\n")
+	names := []string{
+		"output/domain_blocklist.txt",
+		"groups/normal_domain_blocklist.txt",
+		"top/top_domain_blocklist_min3.txt",
+		"summaries/consolidated_summary.json",
+		"download/list.txt",
+	}
+
+	diffs, err := compareOutputs(logger, snapshotDir, names, outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, []replayDiff{
+		{name: "categories/ads_domain_blocklist.txt", status: replayExtra},
+		{name: "domain_blocklist.txt", status: replayIdentical},
+		{
+			name:    "groups/normal_domain_blocklist.txt",
+			status:  replayChanged,
+			added:   []string{"new.example"},
+			removed: []string{"false-positive.example"},
+		},
+		{name: "top/top_domain_blocklist_min3.txt", status: replayMissing},
+	}, diffs)
+	assert.False(t, logReplayDiffs(logger, diffs))
+	assert.True(t, logReplayDiffs(logger, diffs[1:2]))
+}
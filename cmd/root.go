@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/phani-kb/dns-toolkit/internal/common"
@@ -160,8 +163,13 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// Execute runs the root command with a context that is cancelled on SIGINT or SIGTERM,
+// so long-running commands can stop starting new work and leave consistent summaries behind.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -197,4 +205,7 @@ func init() {
 	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(archiveCmd)
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(invalidReportCmd)
 }
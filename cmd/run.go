@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runResume bool
+	runFrom   string
+	runTo     string
+)
+
+// pipelineStage is a single step of the pipeline executed by the run command.
+type pipelineStage struct {
+	name        string
+	dependsOn   []string
+	summaryType string // summary written by the stage; used to verify the stage succeeded
+	run         func(ctx context.Context) error
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the full pipeline (download through archive)",
+	Long: `Run the full pipeline as a dependency graph of stages:
+download -> process -> consolidate -> consolidate_groups -> consolidate_categories -> output -> archive,
+with top and overlap running alongside consolidation once processing is done.
+
+Progress is recorded in the run state file in the summary directory after every stage,
+so an interrupted run can be continued with --resume. Use --from and --to to run a
+subset of the stages; stages outside the range are treated as already satisfied.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := u.EnsureDirectoryExists(Logger, constants.SummaryDir); err != nil {
+			Logger.Errorf("Failed to create summary directory: %v", err)
+			os.Exit(1)
+		}
+
+		stages := defaultPipelineStages()
+		statePath := filepath.Join(constants.SummaryDir, constants.RunStateFile)
+
+		state, selected, err := prepareRun(Logger, stages, statePath, runResume, runFrom, runTo)
+		if err != nil {
+			Logger.Errorf("Failed to prepare run: %v", err)
+			os.Exit(1)
+		}
+		if len(selected) == 0 {
+			Logger.Infof("All stages are already completed; nothing to run")
+			return
+		}
+
+		succeeded := runPipeline(commandContext(cmd), Logger, stages, selected, state, statePath)
+		recordQuarantinedSources(Logger, state, statePath)
+		if !succeeded {
+			Logger.Errorf("Pipeline finished with failures; see %s", statePath)
+			os.Exit(1)
+		}
+		Logger.Infof("Pipeline completed successfully")
+	},
+}
+
+func init() {
+	runCmd.Flags().BoolVar(&runResume, "resume", false, "Resume from the first stage not completed in the last run")
+	runCmd.Flags().StringVar(&runFrom, "from", "", "First stage to run (inclusive)")
+	runCmd.Flags().StringVar(&runTo, "to", "", "Last stage to run (inclusive)")
+}
+
+// defaultPipelineStages returns the pipeline stages in their linear order.
+// Each stage reuses the RunE function of the corresponding command.
+func defaultPipelineStages() []pipelineStage {
+	return []pipelineStage{
+		{
+			name:        "download",
+			summaryType: constants.SummaryTypeDownload,
+			run:         func(ctx context.Context) error { return runCommand(ctx, downloadCmd) },
+		},
+		{
+			name:        "process",
+			dependsOn:   []string{"download"},
+			summaryType: constants.SummaryTypeProcessed,
+			run:         func(ctx context.Context) error { return runCommand(ctx, processCmd) },
+		},
+		{
+			name:        "consolidate",
+			dependsOn:   []string{"process"},
+			summaryType: constants.SummaryTypeConsolidated,
+			run:         func(ctx context.Context) error { return runCommand(ctx, consolidateAllCmd) },
+		},
+		{
+			name:        "consolidate_groups",
+			dependsOn:   []string{"consolidate"},
+			summaryType: constants.SummaryTypeConsolidatedGroups,
+			run:         func(ctx context.Context) error { return runCommand(ctx, consolidateGroupsCmd) },
+		},
+		{
+			name:        "consolidate_categories",
+			dependsOn:   []string{"consolidate_groups"},
+			summaryType: constants.SummaryTypeConsolidatedCategories,
+			run:         func(ctx context.Context) error { return runCommand(ctx, consolidateCategoriesCmd) },
+		},
+		{
+			name:        "top",
+			dependsOn:   []string{"process"},
+			summaryType: constants.SummaryTypeTop,
+			run:         func(ctx context.Context) error { return runCommand(ctx, topEntriesCmd) },
+		},
+		{
+			name:        "overlap",
+			dependsOn:   []string{"process"},
+			summaryType: constants.SummaryTypeOverlap,
+			run:         func(ctx context.Context) error { return runCommand(ctx, overlapCmd) },
+		},
+		{
+			name:      "output",
+			dependsOn: []string{"consolidate_categories", "top"},
+			run:       func(ctx context.Context) error { return runCommand(ctx, generateOutputCmd) },
+		},
+		{
+			name:      "archive",
+			dependsOn: []string{"output", "overlap"},
+			run:       func(ctx context.Context) error { return runCommand(ctx, archiveCmd) },
+		},
+	}
+}
+
+// runCommand runs a command's RunE function with the given context and returns its error.
+func runCommand(ctx context.Context, cmd *cobra.Command) error {
+	cmd.SetContext(ctx)
+	return cmd.RunE(cmd, nil)
+}
+
+// stageIndex returns the position of the named stage, or -1 if it does not exist.
+func stageIndex(stages []pipelineStage, name string) int {
+	for i, stage := range stages {
+		if stage.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectStages returns the set of stages between from and to (inclusive) in pipeline order.
+// Empty from/to default to the first/last stage.
+func selectStages(stages []pipelineStage, from, to string) (map[string]bool, error) {
+	start, end := 0, len(stages)-1
+	if from != "" {
+		if start = stageIndex(stages, from); start < 0 {
+			return nil, fmt.Errorf("unknown stage: %s", from)
+		}
+	}
+	if to != "" {
+		if end = stageIndex(stages, to); end < 0 {
+			return nil, fmt.Errorf("unknown stage: %s", to)
+		}
+	}
+	if start > end {
+		return nil, fmt.Errorf("stage %s comes after stage %s", from, to)
+	}
+
+	selected := make(map[string]bool, end-start+1)
+	for _, stage := range stages[start : end+1] {
+		selected[stage.name] = true
+	}
+	return selected, nil
+}
+
+// firstIncompleteStage returns the first stage, in pipeline order, that is not completed in the state.
+func firstIncompleteStage(stages []pipelineStage, state *c.RunState) string {
+	for _, stage := range stages {
+		stageState := state.GetStage(stage.name)
+		if stageState == nil || stageState.Status != constants.StageStatusCompleted {
+			return stage.name
+		}
+	}
+	return ""
+}
+
+// newRunState creates a run state with every stage pending.
+func newRunState(stages []pipelineStage) *c.RunState {
+	now := time.Now().Format(constants.TimestampFormat)
+	state := &c.RunState{StartedAt: now, UpdatedAt: now}
+	for _, stage := range stages {
+		state.Stages = append(state.Stages, c.RunStageState{
+			Name:   stage.name,
+			Status: constants.StageStatusPending,
+		})
+	}
+	return state
+}
+
+// loadRunState reads the run state file.
+func loadRunState(statePath string) (*c.RunState, error) {
+	content, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, err
+	}
+	var state c.RunState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("invalid run state file %s: %w", statePath, err)
+	}
+	return &state, nil
+}
+
+// saveRunState writes the run state file atomically.
+func saveRunState(logger *multilog.Logger, state *c.RunState, statePath string) {
+	state.UpdatedAt = time.Now().Format(constants.TimestampFormat)
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		logger.Errorf("Failed to marshal run state: %v", err)
+		return
+	}
+	if err := u.WriteFileAtomic(statePath, content, 0644); err != nil {
+		logger.Errorf("Failed to write run state to %s: %v", statePath, err)
+	}
+}
+
+// prepareRun builds the run state and the set of stages to execute from the command flags.
+func prepareRun(
+	logger *multilog.Logger,
+	stages []pipelineStage,
+	statePath string,
+	resume bool,
+	from, to string,
+) (*c.RunState, map[string]bool, error) {
+	state := newRunState(stages)
+
+	if resume {
+		if from != "" {
+			return nil, nil, fmt.Errorf("--resume cannot be combined with --from")
+		}
+		previous, err := loadRunState(statePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("no run state to resume: %w", err)
+		}
+		from = firstIncompleteStage(stages, previous)
+		if from == "" {
+			return previous, nil, nil
+		}
+		logger.Infof("Resuming pipeline from stage %s", from)
+		for i := range state.Stages {
+			if prev := previous.GetStage(state.Stages[i].Name); prev != nil {
+				state.Stages[i] = *prev
+			}
+		}
+		state.StartedAt = previous.StartedAt
+	}
+
+	selected, err := selectStages(stages, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range state.Stages {
+		stageState := &state.Stages[i]
+		if selected[stageState.Name] {
+			stageState.Status = constants.StageStatusPending
+			stageState.StartedAt, stageState.EndedAt, stageState.Error = "", "", ""
+		} else if !resume {
+			stageState.Status = constants.StageStatusSkipped
+			stageState.Error = "not selected"
+		}
+	}
+
+	return state, selected, nil
+}
+
+// runPipeline executes the selected stages, running each one as soon as its dependencies
+// have completed. Stages whose dependencies failed are skipped. Dependencies outside the
+// selection are treated as satisfied. Stages not yet started when ctx is cancelled are
+// skipped. The run state is saved after every transition.
+//
+// Returns true if all selected stages completed successfully.
+func runPipeline(
+	ctx context.Context,
+	logger *multilog.Logger,
+	stages []pipelineStage,
+	selected map[string]bool,
+	state *c.RunState,
+	statePath string,
+) bool {
+	var mu sync.Mutex
+	done := make(map[string]chan struct{}, len(stages))
+	succeeded := make(map[string]bool, len(stages))
+	for _, stage := range stages {
+		done[stage.name] = make(chan struct{})
+	}
+
+	update := func(name, status, errMsg string) {
+		mu.Lock()
+		defer mu.Unlock()
+		stageState := state.GetStage(name)
+		if stageState == nil {
+			state.Stages = append(state.Stages, c.RunStageState{Name: name})
+			stageState = &state.Stages[len(state.Stages)-1]
+		}
+		now := time.Now().Format(constants.TimestampFormat)
+		stageState.Status = status
+		stageState.Error = errMsg
+		switch status {
+		case constants.StageStatusRunning:
+			stageState.StartedAt = now
+			stageState.EndedAt = ""
+		case constants.StageStatusCompleted, constants.StageStatusFailed, constants.StageStatusSkipped:
+			stageState.EndedAt = now
+			succeeded[name] = status == constants.StageStatusCompleted
+		}
+		saveRunState(logger, state, statePath)
+	}
+
+	saveRunState(logger, state, statePath)
+
+	var wg sync.WaitGroup
+	for _, stage := range stages {
+		if !selected[stage.name] {
+			close(done[stage.name])
+			continue
+		}
+
+		wg.Add(1)
+		go func(stage pipelineStage) {
+			defer wg.Done()
+			defer close(done[stage.name])
+
+			var blockedBy string
+			for _, dep := range stage.dependsOn {
+				ch, ok := done[dep]
+				if !ok || !selected[dep] {
+					continue
+				}
+				<-ch
+				mu.Lock()
+				ok = succeeded[dep]
+				mu.Unlock()
+				if !ok && blockedBy == "" {
+					blockedBy = dep
+				}
+			}
+			if blockedBy != "" {
+				logger.Warnf("Skipping stage %s: dependency %s did not complete", stage.name, blockedBy)
+				update(stage.name, constants.StageStatusSkipped, "dependency "+blockedBy+" did not complete")
+				return
+			}
+			if ctx.Err() != nil {
+				logger.Warnf("Skipping stage %s: %v", stage.name, ctx.Err())
+				update(stage.name, constants.StageStatusSkipped, "cancelled")
+				return
+			}
+
+			logger.Infof("Stage %s started", stage.name)
+			update(stage.name, constants.StageStatusRunning, "")
+			startedAt := time.Now()
+			if err := runStage(ctx, stage, startedAt); err != nil {
+				logger.Errorf("Stage %s failed: %v", stage.name, err)
+				update(stage.name, constants.StageStatusFailed, err.Error())
+				return
+			}
+			logger.Infof("Stage %s completed in %s", stage.name, time.Since(startedAt))
+			update(stage.name, constants.StageStatusCompleted, "")
+		}(stage)
+	}
+	wg.Wait()
+
+	for name := range selected {
+		if !succeeded[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// runStage runs a stage, recovering from panics, and verifies that the stage wrote its summary.
+// The error returned by the stage fails it.
+func runStage(ctx context.Context, stage pipelineStage, startedAt time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	runErr := stage.run(ctx)
+	if ctx.Err() != nil {
+		return fmt.Errorf("cancelled: %w", ctx.Err())
+	}
+	if runErr != nil {
+		return runErr
+	}
+
+	if stage.summaryType == "" {
+		return nil
+	}
+	summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles[stage.summaryType])
+	info, statErr := os.Stat(summaryFile)
+	if statErr != nil {
+		return fmt.Errorf("summary file %s not written: %w", summaryFile, statErr)
+	}
+	if info.ModTime().Before(startedAt.Truncate(time.Second)) {
+		return fmt.Errorf("summary file %s was not updated", summaryFile)
+	}
+	return nil
+}
+
+// recordQuarantinedSources adds the sources quarantined by the anomaly guard to the run state and logs them.
+func recordQuarantinedSources(logger *multilog.Logger, state *c.RunState, statePath string) {
+	state.Quarantined = collectQuarantinedSources(logger, constants.SummaryDir)
+	for _, source := range state.Quarantined {
+		logger.Warnf("Source %s quarantined during %s: %s", source.Name, source.Stage, source.Reason)
+	}
+	saveRunState(logger, state, statePath)
+}
+
+// collectQuarantinedSources lists the sources flagged as quarantined in the download and processed summaries.
+func collectQuarantinedSources(logger *multilog.Logger, summaryDir string) []c.QuarantinedSource {
+	var quarantined []c.QuarantinedSource
+	downloadFile := filepath.Join(summaryDir, constants.DefaultSummaryFiles[constants.SummaryTypeDownload])
+	for _, summary := range readSummaries[c.DownloadSummary](logger, downloadFile) {
+		if summary.Quarantined {
+			quarantined = append(quarantined, c.QuarantinedSource{
+				Name:   summary.Name,
+				Stage:  "download",
+				Reason: summary.QuarantineReason,
+			})
+		}
+	}
+	processedFile := filepath.Join(summaryDir, constants.DefaultSummaryFiles[constants.SummaryTypeProcessed])
+	for _, summary := range readSummaries[c.ProcessedSummary](logger, processedFile) {
+		if summary.Quarantined {
+			quarantined = append(quarantined, c.QuarantinedSource{
+				Name:   summary.Name,
+				Stage:  "process",
+				Reason: summary.QuarantineReason,
+			})
+		}
+	}
+	return quarantined
+}
+
+// readSummaries reads the summaries of a summary file; a missing or invalid file yields none.
+func readSummaries[T any](logger *multilog.Logger, summaryFile string) []T {
+	content, err := os.ReadFile(summaryFile)
+	if err != nil {
+		return nil
+	}
+	var summaries []T
+	if err := json.Unmarshal(content, &summaries); err != nil {
+		logger.Warnf("Parsing summary file error: %v (file: %s)", err, summaryFile)
+		return nil
+	}
+	return summaries
+}
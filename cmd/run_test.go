@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStages builds a small diamond-shaped pipeline whose stages record their execution.
+func recordingStages(failing string) ([]pipelineStage, func() []string) {
+	var mu sync.Mutex
+	var executed []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			executed = append(executed, name)
+			mu.Unlock()
+			if name == failing {
+				panic("stage failure")
+			}
+			return nil
+		}
+	}
+	stages := []pipelineStage{
+		{name: "a", run: record("a")},
+		{name: "b", dependsOn: []string{"a"}, run: record("b")},
+		{name: "c", dependsOn: []string{"a"}, run: record("c")},
+		{name: "d", dependsOn: []string{"b", "c"}, run: record("d")},
+	}
+	return stages, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), executed...)
+	}
+}
+
+func TestDefaultPipelineStages(t *testing.T) {
+	t.Parallel()
+
+	stages := defaultPipelineStages()
+	seen := make(map[string]bool)
+	for _, stage := range stages {
+		assert.NotNil(t, stage.run, "stage %s should have a run function", stage.name)
+		for _, dep := range stage.dependsOn {
+			assert.True(t, seen[dep], "dependency %s of %s should come earlier in the pipeline", dep, stage.name)
+		}
+		if stage.summaryType != "" {
+			assert.Contains(t, constants.DefaultSummaryFiles, stage.summaryType)
+		}
+		seen[stage.name] = true
+	}
+	assert.Equal(t, "download", stages[0].name)
+	assert.Equal(t, "archive", stages[len(stages)-1].name)
+}
+
+func TestSelectStages(t *testing.T) {
+	t.Parallel()
+
+	stages, _ := recordingStages("")
+
+	tests := []struct {
+		name     string
+		from, to string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "all", expected: []string{"a", "b", "c", "d"}},
+		{name: "from", from: "c", expected: []string{"c", "d"}},
+		{name: "to", to: "b", expected: []string{"a", "b"}},
+		{name: "from and to", from: "b", to: "c", expected: []string{"b", "c"}},
+		{name: "unknown from", from: "x", wantErr: true},
+		{name: "unknown to", to: "x", wantErr: true},
+		{name: "reversed", from: "d", to: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectStages(stages, tt.from, tt.to)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, selected, len(tt.expected))
+			for _, name := range tt.expected {
+				assert.True(t, selected[name], "stage %s should be selected", name)
+			}
+		})
+	}
+}
+
+func TestRunPipeline_Success(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	stages, executed := recordingStages("")
+	statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+	state := newRunState(stages)
+	selected, err := selectStages(stages, "", "")
+	require.NoError(t, err)
+
+	assert.True(t, runPipeline(context.Background(), logger, stages, selected, state, statePath))
+
+	order := executed()
+	require.Len(t, order, 4)
+	assert.Equal(t, "a", order[0])
+	assert.Equal(t, "d", order[3])
+
+	saved, err := loadRunState(statePath)
+	require.NoError(t, err)
+	for _, stage := range saved.Stages {
+		assert.Equal(t, constants.StageStatusCompleted, stage.Status, stage.Name)
+		assert.NotEmpty(t, stage.StartedAt)
+		assert.NotEmpty(t, stage.EndedAt)
+	}
+}
+
+func TestRunPipeline_FailureSkipsDependents(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	stages, executed := recordingStages("b")
+	statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+	state := newRunState(stages)
+	selected, err := selectStages(stages, "", "")
+	require.NoError(t, err)
+
+	assert.False(t, runPipeline(context.Background(), logger, stages, selected, state, statePath))
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, executed())
+
+	saved, err := loadRunState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, constants.StageStatusCompleted, saved.GetStage("a").Status)
+	assert.Equal(t, constants.StageStatusFailed, saved.GetStage("b").Status)
+	assert.Contains(t, saved.GetStage("b").Error, "panic")
+	assert.Equal(t, constants.StageStatusCompleted, saved.GetStage("c").Status)
+	assert.Equal(t, constants.StageStatusSkipped, saved.GetStage("d").Status)
+	assert.Contains(t, saved.GetStage("d").Error, "b")
+}
+
+func TestRunPipeline_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	stages, executed := recordingStages("")
+	statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+	state := newRunState(stages)
+	selected, err := selectStages(stages, "", "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.False(t, runPipeline(ctx, logger, stages, selected, state, statePath))
+	assert.Empty(t, executed())
+
+	saved, err := loadRunState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, constants.StageStatusSkipped, saved.GetStage("a").Status)
+	assert.Equal(t, "cancelled", saved.GetStage("a").Error)
+	assert.Equal(t, constants.StageStatusSkipped, saved.GetStage("d").Status)
+}
+
+func TestPrepareRun(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	stages, _ := recordingStages("")
+
+	t.Run("fresh run with range", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+		state, selected, err := prepareRun(logger, stages, statePath, false, "b", "c")
+		require.NoError(t, err)
+		assert.Len(t, selected, 2)
+		assert.Equal(t, constants.StageStatusSkipped, state.GetStage("a").Status)
+		assert.Equal(t, constants.StageStatusPending, state.GetStage("b").Status)
+		assert.Equal(t, constants.StageStatusSkipped, state.GetStage("d").Status)
+	})
+
+	t.Run("resume without state", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+		_, _, err := prepareRun(logger, stages, statePath, true, "", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("resume with from", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+		_, _, err := prepareRun(logger, stages, statePath, true, "b", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("resume from first incomplete stage", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+		previous := newRunState(stages)
+		previous.GetStage("a").Status = constants.StageStatusCompleted
+		previous.GetStage("b").Status = constants.StageStatusFailed
+		previous.GetStage("c").Status = constants.StageStatusCompleted
+		previous.GetStage("d").Status = constants.StageStatusSkipped
+		saveRunState(logger, previous, statePath)
+
+		state, selected, err := prepareRun(logger, stages, statePath, true, "", "")
+		require.NoError(t, err)
+		assert.False(t, selected["a"])
+		assert.True(t, selected["b"])
+		assert.True(t, selected["c"])
+		assert.True(t, selected["d"])
+		assert.Equal(t, constants.StageStatusCompleted, state.GetStage("a").Status)
+		assert.Equal(t, constants.StageStatusPending, state.GetStage("b").Status)
+		assert.Equal(t, previous.StartedAt, state.StartedAt)
+	})
+
+	t.Run("resume when all completed", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), constants.RunStateFile)
+		previous := newRunState(stages)
+		for i := range previous.Stages {
+			previous.Stages[i].Status = constants.StageStatusCompleted
+		}
+		saveRunState(logger, previous, statePath)
+
+		_, selected, err := prepareRun(logger, stages, statePath, true, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, selected)
+	})
+}
+
+func TestRunStage_VerifiesSummary(t *testing.T) {
+	origSummaryDir := constants.SummaryDir
+	constants.SummaryDir = t.TempDir()
+	defer func() {
+		constants.SummaryDir = origSummaryDir
+	}()
+
+	summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles[constants.SummaryTypeTop])
+
+	noop := pipelineStage{name: "top", summaryType: constants.SummaryTypeTop, run: func(context.Context) error { return nil }}
+	err := runStage(context.Background(), noop, time.Now())
+	assert.Error(t, err, "missing summary should fail the stage")
+
+	require.NoError(t, os.WriteFile(summaryFile, []byte("[]"), 0644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(summaryFile, old, old))
+	err = runStage(context.Background(), noop, time.Now())
+	assert.Error(t, err, "stale summary should fail the stage")
+
+	writer := pipelineStage{
+		name:        "top",
+		summaryType: constants.SummaryTypeTop,
+		run: func(context.Context) error {
+			return os.WriteFile(summaryFile, []byte("[]"), 0644)
+		},
+	}
+	assert.NoError(t, runStage(context.Background(), writer, time.Now()))
+
+	failing := pipelineStage{
+		name:        "top",
+		summaryType: constants.SummaryTypeTop,
+		run: func(context.Context) error {
+			_ = os.WriteFile(summaryFile, []byte("[]"), 0644)
+			return errors.New("failed to create top directory")
+		},
+	}
+	err = runStage(context.Background(), failing, time.Now())
+	assert.EqualError(t, err, "failed to create top directory", "the error of the stage should fail it")
+}
+
+func TestRunStateGetStage(t *testing.T) {
+	t.Parallel()
+
+	state := &c.RunState{Stages: []c.RunStageState{{Name: "download"}}}
+	assert.NotNil(t, state.GetStage("download"))
+	assert.Nil(t, state.GetStage("missing"))
+}
+
+func TestCollectQuarantinedSources(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	summaryDir := t.TempDir()
+	assert.Empty(t, collectQuarantinedSources(logger, summaryDir))
+
+	downloads := []c.DownloadSummary{
+		{Name: "ok"},
+		{Name: "truncated", Quarantined: true, QuarantineReason: "entry count dropped by 95% from 1000 to 50"},
+	}
+	processed := []c.ProcessedSummary{
+		{Name: "ok"},
+		{Name: "collapsed", Quarantined: true, QuarantineReason: "processed entry count dropped"},
+	}
+	downloadFile := filepath.Join(summaryDir, constants.DefaultSummaryFiles[constants.SummaryTypeDownload])
+	_, err := u.SaveSummaries(logger, downloads, downloadFile, c.DownloadSummaryLessFunc)
+	require.NoError(t, err)
+	processedFile := filepath.Join(summaryDir, constants.DefaultSummaryFiles[constants.SummaryTypeProcessed])
+	_, err = u.SaveSummaries(logger, processed, processedFile, c.ProcessedSummaryLessFunc)
+	require.NoError(t, err)
+
+	quarantined := collectQuarantinedSources(logger, summaryDir)
+	assert.Equal(t, []c.QuarantinedSource{
+		{Name: "truncated", Stage: "download", Reason: "entry count dropped by 95% from 1000 to 50"},
+		{Name: "collapsed", Stage: "process", Reason: "processed entry count dropped"},
+	}, quarantined)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"sort"
@@ -31,7 +32,7 @@ var (
 var searchCmd = &cobra.Command{
 	Use:   "search [domain or IP]",
 	Short: "Search for a domain or IP in the processed files",
-	Long:  `Search for a given domain among the valid processed domain files and report the sources in which it was found. Also looks for its IP address among the valid IPv4 processed files.`, // nolint:lll
+	Long:  `Search for a given domain among the valid processed domain files and report the sources in which it was found. Also looks for its IP addresses among the valid IPv4 and IPv6 processed files, and for the CIDR networks holding them.`, // nolint:lll
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		query := strings.ToLower(args[0])
@@ -44,6 +45,10 @@ var searchCmd = &cobra.Command{
 		Logger.Infof("Searching for: %s", query)
 
 		isIP := net.ParseIP(query) != nil
+		if ip, ok := u.CanonicalIPv6(query); ok {
+			// IPv6 entries are kept in canonical form
+			query = ip
+		}
 
 		// Collect IP addresses and CNAMEs based on a query
 		ipAddresses, cnames := collectQueryData(query, isIP)
@@ -159,16 +164,19 @@ func resolveDomainToIPs(domain string, ipAddresses u.StringSet, lookupIPFunc fun
 	}
 
 	for _, ip := range ips {
-		// Only include IPv4 addresses
+		ipString := ip.String()
+		// Skip specific IPs like 0.0.0.0, 127.0.0.1, ::1, etc.
+		var searchable bool
 		if ip.To4() != nil {
-			ipString := ip.String()
-			// Skip specific IPs like 0.0.0.0, 127.0.0.1, etc.
-			if u.IsSkipIP(Logger, ipString) {
-				ipAddresses.Add(ipString)
-				Logger.Infof("Domain %s resolved to IP: %s", domain, ipString)
-			} else {
-				Logger.Debugf("Skipping special IP for domain %s: %s", domain, ipString)
-			}
+			searchable = u.IsSkipIP(Logger, ipString)
+		} else {
+			searchable = !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsMulticast()
+		}
+		if searchable {
+			ipAddresses.Add(ipString)
+			Logger.Infof("Domain %s resolved to IP: %s", domain, ipString)
+		} else {
+			Logger.Debugf("Skipping special IP for domain %s: %s", domain, ipString)
 		}
 	}
 }
@@ -240,15 +248,27 @@ func searchInFileType(query string, isIP bool, ipAddresses u.StringSet, cnames [
 		}
 	}
 
-	// Search for IP addresses
+	// Search for IP addresses, and for the CIDR networks holding them
 	for _, ip := range ipAddresses.ToSlice() {
-		results := searchInFiles(ip, constants.SourceTypeIpv4, fileType, exactMatch)
-		mu.Lock()
-		mergeSearchResults(ipResults, results)
-		mu.Unlock()
+		for _, sourceType := range ipSourceTypes(ip) {
+			results := searchInFilesWith(ip, sourceType, fileType, func(filePath string) (bool, error) {
+				return ipEntryContains(ip, filePath, exactMatch)
+			})
+			mu.Lock()
+			mergeSearchResults(ipResults, results)
+			mu.Unlock()
+		}
 	}
 }
 
+// ipSourceTypes returns the generic source types of the files that may hold an IP address
+func ipSourceTypes(ip string) []string {
+	if u.IsIPv6(ip) {
+		return []string{constants.SourceTypeIpv6, constants.SourceTypeCidrIpv6}
+	}
+	return []string{constants.SourceTypeIpv4, constants.SourceTypeCidrIpv4}
+}
+
 // mergeSearchResults merges search results from different sources
 func mergeSearchResults(target, source map[string][]string) {
 	for k, v := range source {
@@ -347,7 +367,7 @@ func processIpSearchResults(ip string, ipResults map[string][]string) map[string
 		}
 
 		for _, file := range files {
-			found, err := entryContains(ip, file, exactMatch)
+			found, err := ipEntryContains(ip, file, exactMatch)
 			if err != nil {
 				Logger.Errorf("Error checking file %s for IP %s: %v", file, ip, err)
 				continue
@@ -371,6 +391,18 @@ func processIpSearchResults(ip string, ipResults map[string][]string) map[string
 
 // searchInFiles searches for the query in files of the specified type in the given directory
 func searchInFiles(query string, sourceType string, searchFileType string, exactMatch bool) map[string][]string {
+	return searchInFilesWith(query, sourceType, searchFileType, func(filePath string) (bool, error) {
+		return entryContains(query, filePath, exactMatch)
+	})
+}
+
+// searchInFilesWith searches for the query in files of the specified type, using contains to search a file
+func searchInFilesWith(
+	query string,
+	sourceType string,
+	searchFileType string,
+	contains func(filePath string) (bool, error),
+) map[string][]string {
 	results := make(map[string][]string)
 
 	var allFiles []string
@@ -402,7 +434,7 @@ func searchInFiles(query string, sourceType string, searchFileType string, exact
 
 	for _, file := range allFiles {
 		// Search in file
-		found, err := entryContains(query, file, exactMatch)
+		found, err := contains(file)
 		if err != nil {
 			Logger.Errorf("Error searching in file %s: %v", file, err)
 			continue
@@ -462,6 +494,36 @@ func getOutputFiles(logger *multilog.Logger) ([]string, error) {
 
 // entryContains checks if the file contains the query string
 func entryContains(query, filePath string, exactMatch bool) (bool, error) {
+	return anyEntry(filePath, func(line string) bool {
+		return entryMatches(line, query, exactMatch)
+	})
+}
+
+// ipEntryContains checks if the file contains the IP address, or a CIDR network holding it
+func ipEntryContains(ip, filePath string, exactMatch bool) (bool, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return entryContains(ip, filePath, exactMatch)
+	}
+	return anyEntry(filePath, func(line string) bool {
+		if entryMatches(line, ip, exactMatch) {
+			return true
+		}
+		prefix, err := netip.ParsePrefix(line)
+		return err == nil && prefix.Contains(addr)
+	})
+}
+
+// entryMatches checks if a lowercase entry is the query, or contains it unless exactMatch is set
+func entryMatches(line, query string, exactMatch bool) bool {
+	if exactMatch {
+		return line == query
+	}
+	return strings.Contains(line, query)
+}
+
+// anyEntry checks if match accepts one of the lowercase entries of the file
+func anyEntry(filePath string, match func(line string) bool) (bool, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return false, err
@@ -475,14 +537,8 @@ func entryContains(query, filePath string, exactMatch bool) (bool, error) {
 			continue
 		}
 
-		if exactMatch {
-			if line == query {
-				return true, nil
-			}
-		} else {
-			if strings.Contains(line, query) {
-				return true, nil
-			}
+		if match(line) {
+			return true, nil
 		}
 	}
 
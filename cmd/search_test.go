@@ -148,6 +148,33 @@ func TestEntryContainsFileError(t *testing.T) {
 	assert.False(t, result)
 }
 
+func TestIpEntryContains(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "ips.txt")
+	require.NoError(t, os.WriteFile(testFile, []byte("# networks\n192.0.2.0/24\n2001:db8:1::/48\n2001:db8::5\n"), 0644))
+
+	tests := []struct {
+		ip         string
+		exactMatch bool
+		expected   bool
+	}{
+		{ip: "192.0.2.10", exactMatch: true, expected: true},
+		{ip: "198.51.100.1", exactMatch: true, expected: false},
+		{ip: "2001:db8:1:ff::1", exactMatch: true, expected: true},
+		{ip: "2001:db8::5", exactMatch: true, expected: true},
+		{ip: "2001:db8:2::1", exactMatch: true, expected: false},
+		{ip: "2001:db8::", exactMatch: false, expected: true},
+	}
+
+	for _, tt := range tests {
+		found, err := ipEntryContains(tt.ip, testFile, tt.exactMatch)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, found, tt.ip)
+	}
+}
+
 func TestCategorizeFileContent(t *testing.T) {
 	t.Parallel()
 
@@ -178,6 +205,16 @@ func TestCategorizeFileContent(t *testing.T) {
 			},
 			expected: constants.SourceTypeIpv6,
 		},
+		{
+			name:     "compressed IPv6",
+			lines:    []string{"2001:db8::1", "::1", "fe80::1:2", "example.com"},
+			expected: constants.SourceTypeIpv6,
+		},
+		{
+			name:     "CIDR IPv6 entries",
+			lines:    []string{"2001:db8::/32", "2001:db8:1::/48", "fc00::/7"},
+			expected: constants.SourceTypeCidrIpv6,
+		},
 		{
 			name:     "CIDR IPv4 entries (but IPv4 regex wins)",
 			lines:    []string{"192.168.1.0/24", "10.0.0.0/8", "172.16.0.0/12", "192.168.2.0/24", "10.1.0.0/8"},
@@ -250,13 +287,24 @@ func Test_resolveDomainToIPs(t *testing.T) {
 				net.ParseIP("5.6.7.8"),
 				net.ParseIP("2001:db8::1"),
 			},
-			wantIPs: []string{"5.6.7.8"},
+			wantIPs: []string{"5.6.7.8", "2001:db8::1"},
 		},
 		{
-			name:   "no IPv4",
+			name:   "only IPv6",
 			domain: "ipv6only.com",
 			lookupResult: []net.IP{
-				net.ParseIP("2001:db8::2"),
+				net.ParseIP("2001:0db8:0000::2"),
+			},
+			wantIPs: []string{"2001:db8::2"},
+		},
+		{
+			name:   "special IPs",
+			domain: "special.com",
+			lookupResult: []net.IP{
+				net.ParseIP("127.0.0.1"),
+				net.ParseIP("::1"),
+				net.ParseIP("::"),
+				net.ParseIP("fe80::1"),
 			},
 			wantIPs: nil,
 		},
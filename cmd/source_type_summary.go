@@ -24,7 +24,9 @@ func initializeSourceTypeCounts() map[string]int {
 		constants.SourceTypeIpv4RangeExpand:          0,
 		constants.SourceTypeIpv6:                     0,
 		constants.SourceTypeCidrIpv4:                 0,
+		constants.SourceTypeCidrIpv6:                 0,
 		constants.SourceTypeIpv4CidrExpand:           0,
+		constants.SourceTypeIpv6CidrExpand:           0,
 		constants.SourceTypeDomain:                   0,
 		constants.SourceTypeDomainFinder:             0,
 		constants.SourceTypeAdguard:                  0,
@@ -33,6 +35,7 @@ func initializeSourceTypeCounts() map[string]int {
 		constants.SourceTypeAdguardCsvHttpUrlFind:    0,
 		constants.SourceTypeIpv4Hostname:             0,
 		constants.SourceTypeHostname:                 0,
+		constants.SourceTypeHostnameSinkhole:         0,
 		constants.SourceTypeUnknown:                  0,
 		constants.SourceTypeDomainAdguard:            0,
 		constants.SourceTypeDomainCsvHttpUrlFind:     0,
@@ -48,6 +51,11 @@ func initializeSourceTypeCounts() map[string]int {
 		constants.SourceTypeIpv4Url:                  0,
 		constants.SourceTypeIpv6Find:                 0,
 		constants.SourceTypeIpv6Htaccess:             0,
+		constants.SourceTypeDomainDnsmasq:            0,
+		constants.SourceTypeDomainUnbound:            0,
+		constants.SourceTypeRpz:                      0,
+		constants.SourceTypeHtmlSelector:             0,
+		constants.SourceTypeMixed:                    0,
 	}
 }
 
@@ -178,8 +186,10 @@ func categorizeFileContent(logger *multilog.Logger, lines []string) string {
 		// NOTE: change in the order of regex checks may affect the results
 		if constants.SourceTypeRegexMap[constants.SourceTypeIpv4Hostname].MatchString(line) {
 			regexCounts[constants.SourceTypeIpv4Hostname]++
-		} else if constants.SourceTypeRegexMap[constants.SourceTypeIpv6].MatchString(line) {
+		} else if _, ok := u.FindIPv6(line); ok {
 			regexCounts[constants.SourceTypeIpv6]++
+		} else if _, ok := u.FindCIDRv6(line); ok {
+			regexCounts[constants.SourceTypeCidrIpv6]++
 		} else if constants.SourceTypeRegexMap[constants.SourceTypeCidrIpv4].MatchString(line) {
 			regexCounts[constants.SourceTypeCidrIpv4]++
 		} else if constants.SourceTypeRegexMap[constants.SourceTypeDomain].MatchString(line) {
@@ -23,9 +23,10 @@ var (
 )
 
 var topEntriesCmd = &cobra.Command{
-	Use:   "top",
-	Short: "Find top entry(s) in each generic source type",
-	Run: func(cmd *cobra.Command, args []string) {
+	Use:          "top",
+	Short:        "Find top entry(s) in each generic source type",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		Logger.Infof("Profiling configuration: CPU=%v, Memory=%v, Goroutine=%v, Block=%v",
 			cpuProfile, memProfile, goroutineProfile, blockProfile)
 
@@ -101,6 +102,7 @@ var topEntriesCmd = &cobra.Command{
 
 			// Process top entries using the service
 			_, err := topService.ProcessTopEntries(
+				commandContext(cmd),
 				Logger,
 				genericSourceTypes,
 				processedFiles,
@@ -125,6 +127,7 @@ var topEntriesCmd = &cobra.Command{
 				OutputDir:       profileDir,
 			})
 		}
+		return nil
 	},
 }
 
@@ -12,7 +12,7 @@ func TestTopEntriesCommand(t *testing.T) {
 	assert.NotNil(t, topEntriesCmd)
 	assert.Equal(t, "top", topEntriesCmd.Use)
 	assert.Contains(t, topEntriesCmd.Short, "top entry")
-	assert.NotNil(t, topEntriesCmd.Run)
+	assert.NotNil(t, topEntriesCmd.RunE)
 }
 
 func TestTopEntriesFlags(t *testing.T) {
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+
+	consts "github.com/phani-kb/dns-toolkit/internal/constants"
+)
+
+// AnomalyGuardConfig rejects a new download or processing result of a source that looks broken
+// compared to the previous one, so that the last-known-good copy is kept and the source is quarantined.
+type AnomalyGuardConfig struct {
+	Disabled       bool `yaml:"disabled,omitempty"`
+	MaxDropPercent int  `yaml:"max_drop_percent,omitempty"` // largest accepted drop of the entry count, 80 if 0
+	MinEntries     int  `yaml:"min_entries,omitempty"`      // smaller previous counts are not compared, 100 if 0
+	AllowHTML      bool `yaml:"allow_html,omitempty"`       // accept an HTML body for a text list
+}
+
+func (g *AnomalyGuardConfig) Validate() error {
+	if g.MaxDropPercent < 0 || g.MaxDropPercent > 100 {
+		return fmt.Errorf("max_drop_percent must be between 0 and 100: %d", g.MaxDropPercent)
+	}
+	if g.MinEntries < 0 {
+		return fmt.Errorf("min_entries must not be negative: %d", g.MinEntries)
+	}
+	return nil
+}
+
+// CheckEntries compares a new entry count with the previous one and returns why it is rejected,
+// or an empty string if it is accepted.
+func (g *AnomalyGuardConfig) CheckEntries(previous, current int) string {
+	maxDrop, minEntries := g.MaxDropPercent, g.MinEntries
+	if maxDrop == 0 {
+		maxDrop = consts.DefaultAnomalyMaxDropPercent
+	}
+	if minEntries == 0 {
+		minEntries = consts.DefaultAnomalyMinEntries
+	}
+	if g.Disabled || previous < minEntries || current >= previous {
+		return ""
+	}
+	if (previous-current)*100 > maxDrop*previous {
+		return fmt.Sprintf("entry count dropped by %d%% from %d to %d",
+			(previous-current)*100/previous, previous, current)
+	}
+	return ""
+}
+
+// AnomalyGuard is the guard applied to a single download, with the history it is compared against.
+type AnomalyGuard struct {
+	AnomalyGuardConfig
+	PreviousEntries int  // entries of the last accepted download, 0 if unknown
+	ExpectHTML      bool // the source is an HTML page by design
+}
+
+// CheckHTML returns why an HTML body is rejected for the download, or an empty string if it is accepted.
+func (g *AnomalyGuard) CheckHTML(html bool) string {
+	if !html || g.Disabled || g.AllowHTML || g.ExpectHTML {
+		return ""
+	}
+	return "received an HTML page instead of a text list"
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomalyGuardConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, (&AnomalyGuardConfig{}).Validate())
+	assert.NoError(t, (&AnomalyGuardConfig{MaxDropPercent: 100, MinEntries: 10}).Validate())
+	assert.Error(t, (&AnomalyGuardConfig{MaxDropPercent: 101}).Validate())
+	assert.Error(t, (&AnomalyGuardConfig{MaxDropPercent: -1}).Validate())
+	assert.Error(t, (&AnomalyGuardConfig{MinEntries: -1}).Validate())
+}
+
+func TestAnomalyGuardConfig_CheckEntries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		guard    AnomalyGuardConfig
+		previous int
+		current  int
+		rejected bool
+	}{
+		{name: "collapsed", previous: 1000, current: 10, rejected: true},
+		{name: "empty", previous: 1000, current: 0, rejected: true},
+		{name: "default threshold", previous: 1000, current: 200},
+		{name: "below default threshold", previous: 1000, current: 199, rejected: true},
+		{name: "grown", previous: 1000, current: 5000},
+		{name: "unknown history", previous: 0, current: 0},
+		{name: "small previous", previous: 99, current: 0},
+		{
+			name:     "custom threshold",
+			guard:    AnomalyGuardConfig{MaxDropPercent: 50},
+			previous: 1000,
+			current:  400,
+			rejected: true,
+		},
+		{name: "custom minimum", guard: AnomalyGuardConfig{MinEntries: 10}, previous: 50, current: 1, rejected: true},
+		{name: "disabled", guard: AnomalyGuardConfig{Disabled: true}, previous: 1000, current: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := tt.guard.CheckEntries(tt.previous, tt.current)
+			if tt.rejected {
+				assert.NotEmpty(t, reason)
+			} else {
+				assert.Empty(t, reason)
+			}
+		})
+	}
+
+	assert.Equal(t, "entry count dropped by 99% from 1000 to 10", (&AnomalyGuardConfig{}).CheckEntries(1000, 10))
+}
+
+func TestAnomalyGuard_CheckHTML(t *testing.T) {
+	t.Parallel()
+
+	assert.NotEmpty(t, (&AnomalyGuard{}).CheckHTML(true))
+	assert.Empty(t, (&AnomalyGuard{}).CheckHTML(false))
+	assert.Empty(t, (&AnomalyGuard{ExpectHTML: true}).CheckHTML(true))
+	assert.Empty(t, (&AnomalyGuard{AnomalyGuardConfig: AnomalyGuardConfig{AllowHTML: true}}).CheckHTML(true))
+	assert.Empty(t, (&AnomalyGuard{AnomalyGuardConfig: AnomalyGuardConfig{Disabled: true}}).CheckHTML(true))
+}
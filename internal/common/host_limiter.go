@@ -0,0 +1,150 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	consts "github.com/phani-kb/dns-toolkit/internal/constants"
+	"golang.org/x/time/rate"
+)
+
+// HostLimitConfig limits the downloads from the hosts matching Pattern.
+type HostLimitConfig struct {
+	Pattern           string  `yaml:"pattern"`                       // host name or glob, e.g. *.githubusercontent.com
+	MaxConcurrent     int     `yaml:"max_concurrent,omitempty"`      // concurrent downloads per host
+	RequestsPerMinute float64 `yaml:"requests_per_minute,omitempty"` // request rate per host, unlimited if 0
+}
+
+func (h *HostLimitConfig) Validate() error {
+	if h.Pattern == "" {
+		return fmt.Errorf("host pattern is required")
+	}
+	if _, err := path.Match(h.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid host pattern %s: %w", h.Pattern, err)
+	}
+	if h.MaxConcurrent < 0 {
+		return fmt.Errorf("max_concurrent must not be negative: %d", h.MaxConcurrent)
+	}
+	if h.RequestsPerMinute < 0 {
+		return fmt.Errorf("requests_per_minute must not be negative: %v", h.RequestsPerMinute)
+	}
+	return nil
+}
+
+// HostUnavailableError is returned for requests to a host whose circuit breaker is open.
+type HostUnavailableError struct {
+	Host     string
+	Failures int
+}
+
+func (e *HostUnavailableError) Error() string {
+	return fmt.Sprintf("host %s skipped for the rest of the run after %d consecutive failures", e.Host, e.Failures)
+}
+
+// HostLimiter enforces the per-host limits of a download run and acts as a circuit breaker,
+// refusing requests to a host for the rest of the run after consecutive failures.
+// It is safe for concurrent use.
+type HostLimiter struct {
+	limits    []HostLimitConfig
+	threshold int // consecutive failures that open the circuit, disabled if <= 0
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	notAfter map[string]time.Time // requests to the host wait until then, e.g. after a Retry-After
+	failures map[string]int
+}
+
+// NewHostLimiter creates a host limiter from the configured limits; the first matching pattern applies to a host.
+func NewHostLimiter(limits []HostLimitConfig, threshold int) *HostLimiter {
+	return &HostLimiter{
+		limits:    limits,
+		threshold: threshold,
+		limiters:  make(map[string]*rate.Limiter),
+		notAfter:  make(map[string]time.Time),
+		failures:  make(map[string]int),
+	}
+}
+
+func (h *HostLimiter) limitFor(host string) (HostLimitConfig, bool) {
+	host = strings.ToLower(host)
+	for _, limit := range h.limits {
+		if matched, _ := path.Match(strings.ToLower(limit.Pattern), host); matched {
+			return limit, true
+		}
+	}
+	return HostLimitConfig{}, false
+}
+
+// MaxConcurrent returns the number of downloads allowed to run at once against host.
+func (h *HostLimiter) MaxConcurrent(host string) int {
+	if limit, ok := h.limitFor(host); ok && limit.MaxConcurrent > 0 {
+		return limit.MaxConcurrent
+	}
+	return consts.DefaultHostMaxConcurrent
+}
+
+// Wait blocks until a request to host is allowed, or returns a *HostUnavailableError if its circuit is open.
+func (h *HostLimiter) Wait(ctx context.Context, host string) error {
+	host = strings.ToLower(host)
+
+	h.mu.Lock()
+	if failures := h.failures[host]; h.threshold > 0 && failures >= h.threshold {
+		h.mu.Unlock()
+		return &HostUnavailableError{Host: host, Failures: failures}
+	}
+	limiter, exists := h.limiters[host]
+	if !exists {
+		if limit, ok := h.limitFor(host); ok && limit.RequestsPerMinute > 0 {
+			limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerMinute/60), 1)
+		}
+		h.limiters[host] = limiter
+	}
+	delay := time.Until(h.notAfter[host])
+	h.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if limiter != nil {
+		return limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// Delay holds back further requests to host for d, e.g. as asked by a Retry-After header.
+func (h *HostLimiter) Delay(host string, d time.Duration) {
+	host = strings.ToLower(host)
+	until := time.Now().Add(d)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until.After(h.notAfter[host]) {
+		h.notAfter[host] = until
+	}
+}
+
+// RecordSuccess resets the consecutive failures of host.
+func (h *HostLimiter) RecordSuccess(host string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failures, strings.ToLower(host))
+}
+
+// RecordFailure counts a failure of host and reports whether it opened the circuit.
+func (h *HostLimiter) RecordFailure(host string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	host = strings.ToLower(host)
+	h.failures[host]++
+	return h.threshold > 0 && h.failures[host] == h.threshold
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	consts "github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLimitConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, (&HostLimitConfig{Pattern: "*.githubusercontent.com", MaxConcurrent: 2}).Validate())
+	assert.Error(t, (&HostLimitConfig{}).Validate())
+	assert.Error(t, (&HostLimitConfig{Pattern: "[a-"}).Validate())
+	assert.Error(t, (&HostLimitConfig{Pattern: "example.com", MaxConcurrent: -1}).Validate())
+	assert.Error(t, (&HostLimitConfig{Pattern: "example.com", RequestsPerMinute: -1}).Validate())
+}
+
+func TestHostLimiter_MaxConcurrent(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewHostLimiter([]HostLimitConfig{
+		{Pattern: "raw.githubusercontent.com", MaxConcurrent: 1},
+		{Pattern: "*.example.com", MaxConcurrent: 4},
+		{Pattern: "*", RequestsPerMinute: 60},
+	}, 0)
+
+	assert.Equal(t, 1, limiter.MaxConcurrent("Raw.GitHubUserContent.com"))
+	assert.Equal(t, 4, limiter.MaxConcurrent("lists.example.com"))
+	assert.Equal(t, consts.DefaultHostMaxConcurrent, limiter.MaxConcurrent("example.org"))
+}
+
+func TestHostLimiter_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	limiter := NewHostLimiter(nil, 2)
+
+	assert.False(t, limiter.RecordFailure("example.com"))
+	limiter.RecordSuccess("example.com")
+	assert.False(t, limiter.RecordFailure("example.com"))
+	assert.NoError(t, limiter.Wait(ctx, "example.com"), "a success should reset the failures")
+
+	assert.True(t, limiter.RecordFailure("example.com"))
+	err := limiter.Wait(ctx, "example.com")
+	var hostErr *HostUnavailableError
+	require.True(t, errors.As(err, &hostErr))
+	assert.Equal(t, "example.com", hostErr.Host)
+	assert.NoError(t, limiter.Wait(ctx, "example.org"), "other hosts should not be affected")
+
+	disabled := NewHostLimiter(nil, -1)
+	for i := 0; i < 10; i++ {
+		assert.False(t, disabled.RecordFailure("example.com"))
+	}
+	assert.NoError(t, disabled.Wait(ctx, "example.com"))
+}
+
+func TestHostLimiter_Delay(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewHostLimiter(nil, 0)
+	limiter.Delay("example.com", 50*time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background(), "example.com"))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	start = time.Now()
+	require.NoError(t, limiter.Wait(context.Background(), "example.org"))
+	assert.Less(t, time.Since(start), 40*time.Millisecond)
+
+	limiter.Delay("example.com", time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx, "example.com"), context.Canceled)
+}
+
+func TestHostLimiter_RequestsPerMinute(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewHostLimiter([]HostLimitConfig{{Pattern: "example.com", RequestsPerMinute: 1200}}, 0)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(context.Background(), "example.com"))
+	}
+	// 1200 per minute allows one request every 50ms after the first
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// minisignAlgorithm is the signature algorithm prefix of minisign public keys.
+var minisignAlgorithm = []byte("Ed")
+
+// checksumLengths maps the hex length of a digest to the algorithm that produces it.
+var checksumLengths = map[int]string{
+	32:  "md5",
+	64:  "sha256",
+	128: "sha512",
+}
+
+// IntegrityOptions are per-source options to verify a download before it replaces the previous copy.
+// The checksum is either pinned or published next to the list; a detached signature may be required as well.
+type IntegrityOptions struct {
+	Checksum     string `json:"checksum,omitempty"`      // pinned checksum as <algorithm>:<hex> or bare hex
+	ChecksumURL  string `json:"checksum_url,omitempty"`  // published checksum file, e.g. a .sha256 sidecar
+	SignatureURL string `json:"signature_url,omitempty"` // detached ed25519 or minisign signature
+	PublicKey    string `json:"public_key,omitempty"`    // base64 ed25519 or minisign public key
+}
+
+func (o *IntegrityOptions) Validate() error {
+	if o.Checksum == "" && o.ChecksumURL == "" && o.SignatureURL == "" {
+		return fmt.Errorf("one of checksum, checksum_url or signature_url is required")
+	}
+	if o.Checksum != "" && o.ChecksumURL != "" {
+		return fmt.Errorf("checksum and checksum_url cannot both be set")
+	}
+	if o.Checksum != "" {
+		if _, _, err := ParseChecksum(o.Checksum); err != nil {
+			return err
+		}
+	}
+	for _, rawURL := range []string{o.ChecksumURL, o.SignatureURL} {
+		if rawURL == "" {
+			continue
+		}
+		parsed, err := url.Parse(rawURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid integrity url: %s", rawURL)
+		}
+	}
+	if (o.SignatureURL == "") != (o.PublicKey == "") {
+		return fmt.Errorf("signature_url and public_key must be set together")
+	}
+	if o.PublicKey != "" {
+		if _, _, err := ParsePublicKey(o.PublicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseChecksum splits a checksum written as <algorithm>:<hex> into its algorithm and lowercase digest.
+// Without an algorithm prefix, the algorithm is inferred from the length of the digest.
+func ParseChecksum(checksum string) (string, string, error) {
+	algorithm, digest, found := strings.Cut(strings.TrimSpace(checksum), ":")
+	if !found {
+		digest, algorithm = algorithm, ""
+	}
+	algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if _, err := hex.DecodeString(digest); err != nil || digest == "" {
+		return "", "", fmt.Errorf("invalid checksum: %s", checksum)
+	}
+	expected, known := checksumLengths[len(digest)]
+	if !known {
+		return "", "", fmt.Errorf("invalid checksum length: %s", checksum)
+	}
+	if algorithm == "" {
+		algorithm = expected
+	}
+	if algorithm != expected {
+		return "", "", fmt.Errorf("checksum does not match algorithm %s: %s", algorithm, checksum)
+	}
+	return algorithm, digest, nil
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key, either raw or in minisign format.
+// The contents of a minisign public key file, including its untrusted comment, are accepted as well.
+// The key id is only returned for minisign keys.
+func ParsePublicKey(publicKey string) (ed25519.PublicKey, []byte, error) {
+	var encoded string
+	for _, line := range strings.Split(publicKey, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "untrusted comment:") {
+			encoded = line
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	switch {
+	case len(decoded) == ed25519.PublicKeySize:
+		return decoded, nil, nil
+	case len(decoded) == 2+8+ed25519.PublicKeySize && bytes.Equal(decoded[:2], minisignAlgorithm):
+		return decoded[10:], decoded[2:10], nil
+	default:
+		return nil, nil, fmt.Errorf("invalid public key: expected an ed25519 or minisign key")
+	}
+}
@@ -0,0 +1,133 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrityOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	publicKey := base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize))
+	sha256Checksum := strings.Repeat("a", 64)
+
+	tests := []struct {
+		name      string
+		integrity IntegrityOptions
+		wantErr   bool
+	}{
+		{name: "pinned checksum", integrity: IntegrityOptions{Checksum: "sha256:" + sha256Checksum}},
+		{name: "checksum url", integrity: IntegrityOptions{ChecksumURL: "https://example.com/list.txt.sha256"}},
+		{
+			name: "signature",
+			integrity: IntegrityOptions{
+				SignatureURL: "https://example.com/list.txt.minisig",
+				PublicKey:    publicKey,
+			},
+		},
+		{name: "empty", integrity: IntegrityOptions{}, wantErr: true},
+		{
+			name: "checksum and checksum url",
+			integrity: IntegrityOptions{
+				Checksum:    sha256Checksum,
+				ChecksumURL: "https://example.com/list.txt.sha256",
+			},
+			wantErr: true,
+		},
+		{name: "invalid checksum", integrity: IntegrityOptions{Checksum: "sha256:xyz"}, wantErr: true},
+		{name: "invalid checksum url", integrity: IntegrityOptions{ChecksumURL: "ftp://example.com/x"}, wantErr: true},
+		{
+			name:      "signature without public key",
+			integrity: IntegrityOptions{SignatureURL: "https://example.com/list.txt.sig"},
+			wantErr:   true,
+		},
+		{
+			name:      "public key without signature",
+			integrity: IntegrityOptions{Checksum: sha256Checksum, PublicKey: publicKey},
+			wantErr:   true,
+		},
+		{
+			name: "invalid public key",
+			integrity: IntegrityOptions{
+				SignatureURL: "https://example.com/list.txt.sig",
+				PublicKey:    "c2hvcnQ=",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.integrity.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	t.Parallel()
+
+	md5Checksum := strings.Repeat("b", 32)
+	sha256Checksum := strings.Repeat("c", 64)
+	sha512Checksum := strings.Repeat("d", 128)
+
+	tests := []struct {
+		checksum  string
+		algorithm string
+		digest    string
+		wantErr   bool
+	}{
+		{checksum: md5Checksum, algorithm: "md5", digest: md5Checksum},
+		{checksum: sha256Checksum, algorithm: "sha256", digest: sha256Checksum},
+		{checksum: sha512Checksum, algorithm: "sha512", digest: sha512Checksum},
+		{checksum: "SHA256:" + strings.ToUpper(sha256Checksum) + "\n", algorithm: "sha256", digest: sha256Checksum},
+		{checksum: "md5:" + sha256Checksum, wantErr: true},
+		{checksum: "sha256:abc", wantErr: true},
+		{checksum: "sha256:" + strings.Repeat("z", 64), wantErr: true},
+		{checksum: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		algorithm, digest, err := ParseChecksum(tt.checksum)
+		if tt.wantErr {
+			assert.Error(t, err, tt.checksum)
+			continue
+		}
+		require.NoError(t, err, tt.checksum)
+		assert.Equal(t, tt.algorithm, algorithm)
+		assert.Equal(t, tt.digest, digest)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	t.Parallel()
+
+	public, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	keyID := []byte{8, 7, 6, 5, 4, 3, 2, 1}
+
+	key, id, err := ParsePublicKey(base64.StdEncoding.EncodeToString(public))
+	require.NoError(t, err)
+	assert.Equal(t, public, key)
+	assert.Nil(t, id)
+
+	minisignKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), public...))
+	key, id, err = ParsePublicKey("untrusted comment: minisign public key 0102030405060708\n" + minisignKey + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, public, key)
+	assert.Equal(t, keyID, id)
+
+	_, _, err = ParsePublicKey("not base64!")
+	assert.Error(t, err)
+	_, _, err = ParsePublicKey(base64.StdEncoding.EncodeToString(append([]byte("XX"), make([]byte, 40)...)))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 
 	consts "github.com/phani-kb/dns-toolkit/internal/constants"
@@ -13,10 +14,11 @@ type Named interface {
 }
 
 type SourceType struct {
-	Name      string     `json:"name"`
-	Notes     string     `json:"notes,omitempty"`
-	ListTypes []ListType `json:"list_types,omitempty"`
-	Disabled  bool       `json:"disabled,omitempty"`
+	Name      string         `json:"name"`
+	Notes     string         `json:"notes,omitempty"`
+	ListTypes []ListType     `json:"list_types,omitempty"`
+	Processor *ProcessorSpec `json:"processor,omitempty"` // processor declared inline for the feed format
+	Disabled  bool           `json:"disabled,omitempty"`
 }
 
 func (st *SourceType) Validate() error {
@@ -71,21 +73,29 @@ func (lt *ListType) Validate() error {
 // DownloadSummary represents information about a downloaded DNS blocklist file.
 // It contains metadata about the source, content types, and download status.
 type DownloadSummary struct {
-	Name                        string       `json:"name"`                                    // Name of the source
-	URL                         string       `json:"url"`                                     // URL where the list was downloaded from
-	Filepath                    string       `json:"filepath"`                                // Path to the downloaded file
-	Frequency                   string       `json:"frequency"`                               // Frequency of updates
-	Checksum                    string       `json:"checksum"`                                // Checksum of the file content
-	Error                       string       `json:"error"`                                   // Error message if download failed
-	LastDownloadTimestamp       string       `json:"last_download_timestamp"`                 // Timestamp of the last successful download
-	LastCheckedTimestamp        string       `json:"last_checked_timestamp"`                  // Timestamp when last checked for updates
-	Types                       []SourceType `json:"types"`                                   // Array of entry types (ipv4, domain, etc.)
-	Categories                  []string     `json:"categories,omitempty"`                    // Categories this source belongs to
-	TypeCount                   int          `json:"type_count"`                              // Number of entry types in the source
-	CountToConsider             int          `json:"count_to_consider,omitempty"`             // Number of entries to consider
-	SkipGeneralConsolidation    bool         `json:"skip_general_consolidation,omitempty"`    // Whether to skip general consolidation
-	SkipGroupsConsolidation     bool         `json:"skip_groups_consolidation,omitempty"`     // Whether to skip group consolidation
-	SkipCategoriesConsolidation bool         `json:"skip_categories_consolidation,omitempty"` // Whether to skip category consolidation
+	Name                        string            `json:"name"`                                    // Name of the source
+	URL                         string            `json:"url"`                                     // URL where the list was downloaded from
+	ServedURL                   string            `json:"served_url,omitempty"`                    // URL that served the content, the url or one of its mirrors
+	Filepath                    string            `json:"filepath"`                                // Path to the downloaded file
+	Frequency                   string            `json:"frequency"`                               // Frequency of updates
+	Checksum                    string            `json:"checksum"`                                // Checksum of the file content
+	MirrorChecksums             map[string]string `json:"mirror_checksums,omitempty"`              // Checksum of the content served by each url that responded
+	MirrorsConsistent           *bool             `json:"mirrors_consistent,omitempty"`            // Whether all responding urls served the same content
+	ETag                        string            `json:"etag,omitempty"`                          // ETag validator returned by the server
+	LastModified                string            `json:"last_modified,omitempty"`                 // Last-Modified validator returned by the server
+	Error                       string            `json:"error"`                                   // Error message if download failed
+	EntryCount                  int               `json:"entry_count,omitempty"`                   // Number of non-comment lines in the downloaded file
+	Quarantined                 bool              `json:"quarantined,omitempty"`                   // Whether the last download was rejected by the anomaly guard
+	QuarantineReason            string            `json:"quarantine_reason,omitempty"`             // Why the anomaly guard rejected the last download
+	LastDownloadTimestamp       string            `json:"last_download_timestamp"`                 // Timestamp of the last successful download
+	LastCheckedTimestamp        string            `json:"last_checked_timestamp"`                  // Timestamp when last checked for updates
+	Types                       []SourceType      `json:"types"`                                   // Array of entry types (ipv4, domain, etc.)
+	Categories                  []string          `json:"categories,omitempty"`                    // Categories this source belongs to
+	TypeCount                   int               `json:"type_count"`                              // Number of entry types in the source
+	CountToConsider             int               `json:"count_to_consider,omitempty"`             // Number of entries to consider
+	SkipGeneralConsolidation    bool              `json:"skip_general_consolidation,omitempty"`    // Whether to skip general consolidation
+	SkipGroupsConsolidation     bool              `json:"skip_groups_consolidation,omitempty"`     // Whether to skip group consolidation
+	SkipCategoriesConsolidation bool              `json:"skip_categories_consolidation,omitempty"` // Whether to skip category consolidation
 }
 
 func (ds *DownloadSummary) ToJSON() string {
@@ -159,6 +169,18 @@ func (ds *DownloadSummary) UnmarshalJSON(data []byte) error {
 					sourceType.Disabled = disabled
 				}
 
+				// Extract the declared processor if present
+				if processorRaw, ok := typeItem["processor"]; ok && processorRaw != nil {
+					processorData, err := json.Marshal(processorRaw)
+					if err != nil {
+						return err
+					}
+					sourceType.Processor = &ProcessorSpec{}
+					if err := json.Unmarshal(processorData, sourceType.Processor); err != nil {
+						return fmt.Errorf("invalid processor of type %s: %w", sourceType.Name, err)
+					}
+				}
+
 				// Extract list_types if present
 				if listTypesRaw, ok := typeItem["list_types"].([]interface{}); ok {
 					for _, ltRaw := range listTypesRaw {
@@ -202,40 +224,179 @@ func (ds *DownloadSummary) UnmarshalJSON(data []byte) error {
 }
 
 type DownloadFile struct {
-	Name      string           `json:"name"`
-	Folder    string           `json:"folder"`
-	Filename  string           `json:"filename"`
-	URL       string           `json:"url"`
-	Frequency string           `json:"frequency"`
-	Targets   []DownloadTarget `json:"targets"`
-	IsArchive bool             `json:"is_archive"`
+	Name            string            `json:"name"`
+	Folder          string            `json:"folder"`
+	Filename        string            `json:"filename"`
+	URL             string            `json:"url"`
+	Mirrors         []string          `json:"mirrors,omitempty"` // fallback urls, tried in order
+	Frequency       string            `json:"frequency"`
+	ETag            string            `json:"etag,omitempty"`             // validator from the previous download
+	LastModified    string            `json:"last_modified,omitempty"`    // validator from the previous download
+	ServedURL       string            `json:"served_url,omitempty"`       // set by the downloader
+	MirrorChecksums map[string]string `json:"mirror_checksums,omitempty"` // set by the downloader when verifying mirrors
+	HTTP            *HTTPOptions      `json:"http,omitempty"`
+	Exec            *ExecOptions      `json:"exec,omitempty"`
+	Integrity       *IntegrityOptions `json:"integrity,omitempty"` // verified before the previous copy is replaced
+	Proxy           *ProxyConfig      `json:"-"`
+	Hosts           *HostLimiter      `json:"-"` // per-host limits shared by the downloads of a run
+	Guard           *AnomalyGuard     `json:"-"` // compared with the previous download before it is replaced
+	Targets         []DownloadTarget  `json:"targets"`
+	ExtractFolder   string            `json:"extract_folder,omitempty"` // cleared before extraction, the download folder if empty
+	IsArchive       bool              `json:"is_archive"`
+	VerifyMirrors   bool              `json:"verify_mirrors,omitempty"`
+}
+
+// MirrorsConsistent reports whether every url that responded during mirror verification served the same content.
+func (df *DownloadFile) MirrorsConsistent() bool {
+	var first string
+	for _, checksum := range df.MirrorChecksums {
+		if first == "" {
+			first = checksum
+		} else if checksum != first {
+			return false
+		}
+	}
+	return true
+}
+
+// HTTPOptions are per-source options for the HTTP requests made to download a source.
+// Header values and basic auth credentials may reference environment variables as ${NAME}.
+type HTTPOptions struct {
+	Headers            map[string]string `json:"headers,omitempty"`
+	BasicAuth          *BasicAuth        `json:"basic_auth,omitempty"`
+	UserAgent          string            `json:"user_agent,omitempty"`
+	Timeout            int               `json:"timeout,omitempty"` // seconds
+	MaxRetries         int               `json:"max_retries,omitempty"`
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"`
+	SkipProxy          bool              `json:"skip_proxy,omitempty"` // connect directly even if a proxy is configured
+}
+
+// ExecOptions are per-source options for the exec downloader, which runs a command to fetch a source.
+// The command gets the source name, url and target path in the DNS_TOOLKIT_SOURCE, DNS_TOOLKIT_URL and
+// DNS_TOOLKIT_TARGET environment variables and either prints the list or writes it to the target path.
+// Args and env values may reference environment variables as ${NAME}.
+type ExecOptions struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Timeout int               `json:"timeout,omitempty"` // seconds, 300 if 0
+}
+
+// ProcessorSpec declares the processor of a source type inline, for a feed format that does not need
+// a processor of its own. The fields used depend on the kind: column, delimiter and skip_header for csv,
+// pattern and group for regex, path for json, and selector, attribute and extract for html.
+type ProcessorSpec struct {
+	Kind       string `json:"kind"`                  // csv, regex, json or html
+	Column     int    `json:"column,omitempty"`      // zero-based column of the entry
+	Delimiter  string `json:"delimiter,omitempty"`   // column delimiter, a comma if empty
+	SkipHeader bool   `json:"skip_header,omitempty"` // whether the first row is a header
+	Pattern    string `json:"pattern,omitempty"`     // regular expression matched against each line
+	Group      int    `json:"group,omitempty"`       // capture group of the entry, the whole match if 0
+	Path       string `json:"path,omitempty"`        // JSONPath of the entries, such as $.data[*].host
+	Selector   string `json:"selector,omitempty"`    // CSS selector of the elements holding the entries
+	Attribute  string `json:"attribute,omitempty"`   // attribute holding the entry, the element text if empty
+	Extract    string `json:"extract,omitempty"`     // domain, ipv4 or url_host
+}
+
+func (o *ExecOptions) Validate() error {
+	if strings.TrimSpace(o.Command) == "" {
+		return fmt.Errorf("command is required")
+	}
+	for name := range o.Env {
+		if strings.TrimSpace(name) == "" || strings.Contains(name, "=") {
+			return fmt.Errorf("invalid env name: %q", name)
+		}
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	return nil
 }
 
+// ProxyConfig is the outbound proxy used for downloads.
+// Credentials may reference environment variables as ${NAME}.
+type ProxyConfig struct {
+	URL      string   `yaml:"url"` // http, https or socks5 proxy URL
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	NoProxy  []string `yaml:"no_proxy,omitempty"` // hosts, domains (.example.com), IPs or CIDRs to reach directly
+}
+
+func (p *ProxyConfig) Validate() error {
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("unsupported proxy scheme: %s", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("proxy host is required: %s", p.URL)
+	}
+	if p.Password != "" && p.Username == "" {
+		return fmt.Errorf("proxy username is required when a password is set")
+	}
+	return nil
+}
+
+type BasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (o *HTTPOptions) Validate() error {
+	for name := range o.Headers {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("header name is required")
+		}
+	}
+	if o.BasicAuth != nil && o.BasicAuth.Username == "" {
+		return fmt.Errorf("basic_auth username is required")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %d", o.Timeout)
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("invalid max_retries: %d", o.MaxRetries)
+	}
+	return nil
+}
+
+// DownloadTarget is a file taken from a download. SourceFile may be a glob pattern matching a single file;
+// when SourceFiles is set, the files matching each of its names or patterns are concatenated into the target.
 type DownloadTarget struct {
-	SourceFolder string `json:"source_folder"`
-	SourceFile   string `json:"source_file"`
-	TargetFolder string `json:"target_folder"`
-	TargetFile   string `json:"target_file"`
+	SourceFolder string   `json:"source_folder"`
+	SourceFile   string   `json:"source_file"`
+	SourceFiles  []string `json:"source_files,omitempty"`
+	TargetFolder string   `json:"target_folder"`
+	TargetFile   string   `json:"target_file"`
 }
 
 // nolint:lll
 // ProcessedFile contains information about a file after it has been processed.
 // It includes metadata about the content type and validation results.
 type ProcessedFile struct {
-	Name                        string   `json:"name"`                                    // Name of the source
-	GenericSourceType           string   `json:"generic_source_type"`                     // Generic categorization of the content (domain, ipv4, etc.)
-	ActualSourceType            string   `json:"actual_source_type"`                      // Specific source type detected
-	ListType                    string   `json:"list_type"`                               // Type of list (blocklist or allowlist)
-	Filepath                    string   `json:"filepath"`                                // Path of the processed file
-	Checksum                    string   `json:"checksum"`                                // Checksum of the file content
-	Groups                      []string `json:"groups,omitempty"`                        // Size groups this file belongs to (mini, lite, normal, big)
-	Categories                  []string `json:"categories,omitempty"`                    // Categories this file belongs to
-	NumberOfEntries             int      `json:"number_of_entries"`                       // Count of entries in the file
-	MustConsider                bool     `json:"must_consider,omitempty"`                 // Whether the file must be considered
-	Valid                       bool     `json:"valid"`                                   // Whether the file contains valid entries
-	SkipGeneralConsolidation    bool     `json:"skip_general_consolidation,omitempty"`    // Whether to skip general consolidation
-	SkipGroupsConsolidation     bool     `json:"skip_groups_consolidation,omitempty"`     // Whether to skip group consolidation
-	SkipCategoriesConsolidation bool     `json:"skip_categories_consolidation,omitempty"` // Whether to skip category consolidation
+	Name                        string              `json:"name"`                                    // Name of the source
+	GenericSourceType           string              `json:"generic_source_type"`                     // Generic categorization of the content (domain, ipv4, etc.)
+	ActualSourceType            string              `json:"actual_source_type"`                      // Specific source type detected
+	ListType                    string              `json:"list_type"`                               // Type of list (blocklist or allowlist)
+	Filepath                    string              `json:"filepath"`                                // Path of the processed file
+	DownloadFilepath            string              `json:"download_filepath,omitempty"`             // Path of the downloaded file the entries were processed from
+	DownloadChecksum            string              `json:"download_checksum,omitempty"`             // Checksum of the downloaded file the entries were processed from
+	Checksum                    string              `json:"checksum"`                                // Checksum of the file content
+	Groups                      []string            `json:"groups,omitempty"`                        // Size groups this file belongs to (mini, lite, normal, big)
+	Categories                  []string            `json:"categories,omitempty"`                    // Categories this file belongs to
+	NumberOfEntries             int                 `json:"number_of_entries"`                       // Count of entries in the file
+	MustConsider                bool                `json:"must_consider,omitempty"`                 // Whether the file must be considered
+	Valid                       bool                `json:"valid"`                                   // Whether the file contains valid entries
+	MatchSemantics              string              `json:"match_semantics,omitempty"`               // How the entries match hostnames (exact or subdomains), empty if unknown
+	InvalidReasons              map[string]int      `json:"invalid_reasons,omitempty"`               // Number of invalid entries by reason code, for the invalid files
+	InvalidSamples              map[string][]string `json:"invalid_samples,omitempty"`               // First invalid entries of each reason code, for the invalid files
+	SkipGeneralConsolidation    bool                `json:"skip_general_consolidation,omitempty"`    // Whether to skip general consolidation
+	SkipGroupsConsolidation     bool                `json:"skip_groups_consolidation,omitempty"`     // Whether to skip group consolidation
+	SkipCategoriesConsolidation bool                `json:"skip_categories_consolidation,omitempty"` // Whether to skip category consolidation
 }
 
 // UnmarshalJSON implements custom JSON unmarshalling for ProcessedFile to handle the group array
@@ -282,14 +443,19 @@ func (pf *ProcessedFile) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// nolint:lll
 // ProcessedSummary summarizes the results of processing a source file.
 // It tracks both valid and invalid entries found during processing.
 type ProcessedSummary struct {
-	Name                   string          `json:"name"`                     // Source name
-	LastProcessedTimestamp string          `json:"last_processed_timestamp"` // When processing was completed
-	Types                  []SourceType    `json:"types"`                    // Content types in the source
-	ValidFiles             []ProcessedFile `json:"valid_files,omitempty"`    // Map of valid files by type
-	InvalidFiles           []ProcessedFile `json:"invalid_files,omitempty"`  // Map of invalid files by type
+	Name                   string          `json:"name"`                         // Source name
+	LastProcessedTimestamp string          `json:"last_processed_timestamp"`     // When processing was completed
+	Types                  []SourceType    `json:"types"`                        // Content types in the source
+	ValidFiles             []ProcessedFile `json:"valid_files,omitempty"`        // Map of valid files by type
+	InvalidFiles           []ProcessedFile `json:"invalid_files,omitempty"`      // Map of invalid files by type
+	ConfigFingerprint      string          `json:"config_fingerprint,omitempty"` // Fingerprint of the type/list-type configuration used
+	ProcessorVersion       string          `json:"processor_version,omitempty"`  // Version of the processing logic used
+	Quarantined            bool            `json:"quarantined,omitempty"`        // Whether the last processing result was rejected by the anomaly guard
+	QuarantineReason       string          `json:"quarantine_reason,omitempty"`  // Why the anomaly guard rejected the last processing result
 }
 
 func (ps *ProcessedSummary) GetSourceTypes() []SourceType {
@@ -649,3 +815,38 @@ type ArchiveSummaryFile struct {
 func (asf *ArchiveSummaryFile) GetName() string {
 	return asf.Name
 }
+
+// RunState tracks the progress of a pipeline run across its stages.
+// It is persisted after every stage transition so an interrupted run can be resumed.
+type RunState struct {
+	StartedAt   string              `json:"started_at"`            // Timestamp when the run started
+	UpdatedAt   string              `json:"updated_at"`            // Timestamp of the last state change
+	Stages      []RunStageState     `json:"stages"`                // State of each pipeline stage, in pipeline order
+	Quarantined []QuarantinedSource `json:"quarantined,omitempty"` // Sources whose last-known-good copy was kept
+}
+
+// QuarantinedSource is a source whose last-known-good copy was kept by the anomaly guard.
+type QuarantinedSource struct {
+	Name   string `json:"name"`   // Name of the source
+	Stage  string `json:"stage"`  // Stage that rejected the result, download or process
+	Reason string `json:"reason"` // Why the result was rejected
+}
+
+// RunStageState records the status of a single pipeline stage.
+type RunStageState struct {
+	Name      string `json:"name"`                 // Name of the stage
+	Status    string `json:"status"`               // pending, running, completed, failed or skipped
+	StartedAt string `json:"started_at,omitempty"` // Timestamp when the stage started
+	EndedAt   string `json:"ended_at,omitempty"`   // Timestamp when the stage ended
+	Error     string `json:"error,omitempty"`      // Reason the stage failed or was skipped
+}
+
+// GetStage returns the state of the named stage, or nil if the stage is not tracked.
+func (rs *RunState) GetStage(name string) *RunStageState {
+	for i := range rs.Stages {
+		if rs.Stages[i].Name == name {
+			return &rs.Stages[i]
+		}
+	}
+	return nil
+}
@@ -220,6 +220,38 @@ func TestDownloadSummary_UnmarshalJSON(t *testing.T) {
 				Categories: []string{},
 			},
 		},
+		{
+			name: "declared processor",
+			jsonData: `{
+				"name": "test",
+				"types": [
+					{
+						"name": "domain",
+						"processor": {"kind": "csv", "column": 1, "delimiter": ";", "skip_header": true},
+						"list_types": [{"name": "blocklist"}]
+					}
+				]
+			}`,
+			expected: DownloadSummary{
+				Name: "test",
+				Types: []SourceType{
+					{
+						Name:      "domain",
+						Processor: &ProcessorSpec{Kind: "csv", Column: 1, Delimiter: ";", SkipHeader: true},
+						ListTypes: []ListType{{Name: "blocklist"}},
+					},
+				},
+				Categories: []string{},
+			},
+		},
+		{
+			name: "invalid processor",
+			jsonData: `{
+				"name": "test",
+				"types": [{"name": "domain", "processor": {"column": "first"}}]
+			}`,
+			hasError: true,
+		},
 		{
 			name: "invalid types field",
 			jsonData: `{
@@ -708,3 +740,77 @@ func TestTopSummaryMarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestProxyConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   ProxyConfig
+		wantErr bool
+	}{
+		{name: "http proxy", proxy: ProxyConfig{URL: "http://proxy.local:3128"}},
+		{name: "socks5 proxy", proxy: ProxyConfig{URL: "socks5://127.0.0.1:1080"}},
+		{
+			name:  "proxy with credentials",
+			proxy: ProxyConfig{URL: "https://proxy.local", Username: "${PROXY_USER}", Password: "${PROXY_PASS}"},
+		},
+		{name: "missing scheme", proxy: ProxyConfig{URL: "proxy.local:3128"}, wantErr: true},
+		{name: "unsupported scheme", proxy: ProxyConfig{URL: "ftp://proxy.local"}, wantErr: true},
+		{name: "missing host", proxy: ProxyConfig{URL: "http://"}, wantErr: true},
+		{name: "password without username", proxy: ProxyConfig{URL: "http://proxy.local", Password: "secret"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.proxy.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExecOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		exec    ExecOptions
+		wantErr bool
+	}{
+		{name: "command", exec: ExecOptions{Command: "fetch-list.sh"}},
+		{
+			name: "command with args and env",
+			exec: ExecOptions{
+				Command: "curl",
+				Args:    []string{"-fsS", "${PORTAL_URL}"},
+				Env:     map[string]string{"TOKEN": "${PORTAL_TOKEN}"},
+				Timeout: 30,
+			},
+		},
+		{name: "missing command", exec: ExecOptions{Args: []string{"-v"}}, wantErr: true},
+		{name: "invalid env name", exec: ExecOptions{Command: "sh", Env: map[string]string{"A=B": "c"}}, wantErr: true},
+		{name: "negative timeout", exec: ExecOptions{Command: "sh", Timeout: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.exec.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDownloadFile_MirrorsConsistent(t *testing.T) {
+	file := DownloadFile{}
+	assert.True(t, file.MirrorsConsistent())
+
+	file.MirrorChecksums = map[string]string{"https://a.example": "abc", "https://b.example": "abc"}
+	assert.True(t, file.MirrorsConsistent())
+
+	file.MirrorChecksums["https://c.example"] = "def"
+	assert.False(t, file.MirrorsConsistent())
+}
@@ -10,42 +10,62 @@ import (
 
 // DTWorkerPool provides a fixed-size pool of workers for parallel task processing
 type DTWorkerPool struct {
-	limiter   *rate.Limiter // used to limit task start rate
-	semaphore chan struct{} // Used to limit concurrency
+	ctx       context.Context // tasks are not started once the context is cancelled
+	limiter   *rate.Limiter   // used to limit task start rate
+	semaphore chan struct{}   // Used to limit concurrency
 	wg        sync.WaitGroup
 }
 
 // NewDTWorkerPool creates a new worker pool with specified capacity
 // If maxWorkers is <= 0, it defaults to number of CPUs
 func NewDTWorkerPool(maxWorkers int) *DTWorkerPool {
+	return NewDTWorkerPoolWithContext(context.Background(), maxWorkers)
+}
+
+// NewDTWorkerPoolWithContext creates a worker pool that stops starting new tasks once ctx is cancelled
+// If maxWorkers is <= 0, it defaults to number of CPUs
+func NewDTWorkerPoolWithContext(ctx context.Context, maxWorkers int) *DTWorkerPool {
 	if maxWorkers <= 0 {
 		maxWorkers = runtime.GOMAXPROCS(0)
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	return &DTWorkerPool{
+		ctx:       ctx,
 		semaphore: make(chan struct{}, maxWorkers),
 	}
 }
 
 // NewDTWorkerPoolWithLimiter creates a worker pool with a rate limiter controlling task start rate
-func NewDTWorkerPoolWithLimiter(maxWorkers int, limiter *rate.Limiter) *DTWorkerPool {
-	pool := NewDTWorkerPool(maxWorkers)
+func NewDTWorkerPoolWithLimiter(ctx context.Context, maxWorkers int, limiter *rate.Limiter) *DTWorkerPool {
+	pool := NewDTWorkerPoolWithContext(ctx, maxWorkers)
 	pool.limiter = limiter
 	return pool
 }
 
-// Submit submits a task to the pool and blocks if the pool is at capacity
+// Submit submits a task to the pool and blocks if the pool is at capacity.
+// Tasks submitted after the pool's context is cancelled are dropped.
 func (p *DTWorkerPool) Submit(task func()) {
+	select {
+	case <-p.ctx.Done():
+		return
+	case p.semaphore <- struct{}{}: // Acquire semaphore
+	}
 	p.wg.Add(1)
-	p.semaphore <- struct{}{} // Acquire semaphore
 
 	go func() {
 		defer p.wg.Done()
 		defer func() { <-p.semaphore }() // Release semaphore
 
+		if p.ctx.Err() != nil {
+			return
+		}
+
 		if p.limiter != nil {
-			if err := p.limiter.Wait(context.Background()); err != nil {
-				// the limiter fails
+			if err := p.limiter.Wait(p.ctx); err != nil {
+				// the limiter fails or the context is cancelled
 				return
 			}
 		}
@@ -58,3 +78,88 @@ func (p *DTWorkerPool) Submit(task func()) {
 func (p *DTWorkerPool) Wait() {
 	p.wg.Wait()
 }
+
+// Err returns the error of the pool's context, non-nil once it has been cancelled
+func (p *DTWorkerPool) Err() error {
+	return p.ctx.Err()
+}
+
+// FairScheduler runs keyed tasks on a worker pool, taking turns between keys so that a key with
+// many tasks does not hold up the others, and never running more tasks of a key at once than its limit.
+// Tasks are added with Add before calling Run.
+type FairScheduler struct {
+	pool   *DTWorkerPool
+	limit  func(key string) int // maximum running tasks of a key, unlimited if <= 0
+	queues map[string][]func()
+	keys   []string // keys in the order they were first added
+	total  int
+}
+
+// NewFairScheduler creates a scheduler for pool with the given per-key concurrency limit, which may be nil
+func NewFairScheduler(pool *DTWorkerPool, limit func(key string) int) *FairScheduler {
+	if limit == nil {
+		limit = func(string) int { return 0 }
+	}
+	return &FairScheduler{
+		pool:   pool,
+		limit:  limit,
+		queues: make(map[string][]func()),
+	}
+}
+
+// Add queues a task under key
+func (s *FairScheduler) Add(key string, task func()) {
+	if _, exists := s.queues[key]; !exists {
+		s.keys = append(s.keys, key)
+	}
+	s.queues[key] = append(s.queues[key], task)
+	s.total++
+}
+
+// Run submits the queued tasks to the pool in round-robin order across keys and waits for them to complete.
+// Once the pool's context is cancelled, no more tasks are submitted.
+func (s *FairScheduler) Run() {
+	done := make(chan string, s.total)
+	running := make(map[string]int)
+	pending := s.total
+	next := 0
+
+	for pending > 0 {
+		key, task, ok := s.nextTask(running, &next)
+		if !ok {
+			select {
+			case finished := <-done:
+				running[finished]--
+			case <-s.pool.ctx.Done():
+				s.pool.Wait()
+				return
+			}
+			continue
+		}
+		pending--
+		running[key]++
+		s.pool.Submit(func() {
+			defer func() { done <- key }()
+			task()
+		})
+	}
+	s.pool.Wait()
+}
+
+// nextTask pops the task of the next key, after the last one served, that has tasks and is under its limit
+func (s *FairScheduler) nextTask(running map[string]int, next *int) (string, func(), bool) {
+	for i := 0; i < len(s.keys); i++ {
+		key := s.keys[(*next+i)%len(s.keys)]
+		queue := s.queues[key]
+		if len(queue) == 0 {
+			continue
+		}
+		if limit := s.limit(key); limit > 0 && running[key] >= limit {
+			continue
+		}
+		s.queues[key] = queue[1:]
+		*next = (*next + i + 1) % len(s.keys)
+		return key, queue[0], true
+	}
+	return "", nil, false
+}
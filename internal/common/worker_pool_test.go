@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -72,3 +73,139 @@ func TestDTWorkerPool_Concurrency(t *testing.T) {
 
 	assert.LessOrEqual(t, maxConcurrentWorkers, int32(maxWorkers))
 }
+
+func TestDTWorkerPool_Cancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewDTWorkerPoolWithContext(ctx, 1)
+
+	var counter int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	pool.Submit(func() {
+		close(started)
+		<-release
+		atomic.AddInt32(&counter, 1)
+	})
+	<-started
+
+	cancel()
+	close(release)
+
+	// Submitted after cancellation: must not run
+	for i := 0; i < 5; i++ {
+		pool.Submit(func() {
+			atomic.AddInt32(&counter, 1)
+		})
+	}
+	pool.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter), "only the task started before cancellation should run")
+	assert.ErrorIs(t, pool.Err(), context.Canceled)
+}
+
+func TestNewDTWorkerPoolWithContext_NilContext(t *testing.T) {
+	t.Parallel()
+
+	//nolint:staticcheck // a nil context falls back to context.Background
+	pool := NewDTWorkerPoolWithContext(nil, 1)
+	var counter int32
+	pool.Submit(func() { atomic.AddInt32(&counter, 1) })
+	pool.Wait()
+	assert.Equal(t, int32(1), counter)
+	assert.NoError(t, pool.Err())
+}
+
+func TestFairScheduler_RoundRobin(t *testing.T) {
+	t.Parallel()
+
+	pool := NewDTWorkerPool(1)
+	scheduler := NewFairScheduler(pool, nil)
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	for _, name := range []string{"a1", "a2", "a3"} {
+		scheduler.Add("a", record(name))
+	}
+	scheduler.Add("b", record("b1"))
+	scheduler.Add("c", record("c1"))
+	scheduler.Add("c", record("c2"))
+
+	scheduler.Run()
+
+	assert.Equal(t, []string{"a1", "b1", "c1", "a2", "c2", "a3"}, order)
+}
+
+func TestFairScheduler_LimitPerKey(t *testing.T) {
+	t.Parallel()
+
+	pool := NewDTWorkerPool(4)
+	scheduler := NewFairScheduler(pool, func(key string) int {
+		if key == "limited" {
+			return 1
+		}
+		return 0
+	})
+
+	var current, maxConcurrent, others int32
+	for i := 0; i < 5; i++ {
+		scheduler.Add("limited", func() {
+			count := atomic.AddInt32(&current, 1)
+			for {
+				seen := atomic.LoadInt32(&maxConcurrent)
+				if count <= seen || atomic.CompareAndSwapInt32(&maxConcurrent, seen, count) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+		})
+		scheduler.Add("other", func() {
+			atomic.AddInt32(&others, 1)
+		})
+	}
+
+	scheduler.Run()
+
+	assert.Equal(t, int32(1), maxConcurrent)
+	assert.Equal(t, int32(5), others)
+}
+
+func TestFairScheduler_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewDTWorkerPoolWithContext(ctx, 2)
+	scheduler := NewFairScheduler(pool, func(string) int { return 1 })
+
+	var executed int32
+	for i := 0; i < 5; i++ {
+		scheduler.Add("host", func() {
+			atomic.AddInt32(&executed, 1)
+			cancel()
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	assert.Equal(t, int32(1), executed)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -38,18 +39,24 @@ type FilesChecksumConfig struct {
 }
 
 type DNSToolkitConfig struct {
-	SourceFiles               []string            `yaml:"source_files"`
-	SkipCertVerificationHosts []string            `yaml:"skip_cert_verification_hosts,omitempty"`
-	Folders                   FoldersConfig       `yaml:"folders"`
-	SourceFilters             SourceFilters       `yaml:"source_filters"`
-	FilesChecksum             FilesChecksumConfig `yaml:"files_checksum"`
-	Override                  OverrideConfig      `yaml:"override,omitempty"`
-	MaxWorkers                int                 `yaml:"max_workers"`
-	MaxRetries                int                 `yaml:"max_retries"`
-	SkipUnchangedDownloads    bool                `yaml:"skip_unchanged_downloads"`
-	SkipCertVerification      bool                `yaml:"skip_cert_verification,omitempty"`
-	SkipNameSpecialCharsCheck bool                `yaml:"skip_name_special_chars_check,omitempty"`
-	MinOverlapPercent         float64             `yaml:"min_overlap_percent,omitempty"`
+	SourceFiles               []string             `yaml:"source_files"`
+	Folders                   FoldersConfig        `yaml:"folders"`
+	SourceFilters             SourceFilters        `yaml:"source_filters"`
+	FilesChecksum             FilesChecksumConfig  `yaml:"files_checksum"`
+	Override                  OverrideConfig       `yaml:"override,omitempty"`
+	MaxWorkers                int                  `yaml:"max_workers"`
+	MaxRetries                int                  `yaml:"max_retries"`
+	SkipUnchangedDownloads    bool                 `yaml:"skip_unchanged_downloads"`
+	SkipNameSpecialCharsCheck bool                 `yaml:"skip_name_special_chars_check,omitempty"`
+	MinOverlapPercent         float64              `yaml:"min_overlap_percent,omitempty"`
+	Proxy                     *c.ProxyConfig       `yaml:"proxy,omitempty"`
+	HostLimits                []c.HostLimitConfig  `yaml:"host_limits,omitempty"`
+	CircuitBreakerFailures    int                  `yaml:"circuit_breaker_failures,omitempty"`
+	AnomalyGuard              c.AnomalyGuardConfig `yaml:"anomaly_guard,omitempty"`
+	// Deprecated: set insecure_skip_verify in the http options of the sources instead.
+	SkipCertVerification bool `yaml:"skip_cert_verification,omitempty"`
+	// Deprecated: set insecure_skip_verify in the http options of the sources instead.
+	SkipCertVerificationHosts []string `yaml:"skip_cert_verification_hosts,omitempty"`
 }
 
 type OverrideConfig struct {
@@ -79,6 +86,22 @@ func (dc *DNSToolkitConfig) Validate() error {
 		}
 	}
 
+	if dc.Proxy != nil {
+		if err := dc.Proxy.Validate(); err != nil {
+			return fmt.Errorf("proxy validation error: %w", err)
+		}
+	}
+
+	for i := range dc.HostLimits {
+		if err := dc.HostLimits[i].Validate(); err != nil {
+			return fmt.Errorf("host limits validation error: %w", err)
+		}
+	}
+
+	if err := dc.AnomalyGuard.Validate(); err != nil {
+		return fmt.Errorf("anomaly guard validation error: %w", err)
+	}
+
 	if dc.MaxWorkers > runtime.GOMAXPROCS(0) {
 		dc.MaxWorkers = runtime.GOMAXPROCS(0)
 	}
@@ -93,6 +116,63 @@ func (dc *DNSToolkitConfig) GetMinOverlapPercent() float64 {
 	return constants.MinOverlapPercent
 }
 
+// GetCircuitBreakerFailures returns the consecutive failures after which a host is skipped
+// for the rest of the run; a negative value disables the circuit breaker.
+func (dc *DNSToolkitConfig) GetCircuitBreakerFailures() int {
+	if dc.CircuitBreakerFailures != 0 {
+		return dc.CircuitBreakerFailures
+	}
+	return constants.DefaultCircuitBreakerFailures
+}
+
+// GetProxy returns the proxy to use for the given source, or nil when none is configured
+// or the source opts out.
+func (dc *DNSToolkitConfig) GetProxy(source Source) *c.ProxyConfig {
+	if dc.Proxy == nil || (source.HTTP != nil && source.HTTP.SkipProxy) {
+		return nil
+	}
+	return dc.Proxy
+}
+
+// GetHTTP returns the http options to use for the given source. While the deprecated
+// skip_cert_verification is set, a source whose host contains one of skip_cert_verification_hosts
+// still skips certificate verification.
+func (dc *DNSToolkitConfig) GetHTTP(source Source) *c.HTTPOptions {
+	if !dc.SkipCertVerification || (source.HTTP != nil && source.HTTP.InsecureSkipVerify) {
+		return source.HTTP
+	}
+	parsedURL, err := url.Parse(source.URL)
+	if err != nil || !containsHost(dc.SkipCertVerificationHosts, parsedURL.Host) {
+		return source.HTTP
+	}
+	var httpOptions c.HTTPOptions
+	if source.HTTP != nil {
+		httpOptions = *source.HTTP
+	}
+	httpOptions.InsecureSkipVerify = true
+	return &httpOptions
+}
+
+// warnDeprecated logs the deprecated settings of the config that are still in use.
+func (dc *DNSToolkitConfig) warnDeprecated(logger *multilog.Logger) {
+	if dc.SkipCertVerification || len(dc.SkipCertVerificationHosts) > 0 {
+		logger.Warnf(
+			"skip_cert_verification and skip_cert_verification_hosts are deprecated, " +
+				"set insecure_skip_verify in the http options of the sources instead",
+		)
+	}
+}
+
+// containsHost reports whether host contains any of hosts.
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h != "" && strings.Contains(host, h) {
+			return true
+		}
+	}
+	return false
+}
+
 // validateSourceFile checks if a source file exists, handling relative paths in test mode
 func validateSourceFile(sourceFile string) error {
 	resolvedPath := resolveFilePath(sourceFile)
@@ -176,6 +256,7 @@ func LoadAppConfig(logger *multilog.Logger, configPath string) (AppConfig, []Sou
 	if err := ValidateAppConfig(appConfig); err != nil {
 		return AppConfig{}, nil, err
 	}
+	appConfig.DNSToolkit.warnDeprecated(logger)
 
 	var sourcesConfigs []SourcesConfig
 	for _, sourceFile := range appConfig.DNSToolkit.SourceFiles {
@@ -193,6 +274,9 @@ func LoadAppConfig(logger *multilog.Logger, configPath string) (AppConfig, []Sou
 		if err := sourcesConfig.ValidateWithConfig(&appConfig); err != nil {
 			return AppConfig{}, nil, fmt.Errorf("validating sources config %s: %w", sourceFile, err)
 		}
+		if err := sourcesConfig.RegisterDeclaredProcessors(); err != nil {
+			return AppConfig{}, nil, fmt.Errorf("registering processors of %s: %w", sourceFile, err)
+		}
 
 		sourcesConfigs = append(sourcesConfigs, sourcesConfig)
 	}
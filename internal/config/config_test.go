@@ -186,6 +186,230 @@ func TestSourceValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Valid http options",
+			source: Source{
+				Name:  "test-source",
+				URL:   "http://example.com",
+				Types: []c.SourceType{{Name: "domain"}},
+				HTTP: &c.HTTPOptions{
+					Headers:   map[string]string{"Auth-Key": "${API_KEY}"},
+					BasicAuth: &c.BasicAuth{Username: "user", Password: "${PASSWORD}"},
+					Timeout:   120,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Http options with negative timeout",
+			source: Source{
+				Name:  "test-source",
+				URL:   "http://example.com",
+				Types: []c.SourceType{{Name: "domain"}},
+				HTTP:  &c.HTTPOptions{Timeout: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Http options with basic auth without username",
+			source: Source{
+				Name:  "test-source",
+				URL:   "http://example.com",
+				Types: []c.SourceType{{Name: "domain"}},
+				HTTP:  &c.HTTPOptions{BasicAuth: &c.BasicAuth{Password: "secret"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid exec downloader",
+			source: Source{
+				Name:       "test-source",
+				URL:        "https://portal.example.com/list.txt",
+				Types:      []c.SourceType{{Name: "domain"}},
+				Downloader: "exec",
+				Exec: &c.ExecOptions{
+					Command: "fetch-list.sh",
+					Args:    []string{"--token", "${PORTAL_TOKEN}"},
+					Env:     map[string]string{"PORTAL_USER": "${PORTAL_USER}"},
+					Timeout: 60,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Exec downloader without exec options",
+			source: Source{
+				Name:       "test-source",
+				URL:        "https://portal.example.com/list.txt",
+				Types:      []c.SourceType{{Name: "domain"}},
+				Downloader: "exec",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Exec options without exec downloader",
+			source: Source{
+				Name:  "test-source",
+				URL:   "https://portal.example.com/list.txt",
+				Types: []c.SourceType{{Name: "domain"}},
+				Exec:  &c.ExecOptions{Command: "fetch-list.sh"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Exec downloader with mirrors",
+			source: Source{
+				Name:       "test-source",
+				URL:        "https://portal.example.com/list.txt",
+				Mirrors:    []string{"https://mirror.example.org/list.txt"},
+				Types:      []c.SourceType{{Name: "domain"}},
+				Downloader: "exec",
+				Exec:       &c.ExecOptions{Command: "fetch-list.sh"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Exec downloader without command",
+			source: Source{
+				Name:       "test-source",
+				URL:        "https://portal.example.com/list.txt",
+				Types:      []c.SourceType{{Name: "domain"}},
+				Downloader: "exec",
+				Exec:       &c.ExecOptions{Timeout: 10},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid mirrors",
+			source: Source{
+				Name:          "test-source",
+				URL:           "https://example.com/list.txt",
+				Mirrors:       []string{"https://mirror1.example.org/list.txt", "http://mirror2.example.net/list"},
+				VerifyMirrors: true,
+				Types:         []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid mirror url",
+			source: Source{
+				Name:    "test-source",
+				URL:     "https://example.com/list.txt",
+				Mirrors: []string{"mirror.example.org/list.txt"},
+				Types:   []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Mirror same as url",
+			source: Source{
+				Name:    "test-source",
+				URL:     "https://example.com/list.txt",
+				Mirrors: []string{"https://example.com/list.txt"},
+				Types:   []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Mirror with different archive type",
+			source: Source{
+				Name:    "test-source",
+				URL:     "https://example.com/list.zip",
+				Files:   []string{"list.txt"},
+				Mirrors: []string{"https://mirror.example.org/list.tar.gz"},
+				Types:   []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Mirrors without url",
+			source: Source{
+				Name:    "test-source",
+				Content: []string{"example.com"},
+				Mirrors: []string{"https://mirror.example.org/list.txt"},
+				Types:   []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Compressed url",
+			source: Source{
+				Name:  "test-source",
+				URL:   "https://example.com/list.txt.gz",
+				Types: []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Compressed url with files",
+			source: Source{
+				Name:  "test-source",
+				URL:   "https://example.com/list.txt.bz2",
+				Files: []string{"list.txt"},
+				Types: []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Archive with invalid files pattern",
+			source: Source{
+				Name:  "test-source",
+				URL:   "https://example.com/lists.zip",
+				Files: []string{"lists/[a-.txt"},
+				Types: []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Concatenate files without files",
+			source: Source{
+				Name:             "test-source",
+				URL:              "https://example.com/list.txt",
+				ConcatenateFiles: true,
+				Types:            []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Verify mirrors without mirrors",
+			source: Source{
+				Name:          "test-source",
+				URL:           "https://example.com/list.txt",
+				VerifyMirrors: true,
+				Types:         []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Valid integrity",
+			source: Source{
+				Name:      "test-source",
+				URL:       "https://example.com/list.txt",
+				Integrity: &c.IntegrityOptions{ChecksumURL: "https://example.com/list.txt.sha256"},
+				Types:     []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid integrity",
+			source: Source{
+				Name:      "test-source",
+				URL:       "https://example.com/list.txt",
+				Integrity: &c.IntegrityOptions{Checksum: "sha256:abc"},
+				Types:     []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Integrity without url",
+			source: Source{
+				Name:      "test-source",
+				Content:   []string{"example.com"},
+				Integrity: &c.IntegrityOptions{Checksum: "d41d8cd98f00b204e9800998ecf8427e"},
+				Types:     []c.SourceType{{Name: "domain"}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -313,8 +537,10 @@ func TestSourceGetDownloadFile(t *testing.T) {
 	t.Parallel()
 
 	source := Source{
-		Name: "test-source",
-		URL:  "http://example.com/file.txt",
+		Name:          "test-source",
+		URL:           "http://example.com/file.txt",
+		Mirrors:       []string{"http://mirror.example.com/file.txt"},
+		VerifyMirrors: true,
 	}
 
 	logger := CreateTestLogger()
@@ -324,6 +550,8 @@ func TestSourceGetDownloadFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "test-source", downloadFile.Name)
 	assert.Equal(t, "http://example.com/file.txt", downloadFile.URL)
+	assert.Equal(t, source.Mirrors, downloadFile.Mirrors)
+	assert.True(t, downloadFile.VerifyMirrors)
 }
 
 func TestValidateDNSToolkitAppConfig(t *testing.T) {
@@ -862,3 +1090,49 @@ func TestGetMinOverlapPercent(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProxy(t *testing.T) {
+	t.Parallel()
+
+	proxy := &c.ProxyConfig{URL: "http://proxy.local:3128"}
+	config := &DNSToolkitConfig{Proxy: proxy}
+
+	assert.Same(t, proxy, config.GetProxy(Source{Name: "default"}))
+	assert.Same(t, proxy, config.GetProxy(Source{Name: "http", HTTP: &c.HTTPOptions{Timeout: 10}}))
+	assert.Nil(t, config.GetProxy(Source{Name: "direct", HTTP: &c.HTTPOptions{SkipProxy: true}}))
+	assert.Nil(t, (&DNSToolkitConfig{}).GetProxy(Source{Name: "default"}))
+}
+
+func TestGetHTTP(t *testing.T) {
+	t.Parallel()
+
+	httpOptions := &c.HTTPOptions{Timeout: 10}
+	source := Source{Name: "vxvault", URL: "https://vxvault.net/URL_List.php", HTTP: httpOptions}
+	other := Source{Name: "other", URL: "https://example.com/list.txt", HTTP: httpOptions}
+
+	assert.Same(t, httpOptions, (&DNSToolkitConfig{}).GetHTTP(source))
+
+	config := &DNSToolkitConfig{
+		SkipCertVerification:      true,
+		SkipCertVerificationHosts: []string{"vxvault.net"},
+	}
+	insecure := config.GetHTTP(source)
+	if assert.NotNil(t, insecure) {
+		assert.True(t, insecure.InsecureSkipVerify, "deprecated hosts should still skip certificate verification")
+		assert.Equal(t, 10, insecure.Timeout)
+	}
+	assert.False(t, httpOptions.InsecureSkipVerify, "the http options of the source should not be modified")
+	assert.Same(t, httpOptions, config.GetHTTP(other))
+	assert.True(t, config.GetHTTP(Source{Name: "no-http", URL: source.URL}).InsecureSkipVerify)
+
+	config.SkipCertVerification = false
+	assert.Same(t, httpOptions, config.GetHTTP(source))
+}
+
+func TestGetCircuitBreakerFailures(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, constants.DefaultCircuitBreakerFailures, (&DNSToolkitConfig{}).GetCircuitBreakerFailures())
+	assert.Equal(t, 3, (&DNSToolkitConfig{CircuitBreakerFailures: 3}).GetCircuitBreakerFailures())
+	assert.Equal(t, -1, (&DNSToolkitConfig{CircuitBreakerFailures: -1}).GetCircuitBreakerFailures())
+}
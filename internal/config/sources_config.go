@@ -4,13 +4,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
 )
@@ -45,28 +48,56 @@ func (sc *SourcesConfig) ValidateWithConfig(appConfig *AppConfig) error {
 	return nil
 }
 
+// RegisterDeclaredProcessors registers the processors declared inline by the source types of the sources,
+// for their list types. Each one is only used for the source that declared it.
+func (sc *SourcesConfig) RegisterDeclaredProcessors() error {
+	for _, source := range sc.Sources {
+		for _, t := range source.Types {
+			if t.Processor == nil {
+				continue
+			}
+			listTypes := make([]string, 0, len(t.ListTypes))
+			for _, lt := range t.ListTypes {
+				listTypes = append(listTypes, lt.Name)
+			}
+			err := processors.RegisterDeclaredProcessor(source.Name, t.Name, listTypes, *t.Processor)
+			if err != nil {
+				return fmt.Errorf("registering the processor of %s (%s): %w", source.Name, t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 type Source struct {
-	Name                        string         `json:"name"`
-	URL                         string         `json:"url"`
-	Frequency                   string         `json:"frequency,omitempty"`
-	License                     string         `json:"license,omitempty"`
-	Website                     string         `json:"website,omitempty"`
-	Notes                       string         `json:"notes,omitempty"`
-	URLPerCategory              string         `json:"url_per_category,omitempty"`
-	URLPerGroup                 string         `json:"url_per_group,omitempty"`
-	Types                       []c.SourceType `json:"types"`
-	Files                       []string       `json:"files,omitempty"`
-	Categories                  []string       `json:"categories,omitempty"`
-	Countries                   []string       `json:"countries,omitempty"`
-	Content                     []string       `json:"content,omitempty"`
-	ContentPerCategory          []string       `json:"content_per_category,omitempty"`
-	ContentPerGroup             []string       `json:"content_per_group,omitempty"`
-	TypeCount                   int            `json:"type_count"`
-	CountToConsider             int            `json:"count_to_consider,omitempty"`
-	Disabled                    bool           `json:"disabled,omitempty"`
-	SkipGeneralConsolidation    bool           `json:"skip_general_consolidation,omitempty"`
-	SkipGroupsConsolidation     bool           `json:"skip_groups_consolidation,omitempty"`
-	SkipCategoriesConsolidation bool           `json:"skip_categories_consolidation,omitempty"`
+	Name                        string              `json:"name"`
+	URL                         string              `json:"url"`
+	Mirrors                     []string            `json:"mirrors,omitempty"`
+	Frequency                   string              `json:"frequency,omitempty"`
+	License                     string              `json:"license,omitempty"`
+	Website                     string              `json:"website,omitempty"`
+	Notes                       string              `json:"notes,omitempty"`
+	URLPerCategory              string              `json:"url_per_category,omitempty"`
+	URLPerGroup                 string              `json:"url_per_group,omitempty"`
+	Types                       []c.SourceType      `json:"types"`
+	Downloader                  string              `json:"downloader,omitempty"` // registered downloader to use
+	HTTP                        *c.HTTPOptions      `json:"http,omitempty"`
+	Exec                        *c.ExecOptions      `json:"exec,omitempty"` // command run by the exec downloader
+	Integrity                   *c.IntegrityOptions `json:"integrity,omitempty"`
+	Files                       []string            `json:"files,omitempty"` // file names or glob patterns in the archive
+	Categories                  []string            `json:"categories,omitempty"`
+	Countries                   []string            `json:"countries,omitempty"`
+	Content                     []string            `json:"content,omitempty"`
+	ContentPerCategory          []string            `json:"content_per_category,omitempty"`
+	ContentPerGroup             []string            `json:"content_per_group,omitempty"`
+	TypeCount                   int                 `json:"type_count"`
+	CountToConsider             int                 `json:"count_to_consider,omitempty"`
+	Disabled                    bool                `json:"disabled,omitempty"`
+	SkipGeneralConsolidation    bool                `json:"skip_general_consolidation,omitempty"`
+	SkipGroupsConsolidation     bool                `json:"skip_groups_consolidation,omitempty"`
+	SkipCategoriesConsolidation bool                `json:"skip_categories_consolidation,omitempty"`
+	VerifyMirrors               bool                `json:"verify_mirrors,omitempty"`
+	ConcatenateFiles            bool                `json:"concatenate_files,omitempty"` // combine all files into one target
 }
 
 func (s *Source) Validate() error {
@@ -107,6 +138,36 @@ func (s *Source) ValidateWithConfig(appConfig *AppConfig) error {
 				break
 			}
 		}
+		if ext := u.GetCompressedExtension(s.URL); ext != "" && len(s.Files) > 0 {
+			return fmt.Errorf("files property is not supported for url ending with %s", ext)
+		}
+	}
+	for _, file := range s.Files {
+		if _, err := filepath.Match(file, ""); err != nil {
+			return fmt.Errorf("invalid files pattern %s: %w", file, err)
+		}
+	}
+	if s.ConcatenateFiles && len(s.Files) == 0 {
+		return fmt.Errorf("concatenate_files requires files")
+	}
+	if err := s.validateMirrors(); err != nil {
+		return fmt.Errorf("mirrors validation error: %w", err)
+	}
+	if s.HTTP != nil {
+		if err := s.HTTP.Validate(); err != nil {
+			return fmt.Errorf("http validation error: %w", err)
+		}
+	}
+	if err := s.validateExec(); err != nil {
+		return fmt.Errorf("exec validation error: %w", err)
+	}
+	if s.Integrity != nil {
+		if s.URL == "" {
+			return fmt.Errorf("integrity requires url")
+		}
+		if err := s.Integrity.Validate(); err != nil {
+			return fmt.Errorf("integrity validation error: %w", err)
+		}
 	}
 	if len(s.Types) == 0 {
 		return fmt.Errorf("at least one type is required")
@@ -120,6 +181,14 @@ func (s *Source) ValidateWithConfig(appConfig *AppConfig) error {
 		if err != nil {
 			return fmt.Errorf("type validation error: %w", err)
 		}
+		if t.Processor == nil && t.Name == constants.SourceTypeHtmlSelector {
+			return fmt.Errorf("type %s requires a processor", t.Name)
+		}
+		if t.Processor != nil {
+			if err := processors.ValidateProcessorSpec(t.Name, *t.Processor); err != nil {
+				return fmt.Errorf("processor validation error for type %s: %w", t.Name, err)
+			}
+		}
 		typesTracker[t.Name] = true
 	}
 	if s.Frequency != "" && !constants.ValidFrequencies[s.Frequency] {
@@ -164,7 +233,8 @@ func (s *Source) UnmarshalJSON(data []byte) error {
 				MustConsider bool   `json:"must_consider"`
 				Disabled     bool   `json:"disabled"`
 			} `json:"list_types,omitempty"`
-			Name string `json:"name"`
+			Processor *c.ProcessorSpec `json:"processor,omitempty"`
+			Name      string           `json:"name"`
 		} `json:"types"`
 		Categories string `json:"categories"`
 		Countries  string `json:"countries"`
@@ -199,6 +269,7 @@ func (s *Source) UnmarshalJSON(data []byte) error {
 		s.Types[i] = c.SourceType{
 			Name:      t.Name,
 			ListTypes: listTypes,
+			Processor: t.Processor,
 		}
 	}
 	s.TypeCount = len(s.Types)
@@ -708,14 +779,72 @@ func (sc *SourcesConfig) GetSourceByName(name string) (Source, bool) {
 	return Source{}, false
 }
 
+// validateMirrors checks that the mirrors are distinct http(s) urls serving the same kind of file as the url.
+func (s *Source) validateMirrors() error {
+	if len(s.Mirrors) == 0 {
+		if s.VerifyMirrors {
+			return fmt.Errorf("verify_mirrors requires mirrors")
+		}
+		return nil
+	}
+	if s.URL == "" {
+		return fmt.Errorf("url is required when mirrors are set")
+	}
+	seen := map[string]bool{s.URL: true}
+	for _, mirror := range s.Mirrors {
+		parsed, err := url.Parse(mirror)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid mirror url: %s", mirror)
+		}
+		if seen[mirror] {
+			return fmt.Errorf("duplicate mirror url: %s", mirror)
+		}
+		if u.GetArchiveExtension(mirror) != u.GetArchiveExtension(s.URL) ||
+			u.GetCompressedExtension(mirror) != u.GetCompressedExtension(s.URL) {
+			return fmt.Errorf("mirror %s does not serve the same file type as %s", mirror, s.URL)
+		}
+		seen[mirror] = true
+	}
+	return nil
+}
+
+// validateExec checks that the exec options are set exactly when the exec downloader is selected.
+// The command is given the url instead of fetching it, so mirrors are not supported.
+func (s *Source) validateExec() error {
+	if s.Downloader != constants.DownloaderExec {
+		if s.Exec != nil {
+			return fmt.Errorf("exec requires downloader %s", constants.DownloaderExec)
+		}
+		return nil
+	}
+	if s.Exec == nil {
+		return fmt.Errorf("downloader %s requires exec", constants.DownloaderExec)
+	}
+	if s.URL == "" {
+		return fmt.Errorf("downloader %s requires url", constants.DownloaderExec)
+	}
+	if len(s.Mirrors) > 0 {
+		return fmt.Errorf("mirrors are not supported by downloader %s", constants.DownloaderExec)
+	}
+	return s.Exec.Validate()
+}
+
+// targetFileReplacer turns an archive file name or pattern into a flat target file name.
+var targetFileReplacer = strings.NewReplacer("/", "_", "*", "_", "?", "_", "[", "_", "]", "_")
+
 // GetDownloadFile returns a DownloadFile struct for the source.
 func (s *Source) GetDownloadFile(_ *multilog.Logger, downloadDir string) (c.DownloadFile, error) {
 	downloadFile := c.DownloadFile{
-		Name:      s.Name,
-		URL:       s.URL,
-		Folder:    downloadDir,
-		Frequency: s.Frequency,
-		Targets:   make([]c.DownloadTarget, 0, len(s.Files)), // Pre-allocate capacity
+		Name:          s.Name,
+		URL:           s.URL,
+		Mirrors:       s.Mirrors,
+		Folder:        downloadDir,
+		Frequency:     s.Frequency,
+		HTTP:          s.HTTP,
+		Exec:          s.Exec,
+		Integrity:     s.Integrity,
+		VerifyMirrors: s.VerifyMirrors,
+		Targets:       make([]c.DownloadTarget, 0, len(s.Files)), // Pre-allocate capacity
 	}
 
 	if u.IsArchive(s.URL) {
@@ -731,16 +860,47 @@ func (s *Source) GetDownloadFile(_ *multilog.Logger, downloadDir string) (c.Down
 		downloadFile.Filename = s.Name + ext
 		downloadFile.IsArchive = true
 
+		sourceFolder := downloadFile.Folder
+		if s.ConcatenateFiles || slices.ContainsFunc(s.Files, u.IsGlobPattern) {
+			// Patterns are matched against a fresh extraction, not the files left by earlier downloads
+			downloadFile.ExtractFolder = filepath.Join(downloadFile.Folder, s.Name+"_extracted")
+			sourceFolder = downloadFile.ExtractFolder
+		}
+
+		if s.ConcatenateFiles {
+			downloadFile.Targets = append(downloadFile.Targets, c.DownloadTarget{
+				SourceFolder: sourceFolder,
+				SourceFiles:  s.Files,
+				TargetFile:   s.Name + ".txt",
+				TargetFolder: downloadFile.Folder,
+			})
+			return downloadFile, nil
+		}
+
 		// Process all files in the archive
 		for _, file := range s.Files {
-			filename := fmt.Sprintf("%s-%s", s.Name, strings.ReplaceAll(file, "/", "_"))
+			filename := fmt.Sprintf("%s-%s", s.Name, targetFileReplacer.Replace(file))
 			downloadFile.Targets = append(downloadFile.Targets, c.DownloadTarget{
-				SourceFolder: downloadFile.Folder,
+				SourceFolder: sourceFolder,
 				SourceFile:   file,
 				TargetFile:   filename,
 				TargetFolder: downloadFile.Folder,
 			})
 		}
+	} else if ext := u.GetCompressedExtension(s.URL); ext != "" {
+		if len(s.Files) != 0 {
+			return downloadFile, fmt.Errorf("files are not supported for url ending with %s", ext)
+		}
+
+		// Decompressed in place: the payload name.txt.gz becomes the target name.txt
+		downloadFile.Filename = s.Name + ".txt" + ext
+		downloadFile.IsArchive = true
+		downloadFile.Targets = append(downloadFile.Targets, c.DownloadTarget{
+			SourceFolder: downloadFile.Folder,
+			SourceFile:   s.Name + ".txt",
+			TargetFile:   s.Name + ".txt",
+			TargetFolder: downloadFile.Folder,
+		})
 	} else {
 		// Handle non-archive files
 		if len(s.Files) != 0 {
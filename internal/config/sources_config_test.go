@@ -9,6 +9,7 @@ import (
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
 	"github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -214,6 +215,96 @@ func TestSourceValidation(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "duplicate type")
 	})
+
+	t.Run("html_selector type requires a processor", func(t *testing.T) {
+		source := Source{
+			Name:  "test-source",
+			URL:   "http://example.com/page.html",
+			Types: []c.SourceType{{Name: "html_selector"}},
+		}
+		err := source.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "type html_selector requires a processor")
+
+		source.Types[0].Processor = &c.ProcessorSpec{Kind: "html", Selector: "table td", Extract: "url_host"}
+		assert.NoError(t, source.Validate())
+	})
+
+	t.Run("Declared processors are validated", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			sourceType  string
+			spec        c.ProcessorSpec
+			expectedErr string
+		}{
+			{
+				name:       "csv",
+				sourceType: "domain",
+				spec:       c.ProcessorSpec{Kind: "csv", Column: 1, Delimiter: ";", SkipHeader: true},
+			},
+			{
+				name:       "regex",
+				sourceType: "ipv4",
+				spec:       c.ProcessorSpec{Kind: "regex", Pattern: `^ip=(\S+)`, Group: 1},
+			},
+			{name: "json", sourceType: "cidr_ipv6", spec: c.ProcessorSpec{Kind: "json", Path: "$.data[*].prefix"}},
+			{
+				name:        "missing kind",
+				sourceType:  "domain",
+				spec:        c.ProcessorSpec{},
+				expectedErr: "processor kind is required",
+			},
+			{
+				name:        "unsupported type",
+				sourceType:  "adguard",
+				spec:        c.ProcessorSpec{Kind: "csv"},
+				expectedErr: "a processor cannot be declared for type adguard",
+			},
+			{
+				name:        "invalid delimiter",
+				sourceType:  "domain",
+				spec:        c.ProcessorSpec{Kind: "csv", Delimiter: ",,"},
+				expectedErr: "invalid delimiter",
+			},
+			{
+				name:        "invalid pattern",
+				sourceType:  "domain",
+				spec:        c.ProcessorSpec{Kind: "regex", Pattern: "(unclosed"},
+				expectedErr: "invalid pattern",
+			},
+			{
+				name:        "group out of range",
+				sourceType:  "domain",
+				spec:        c.ProcessorSpec{Kind: "regex", Pattern: "(a)", Group: 2},
+				expectedErr: "group 2 is out of range",
+			},
+			{
+				name:        "invalid path",
+				sourceType:  "domain",
+				spec:        c.ProcessorSpec{Kind: "json", Path: "data.host"},
+				expectedErr: "path must start with $",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				spec := tt.spec
+				source := Source{
+					Name:  "test-source",
+					URL:   "http://example.com/list.txt",
+					Types: []c.SourceType{{Name: tt.sourceType, Processor: &spec}},
+				}
+				err := source.Validate()
+				if tt.expectedErr == "" {
+					assert.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "processor validation error for type "+tt.sourceType)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			})
+		}
+	})
 }
 
 // TestSourceFiltering tests filtering sources with various criteria
@@ -496,6 +587,29 @@ func TestSourceJSONUnmarshaling(t *testing.T) {
 		assert.Contains(t, source.Files, "file2")
 	})
 
+	t.Run("Source with a declared processor", func(t *testing.T) {
+		jsonData := `{
+			"name": "test-source",
+			"url": "http://example.com/list.csv",
+			"types": [
+				{
+					"name": "domain",
+					"processor": {"kind": "csv", "column": 2, "delimiter": ";", "skip_header": true}
+				}
+			]
+		}`
+
+		var source Source
+		err := json.Unmarshal([]byte(jsonData), &source)
+		assert.NoError(t, err)
+		require.NotNil(t, source.Types[0].Processor)
+		assert.Equal(
+			t,
+			c.ProcessorSpec{Kind: "csv", Column: 2, Delimiter: ";", SkipHeader: true},
+			*source.Types[0].Processor,
+		)
+	})
+
 	t.Run("Invalid JSON", func(t *testing.T) {
 		jsonData := `{invalid json`
 
@@ -505,6 +619,44 @@ func TestSourceJSONUnmarshaling(t *testing.T) {
 	})
 }
 
+func TestRegisterDeclaredProcessors(t *testing.T) {
+	sourcesConfig := SourcesConfig{
+		Sources: []Source{
+			{
+				Name: "declared-source",
+				URL:  "http://example.com/list.csv",
+				Types: []c.SourceType{
+					{
+						Name:      "domain",
+						ListTypes: []c.ListType{{Name: "blocklist"}, {Name: "allowlist"}},
+						Processor: &c.ProcessorSpec{Kind: "csv", Column: 1},
+					},
+					{Name: "ipv4", ListTypes: []c.ListType{{Name: "blocklist"}}},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, sourcesConfig.RegisterDeclaredProcessors())
+	t.Cleanup(func() {
+		for _, listType := range []string{"blocklist", "allowlist"} {
+			processors.Processors.UnregisterProcessor(processors.DeclaredProcessorKey("declared-source", "domain"), listType)
+		}
+	})
+
+	for _, listType := range []string{"blocklist", "allowlist"} {
+		processor, exists := processors.Processors.GetProcessor(
+			processors.DeclaredProcessorKey("declared-source", "domain"),
+			listType,
+		)
+		require.True(t, exists)
+		assert.Equal(t, "domain", processor.GetSourceType())
+		assert.Equal(t, listType, processor.GetListType())
+	}
+	_, exists := processors.Processors.GetProcessor(processors.DeclaredProcessorKey("declared-source", "ipv4"), "blocklist")
+	assert.False(t, exists)
+}
+
 // TestLoadingSourcesWithDefaultValues tests that default values are properly applied when loading a sources config
 func TestLoadingSourcesWithDefaultValues(t *testing.T) {
 	testDir, err := os.MkdirTemp("", "dns-toolkit-test")
@@ -711,6 +863,57 @@ func TestGetDownloadFileDetailedCases(t *testing.T) {
 		assert.Equal(t, "target-test-source.txt", target.TargetFile)
 		assert.Equal(t, downloadDir, target.TargetFolder)
 	})
+
+	t.Run("Compressed single file", func(t *testing.T) {
+		source := Source{Name: "compressed", URL: "http://example.com/domains.txt.xz"}
+
+		downloadFile, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+		assert.True(t, downloadFile.IsArchive)
+		assert.Equal(t, "compressed.txt.xz", downloadFile.Filename)
+		require.Len(t, downloadFile.Targets, 1)
+		assert.Equal(t, "compressed.txt", downloadFile.Targets[0].SourceFile)
+		assert.Equal(t, "compressed.txt", downloadFile.Targets[0].TargetFile)
+
+		source.Files = []string{"domains.txt"}
+		_, err = source.GetDownloadFile(logger, downloadDir)
+		assert.Error(t, err)
+	})
+
+	t.Run("Archive with glob pattern", func(t *testing.T) {
+		source := Source{
+			Name:  "glob",
+			URL:   "http://example.com/lists.tar.gz",
+			Files: []string{"lists/domains-*.txt", "README"},
+		}
+
+		downloadFile, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+		extractFolder := filepath.Join(downloadDir, "glob_extracted")
+		assert.Equal(t, extractFolder, downloadFile.ExtractFolder)
+		require.Len(t, downloadFile.Targets, 2)
+		assert.Equal(t, extractFolder, downloadFile.Targets[0].SourceFolder)
+		assert.Equal(t, "lists/domains-*.txt", downloadFile.Targets[0].SourceFile)
+		assert.Equal(t, "glob-lists_domains-_.txt", downloadFile.Targets[0].TargetFile)
+		assert.Equal(t, downloadDir, downloadFile.Targets[0].TargetFolder)
+	})
+
+	t.Run("Archive with concatenated files", func(t *testing.T) {
+		source := Source{
+			Name:             "combined",
+			URL:              "http://example.com/lists.zip",
+			Files:            []string{"a.txt", "b/*.txt"},
+			ConcatenateFiles: true,
+		}
+
+		downloadFile, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+		require.Len(t, downloadFile.Targets, 1)
+		target := downloadFile.Targets[0]
+		assert.Equal(t, []string{"a.txt", "b/*.txt"}, target.SourceFiles)
+		assert.Equal(t, filepath.Join(downloadDir, "combined_extracted"), target.SourceFolder)
+		assert.Equal(t, "combined.txt", target.TargetFile)
+	})
 }
 
 func TestCfgGetUserAgentFunction(t *testing.T) {
@@ -1,7 +1,7 @@
 package consolidators
 
 import (
-	"strings"
+	"context"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
@@ -10,10 +10,9 @@ import (
 
 const sourceType = "adguard"
 
-const adguardExceptionPrefix = "@@"
-
-var adguardExceptionSuffixesToStrip = []string{
-	"$important",
+// adguardExceptionModifiersToStrip are removed from an exception rule to get the blocking rule it allows
+var adguardExceptionModifiersToStrip = []string{
+	"important",
 }
 
 type AdguardConsolidator struct {
@@ -27,10 +26,32 @@ func NewAdguardConsolidator(sourceType, listType string) *AdguardConsolidator {
 }
 
 func (c *AdguardConsolidator) Consolidate(
+	ctx context.Context,
 	logger *multilog.Logger,
 	processedFiles []c.ProcessedFile,
 ) (u.StringSet, []c.FileInfo) {
-	return c.BaseConsolidator.Consolidate(logger, processedFiles)
+	entrySet, fileInfos := c.BaseConsolidator.Consolidate(ctx, logger, processedFiles)
+	return dedupeAdguardRules(logger, entrySet), fileInfos
+}
+
+// dedupeAdguardRules keeps one rule, in its canonical form, of the rules that are semantically equal
+// even when written differently, such as with the modifiers in another order.
+// An entry that is not a well-formed rule is kept as is.
+func dedupeAdguardRules(logger *multilog.Logger, entrySet u.StringSet) u.StringSet {
+	deduped := u.NewStringSetWithCapacity(len(entrySet))
+	for entry, mustConsider := range entrySet {
+		if rule, err := u.ParseAdguardRule(entry); err == nil {
+			entry = rule.String()
+		}
+		if consider, found := deduped.Get(entry); found {
+			mustConsider = mustConsider || consider
+		}
+		deduped.AddWithConsider(entry, mustConsider)
+	}
+	if removed := len(entrySet) - len(deduped); removed > 0 {
+		logger.Debugf("Removed %d AdGuard rule(s) equal to other rules", removed)
+	}
+	return deduped
 }
 
 func (c *AdguardConsolidator) FilterEntries(
@@ -40,15 +61,18 @@ func (c *AdguardConsolidator) FilterEntries(
 ) (u.StringSet, u.StringSet) {
 	newFilterSet := u.NewStringSet(nil)
 	for entry := range filterSet {
-		if strings.HasPrefix(entry, adguardExceptionPrefix) {
-			newEntry := entry[len(adguardExceptionPrefix):]
-			for _, suffix := range adguardExceptionSuffixesToStrip {
-				newEntry = strings.TrimSuffix(newEntry, suffix)
-			}
-			newFilterSet.Add(newEntry)
-		} else {
+		rule, err := u.ParseAdguardRule(entry)
+		if err != nil {
 			newFilterSet.Add(entry)
+			continue
+		}
+		if rule.Exception {
+			rule.Exception = false
+			for _, name := range adguardExceptionModifiersToStrip {
+				rule = rule.WithoutModifier(name)
+			}
 		}
+		newFilterSet.Add(rule.String())
 	}
 	filterSet = newFilterSet
 	return c.BaseConsolidator.FilterEntries(logger, entrySet, filterSet)
@@ -1,6 +1,7 @@
 package consolidators
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -40,6 +41,27 @@ func TestAdguardConsolidator_FilterEntries(t *testing.T) {
 	assert.False(t, ignored.Contains("domain4.com"))
 }
 
+func TestAdguardConsolidator_FilterEntriesRules(t *testing.T) {
+	ac := NewAdguardConsolidator("adguard", "blocklist")
+	logger := multilog.NewLogger()
+
+	entrySet := u.NewStringSet([]string{
+		"||ads.example.com^",
+		"||tracker.example.com^$dnstype=A|AAAA",
+		"||cdn.example.com^",
+	})
+	filterSet := u.NewStringSet([]string{
+		"@@||ADS.example.com^$important",
+		"@@||tracker.example.com^$dnstype=AAAA|A",
+		"@@||cdn.example.com^$dnstype=A",
+	})
+
+	filtered, ignored := ac.FilterEntries(logger, entrySet, filterSet)
+
+	assert.ElementsMatch(t, []string{"||cdn.example.com^"}, filtered.ToSlice())
+	assert.ElementsMatch(t, []string{"||ads.example.com^", "||tracker.example.com^$dnstype=A|AAAA"}, ignored.ToSlice())
+}
+
 func TestAdguardConsolidator_Consolidate(t *testing.T) {
 	ac := NewAdguardConsolidator("adguard", "blocklist")
 	logger := multilog.NewLogger()
@@ -57,11 +79,43 @@ func TestAdguardConsolidator_Consolidate(t *testing.T) {
 			Name:              "test",
 		},
 	}
-	set, infos := ac.Consolidate(logger, files)
+	set, infos := ac.Consolidate(context.Background(), logger, files)
 	assert.Equal(t, 2, len(set))
 	assert.Equal(t, 1, len(infos))
 }
 
+func TestAdguardConsolidator_ConsolidateEqualRules(t *testing.T) {
+	ac := NewAdguardConsolidator("adguard", "blocklist")
+	logger := multilog.NewLogger()
+
+	tempDir := t.TempDir()
+	firstFile := filepath.Join(tempDir, "first.txt")
+	secondFile := filepath.Join(tempDir, "second.txt")
+	require.NoError(t, os.WriteFile(firstFile, []byte("||Ads.example.com^\n||x.example.com^$important,dnstype=A\n"), 0644))
+	require.NoError(t, os.WriteFile(secondFile, []byte("||ads.example.com^$\n||x.example.com^$dnstype=A,important\n"+
+		"||ads.example.com\n"), 0644))
+
+	files := []c.ProcessedFile{
+		{GenericSourceType: "adguard", ListType: "blocklist", Filepath: firstFile, NumberOfEntries: 2, Name: "first"},
+		{
+			GenericSourceType: "adguard",
+			ListType:          "blocklist",
+			Filepath:          secondFile,
+			NumberOfEntries:   3,
+			Name:              "second",
+			MustConsider:      true,
+		},
+	}
+	set, infos := ac.Consolidate(context.Background(), logger, files)
+	assert.ElementsMatch(t, []string{
+		"||ads.example.com^",
+		"||x.example.com^$dnstype=A,important",
+		"||ads.example.com",
+	}, set.ToSlice())
+	assert.True(t, set.MustConsider("||ads.example.com^"))
+	assert.Len(t, infos, 2)
+}
+
 func TestAdguardConsolidator_SaveEntries(t *testing.T) {
 	ac := NewAdguardConsolidator("adguard", "blocklist")
 	logger := multilog.NewLogger()
@@ -1,6 +1,8 @@
 package consolidators
 
 import (
+	"context"
+
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
@@ -31,6 +33,7 @@ func (bc *BaseConsolidator) IsValid(processedFile c.ProcessedFile) bool {
 }
 
 func (bc *BaseConsolidator) Consolidate(
+	ctx context.Context,
 	logger *multilog.Logger,
 	processedFiles []c.ProcessedFile,
 ) (u.StringSet, []c.FileInfo) {
@@ -38,6 +41,10 @@ func (bc *BaseConsolidator) Consolidate(
 	var fileInfos []c.FileInfo
 
 	for _, processedFile := range processedFiles {
+		if ctx.Err() != nil {
+			logger.Warnf("Consolidation of %s/%s cancelled: %v", bc.sourceType, bc.listType, ctx.Err())
+			break
+		}
 		if !bc.IsValid(processedFile) {
 			// logger.Debugf("Skipping invalid processed file: %s", processedFile.Filepath)
 			continue
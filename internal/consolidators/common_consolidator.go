@@ -1,6 +1,8 @@
 package consolidators
 
 import (
+	"context"
+
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
@@ -11,7 +13,7 @@ var commonSourceTypes = []string{
 	constants.SourceTypeIpv4,
 	constants.SourceTypeIpv6,
 	constants.SourceTypeCidrIpv4,
-	constants.SourceTypeDomain,
+	constants.SourceTypeCidrIpv6,
 }
 
 var commonSupportedListTypes = []string{
@@ -30,10 +32,11 @@ func NewCommonConsolidator(sourceType, listType string) *CommonConsolidator {
 }
 
 func (c *CommonConsolidator) Consolidate(
+	ctx context.Context,
 	logger *multilog.Logger,
 	processedFiles []c.ProcessedFile,
 ) (u.StringSet, []c.FileInfo) {
-	return c.BaseConsolidator.Consolidate(logger, processedFiles)
+	return c.BaseConsolidator.Consolidate(ctx, logger, processedFiles)
 }
 
 func (c *CommonConsolidator) FilterEntries(
@@ -64,11 +67,14 @@ func init() {
 	}
 }
 
-// InitForTesting replicates the init() function for testing purposes
+// InitForTesting replicates the init() functions of the common and domain consolidators for testing purposes
 func InitForTesting() {
 	for _, st := range commonSourceTypes {
 		RegisterConsolidatorTypes(st, commonSupportedListTypes, func(st, lt string) Consolidator {
 			return NewCommonConsolidator(st, lt)
 		})
 	}
+	RegisterConsolidatorTypes(constants.SourceTypeDomain, commonSupportedListTypes, func(st, lt string) Consolidator {
+		return NewDomainConsolidator(st, lt)
+	})
 }
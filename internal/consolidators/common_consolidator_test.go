@@ -1,6 +1,7 @@
 package consolidators_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -97,6 +98,26 @@ func TestCommonConsolidatorConsolidate(t *testing.T) {
 			expectedFiles:  1,
 			shouldHaveData: true,
 		},
+		{
+			name:       "cidr_ipv6_consolidation",
+			sourceType: constants.SourceTypeCidrIpv6,
+			listType:   constants.ListTypeBlocklist,
+			files: []fileData{
+				{
+					name:    "cidrs_a.txt",
+					content: "2001:db8::/32\nfc00::/7\n",
+					entries: 2,
+				},
+				{
+					name:    "cidrs_b.txt",
+					content: "2001:db8::/32\n2a00:1450::/32\n",
+					entries: 2,
+				},
+			},
+			expectedCount:  3,
+			expectedFiles:  2,
+			shouldHaveData: true,
+		},
 		{
 			name:           "empty_files",
 			sourceType:     constants.SourceTypeDomain,
@@ -201,7 +222,7 @@ func TestCommonConsolidatorConsolidate(t *testing.T) {
 				})
 			}
 
-			consolidatedSet, fileInfos := cc.Consolidate(logger, processedFiles)
+			consolidatedSet, fileInfos := cc.Consolidate(context.Background(), logger, processedFiles)
 
 			assert.Equal(t, tt.expectedCount, len(consolidatedSet), "Consolidated entries count should match expected")
 			assert.Equal(t, tt.expectedFiles, len(fileInfos), "File info count should match expected")
@@ -1,6 +1,8 @@
 package consolidators
 
 import (
+	"context"
+
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
@@ -14,6 +16,7 @@ type GenericConsolidator struct {
 }
 
 func (gc *GenericConsolidator) Consolidate(
+	_ context.Context,
 	logger *multilog.Logger,
 	processedFiles []c.ProcessedFile,
 ) (u.StringSet, []c.FileInfo) {
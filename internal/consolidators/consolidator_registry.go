@@ -1,6 +1,7 @@
 package consolidators
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -10,7 +11,11 @@ import (
 )
 
 type Consolidator interface {
-	Consolidate(logger *multilog.Logger, processedFiles []c.ProcessedFile) (u.StringSet, []c.FileInfo)
+	Consolidate(
+		ctx context.Context,
+		logger *multilog.Logger,
+		processedFiles []c.ProcessedFile,
+	) (u.StringSet, []c.FileInfo)
 	FilterEntries(logger *multilog.Logger, entrySet u.StringSet, filterSet u.StringSet) (u.StringSet, u.StringSet)
 	SaveEntries(logger *multilog.Logger, entrySet u.StringSet, filePath string) error
 	IsValid(processedFile c.ProcessedFile) bool
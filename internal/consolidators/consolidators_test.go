@@ -1,6 +1,7 @@
 package consolidators_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -150,7 +151,7 @@ func TestBaseConsolidatorConsolidate(t *testing.T) {
 		},
 	}
 
-	consolidatedSet, fileInfos := bc.Consolidate(logger, processedFiles)
+	consolidatedSet, fileInfos := bc.Consolidate(context.Background(), logger, processedFiles)
 
 	assert.Equal(t, 4, len(consolidatedSet), "Should consolidate entries from both valid files")
 	assert.Equal(t, 2, len(fileInfos), "Should have info for two valid files")
@@ -163,10 +164,34 @@ func TestBaseConsolidatorConsolidate(t *testing.T) {
 		Name:              "mismatch",
 	}
 
-	_, fileInfosMismatch := bc.Consolidate(logger, []common.ProcessedFile{invalidEntryCountFile})
+	_, fileInfosMismatch := bc.Consolidate(context.Background(), logger, []common.ProcessedFile{invalidEntryCountFile})
 	assert.Equal(t, 0, len(fileInfosMismatch), "Should skip files with entry count mismatch")
 }
 
+// TestBaseConsolidatorConsolidate_Cancelled tests that a cancelled context stops consolidation
+func TestBaseConsolidatorConsolidate_Cancelled(t *testing.T) {
+	bc := consolidators.NewBaseConsolidator("test-source", "blocklist")
+	logger := multilog.NewLogger()
+
+	testFile := filepath.Join(t.TempDir(), "test.txt")
+	require.NoError(t, os.WriteFile(testFile, []byte("domain1.com\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	consolidatedSet, fileInfos := bc.Consolidate(ctx, logger, []common.ProcessedFile{
+		{
+			GenericSourceType: "test-source",
+			ListType:          "blocklist",
+			Filepath:          testFile,
+			NumberOfEntries:   1,
+			Name:              "test",
+		},
+	})
+	assert.Empty(t, consolidatedSet)
+	assert.Empty(t, fileInfos)
+}
+
 // TestBaseConsolidatorFilterEntries tests the FilterEntries functionality
 func TestBaseConsolidatorFilterEntries(t *testing.T) {
 	sourceType := "test-source"
@@ -250,7 +275,7 @@ func TestGenericConsolidator(t *testing.T) {
 	assert.True(t, gc.IsValid(validFile), "Should validate correct file")
 
 	logger := multilog.NewLogger()
-	result, fileInfos := gc.Consolidate(logger, []common.ProcessedFile{})
+	result, fileInfos := gc.Consolidate(context.Background(), logger, []common.ProcessedFile{})
 
 	assert.Equal(t, 2, len(result), "Should have entries from custom function")
 	assert.Equal(t, 1, len(fileInfos), "Should have file info from custom function")
@@ -313,6 +338,7 @@ func TestCommonConsolidator(t *testing.T) {
 		constants.SourceTypeIpv4,
 		constants.SourceTypeIpv6,
 		constants.SourceTypeCidrIpv4,
+		constants.SourceTypeCidrIpv6,
 		constants.SourceTypeDomain,
 	}
 
@@ -0,0 +1,133 @@
+package consolidators
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+)
+
+// DomainConsolidator consolidates domain entries with their matching semantics:
+// *.example.com matches example.com and its subdomains, example.com only matches example.com.
+// The entries of a processed file whose entries all match the subdomains, such as a dnsmasq file,
+// are consolidated as *. entries. The entries are saved as plain domains, with the domains matching
+// their subdomains listed in a file of their own, see u.SubdomainsFilepath.
+type DomainConsolidator struct {
+	BaseConsolidator
+}
+
+func NewDomainConsolidator(sourceType, listType string) *DomainConsolidator {
+	return &DomainConsolidator{
+		BaseConsolidator: NewBaseConsolidator(sourceType, listType),
+	}
+}
+
+func (dc *DomainConsolidator) Consolidate(
+	ctx context.Context,
+	logger *multilog.Logger,
+	processedFiles []c.ProcessedFile,
+) (u.StringSet, []c.FileInfo) {
+	consolidatedSet := u.NewStringSet([]string{})
+	var fileInfos []c.FileInfo
+
+	for _, processedFile := range processedFiles {
+		if ctx.Err() != nil {
+			logger.Warnf("Consolidation of %s/%s cancelled: %v", dc.sourceType, dc.listType, ctx.Err())
+			break
+		}
+		entrySet, infos := dc.BaseConsolidator.Consolidate(ctx, logger, []c.ProcessedFile{processedFile})
+		for entry, mustConsider := range entrySet {
+			entry = u.DomainEntry(entry, processedFile.MatchSemantics)
+			if consider, found := consolidatedSet.Get(entry); found {
+				mustConsider = mustConsider || consider
+			}
+			consolidatedSet.AddWithConsider(entry, mustConsider)
+		}
+		fileInfos = append(fileInfos, infos...)
+	}
+
+	return mergeCoveredDomains(logger, consolidatedSet), fileInfos
+}
+
+// mergeCoveredDomains removes the exact entries of the domains that also have a *. entry,
+// which matches them already.
+func mergeCoveredDomains(logger *multilog.Logger, entrySet u.StringSet) u.StringSet {
+	removed := 0
+	for entry, mustConsider := range entrySet {
+		if strings.HasPrefix(entry, constants.WildcardDomainPrefix) {
+			continue
+		}
+		wildcard := u.DomainEntry(entry, constants.MatchSemanticsSubdomains)
+		if consider, found := entrySet.Get(wildcard); found {
+			entrySet.AddWithConsider(wildcard, mustConsider || consider)
+			entrySet.Remove(entry)
+			removed++
+		}
+	}
+	if removed > 0 {
+		logger.Debugf("Removed %d domain(s) matched by the *. entry of the domain", removed)
+	}
+	return entrySet
+}
+
+// FilterEntries filters the entries with the filter entries by domain:
+// a filter entry filters both the exact entry and the *. entry of its domain.
+func (dc *DomainConsolidator) FilterEntries(
+	logger *multilog.Logger,
+	entrySet u.StringSet,
+	filterSet u.StringSet,
+) (u.StringSet, u.StringSet) {
+	newFilterSet := u.NewStringSetWithCapacity(2 * len(filterSet))
+	for entry, mustFilter := range filterSet {
+		domain, _, _ := u.ParseDomainEntry(entry)
+		for _, key := range []string{domain, u.DomainEntry(domain, constants.MatchSemanticsSubdomains)} {
+			newFilterSet.AddWithConsider(key, mustFilter || newFilterSet.MustConsider(key))
+		}
+	}
+	return dc.BaseConsolidator.FilterEntries(logger, entrySet, newFilterSet)
+}
+
+// SaveEntries saves the domains of the entries to the file, and the domains of the *. entries
+// to the subdomains file next to it, which is removed when no entry matches the subdomains.
+func (dc *DomainConsolidator) SaveEntries(
+	logger *multilog.Logger,
+	entrySet u.StringSet,
+	filePath string,
+) error {
+	domains := u.NewStringSetWithCapacity(len(entrySet))
+	subdomains := u.NewStringSet([]string{})
+	for entry := range entrySet {
+		domain, semantics, _ := u.ParseDomainEntry(entry)
+		domains.Add(domain)
+		if semantics == constants.MatchSemanticsSubdomains {
+			subdomains.Add(domain)
+		}
+	}
+	if err := dc.BaseConsolidator.SaveEntries(logger, domains, filePath); err != nil {
+		return err
+	}
+
+	subdomainsPath := u.SubdomainsFilepath(filePath)
+	if len(subdomains) == 0 {
+		if err := os.Remove(subdomainsPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	logger.Debugf("Saving %d domain(s) matching their subdomains to file: %s", len(subdomains), subdomainsPath)
+	return u.WriteEntriesToFile(logger, subdomainsPath, subdomains.ToSlice())
+}
+
+func (dc *DomainConsolidator) IsValid(processedFile c.ProcessedFile) bool {
+	return dc.BaseConsolidator.IsValid(processedFile)
+}
+
+func init() {
+	RegisterConsolidatorTypes(constants.SourceTypeDomain, commonSupportedListTypes, func(st, lt string) Consolidator {
+		return NewDomainConsolidator(st, lt)
+	})
+}
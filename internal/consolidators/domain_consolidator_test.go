@@ -0,0 +1,116 @@
+package consolidators
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDomainConsolidator_Consolidate(t *testing.T) {
+	dc := NewDomainConsolidator(constants.SourceTypeDomain, constants.ListTypeBlocklist)
+	logger := multilog.NewLogger()
+
+	tempDir := t.TempDir()
+	plainFile := filepath.Join(tempDir, "plain.txt")
+	dnsmasqFile := filepath.Join(tempDir, "dnsmasq.txt")
+	require.NoError(t, os.WriteFile(plainFile, []byte("ads.example.com\n*.tracker.example.com\nexample.org\n"), 0644))
+	require.NoError(t, os.WriteFile(dnsmasqFile, []byte("ads.example.com\nexample.net\n"), 0644))
+
+	files := []c.ProcessedFile{
+		{
+			GenericSourceType: constants.SourceTypeDomain,
+			ListType:          constants.ListTypeBlocklist,
+			Filepath:          plainFile,
+			NumberOfEntries:   3,
+			Name:              "plain",
+			MustConsider:      true,
+		},
+		{
+			GenericSourceType: constants.SourceTypeDomain,
+			ListType:          constants.ListTypeBlocklist,
+			Filepath:          dnsmasqFile,
+			NumberOfEntries:   2,
+			Name:              "dnsmasq",
+			MatchSemantics:    constants.MatchSemanticsSubdomains,
+		},
+	}
+	set, infos := dc.Consolidate(context.Background(), logger, files)
+	assert.ElementsMatch(t, []string{
+		"*.ads.example.com",
+		"*.tracker.example.com",
+		"example.org",
+		"*.example.net",
+	}, set.ToSlice())
+	assert.True(t, set.MustConsider("*.ads.example.com"), "the merged exact entry should keep must consider")
+	assert.Len(t, infos, 2)
+}
+
+func TestDomainConsolidator_FilterEntries(t *testing.T) {
+	dc := NewDomainConsolidator(constants.SourceTypeDomain, constants.ListTypeBlocklist)
+	logger := multilog.NewLogger()
+
+	entrySet := u.NewStringSet([]string{"ads.example.com", "*.cdn.example.com", "*.tracker.example.com", "example.org"})
+	filterSet := u.NewStringSet([]string{"*.ads.example.com", "cdn.example.com", "*.tracker.example.com"})
+
+	filtered, ignored := dc.FilterEntries(logger, entrySet, filterSet)
+
+	assert.ElementsMatch(t, []string{"example.org"}, filtered.ToSlice())
+	assert.ElementsMatch(
+		t,
+		[]string{"ads.example.com", "*.cdn.example.com", "*.tracker.example.com"},
+		ignored.ToSlice(),
+		"an exact filter entry also filters the *. entry of its domain",
+	)
+}
+
+func TestDomainConsolidator_AllowlistedWildcard(t *testing.T) {
+	dc := NewDomainConsolidator(constants.SourceTypeDomain, constants.ListTypeBlocklist)
+	logger := multilog.NewLogger()
+
+	blocklistFile := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(blocklistFile, []byte("*.tracker.example.com\ntracker.example.com\nexample.org\n"), 0644))
+	files := []c.ProcessedFile{
+		{
+			GenericSourceType: constants.SourceTypeDomain,
+			ListType:          constants.ListTypeBlocklist,
+			Filepath:          blocklistFile,
+			NumberOfEntries:   3,
+			Name:              "wildcards",
+		},
+	}
+
+	set, _ := dc.Consolidate(context.Background(), logger, files)
+	filtered, ignored := dc.FilterEntries(logger, set, u.NewStringSet([]string{"tracker.example.com"}))
+
+	assert.ElementsMatch(t, []string{"example.org"}, filtered.ToSlice())
+	assert.ElementsMatch(t, []string{"*.tracker.example.com"}, ignored.ToSlice())
+}
+
+func TestDomainConsolidator_SaveEntries(t *testing.T) {
+	dc := NewDomainConsolidator(constants.SourceTypeDomain, constants.ListTypeBlocklist)
+	logger := multilog.NewLogger()
+
+	filePath := filepath.Join(t.TempDir(), "domain_blocklist.txt")
+	entrySet := u.NewStringSet([]string{"ads.example.com", "*.tracker.example.com", "*.example.net"})
+	require.NoError(t, dc.SaveEntries(logger, entrySet, filePath))
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "ads.example.com\nexample.net\ntracker.example.com\n", string(content), "the domains are saved plain")
+
+	subdomainsPath := u.SubdomainsFilepath(filePath)
+	content, err = os.ReadFile(subdomainsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "example.net\ntracker.example.com\n", string(content))
+
+	require.NoError(t, dc.SaveEntries(logger, u.NewStringSet([]string{"ads.example.com"}), filePath))
+	assert.NoFileExists(t, subdomainsPath, "the subdomains file of a previous run is removed")
+}
@@ -13,6 +13,14 @@ const (
 	GitHubRepoURL  = "https://github.com/phani-kb/dns-toolkit"
 )
 
+// ProcessorVersion identifies the behaviour of the process step. Bump it whenever the
+// extraction logic changes so that incremental processing re-parses every source.
+const ProcessorVersion = "16"
+
+// SinkChunkEntries is the number of entries a processed file sink sorts in memory before writing them
+// to a temporary run file; the runs are merged into the processed file when it is committed.
+const SinkChunkEntries = 100000
+
 const (
 	MaxDomainLength = 253 // max total FQDN length
 	MaxLabelLength  = 63  // max length per label
@@ -90,11 +98,41 @@ var DefaultSummaryFiles = map[string]string{
 	"overrides":               "consolidated_overrides_summary.json",
 }
 
+// RunStateFile is the name of the file, stored in the summary directory, that tracks
+// the progress of the run command so that an interrupted pipeline can be resumed.
+const RunStateFile = "run_state.json"
+
+// Kinds of the processors a source type may declare inline in the sources configuration
+const (
+	ProcessorKindCsv   = "csv"   // keeps a column of each row
+	ProcessorKindRegex = "regex" // keeps a group of the first match of each line
+	ProcessorKindJSON  = "json"  // keeps the values selected by a JSONPath expression
+	ProcessorKindHtml  = "html"  // keeps the values of the elements selected by a CSS selector, for html_selector
+)
+
+// Kinds of the entries an html_selector processor extracts from the selected elements
+const (
+	HtmlExtractDomain  = "domain"   // the value is a domain
+	HtmlExtractIpv4    = "ipv4"     // the IPv4 addresses found in the value
+	HtmlExtractURLHost = "url_host" // the host of the URL in the value, such as the href of a link
+)
+
+// Pipeline stage statuses recorded in the run state file
+const (
+	StageStatusPending   = "pending"
+	StageStatusRunning   = "running"
+	StageStatusCompleted = "completed"
+	StageStatusFailed    = "failed"
+	StageStatusSkipped   = "skipped"
+)
+
 const (
 	FrequencyDaily   = "daily"
 	FrequencyWeekly  = "weekly"
 	FrequencyMonthly = "monthly"
 
+	DownloaderExec = "exec" // runs a configured command instead of an HTTP request
+
 	CategoryAdult           = "adult"
 	CategoryMalware         = "malware"
 	CategoryAds             = "ads"
@@ -158,7 +196,9 @@ const (
 	SourceTypeIpv4RangeExpand            = "ipv4_range_expand"
 	SourceTypeIpv6                       = "ipv6"
 	SourceTypeCidrIpv4                   = "cidr_ipv4"
+	SourceTypeCidrIpv6                   = "cidr_ipv6"
 	SourceTypeIpv4CidrExpand             = "ipv4_cidr_expand"
+	SourceTypeIpv6CidrExpand             = "ipv6_cidr_expand"
 	SourceTypeDomain                     = "domain"
 	SourceTypeDomainComment              = "domain_comment"
 	SourceTypeDomainFinder               = "domain_finder"
@@ -169,6 +209,7 @@ const (
 	SourceTypeIpv4Hostname               = "ipv4_hostname"
 	SourceTypeMixed                      = "mixed"
 	SourceTypeHostname                   = "hostname"
+	SourceTypeHostnameSinkhole           = "hostname_sinkhole"
 	SourceTypeUnknown                    = "unknown"
 	SourceTypeDomainAdguard              = "domain_adguard"
 	SourceTypeDomainCsvHttpUrlFind       = "domain_csv_http_url_find"
@@ -188,6 +229,10 @@ const (
 	SourceTypeTopDomains                 = "domain_top"
 	SourceTypeDomainCustomHtmlPuppyScams = "domain_custom_html_puppyscams"
 	SourceTypeIpv4FromDomain             = "ipv4_from_domain"
+	SourceTypeDomainDnsmasq              = "domain_dnsmasq"
+	SourceTypeDomainUnbound              = "domain_unbound"
+	SourceTypeRpz                        = "rpz"
+	SourceTypeHtmlSelector               = "html_selector"
 
 	ListTypeBlocklist = "blocklist"
 	ListTypeAllowlist = "allowlist"
@@ -199,7 +244,9 @@ var (
 		SourceTypeIpv4RangeExpand:            true,
 		SourceTypeIpv6:                       true,
 		SourceTypeCidrIpv4:                   true,
+		SourceTypeCidrIpv6:                   true,
 		SourceTypeIpv4CidrExpand:             true,
+		SourceTypeIpv6CidrExpand:             true,
 		SourceTypeDomain:                     true,
 		SourceTypeDomainComment:              true,
 		SourceTypeDomainFinder:               true,
@@ -210,6 +257,7 @@ var (
 		SourceTypeIpv4Hostname:               true,
 		SourceTypeMixed:                      true,
 		SourceTypeHostname:                   true,
+		SourceTypeHostnameSinkhole:           true,
 		SourceTypeUnknown:                    true,
 		SourceTypeDomainAdguard:              true,
 		SourceTypeDomainCsvHttpUrlFind:       true,
@@ -229,6 +277,10 @@ var (
 		SourceTypeTopDomains:                 true,
 		SourceTypeDomainCustomHtmlPuppyScams: true,
 		SourceTypeIpv4FromDomain:             true,
+		SourceTypeDomainDnsmasq:              true,
+		SourceTypeDomainUnbound:              true,
+		SourceTypeRpz:                        true,
+		SourceTypeHtmlSelector:               true,
 	}
 	ValidListTypes = map[string]bool{
 		ListTypeBlocklist: true,
@@ -236,6 +288,62 @@ var (
 	}
 )
 
+// Match semantics of the entries of a processed file
+const (
+	MatchSemanticsExact      = "exact"      // an entry only matches the hostname itself
+	MatchSemanticsSubdomains = "subdomains" // an entry also matches the subdomains of the hostname
+)
+
+// Reason codes of the invalid entries of a processed file
+const (
+	InvalidReasonProcessor      = "processor"         // rejected by a processor rule, whose reason follows the entry
+	InvalidReasonTooLong        = "too_long"          // longer than MaxDomainLength
+	InvalidReasonLabelTooLong   = "label_too_long"    // a label longer than 63 characters
+	InvalidReasonBadLabel       = "bad_label"         // a label with characters not allowed in a hostname
+	InvalidReasonSingleLabel    = "single_label"      // a name without a dot
+	InvalidReasonPunycode       = "punycode"          // an xn-- label that does not decode
+	InvalidReasonPort           = "port"              // a host followed by a port
+	InvalidReasonURL            = "url"               // a URL or a host followed by a path
+	InvalidReasonIPInDomainList = "ip_in_domain_list" // an IP address or CIDR in a domain list
+	InvalidReasonDomainInIPList = "domain_in_ip_list" // a domain in an IP address or CIDR list
+	InvalidReasonOtherIPType    = "other_ip_type"     // an IPv6 address in an IPv4 list, a CIDR in an address list, ...
+	InvalidReasonInvalidIP      = "invalid_ip"        // a malformed IP address or CIDR
+	InvalidReasonExtraFields    = "extra_fields"      // a valid entry followed by other fields
+	InvalidReasonSyntax         = "syntax"            // a line that is not a rule, option or record of the format
+	InvalidReasonUnsupported    = "unsupported"       // a rule, option, record or directive of the format without entries
+	InvalidReasonNonSinkhole    = "non_sinkhole"      // a name mapped or forwarded to an address that is not a sinkhole
+	InvalidReasonOtherListType  = "other_list_type"   // an allowing rule in a blocklist or a blocking rule in an allowlist
+	InvalidReasonOutsideZone    = "outside_zone"      // a record of a name outside the zone of a zone file
+	InvalidReasonNoMatch        = "no_match"          // none of the above, or a type without diagnosis
+)
+
+// InvalidReasonSamples is the number of invalid entries of each reason code kept with a processed file
+const InvalidReasonSamples = 5
+
+// WildcardDomainPrefix marks a domain entry that also matches the subdomains of the domain, as in *.example.com
+const WildcardDomainPrefix = "*."
+
+// SubdomainsFileSuffix names the file listing the domains of a consolidated domain file that also match
+// their subdomains, next to the file, as in domain_blocklist_subdomains.txt
+const SubdomainsFileSuffix = "_subdomains"
+
+// Formats the domain blocklists are rendered to, each with the rules of its DNS blocker
+const (
+	DomainFormatDomains = "domains" // one domain per line, *.example.com for the subdomains
+	DomainFormatHosts   = "hosts"   // hosts file lines, which cannot match subdomains
+	DomainFormatAdguard = "adguard" // |example.com^ or ||example.com^ rules
+	DomainFormatUnbound = "unbound" // local-data or local-zone always_nxdomain lines
+	DomainFormatDnsmasq = "dnsmasq" // host-record or address lines
+)
+
+var DomainFormats = []string{
+	DomainFormatDomains,
+	DomainFormatHosts,
+	DomainFormatAdguard,
+	DomainFormatUnbound,
+	DomainFormatDnsmasq,
+}
+
 var ListTypes = []string{
 	ListTypeBlocklist,
 	ListTypeAllowlist,
@@ -246,10 +354,11 @@ var ListTypeMap = map[string]string{
 	ListTypeAllowlist: "AL",
 }
 
+// SourceTypeRegexMap holds the patterns of the source types matched line by line.
+// IPv6 addresses and networks are parsed with net/netip instead, see utils.FindIPv6 and utils.FindCIDRv6.
 var SourceTypeRegexMap = map[string]*regexp.Regexp{
 	// match only standalone IPs, not those in CIDR notation (for line-based matching)
 	SourceTypeIpv4:     regexp.MustCompile(`(?:^|[^/\d])(\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3})(?:[^\d/]|$)`),
-	SourceTypeIpv6:     regexp.MustCompile(`\b([0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`),
 	SourceTypeCidrIpv4: regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}/\d{1,2}\b`),
 	SourceTypeDomain: regexp.MustCompile(
 		`^([a-zA-Z0-9_]([a-zA-Z0-9\-_]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?\.([a-zA-Z]{2,})$`,
@@ -280,14 +389,17 @@ var (
 		SourceTypeIpv4,
 		SourceTypeIpv6,
 		SourceTypeCidrIpv4,
+		SourceTypeCidrIpv6,
 		SourceTypeDomain,
 		SourceTypeAdguard,
 	}
 
 	GenericSourceTypeAliases = map[string]string{
-		SourceTypeHostname:        SourceTypeDomain,
-		SourceTypeIpv4RangeExpand: SourceTypeIpv4,
-		SourceTypeIpv4CidrExpand:  SourceTypeIpv4,
+		SourceTypeHostname:         SourceTypeDomain,
+		SourceTypeHostnameSinkhole: SourceTypeDomain,
+		SourceTypeIpv4RangeExpand:  SourceTypeIpv4,
+		SourceTypeIpv4CidrExpand:   SourceTypeIpv4,
+		SourceTypeIpv6CidrExpand:   SourceTypeIpv6,
 	}
 )
 
@@ -296,12 +408,14 @@ var (
 		SourceTypeDomain:  "data/allowlist_domains.txt",
 		SourceTypeAdguard: "data/allowlist_adg.txt",
 		SourceTypeIpv4:    "data/allowlist_ipv4.txt",
+		SourceTypeIpv6:    "data/allowlist_ipv6.txt",
 	}
 
 	CustomAllowlistFilesMap = map[string]string{
 		SourceTypeDomain:  "data/custom/allowlist_domains.txt",
 		SourceTypeAdguard: "data/custom/allowlist_adg.txt",
 		SourceTypeIpv4:    "data/custom/allowlist_ipv4.txt",
+		SourceTypeIpv6:    "data/custom/allowlist_ipv6.txt",
 	}
 
 	CustomOverrideFilesMap = map[string]map[string]string{
@@ -317,6 +431,10 @@ var (
 			ForcedAllow: "data/custom/ipv4_forced_allow.txt",
 			ForcedBlock: "data/custom/ipv4_forced_block.txt",
 		},
+		SourceTypeIpv6: {
+			ForcedAllow: "data/custom/ipv6_forced_allow.txt",
+			ForcedBlock: "data/custom/ipv6_forced_block.txt",
+		},
 	}
 )
 
@@ -401,6 +519,9 @@ var (
 
 var ArchiveExtensions = []string{".zip", ".tar.gz"}
 
+// CompressedExtensions are the extensions of single-file compressed downloads, decompressed transparently
+var CompressedExtensions = []string{".gz", ".bz2", ".xz"}
+
 const (
 	SearchProcessedFile    = "processed"
 	SearchConsolidatedFile = "consolidated"
@@ -415,7 +536,15 @@ const (
 	DefaultHashAlgorithm          = "md5"
 	DefaultMaxRetries             = 3
 	DefaultRetryDelayInSeconds    = 10
+	DefaultHostMaxConcurrent      = 2
+	DefaultCircuitBreakerFailures = 5
+	MaxRetryAfter                 = 5 * time.Minute
+	DefaultAnomalyMaxDropPercent  = 80
+	DefaultAnomalyMinEntries      = 100
 	DefaultClientTimeoutInSeconds = 30
+	DefaultExecTimeoutInSeconds   = 300
+	LargeDownloadSize             = 16 << 20 // bytes from which the progress of a download is logged
+	DownloadProgressInterval      = 10 * time.Second
 	DefaultMaxRedirects           = 20
 	EntryAverageCharLength        = 30
 	EntryMinCharLength            = 6
@@ -0,0 +1,65 @@
+package downloaders
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+)
+
+// checkAnomaly compares the files of a new download with the history of guard.
+// A compressed payload is checked by its decompressed content, given its extension in ext.
+func checkAnomaly(logger *multilog.Logger, rawURL string, guard c.AnomalyGuard, ext string, paths ...string) error {
+	entries := 0
+	for _, path := range paths {
+		count, html, err := scanEntries(logger, path, ext)
+		if err != nil {
+			return err
+		}
+		if reason := guard.CheckHTML(html); reason != "" {
+			return &AnomalyError{URL: rawURL, Reason: reason}
+		}
+		entries += count
+	}
+	if reason := guard.CheckEntries(guard.PreviousEntries, entries); reason != "" {
+		return &AnomalyError{URL: rawURL, Reason: reason}
+	}
+	return nil
+}
+
+// checkArchiveAnomaly checks the extracted source files of the targets of file before they are copied.
+func checkArchiveAnomaly(logger *multilog.Logger, file c.DownloadFile) error {
+	var paths []string
+	for _, target := range file.Targets {
+		sources, err := u.ResolveTargetSources(target)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, sources...)
+	}
+	return checkAnomaly(logger, file.URL, *file.Guard, "", paths...)
+}
+
+// scanEntries counts the entries of the file at path and reports whether it starts like an HTML page.
+func scanEntries(logger *multilog.Logger, path, ext string) (int, bool, error) {
+	var reader io.ReadCloser
+	var err error
+	if ext != "" {
+		reader, err = u.OpenDecompressed(logger, path, ext)
+	} else {
+		reader, err = os.Open(path)
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer u.CloseBody(logger, reader)
+
+	buffered := bufio.NewReader(reader)
+	head, _ := buffered.Peek(512) // shorter content is returned with an error
+	html := u.IsHTML(head)
+	entries, err := u.CountEntries(buffered)
+	return entries, html, err
+}
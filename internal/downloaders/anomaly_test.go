@@ -0,0 +1,159 @@
+package downloaders
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// domainList returns a list of count domains, one per line.
+func domainList(count int) string {
+	var sb strings.Builder
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&sb, "host%d.example.com\n", i)
+	}
+	return sb.String()
+}
+
+func TestDefaultDownloader_AnomalyGuard(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	var gzBuf bytes.Buffer
+	gzWriter := gzip.NewWriter(&gzBuf)
+	_, _ = gzWriter.Write([]byte(domainList(5)))
+	require.NoError(t, gzWriter.Close())
+
+	var tarBuf bytes.Buffer
+	tarGzWriter := gzip.NewWriter(&tarBuf)
+	tarWriter := tar.NewWriter(tarGzWriter)
+	truncated := domainList(5)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+		Name:     "feed/domains.txt",
+		Mode:     0644,
+		Size:     int64(len(truncated)),
+		Typeflag: tar.TypeReg,
+	}))
+	_, _ = tarWriter.Write([]byte(truncated))
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, tarGzWriter.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/truncated.txt":
+			_, _ = w.Write([]byte(domainList(5)))
+		case "/shrunk.txt":
+			_, _ = w.Write([]byte(domainList(150)))
+		case "/login.txt":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<!DOCTYPE html><html><body>Please sign in</body></html>"))
+		case "/domains.txt.gz":
+			_, _ = w.Write(gzBuf.Bytes())
+		case "/feed.tar.gz":
+			_, _ = w.Write(tarBuf.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	previous := domainList(200)
+	guard := &c.AnomalyGuard{PreviousEntries: 200}
+
+	tests := []struct {
+		name    string
+		path    string
+		guard   *c.AnomalyGuard
+		wantErr bool
+	}{
+		{name: "collapsed", path: "/truncated.txt", guard: guard, wantErr: true},
+		{name: "small drop", path: "/shrunk.txt", guard: guard},
+		{name: "html", path: "/login.txt", guard: &c.AnomalyGuard{}, wantErr: true},
+		{
+			name:  "html allowed",
+			path:  "/login.txt",
+			guard: &c.AnomalyGuard{AnomalyGuardConfig: c.AnomalyGuardConfig{AllowHTML: true}},
+		},
+		{name: "disabled", path: "/truncated.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloadDir := t.TempDir()
+			filePath := filepath.Join(downloadDir, "list.txt")
+			require.NoError(t, os.WriteFile(filePath, []byte(previous), 0644))
+			file := c.DownloadFile{
+				Name:     "list",
+				URL:      server.URL + tt.path,
+				Folder:   downloadDir,
+				Filename: "list.txt",
+				Guard:    tt.guard,
+			}
+
+			_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+			content, readErr := os.ReadFile(filePath)
+			require.NoError(t, readErr)
+			if tt.wantErr {
+				var anomalyErr *AnomalyError
+				require.True(t, errors.As(err, &anomalyErr), "expected an AnomalyError, got %v", err)
+				assert.Equal(t, file.URL, anomalyErr.URL)
+				assert.Equal(t, previous, string(content), "the previous copy should be kept")
+				entries, readErr := os.ReadDir(downloadDir)
+				require.NoError(t, readErr)
+				assert.Len(t, entries, 1, "no staged file should be left behind")
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEqual(t, previous, string(content))
+		})
+	}
+
+	t.Run("compressed file", func(t *testing.T) {
+		downloadDir := t.TempDir()
+		source := config.Source{Name: "gz", URL: server.URL + "/domains.txt.gz"}
+		file, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+		targetPath := filepath.Join(downloadDir, "gz.txt")
+		require.NoError(t, os.WriteFile(targetPath, []byte(previous), 0644))
+		file.Guard = guard
+
+		_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		var anomalyErr *AnomalyError
+		assert.True(t, errors.As(err, &anomalyErr), "expected an AnomalyError, got %v", err)
+		content, err := os.ReadFile(targetPath)
+		require.NoError(t, err)
+		assert.Equal(t, previous, string(content))
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		downloadDir := t.TempDir()
+		source := config.Source{Name: "feed", URL: server.URL + "/feed.tar.gz", Files: []string{"feed/domains.txt"}}
+		file, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+		targetPath := filepath.Join(downloadDir, "feed.txt")
+		require.NoError(t, os.WriteFile(targetPath, []byte(previous), 0644))
+		file.Guard = guard
+
+		_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		var anomalyErr *AnomalyError
+		assert.True(t, errors.As(err, &anomalyErr), "expected an AnomalyError, got %v", err)
+		content, err := os.ReadFile(targetPath)
+		require.NoError(t, err)
+		assert.Equal(t, previous, string(content))
+	})
+}
@@ -1,6 +1,7 @@
 package downloaders
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +21,7 @@ import (
 	"github.com/phani-kb/dns-toolkit/internal/constants"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
+	"golang.org/x/net/http/httpproxy"
 )
 
 const (
@@ -46,6 +49,28 @@ func (e *CertVerificationError) Error() string {
 	return fmt.Sprintf("Certificate verification failed for host %s: %v", e.Host, e.Err)
 }
 
+// IntegrityError is returned when a download does not match its pinned or published checksum or signature,
+// or when they could not be checked. The previous copy of the file is kept.
+type IntegrityError struct {
+	URL    string
+	Reason string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("Integrity verification failed for %s: %s", e.URL, e.Reason)
+}
+
+// AnomalyError is returned when a download looks broken compared to the previous one of the source,
+// e.g. when its entry count collapsed or an HTML page arrived for a text list. The previous copy is kept.
+type AnomalyError struct {
+	URL    string
+	Reason string
+}
+
+func (e *AnomalyError) Error() string {
+	return fmt.Sprintf("Anomaly detected for %s: %s", e.URL, e.Reason)
+}
+
 type DefaultDownloader struct {
 	rnd           *rand.Rand
 	maxRetries    int
@@ -94,17 +119,227 @@ func (d *DefaultDownloader) Name() string {
 }
 
 func (d *DefaultDownloader) Download(
+	ctx context.Context,
 	logger *multilog.Logger,
-	file c.DownloadFile,
-	skipCertVerify bool,
-	skipCertHosts []string,
+	file *c.DownloadFile,
 	applicationConfig cfg.ApplicationConfig,
 ) (string, bool, error) {
 	fileUrl := file.URL
 	if strings.HasPrefix(fileUrl, "file://") {
 		return d.copyLocalFile(logger, fileUrl, file.Folder, file.Filename)
 	}
-	return d.downloadFile(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+
+	filePath, fetchSkipped, err := d.downloadFile(ctx, logger, file, applicationConfig, true)
+	recordHostResult(logger, file.Hosts, file.URL, err)
+	if err == nil {
+		file.ServedURL = file.URL
+	} else if len(file.Mirrors) > 0 && isRetryableError(err) {
+		var mirrorErr error
+		filePath, fetchSkipped, mirrorErr = d.downloadFromMirrors(ctx, logger, file, applicationConfig)
+		if mirrorErr == nil {
+			err = nil
+		}
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if file.VerifyMirrors && !fetchSkipped {
+		d.verifyMirrors(ctx, logger, file, filePath, applicationConfig)
+	}
+	return filePath, fetchSkipped, nil
+}
+
+// downloadFromMirrors tries the mirrors of file in order after its url failed, returning the first success.
+// Validators belong to the url that returned them, so they are not used or kept for a mirror.
+func (d *DefaultDownloader) downloadFromMirrors(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file *c.DownloadFile,
+	applicationConfig cfg.ApplicationConfig,
+) (string, bool, error) {
+	var lastErr error
+	for _, mirrorURL := range file.Mirrors {
+		if ctx.Err() != nil {
+			return "", false, ctx.Err()
+		}
+		logger.Infof("Trying mirror %s for %s", mirrorURL, file.Name)
+		mirror := mirrorFile(*file, mirrorURL)
+		mirror.ETag, mirror.LastModified = "", ""
+		filePath, fetchSkipped, err := d.downloadFile(ctx, logger, &mirror, applicationConfig, false)
+		recordHostResult(logger, file.Hosts, mirrorURL, err)
+		if err == nil {
+			file.ServedURL = mirrorURL
+			file.ETag, file.LastModified = "", ""
+			return filePath, fetchSkipped, nil
+		}
+		logger.Warnf("Mirror %s failed for %s: %v", mirrorURL, file.Name, err)
+		lastErr = err
+		if !isRetryableError(err) {
+			break
+		}
+	}
+	return "", false, lastErr
+}
+
+// mirrorFile returns a copy of file downloading rawURL, another url serving the same content.
+// The headers and basic auth credentials of the http options belong to the origin of the url of file,
+// so they are not sent to a url of another origin.
+func mirrorFile(file c.DownloadFile, rawURL string) c.DownloadFile {
+	if file.HTTP != nil && !sameOrigin(file.URL, rawURL) {
+		httpOptions := *file.HTTP
+		httpOptions.Headers, httpOptions.BasicAuth = nil, nil
+		file.HTTP = &httpOptions
+	}
+	file.URL = rawURL
+	return file
+}
+
+// sameOrigin reports whether two urls have the same scheme and host, including the port.
+func sameOrigin(rawURL, otherURL string) bool {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	otherParsedURL, err := url.Parse(strings.TrimSpace(otherURL))
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsedURL.Scheme, otherParsedURL.Scheme) &&
+		strings.EqualFold(parsedURL.Host, otherParsedURL.Host)
+}
+
+// isRetryableError reports whether err is specific to the url that was requested,
+// so that another url serving the same content may succeed.
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusForbidden, http.StatusNotFound, http.StatusRequestTimeout, http.StatusGone,
+			http.StatusTooManyRequests:
+			return true
+		}
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var certErr *CertVerificationError
+	var integrityErr *IntegrityError
+	var anomalyErr *AnomalyError
+	var hostErr *c.HostUnavailableError
+	var urlErr *url.Error
+	return errors.As(err, &certErr) || errors.As(err, &integrityErr) || errors.As(err, &anomalyErr) ||
+		errors.As(err, &hostErr) || errors.As(err, &urlErr)
+}
+
+// isHostFailure reports whether err suggests that the host itself is struggling or refusing us,
+// as opposed to a problem with one url, so that it counts towards the host's circuit breaker.
+func isHostFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var hostErr *c.HostUnavailableError
+	var urlErr *url.Error
+	return !errors.As(err, &hostErr) && errors.As(err, &urlErr)
+}
+
+// recordHostResult feeds the outcome of downloading rawURL to the circuit breaker of its host.
+func recordHostResult(logger *multilog.Logger, hosts *c.HostLimiter, rawURL string, err error) {
+	parsedURL, parseErr := url.Parse(strings.TrimSpace(rawURL))
+	if hosts == nil || parseErr != nil {
+		return
+	}
+	host := parsedURL.Hostname()
+	if err == nil {
+		hosts.RecordSuccess(host)
+		return
+	}
+	if isHostFailure(err) && hosts.RecordFailure(host) {
+		logger.Warnf("Too many consecutive failures for host %s, skipping it for the rest of the run", host)
+	}
+}
+
+// verifyMirrors fetches the other urls of file and records the checksum of each one that responds,
+// warning when they do not all serve the same content.
+func (d *DefaultDownloader) verifyMirrors(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file *c.DownloadFile,
+	filePath string,
+	applicationConfig cfg.ApplicationConfig,
+) {
+	checksums := map[string]string{file.ServedURL: u.CalculateChecksum(logger, filePath, constants.DefaultHashAlgorithm)}
+	for _, otherURL := range append([]string{file.URL}, file.Mirrors...) {
+		if otherURL == file.ServedURL {
+			continue
+		}
+		checksum, err := d.fetchChecksum(ctx, logger, *file, otherURL, applicationConfig)
+		if err != nil {
+			logger.Warnf("Verifying mirror %s for %s failed: %v", otherURL, file.Name, err)
+			continue
+		}
+		checksums[otherURL] = checksum
+	}
+	if len(checksums) < 2 {
+		return
+	}
+	file.MirrorChecksums = checksums
+	if !file.MirrorsConsistent() {
+		logger.Warnf("Mirrors of %s serve different content: %v", file.Name, checksums)
+	}
+}
+
+// fetchChecksum downloads rawURL with the options of file and returns the checksum of the content.
+func (d *DefaultDownloader) fetchChecksum(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file c.DownloadFile,
+	rawURL string,
+	applicationConfig cfg.ApplicationConfig,
+) (string, error) {
+	content, err := d.fetchContent(ctx, logger, file, rawURL, applicationConfig)
+	if err != nil {
+		return "", err
+	}
+	return u.CalculateChecksumFromContent(content, constants.DefaultHashAlgorithm), nil
+}
+
+// fetchContent downloads rawURL with the options of file and returns its content.
+// The credentials of file are only sent to rawURL if it has the origin of the url of file.
+func (d *DefaultDownloader) fetchContent(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file c.DownloadFile,
+	rawURL string,
+	applicationConfig cfg.ApplicationConfig,
+) ([]byte, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	file = mirrorFile(file, rawURL)
+	client, err := d.createHTTPClient(logger, file, parsedURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequest(ctx, http.MethodGet, file, userAgentFor(logger, file, applicationConfig))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doRequest(ctx, client, req, file.Hosts)
+	if err != nil {
+		return nil, err
+	}
+	defer u.CloseBody(logger, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: rawURL}
+	}
+	return io.ReadAll(resp.Body)
 }
 
 func (d *DefaultDownloader) PostDownloadProcess(_ *multilog.Logger, _ string, _ int) error {
@@ -142,11 +377,11 @@ func (d *DefaultDownloader) copyLocalFile(
 }
 
 func (d *DefaultDownloader) downloadFile(
+	ctx context.Context,
 	logger *multilog.Logger,
-	file c.DownloadFile,
-	skipCertVerify bool,
-	skipCertHosts []string,
+	file *c.DownloadFile,
 	applicationConfig cfg.ApplicationConfig,
+	allowSkip bool,
 ) (string, bool, error) {
 	filePath := filepath.Join(file.Folder, file.Filename)
 	fileExists := false
@@ -162,27 +397,43 @@ func (d *DefaultDownloader) downloadFile(
 		logger.Errorf("Parsing URL error: %v", err)
 		return "", false, err
 	}
-	client := d.createHTTPClient(logger, skipCertVerify, skipCertHosts, parsedURL)
-	userAgent := cfg.GetUserAgent(logger, applicationConfig)
+	client, err := d.createHTTPClient(logger, *file, parsedURL)
+	if err != nil {
+		logger.Errorf("Creating HTTP client error: %v", err)
+		return "", false, err
+	}
+	userAgent := userAgentFor(logger, *file, applicationConfig)
 	logger.Debugf("User-Agent: %s", userAgent)
-	if fileExists && d.canSkipDownload(logger, client, userAgent, file, localFileSize, localModTime) {
-		archiveErr := d.handleArchiveFile(logger, file, filePath)
+	if allowSkip && fileExists && d.canSkipDownload(ctx, logger, client, userAgent, *file, localFileSize, localModTime) {
+		archiveErr := d.handleArchiveFile(logger, *file, filePath)
 		return filePath, true, archiveErr
 	}
+	// An interrupted download is resumed instead of made conditional, If-Range falls back to the full body
+	offset, partialETag := loadPartial(logger, *file)
+	conditional := fileExists && hasValidators(*file) && offset == 0
 
 	var resp *http.Response
 	var lastErr error
 
-	for attempt := 1; attempt <= d.maxRetries; attempt++ {
-		req, reqErr := http.NewRequest("GET", file.URL, nil)
+	maxRetries := d.maxRetries
+	if file.HTTP != nil && file.HTTP.MaxRetries > 0 {
+		maxRetries = file.HTTP.MaxRetries
+	}
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		req, reqErr := newRequest(ctx, http.MethodGet, *file, userAgent)
 		if reqErr != nil {
 			logger.Errorf("Creating request error: %v", reqErr)
-			lastErr = reqErr
-			continue
+			return "", false, reqErr
 		}
 
-		req.Header.Set("User-Agent", userAgent)
-		resp, err = client.Do(req)
+		if conditional {
+			setConditionalHeaders(req, *file)
+		}
+		if offset > 0 {
+			setRangeHeaders(req, offset, partialETag)
+		}
+		resp, err = doRequest(ctx, client, req, file.Hosts)
 
 		// If we get a response but encounter an error later, we should still close the body
 		if err == nil && resp != nil && resp.Body != nil {
@@ -193,6 +444,11 @@ func (d *DefaultDownloader) downloadFile(
 			}()
 		}
 
+		var hostErr *c.HostUnavailableError
+		if errors.As(err, &hostErr) {
+			logger.Warnf("Skipping %s: %v", file.URL, err)
+			return "", false, err
+		}
 		if err != nil {
 			var urlErr *url.Error
 			isTLSErr := errors.As(err, &urlErr)
@@ -208,34 +464,66 @@ func (d *DefaultDownloader) downloadFile(
 				logger.Warnf("Attempt %d: Failed to download file: %v", attempt, err)
 			}
 
-			if isTLSErr && strings.Contains(urlErr.Error(), "certificate") && attempt == 1 {
+			if isTLSErr && strings.Contains(urlErr.Error(), "certificate") {
 				logger.Warnf(
-					"Certificate error detected, consider adding %s to skipCertVerificationHosts in config",
+					"Certificate error detected for %s, consider setting insecure_skip_verify in the http block of %s",
 					parsedURL.Host,
+					file.Name,
 				)
-				if !skipCertVerify || !containsHost(skipCertHosts, parsedURL.Host) {
-					return "", false, lastErr
-				}
+				return "", false, lastErr
 			}
 
-			time.Sleep(d.retryDelay * time.Duration(1<<uint(attempt-1)))
+			if ctx.Err() != nil {
+				return "", false, ctx.Err()
+			}
+			if sleepErr := sleepWithContext(ctx, d.retryDelay*time.Duration(1<<uint(attempt-1))); sleepErr != nil {
+				return "", false, sleepErr
+			}
+			continue
+		}
+
+		if offset > 0 && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			logger.Infof("Cannot resume %s, downloading it again", file.URL)
+			u.CloseBody(logger, resp.Body)
+			removePartial(logger, partialPath(*file))
+			offset, partialETag = 0, ""
+			attempt-- // the retry is not caused by a failure
 			continue
 		}
 
 		// Check response status
-		if resp != nil && resp.StatusCode == http.StatusTooManyRequests && attempt < d.maxRetries {
+		throttled := resp != nil &&
+			(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable)
+		if throttled {
+			wait, hasRetryAfter := retryAfter(resp)
+			if hasRetryAfter && file.Hosts != nil {
+				// Hold back the other downloads from this host as well
+				file.Hosts.Delay(parsedURL.Hostname(), min(wait, constants.MaxRetryAfter))
+			}
+			if attempt >= maxRetries || (resp.StatusCode == http.StatusServiceUnavailable && !hasRetryAfter) {
+				break
+			}
+			if wait > constants.MaxRetryAfter {
+				logger.Warnf("Attempt %d: Retry-After of %s for %s exceeds %s, giving up",
+					attempt, wait, file.URL, constants.MaxRetryAfter)
+				break
+			}
 			u.CloseBody(logger, resp.Body)
 
-			// For 429, use exponential backoff with jitter
-			waitTime := d.retryDelay * time.Duration(1<<uint(attempt))
-			// Add jitter to avoid synchronized retries
-			jitter := time.Duration(d.rnd.Intn(1000)) * time.Millisecond
-			backoffTime := waitTime + jitter
+			if !hasRetryAfter {
+				// For 429 without Retry-After, use exponential backoff with jitter
+				waitTime := d.retryDelay * time.Duration(1<<uint(attempt))
+				// Add jitter to avoid synchronized retries
+				jitter := time.Duration(d.rnd.Intn(1000)) * time.Millisecond
+				wait = waitTime + jitter
+			}
 
-			logger.Warnf("Attempt %d: Rate limited (429) for %s. Retrying in %.1f seconds...",
-				attempt, file.URL, backoffTime.Seconds())
+			logger.Warnf("Attempt %d: %s for %s. Retrying in %.1f seconds...",
+				attempt, resp.Status, file.URL, wait.Seconds())
 
-			time.Sleep(backoffTime)
+			if sleepErr := sleepWithContext(ctx, wait); sleepErr != nil {
+				return "", false, sleepErr
+			}
 			continue
 		}
 
@@ -248,6 +536,13 @@ func (d *DefaultDownloader) downloadFile(
 
 	defer u.CloseBody(logger, resp.Body)
 
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		logger.Infof("Not modified, skipping download: %s", filePath)
+		updateValidators(file, resp)
+		archiveErr := d.handleArchiveFile(logger, *file, filePath)
+		return filePath, true, archiveErr
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		if resp.StatusCode >= 300 && resp.StatusCode < 400 {
 			location := resp.Header.Get("Location")
@@ -302,21 +597,66 @@ func (d *DefaultDownloader) downloadFile(
 		return "", false, statusErr
 	}
 
-	filePath, err = u.SaveFile(logger, file.Folder, file.Filename, resp.Body)
+	received, partPath, err := d.receiveBody(ctx, logger, client, userAgent, *file, resp, offset, partialETag)
 	if err != nil {
+		logger.Errorf("Receiving %s error: %v", file.URL, err)
 		return "", false, err
 	}
+	verify := d.verifyDownload(ctx, logger, *file, applicationConfig)
+	if err = u.MoveVerifiedFile(logger, partPath, filePath, verify); err != nil {
+		logKeptCopy(logger, err, filePath, fileExists)
+		removePartial(logger, partPath)
+		return "", false, err
+	}
+	removePartial(logger, partPath) // the state of the moved partial file
 	logger.Infof("Downloaded %s", filePath)
-	err = d.handleArchiveFile(logger, file, filePath)
+	file.ETag, file.LastModified = "", ""
+	updateValidators(file, received)
+	err = d.extractArchive(logger, *file, filePath, true)
 	return filePath, false, err
 }
 
+// verifyDownload returns the check of a staged download of file against its integrity options and anomaly guard.
+func (d *DefaultDownloader) verifyDownload(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file c.DownloadFile,
+	applicationConfig cfg.ApplicationConfig,
+) func(stagedPath string) error {
+	return func(stagedPath string) error {
+		if file.Integrity != nil {
+			if err := d.verifyIntegrity(ctx, logger, file, stagedPath, applicationConfig); err != nil {
+				return err
+			}
+		}
+		if file.Guard == nil {
+			return nil
+		}
+		// Archives are checked once extracted, but a compressed file is decompressed over its previous copy
+		compressedExt := u.GetCompressedExtension(file.Filename)
+		if !file.IsArchive || compressedExt != "" {
+			return checkAnomaly(logger, file.URL, *file.Guard, compressedExt, stagedPath)
+		}
+		return nil
+	}
+}
+
+// logKeptCopy logs that the previous copy at filePath is kept when err rejected a new download.
+func logKeptCopy(logger *multilog.Logger, err error, filePath string, fileExists bool) {
+	var integrityErr *IntegrityError
+	var anomalyErr *AnomalyError
+	if (errors.As(err, &integrityErr) || errors.As(err, &anomalyErr)) && fileExists {
+		logger.Errorf("%v, keeping the previous copy of %s", err, filePath)
+	}
+}
+
+// createHTTPClient creates the client used for every request of file, applying its http options
+// and routing through its proxy, if any.
 func (d *DefaultDownloader) createHTTPClient(
 	logger *multilog.Logger,
-	skipCertVerify bool,
-	skipCertHosts []string,
+	file c.DownloadFile,
 	parsedURL *url.URL,
-) *http.Client {
+) (*http.Client, error) {
 	jar, jarErr := cookiejar.New(nil)
 	if jarErr != nil {
 		logger.Warnf("Failed to create cookie jar: %v", jarErr)
@@ -327,21 +667,60 @@ func (d *DefaultDownloader) createHTTPClient(
 		Jar:     jar,
 	}
 
-	if skipCertVerify {
-		for _, host := range skipCertHosts {
-			if strings.Contains(parsedURL.Host, host) {
-				logger.Debugf("Skipping certificate verification for host: %s", parsedURL.Host)
-				transport := &http.Transport{
-					TLSClientConfig: &tls.Config{
-						InsecureSkipVerify: true,
-					},
-				}
-				client.Transport = transport
-				break
-			}
+	httpOptions := file.HTTP
+	insecure := httpOptions != nil && httpOptions.InsecureSkipVerify
+	if httpOptions != nil && httpOptions.Timeout > 0 {
+		client.Timeout = time.Duration(httpOptions.Timeout) * time.Second
+	}
+	if !insecure && file.Proxy == nil {
+		return client, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		logger.Debugf("Skipping certificate verification for host: %s", parsedURL.Host)
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
 		}
 	}
-	return client
+	if file.Proxy != nil {
+		proxyFunc, err := newProxyFunc(*file.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = proxyFunc
+	}
+	client.Transport = transport
+	return client, nil
+}
+
+// newProxyFunc returns a transport proxy function for proxy, with its credentials expanded
+// and its no-proxy hosts connected to directly.
+func newProxyFunc(proxy c.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if proxy.Username != "" {
+		username, err := u.ExpandEnvPlaceholders(proxy.Username)
+		if err != nil {
+			return nil, fmt.Errorf("proxy username: %w", err)
+		}
+		password, err := u.ExpandEnvPlaceholders(proxy.Password)
+		if err != nil {
+			return nil, fmt.Errorf("proxy password: %w", err)
+		}
+		proxyURL.User = url.UserPassword(username, password)
+	}
+	proxyConfig := httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(proxy.NoProxy, ","),
+	}
+	resolve := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return resolve(req.URL)
+	}, nil
 }
 
 func canonicalURLString(u *url.URL) string {
@@ -369,6 +748,7 @@ func canonicalURLString(u *url.URL) string {
 }
 
 func (d *DefaultDownloader) canSkipDownload(
+	ctx context.Context,
 	logger *multilog.Logger,
 	client *http.Client,
 	userAgent string,
@@ -381,12 +761,16 @@ func (d *DefaultDownloader) canSkipDownload(
 		return true
 	}
 
-	headReq, err := http.NewRequest("HEAD", file.URL, nil)
+	// With stored validators the GET is made conditional instead, saving the HEAD round trip
+	if hasValidators(file) {
+		return false
+	}
+
+	headReq, err := newRequest(ctx, http.MethodHead, file, userAgent)
 	if err != nil {
 		return false
 	}
-	headReq.Header.Set("User-Agent", userAgent)
-	resp, err := client.Do(headReq)
+	resp, err := doRequest(ctx, client, headReq, file.Hosts)
 
 	// Always ensure we close the response body if we got a response
 	if resp != nil && resp.Body != nil {
@@ -418,14 +802,42 @@ func (d *DefaultDownloader) canSkipDownload(
 }
 
 func (d *DefaultDownloader) handleArchiveFile(logger *multilog.Logger, file c.DownloadFile, filePath string) error {
+	return d.extractArchive(logger, file, filePath, false)
+}
+
+// extractArchive extracts or decompresses the archive of file and copies its targets,
+// overwriting existing targets when refresh is set, i.e. after a new copy of the archive was downloaded.
+func (d *DefaultDownloader) extractArchive(
+	logger *multilog.Logger,
+	file c.DownloadFile,
+	filePath string,
+	refresh bool,
+) error {
 	if file.IsArchive {
-		if err := u.ExtractArchive(logger, filePath, file.Folder); err != nil {
+		destFolder := file.Folder
+		if file.ExtractFolder != "" {
+			if err := os.RemoveAll(file.ExtractFolder); err != nil {
+				return err
+			}
+			destFolder = file.ExtractFolder
+		}
+		if err := u.ExtractArchive(logger, filePath, destFolder); err != nil {
 			logger.Errorf("Failed to extract archive: %v", err)
 			return err
 		}
 		logger.Debugf("Extracted archive %s", filePath)
+		copyTarget := u.CopySourceToTarget
+		if refresh {
+			copyTarget = u.ForceCopySourceToTarget
+			if file.Guard != nil && u.GetCompressedExtension(file.Filename) == "" {
+				if err := checkArchiveAnomaly(logger, file); err != nil {
+					logger.Errorf("%v, keeping the previous target files", err)
+					return err
+				}
+			}
+		}
 		for _, target := range file.Targets {
-			if err := u.CopySourceToTarget(logger, target); err != nil {
+			if err := copyTarget(logger, target); err != nil {
 				logger.Errorf("Failed to copy target file: %v", err)
 				return err
 			}
@@ -488,14 +900,111 @@ func (d *DefaultDownloader) ShouldDownload(
 	return true
 }
 
-// Helper function to check if a host is in the skipCertHosts list
-func containsHost(hosts []string, targetHost string) bool {
-	for _, host := range hosts {
-		if strings.Contains(targetHost, host) {
-			return true
+// userAgentFor returns the user agent of the http options of file, or the application default.
+func userAgentFor(logger *multilog.Logger, file c.DownloadFile, applicationConfig cfg.ApplicationConfig) string {
+	if file.HTTP != nil && file.HTTP.UserAgent != "" {
+		return file.HTTP.UserAgent
+	}
+	return cfg.GetUserAgent(logger, applicationConfig)
+}
+
+// newRequest creates a request for file with the user agent and the headers and basic auth
+// credentials of its http options, expanding ${NAME} environment variable placeholders.
+func newRequest(ctx context.Context, method string, file c.DownloadFile, userAgent string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, file.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if file.HTTP == nil {
+		return req, nil
+	}
+	for name, value := range file.HTTP.Headers {
+		expanded, err := u.ExpandEnvPlaceholders(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
 		}
+		req.Header.Set(name, expanded)
+	}
+	if auth := file.HTTP.BasicAuth; auth != nil {
+		username, err := u.ExpandEnvPlaceholders(auth.Username)
+		if err != nil {
+			return nil, fmt.Errorf("basic auth username: %w", err)
+		}
+		password, err := u.ExpandEnvPlaceholders(auth.Password)
+		if err != nil {
+			return nil, fmt.Errorf("basic auth password: %w", err)
+		}
+		req.SetBasicAuth(username, password)
+	}
+	return req, nil
+}
+
+// doRequest sends req once the host limiter, if any, allows a request to its host.
+func doRequest(
+	ctx context.Context,
+	client *http.Client,
+	req *http.Request,
+	hosts *c.HostLimiter,
+) (*http.Response, error) {
+	if hosts != nil {
+		if err := hosts.Wait(ctx, req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	return client.Do(req)
+}
+
+// retryAfter returns the delay asked for by the Retry-After header of resp, given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// hasValidators reports whether validators from a previous download are available for file.
+func hasValidators(file c.DownloadFile) bool {
+	return file.ETag != "" || file.LastModified != ""
+}
+
+// setConditionalHeaders adds If-None-Match and If-Modified-Since headers from the stored validators.
+func setConditionalHeaders(req *http.Request, file c.DownloadFile) {
+	if file.ETag != "" {
+		req.Header.Set("If-None-Match", file.ETag)
+	}
+	if file.LastModified != "" {
+		req.Header.Set("If-Modified-Since", file.LastModified)
+	}
+}
+
+// updateValidators stores the ETag and Last-Modified headers of resp in file, if present.
+func updateValidators(file *c.DownloadFile, resp *http.Response) {
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		file.ETag = etag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		file.LastModified = lastModified
+	}
+}
+
+// sleepWithContext waits for the given duration or until ctx is cancelled, whichever comes first.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	return false
 }
 
 func init() {}
@@ -1,12 +1,21 @@
 package downloaders
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -114,7 +123,7 @@ func TestDefaultDownloader_Download_LocalFile(t *testing.T) {
 		Filename: "destination.txt",
 	}
 
-	destPath, exists, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+	destPath, exists, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.NoError(t, err)
 	assert.False(t, exists)
 	assert.Equal(t, filepath.Join(destDir, "destination.txt"), destPath)
@@ -157,7 +166,7 @@ func TestDefaultDownloader_Download_RemoteFile(t *testing.T) {
 		Filename: "remote.txt",
 	}
 
-	destPath, exists, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+	destPath, exists, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.NoError(t, err)
 	assert.False(t, exists)
 	assert.Equal(t, filepath.Join(destDir, "remote.txt"), destPath)
@@ -212,7 +221,7 @@ func TestDefaultDownloader_RetryLogic(t *testing.T) {
 		Filename: "retry.txt",
 	}
 
-	destPath, exists, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+	destPath, exists, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.NoError(t, err)
 	assert.False(t, exists)
 	assert.Equal(t, filepath.Join(destDir, "retry.txt"), destPath)
@@ -223,6 +232,69 @@ func TestDefaultDownloader_RetryLogic(t *testing.T) {
 	assert.Contains(t, string(downloadedContent), "success after retry")
 }
 
+func TestDefaultDownloader_ConditionalRequest(t *testing.T) {
+	logger := setupTestLogger()
+	testDir := t.TempDir()
+
+	origSummaryDir := constants.SummaryDir
+	constants.SummaryDir = filepath.Join(testDir, "summary")
+	defer func() {
+		constants.SummaryDir = origSummaryDir
+	}()
+
+	const etag = `"v1"`
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var headRequests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			headRequests++
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = fmt.Fprintln(w, "example.com")
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	file := c.DownloadFile{
+		Name:     "conditional",
+		URL:      server.URL,
+		Folder:   testDir,
+		Filename: "conditional.txt",
+	}
+
+	destPath, fetchSkipped, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.False(t, fetchSkipped)
+	assert.Equal(t, etag, file.ETag)
+	assert.Equal(t, lastModified, file.LastModified)
+
+	_, fetchSkipped, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.True(t, fetchSkipped, "304 should be reported as fetch skipped")
+	assert.Equal(t, 1, notModified)
+	assert.Equal(t, 0, headRequests, "conditional download should not send a HEAD request")
+	assert.Equal(t, etag, file.ETag)
+
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com\n", string(content))
+
+	// A changed ETag downloads the file again and replaces the validators
+	file.ETag = `"v0"`
+	file.LastModified = ""
+	_, fetchSkipped, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.False(t, fetchSkipped)
+	assert.Equal(t, etag, file.ETag)
+	assert.Equal(t, lastModified, file.LastModified)
+}
+
 func TestCanSkipDownload(t *testing.T) {
 	logger := setupTestLogger()
 	testDir := setupTestDir(t)
@@ -280,7 +352,7 @@ func TestCanSkipDownload(t *testing.T) {
 	require.NoError(t, err)
 
 	file.Folder = mockDir
-	canSkip := d.canSkipDownload(logger, &http.Client{}, "test-agent", file, fileSize, modTime)
+	canSkip := d.canSkipDownload(context.Background(), logger, &http.Client{}, "test-agent", file, fileSize, modTime)
 	assert.True(t, canSkip, "Should skip download for existing files")
 
 	badServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -317,7 +389,7 @@ func TestCanSkipDownloadWithBadRequest(t *testing.T) {
 		Filename: "does_not_exist.txt",
 	}
 
-	canSkip := d.canSkipDownload(logger, &http.Client{}, "test-agent", nonExistentFile, 0, time.Time{})
+	canSkip := d.canSkipDownload(context.Background(), logger, &http.Client{}, "test-agent", nonExistentFile, 0, time.Time{})
 	assert.False(t, canSkip, "Should not skip download when file doesn't exist")
 }
 
@@ -401,17 +473,608 @@ func TestCreateHTTPClient(t *testing.T) {
 	parsedURL, err := url.Parse("https://example.com")
 	require.NoError(t, err)
 
-	client := d.createHTTPClient(logger, false, nil, parsedURL)
+	client, err := d.createHTTPClient(logger, c.DownloadFile{}, parsedURL)
+	require.NoError(t, err)
 	assert.NotNil(t, client, "Should create a default HTTP client")
+	assert.Equal(t, d.clientTimeout, client.Timeout)
+	assert.Nil(t, client.Transport)
+
+	withOptions := c.DownloadFile{HTTP: &c.HTTPOptions{Timeout: 90, InsecureSkipVerify: true}}
+	clientWithOptions, err := d.createHTTPClient(logger, withOptions, parsedURL)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, clientWithOptions.Timeout)
+	transport, ok := clientWithOptions.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+
+	withProxy := c.DownloadFile{Proxy: &c.ProxyConfig{URL: "http://proxy.local:3128"}}
+	clientWithProxy, err := d.createHTTPClient(logger, withProxy, parsedURL)
+	require.NoError(t, err)
+	transport, ok = clientWithProxy.Transport.(*http.Transport)
+	require.True(t, ok)
+	proxyURL, err := transport.Proxy(&http.Request{URL: parsedURL})
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.local:3128", proxyURL.String())
+
+	withBadProxy := c.DownloadFile{Proxy: &c.ProxyConfig{URL: "http://proxy.local", Username: "${DNS_TOOLKIT_TEST_UNSET_USER}"}}
+	_, err = d.createHTTPClient(logger, withBadProxy, parsedURL)
+	assert.ErrorContains(t, err, "DNS_TOOLKIT_TEST_UNSET_USER")
+}
+
+func TestNewProxyFunc(t *testing.T) {
+	t.Setenv("DNS_TOOLKIT_TEST_PROXY_PASSWORD", "s3cret")
+
+	proxyFunc, err := newProxyFunc(c.ProxyConfig{
+		URL:      "http://proxy.local:3128",
+		Username: "user",
+		Password: "${DNS_TOOLKIT_TEST_PROXY_PASSWORD}",
+		NoProxy:  []string{"internal.example.com", ".corp.example", "10.0.0.0/8"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		url     string
+		proxied bool
+	}{
+		{url: "https://lists.example.org/hosts.txt", proxied: true},
+		{url: "http://lists.example.org/hosts.txt", proxied: true},
+		{url: "https://internal.example.com/list.txt", proxied: false},
+		{url: "https://mirror.corp.example/list.txt", proxied: false},
+		{url: "http://10.1.2.3/list.txt", proxied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+			proxyURL, err := proxyFunc(req)
+			require.NoError(t, err)
+			if !tt.proxied {
+				assert.Nil(t, proxyURL)
+				return
+			}
+			require.NotNil(t, proxyURL)
+			assert.Equal(t, "proxy.local:3128", proxyURL.Host)
+			password, _ := proxyURL.User.Password()
+			assert.Equal(t, "user", proxyURL.User.Username())
+			assert.Equal(t, "s3cret", password)
+		})
+	}
+}
+
+func TestDefaultDownloader_Proxy(t *testing.T) {
+	logger := setupTestLogger()
+	testDir := t.TempDir()
+
+	// Stand-in for a forward proxy: it records the proxied requests and answers them itself
+	var mu sync.Mutex
+	var requests []string
+	var proxyAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.String())
+		proxyAuth = r.Header.Get("Proxy-Authorization")
+		mu.Unlock()
+		w.Header().Set("Content-Length", "12")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = fmt.Fprintln(w, "example.com")
+		}
+	}))
+	defer proxy.Close()
+
+	d := newTestDownloader(1)
+	file := c.DownloadFile{
+		Name:     "proxied",
+		URL:      "http://lists.example.test/hosts.txt",
+		Folder:   testDir,
+		Filename: "proxied.txt",
+		Proxy:    &c.ProxyConfig{URL: proxy.URL, Username: "user", Password: "pass"},
+	}
+
+	filePath, fetchSkipped, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.False(t, fetchSkipped)
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com\n", string(content))
+
+	// The local file now matches the proxied HEAD response, so the download is skipped
+	_, fetchSkipped, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.True(t, fetchSkipped)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"GET http://lists.example.test/hosts.txt",
+		"HEAD http://lists.example.test/hosts.txt",
+	}, requests)
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")), proxyAuth)
+}
+
+func TestDefaultDownloader_HTTPOptions(t *testing.T) {
+	t.Setenv("DNS_TOOLKIT_TEST_API_KEY", "key-123")
+	t.Setenv("DNS_TOOLKIT_TEST_PASSWORD", "pa$$")
+
+	logger := setupTestLogger()
+	testDir := t.TempDir()
+
+	var gotKey, gotAgent, gotUser, gotPassword string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("Auth-Key")
+		gotAgent = r.UserAgent()
+		gotUser, gotPassword, _ = r.BasicAuth()
+		_, _ = fmt.Fprintln(w, "example.com")
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	file := c.DownloadFile{
+		URL:      server.URL,
+		Folder:   testDir,
+		Filename: "http_options.txt",
+		HTTP: &c.HTTPOptions{
+			Headers:   map[string]string{"Auth-Key": "${DNS_TOOLKIT_TEST_API_KEY}"},
+			BasicAuth: &c.BasicAuth{Username: "user", Password: "${DNS_TOOLKIT_TEST_PASSWORD}"},
+			UserAgent: "custom-agent/1.0",
+		},
+	}
+
+	_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "key-123", gotKey)
+	assert.Equal(t, "custom-agent/1.0", gotAgent)
+	assert.Equal(t, "user", gotUser)
+	assert.Equal(t, "pa$$", gotPassword)
+
+	file.Filename = "missing_env.txt"
+	file.HTTP.Headers["Auth-Key"] = "${DNS_TOOLKIT_TEST_UNSET_KEY}"
+	_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	assert.ErrorContains(t, err, "DNS_TOOLKIT_TEST_UNSET_KEY")
+}
+
+func TestDefaultDownloader_HTTPOptionsMaxRetries(t *testing.T) {
+	logger := setupTestLogger()
+	testDir := t.TempDir()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "example.com")
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	file := c.DownloadFile{
+		URL:      server.URL,
+		Folder:   testDir,
+		Filename: "retries.txt",
+		HTTP:     &c.HTTPOptions{MaxRetries: 3},
+	}
+
+	_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestDefaultDownloader_InsecureSkipVerify(t *testing.T) {
+	logger := setupTestLogger()
+	testDir := t.TempDir()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, "example.com")
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	file := c.DownloadFile{
+		Name:     "tls",
+		URL:      server.URL,
+		Folder:   testDir,
+		Filename: "tls.txt",
+	}
+
+	_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	var certErr *CertVerificationError
+	assert.ErrorAs(t, err, &certErr)
+
+	file.HTTP = &c.HTTPOptions{InsecureSkipVerify: true}
+	_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	assert.NoError(t, err)
+}
+
+func TestDefaultDownloader_Mirrors(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	newServer := func(status int, body string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(status)
+			_, _ = fmt.Fprint(w, body)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	rateLimited := newServer(http.StatusTooManyRequests, "")
+	unauthorized := newServer(http.StatusUnauthorized, "")
+	notFound := newServer(http.StatusNotFound, "")
+	mirror := newServer(http.StatusOK, "example.com\n")
+	divergent := newServer(http.StatusOK, "example.org\n")
+
+	t.Run("falls back to the first working mirror", func(t *testing.T) {
+		d := newTestDownloader(1)
+		file := c.DownloadFile{
+			Name:     "mirrored",
+			URL:      rateLimited.URL,
+			Mirrors:  []string{notFound.URL, mirror.URL, divergent.URL},
+			Folder:   t.TempDir(),
+			Filename: "mirrored.txt",
+			ETag:     `"primary"`,
+		}
+
+		filePath, fetchSkipped, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		require.NoError(t, err)
+		assert.False(t, fetchSkipped)
+		assert.Equal(t, mirror.URL, file.ServedURL)
+		assert.Empty(t, file.ETag, "validators of a mirror should not be kept for the url")
+		assert.Nil(t, file.MirrorChecksums)
+		content, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "example.com\n", string(content))
+	})
+
+	t.Run("does not fall back on a non-retryable error", func(t *testing.T) {
+		d := newTestDownloader(1)
+		file := c.DownloadFile{
+			Name:     "unauthorized",
+			URL:      unauthorized.URL,
+			Mirrors:  []string{mirror.URL},
+			Folder:   t.TempDir(),
+			Filename: "unauthorized.txt",
+		}
 
-	clientWithSkip := d.createHTTPClient(logger, true, nil, parsedURL)
-	assert.NotNil(t, clientWithSkip, "Should create a client with TLS verification skipped")
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		var httpErr *HTTPStatusError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+		assert.Empty(t, file.ServedURL)
+	})
+
+	t.Run("returns the url error when all mirrors fail", func(t *testing.T) {
+		d := newTestDownloader(1)
+		file := c.DownloadFile{
+			Name:     "failing",
+			URL:      rateLimited.URL,
+			Mirrors:  []string{notFound.URL},
+			Folder:   t.TempDir(),
+			Filename: "failing.txt",
+		}
+
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		var httpErr *HTTPStatusError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
+	})
 
-	clientWithHosts := d.createHTTPClient(logger, false, []string{"example.com"}, parsedURL)
-	assert.NotNil(t, clientWithHosts, "Should create a client with skipCertHosts")
+	t.Run("verifies checksums across mirrors", func(t *testing.T) {
+		d := newTestDownloader(1)
+		file := c.DownloadFile{
+			Name:          "verified",
+			URL:           mirror.URL,
+			Mirrors:       []string{notFound.URL, divergent.URL},
+			Folder:        t.TempDir(),
+			Filename:      "verified.txt",
+			VerifyMirrors: true,
+		}
+
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		require.NoError(t, err)
+		assert.Equal(t, mirror.URL, file.ServedURL)
+		require.Len(t, file.MirrorChecksums, 2, "only the urls that responded should be recorded")
+		assert.NotEqual(t, file.MirrorChecksums[mirror.URL], file.MirrorChecksums[divergent.URL])
+		assert.False(t, file.MirrorsConsistent())
+
+		file.Mirrors = []string{mirror.URL + "/same"}
+		file.MirrorChecksums = nil
+		_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		require.NoError(t, err)
+		assert.Len(t, file.MirrorChecksums, 2)
+		assert.True(t, file.MirrorsConsistent())
+	})
+}
+
+func TestDefaultDownloader_MirrorCredentials(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	var mu sync.Mutex
+	authorizations := make(map[string]string)
+	newServer := func() *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			authorizations[r.Host+r.URL.Path] = r.Header.Get("Authorization") + "|" + r.Header.Get("X-Api-Key")
+			mu.Unlock()
+			if r.URL.Path == "/primary" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = fmt.Fprint(w, "example.com\n")
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	primary := newServer()
+	otherHost := newServer()
+	primaryHost := strings.TrimPrefix(primary.URL, "http://")
+	mirrorHost := strings.TrimPrefix(otherHost.URL, "http://")
+
+	d := newTestDownloader(1)
+	file := c.DownloadFile{
+		Name:          "authenticated",
+		URL:           primary.URL + "/primary",
+		Mirrors:       []string{primary.URL + "/mirror", otherHost.URL + "/mirror"},
+		Folder:        t.TempDir(),
+		Filename:      "authenticated.txt",
+		VerifyMirrors: true,
+		HTTP: &c.HTTPOptions{
+			Headers:   map[string]string{"X-Api-Key": "secret"},
+			BasicAuth: &c.BasicAuth{Username: "user", Password: "pass"},
+		},
+	}
+
+	_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, primary.URL+"/mirror", file.ServedURL)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, authorizations[primaryHost+"/primary"], "Basic ")
+	assert.Contains(t, authorizations[primaryHost+"/mirror"], "|secret", "a mirror of the same origin gets the credentials")
+	assert.Equal(t, "|", authorizations[mirrorHost+"/mirror"], "a mirror of another origin gets no credentials")
+	assert.Equal(t, "secret", file.HTTP.Headers["X-Api-Key"], "the options of the source are kept")
+}
+
+func TestSameOrigin(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, sameOrigin("https://lists.example.com/a.txt", "https://LISTS.example.com/b.txt"))
+	assert.False(t, sameOrigin("https://lists.example.com/a.txt", "https://mirror.example.net/a.txt"))
+	assert.False(t, sameOrigin("https://lists.example.com/a.txt", "http://lists.example.com/a.txt"))
+	assert.False(t, sameOrigin("https://lists.example.com/a.txt", "https://lists.example.com:8443/a.txt"))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rate limited", err: &HTTPStatusError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "not found", err: &HTTPStatusError{StatusCode: http.StatusNotFound}, want: true},
+		{name: "server error", err: &HTTPStatusError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "unauthorized", err: &HTTPStatusError{StatusCode: http.StatusUnauthorized}, want: false},
+		{name: "network", err: &url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("refused")}, want: true},
+		{name: "certificate", err: &CertVerificationError{Host: "example.com"}, want: true},
+		{name: "host unavailable", err: &c.HostUnavailableError{Host: "example.com"}, want: true},
+		{name: "cancelled", err: context.Canceled, want: false},
+		{name: "other", err: errors.New("invalid header"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryableError(tt.err))
+		})
+	}
+}
+
+func TestDefaultDownloader_RetryAfter(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "example.com")
+	}))
+	defer server.Close()
+
+	// The retry delay is far below the Retry-After, which should take precedence
+	d := newTestDownloader(2)
+	hosts := c.NewHostLimiter(nil, 0)
+	file := c.DownloadFile{
+		URL:      server.URL,
+		Folder:   t.TempDir(),
+		Filename: "retry_after.txt",
+		Hosts:    hosts,
+	}
 
-	clientWithBoth := d.createHTTPClient(logger, true, []string{"example.com"}, parsedURL)
-	assert.NotNil(t, clientWithBoth, "Should create a client with both skip options")
+	start := time.Now()
+	_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), attempts)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestDefaultDownloader_RetryAfterTooLong(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(3)
+	file := c.DownloadFile{URL: server.URL, Folder: t.TempDir(), Filename: "retry_after_long.txt"}
+
+	_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+	var httpErr *HTTPStatusError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
+	assert.Equal(t, int32(1), attempts, "should give up instead of waiting a day")
+}
+
+func TestDefaultDownloader_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	hosts := c.NewHostLimiter(nil, 2)
+	for i := 0; i < 4; i++ {
+		file := c.DownloadFile{
+			URL:      fmt.Sprintf("%s/list%d.txt", server.URL, i),
+			Folder:   t.TempDir(),
+			Filename: "list.txt",
+			Hosts:    hosts,
+		}
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		require.Error(t, err)
+		if i >= 2 {
+			var hostErr *c.HostUnavailableError
+			assert.ErrorAs(t, err, &hostErr)
+		}
+	}
+	assert.Equal(t, int32(2), requests, "the host should not be requested once its circuit is open")
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	newResponse := func(value string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		if value != "" {
+			resp.Header.Set("Retry-After", value)
+		}
+		return resp
+	}
+
+	wait, ok := retryAfter(newResponse("120"))
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+
+	wait, ok = retryAfter(newResponse(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour.Seconds(), wait.Seconds(), 2)
+
+	wait, ok = retryAfter(newResponse(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)))
+	assert.True(t, ok)
+	assert.Zero(t, wait)
+
+	_, ok = retryAfter(newResponse(""))
+	assert.False(t, ok)
+	_, ok = retryAfter(newResponse("soon"))
+	assert.False(t, ok)
+}
+
+func TestIsHostFailure(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isHostFailure(&HTTPStatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, isHostFailure(&HTTPStatusError{StatusCode: http.StatusServiceUnavailable}))
+	assert.True(t, isHostFailure(&url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("refused")}))
+	assert.False(t, isHostFailure(&HTTPStatusError{StatusCode: http.StatusNotFound}))
+	assert.False(t, isHostFailure(&c.HostUnavailableError{Host: "example.com"}))
+	assert.False(t, isHostFailure(context.Canceled))
+}
+
+func TestDefaultDownloader_CompressedAndPatterns(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	var gzBuf bytes.Buffer
+	gzWriter := gzip.NewWriter(&gzBuf)
+	_, _ = gzWriter.Write([]byte("example.com\n"))
+	require.NoError(t, gzWriter.Close())
+
+	var tarBuf bytes.Buffer
+	tarGzWriter := gzip.NewWriter(&tarBuf)
+	tarWriter := tar.NewWriter(tarGzWriter)
+	for name, content := range map[string]string{
+		"feed/domains-20261016.txt": "a.example\n",
+		"feed/domains-20261017.txt": "b.example\n",
+	} {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, tarGzWriter.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/domains.txt.gz":
+			_, _ = w.Write(gzBuf.Bytes())
+		case "/feed.tar.gz":
+			_, _ = w.Write(tarBuf.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+
+	t.Run("single compressed file", func(t *testing.T) {
+		downloadDir := t.TempDir()
+		source := config.Source{Name: "gz", URL: server.URL + "/domains.txt.gz"}
+		file, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+
+		_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(downloadDir, "gz.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "example.com\n", string(content))
+	})
+
+	t.Run("concatenated pattern", func(t *testing.T) {
+		downloadDir := t.TempDir()
+		// Left over from an earlier download, it should not be picked up by the pattern
+		require.NoError(t, os.MkdirAll(filepath.Join(downloadDir, "feed"), 0755))
+		stale := filepath.Join(downloadDir, "feed", "domains-20261015.txt")
+		require.NoError(t, os.WriteFile(stale, []byte("stale.example\n"), 0644))
+
+		source := config.Source{
+			Name:             "feed",
+			URL:              server.URL + "/feed.tar.gz",
+			Files:            []string{"feed/domains-*.txt"},
+			ConcatenateFiles: true,
+		}
+		file, err := source.GetDownloadFile(logger, downloadDir)
+		require.NoError(t, err)
+
+		_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(downloadDir, "feed.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "a.example\n\nb.example\n", string(content))
+	})
 }
 
 func TestDefaultDownloader_Download_HTTPError(t *testing.T) {
@@ -440,7 +1103,7 @@ func TestDefaultDownloader_Download_HTTPError(t *testing.T) {
 		Filename: "error.txt",
 	}
 
-	_, _, err = d.Download(logger, file, false, nil, config.ApplicationConfig{})
+	_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.Error(t, err)
 	var httpErr *HTTPStatusError
 	assert.ErrorAs(t, err, &httpErr)
@@ -470,7 +1133,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "malformed_url.txt",
 		}
 
-		_, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.Error(t, err, "Download with malformed URL should fail")
 	})
 
@@ -483,7 +1146,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "invalid_url.txt",
 		}
 
-		_, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.Error(t, err, "Download with invalid URL should fail")
 	})
 
@@ -496,7 +1159,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "non_existent.txt",
 		}
 
-		_, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.Error(t, err, "Download with non-existent local file should fail")
 	})
 
@@ -523,7 +1186,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "destination.txt",
 		}
 
-		_, _, err = d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.Error(t, err, "Download to non-existent folder should fail")
 	})
 	t.Run("HeadRequestError", func(t *testing.T) {
@@ -541,7 +1204,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "error_response.txt",
 		}
 
-		_, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.Error(t, err, "Download should fail with HTTP error")
 
 		var httpErr *HTTPStatusError
@@ -571,7 +1234,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "redirect.txt",
 		}
 
-		filePath, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		filePath, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.NoError(t, err)
 
 		content, err := os.ReadFile(filePath)
@@ -593,7 +1256,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "redirect_loop.txt",
 		}
 
-		_, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "redirect loop detected")
 	})
@@ -623,7 +1286,7 @@ func TestDownloadEdgeCases_Merged(t *testing.T) {
 			Filename: "large_file.bin",
 		}
 
-		filePath, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		filePath, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.NoError(t, err)
 
 		fileInfo, err := os.Stat(filePath)
@@ -666,7 +1329,7 @@ func TestDownloaderConcurrency_Merged(t *testing.T) {
 				Filename: fmt.Sprintf("concurrent_%d.txt", index),
 			}
 
-			_, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+			_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 			results <- err
 		}(i)
 	}
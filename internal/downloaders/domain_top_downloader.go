@@ -2,6 +2,7 @@ package downloaders
 
 import (
 	"bufio"
+	"context"
 	"os"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
@@ -23,13 +24,12 @@ func NewDomainTopDownloaderWithRetries(maxRetries int) Downloader {
 }
 
 func (d *DomainTopDownloader) Download(
+	ctx context.Context,
 	logger *multilog.Logger,
-	file c.DownloadFile,
-	skipCertVerify bool,
-	skipCertHosts []string,
+	file *c.DownloadFile,
 	applicationConfig cfg.ApplicationConfig,
 ) (string, bool, error) {
-	return d.DefaultDownloader.Download(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+	return d.DefaultDownloader.Download(ctx, logger, file, applicationConfig)
 }
 
 func (d *DomainTopDownloader) PostDownloadProcess(logger *multilog.Logger, filePath string, count int) error {
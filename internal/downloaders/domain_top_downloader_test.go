@@ -1,6 +1,7 @@
 package downloaders
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -75,7 +76,7 @@ func TestDomainTopDownloader_Download(t *testing.T) {
 		Filename: "tranco_top.csv",
 	}
 
-	filePath, fetchSkipped, err := downloader.Download(logger, file, false, nil, config.ApplicationConfig{})
+	filePath, fetchSkipped, err := downloader.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 
 	assert.NoError(t, err, "Download should succeed")
 	assert.False(t, fetchSkipped, "Fetch should not be skipped for new download")
@@ -381,7 +382,7 @@ func TestDomainTopDownloader_Integration(t *testing.T) {
 	}
 
 	// Step 1: Download
-	filePath, fetchSkipped, err := downloader.Download(logger, file, false, nil, config.ApplicationConfig{})
+	filePath, fetchSkipped, err := downloader.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.NoError(t, err, "Download should succeed")
 	assert.False(t, fetchSkipped, "Fetch should not be skipped")
 
@@ -441,7 +442,7 @@ func TestDomainTopDownloader_Download_HTTPError(t *testing.T) {
 		Filename: "error_test.csv",
 	}
 
-	_, _, err = downloader.Download(logger, file, false, nil, config.ApplicationConfig{})
+	_, _, err = downloader.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.Error(t, err, "Download should fail with HTTP error")
 
 	var httpErr *HTTPStatusError
@@ -1,18 +1,22 @@
 package downloaders
 
 import (
+	"context"
+
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	cfg "github.com/phani-kb/dns-toolkit/internal/config"
 	"github.com/phani-kb/multilog"
 )
 
 type Downloader interface {
-	// Download is a method to implement the logic to download the file
+	// Download is a method to implement the logic to download the file.
+	// Implementations should abort in-flight requests and retries once ctx is cancelled.
+	// The ETag and LastModified validators of file are used for a conditional request and
+	// are updated with the values returned by the server.
 	Download(
+		ctx context.Context,
 		logger *multilog.Logger,
-		file c.DownloadFile,
-		skipCertVerify bool,
-		skipCertHosts []string,
+		file *c.DownloadFile,
 		applicationConfig cfg.ApplicationConfig,
 	) (string, bool, error)
 
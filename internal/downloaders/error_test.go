@@ -42,18 +42,6 @@ func TestDefaultDownloaderNameFunction(t *testing.T) {
 	assert.Equal(t, "default", name)
 }
 
-func TestContainsHost(t *testing.T) {
-	t.Parallel()
-
-	hosts := []string{"example.com", "test.org", "localhost"}
-
-	assert.True(t, containsHost(hosts, "example.com"))
-	assert.True(t, containsHost(hosts, "test.org"))
-	assert.False(t, containsHost(hosts, "notfound.com"))
-	assert.False(t, containsHost(nil, "example.com"))
-	assert.False(t, containsHost([]string{}, "example.com"))
-}
-
 func TestNewDefaultDownloaderForTesting(t *testing.T) {
 	t.Parallel()
 
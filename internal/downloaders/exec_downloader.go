@@ -0,0 +1,209 @@
+package downloaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	cfg "github.com/phani-kb/dns-toolkit/internal/config"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+)
+
+const (
+	execDownloaderName = constants.DownloaderExec
+	execWaitDelay      = 5 * time.Second // grace period for output held open by children of a killed command
+	maxExecStderr      = 1024            // bytes of stderr kept for the error
+)
+
+// ExecError is returned when the command of the exec downloader fails, times out or prints nothing.
+// The previous copy of the file is kept.
+type ExecError struct {
+	Command  string
+	Reason   string
+	Stderr   string
+	ExitCode int
+}
+
+func (e *ExecError) Error() string {
+	msg := fmt.Sprintf("Command %s failed: %s", e.Command, e.Reason)
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+// ExecDownloader fetches a source by running the command of its exec options,
+// for sources behind authenticated portals or internal tooling.
+type ExecDownloader struct {
+	DefaultDownloader
+}
+
+// NewExecDownloaderWithRetries creates a new ExecDownloader, retrying the requests for published checksums
+// and signatures. The command itself is run once per download.
+func NewExecDownloaderWithRetries(maxRetries int) Downloader {
+	return &ExecDownloader{
+		DefaultDownloader: *NewDefaultDownloaderWithRetries(maxRetries),
+	}
+}
+
+func (d *ExecDownloader) Name() string {
+	return execDownloaderName
+}
+
+// Download runs the command with the source name, url and target path in its environment.
+// Its stdout is the downloaded file, or, if it prints nothing, the file it wrote to the target path.
+// The result is verified like an HTTP download before it replaces the previous copy.
+func (d *ExecDownloader) Download(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file *c.DownloadFile,
+	applicationConfig cfg.ApplicationConfig,
+) (string, bool, error) {
+	if file.Exec == nil {
+		return "", false, fmt.Errorf("exec options are required for %s", file.Name)
+	}
+	filePath := filepath.Join(file.Folder, file.Filename)
+	fileExists := false
+	if _, err := os.Stat(filePath); err == nil {
+		fileExists = true
+	}
+	summaryFile := filepath.Join(constants.SummaryDir, constants.DefaultSummaryFiles["download"])
+	if fileExists && !d.ShouldDownload(logger, summaryFile, *file) {
+		archiveErr := d.handleArchiveFile(logger, *file, filePath)
+		return filePath, true, archiveErr
+	}
+
+	if err := os.MkdirAll(file.Folder, 0755); err != nil {
+		return "", false, err
+	}
+	workDir, err := os.MkdirTemp(file.Folder, ".exec-"+file.Name+"-")
+	if err != nil {
+		return "", false, err
+	}
+	defer func() {
+		if rmErr := os.RemoveAll(workDir); rmErr != nil {
+			logger.Warnf("Removing exec folder %s error: %v", workDir, rmErr)
+		}
+	}()
+
+	outputPath, err := d.runCommand(ctx, logger, *file, workDir)
+	if err != nil {
+		return "", false, err
+	}
+	output, err := os.Open(outputPath)
+	if err != nil {
+		return "", false, err
+	}
+	defer u.CloseFile(logger, output)
+
+	verify := d.verifyDownload(ctx, logger, *file, applicationConfig)
+	if _, err = u.SaveVerifiedFile(logger, file.Folder, file.Filename, output, verify); err != nil {
+		logKeptCopy(logger, err, filePath, fileExists)
+		return "", false, err
+	}
+	logger.Infof("Downloaded %s with %s", filePath, file.Exec.Command)
+	file.ServedURL = file.URL
+	file.ETag, file.LastModified = "", ""
+	err = d.extractArchive(logger, *file, filePath, true)
+	return filePath, false, err
+}
+
+// runCommand runs the command of file in workDir and returns the path of its output.
+func (d *ExecDownloader) runCommand(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file c.DownloadFile,
+	workDir string,
+) (string, error) {
+	options := file.Exec
+	args := make([]string, 0, len(options.Args))
+	for _, arg := range options.Args {
+		expanded, err := u.ExpandEnvPlaceholders(arg)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, expanded)
+	}
+	targetPath := filepath.Join(workDir, file.Filename)
+	env := append(os.Environ(),
+		"DNS_TOOLKIT_SOURCE="+file.Name,
+		"DNS_TOOLKIT_URL="+file.URL,
+		"DNS_TOOLKIT_TARGET="+targetPath,
+	)
+	for name, value := range options.Env {
+		expanded, err := u.ExpandEnvPlaceholders(value)
+		if err != nil {
+			return "", err
+		}
+		env = append(env, name+"="+expanded)
+	}
+
+	stdoutPath := filepath.Join(workDir, "stdout")
+	stdout, err := os.Create(stdoutPath)
+	if err != nil {
+		return "", err
+	}
+	defer u.CloseFile(logger, stdout)
+
+	timeout := time.Duration(options.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = constants.DefaultExecTimeoutInSeconds * time.Second
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stderr strings.Builder
+	cmd := exec.CommandContext(cmdCtx, options.Command, args...)
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = execWaitDelay
+
+	logger.Debugf("Running %s for %s", options.Command, file.Name)
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	stderrTail := strings.TrimSpace(stderr.String())
+	if len(stderrTail) > maxExecStderr {
+		stderrTail = stderrTail[len(stderrTail)-maxExecStderr:]
+	}
+	execErr := &ExecError{Command: options.Command, Stderr: stderrTail, ExitCode: -1}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(cmdCtx.Err(), context.DeadlineExceeded):
+		execErr.Reason = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(runErr, &exitErr):
+		execErr.ExitCode = exitErr.ExitCode()
+		execErr.Reason = fmt.Sprintf("exit code %d", execErr.ExitCode)
+	case runErr != nil:
+		execErr.Reason = runErr.Error()
+	}
+	if execErr.Reason != "" {
+		logger.Errorf("%v", execErr)
+		return "", execErr
+	}
+	if stderrTail != "" {
+		logger.Debugf("%s for %s wrote to stderr: %s", options.Command, file.Name, stderrTail)
+	}
+
+	if info, err := os.Stat(stdoutPath); err == nil && info.Size() > 0 {
+		return stdoutPath, nil
+	}
+	if info, err := os.Stat(targetPath); err == nil && info.Mode().IsRegular() {
+		return targetPath, nil
+	}
+	execErr.ExitCode = 0
+	execErr.Reason = "no output on stdout or at DNS_TOOLKIT_TARGET"
+	return "", execErr
+}
+
+func init() {}
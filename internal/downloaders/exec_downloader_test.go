@@ -0,0 +1,147 @@
+package downloaders
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecDownloader_Name(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "exec", NewExecDownloaderWithRetries(1).Name())
+}
+
+func TestExecDownloader_Download(t *testing.T) {
+	t.Setenv("EXEC_TEST_TOKEN", "secret")
+	logger := setupTestLogger()
+	d := NewExecDownloaderWithRetries(1)
+
+	tests := []struct {
+		name     string
+		script   string
+		env      map[string]string
+		timeout  int
+		previous string
+		guard    *c.AnomalyGuard
+		want     string
+		exitCode int
+		wantErr  bool
+	}{
+		{
+			name:   "stdout",
+			script: `echo "$DNS_TOOLKIT_SOURCE"; echo "$DNS_TOOLKIT_URL" | cut -d/ -f3; echo "$1"`,
+			want:   "exec-list\nportal.example\nsecret\n",
+		},
+		{
+			name:   "target file",
+			script: `printf 'a.example\nb.example\n' > "$DNS_TOOLKIT_TARGET"`,
+			want:   "a.example\nb.example\n",
+		},
+		{
+			name:   "env option",
+			script: `echo "$LIST_TOKEN"`,
+			env:    map[string]string{"LIST_TOKEN": "${EXEC_TEST_TOKEN}"},
+			want:   "secret\n",
+		},
+		{
+			name:     "exit code",
+			script:   `echo partial.example; echo "login failed" >&2; exit 3`,
+			previous: "old.example\n",
+			exitCode: 3,
+			wantErr:  true,
+		},
+		{name: "no output", script: `true`, exitCode: 0, wantErr: true},
+		{name: "timeout", script: `exec sleep 5`, timeout: 1, exitCode: -1, wantErr: true},
+		{
+			name:     "anomaly",
+			script:   `echo only.example`,
+			previous: domainList(200),
+			guard:    &c.AnomalyGuard{PreviousEntries: 200},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			file := &c.DownloadFile{
+				Name:     "exec-list",
+				URL:      "https://portal.example/lists/domains.txt",
+				Folder:   folder,
+				Filename: "list.txt",
+				Guard:    tt.guard,
+				Exec: &c.ExecOptions{
+					Command: "sh",
+					Args:    []string{"-c", tt.script, "sh", "${EXEC_TEST_TOKEN}"},
+					Env:     tt.env,
+					Timeout: tt.timeout,
+				},
+			}
+			filePath := filepath.Join(folder, file.Filename)
+			if tt.previous != "" {
+				require.NoError(t, os.WriteFile(filePath, []byte(tt.previous), 0644))
+			}
+
+			path, skipped, err := d.Download(context.Background(), logger, file, config.ApplicationConfig{})
+			entries, readErr := os.ReadDir(folder)
+			require.NoError(t, readErr)
+			assert.LessOrEqual(t, len(entries), 1, "the exec folder is removed")
+			if tt.wantErr {
+				require.Error(t, err)
+				var execErr *ExecError
+				if tt.guard == nil {
+					require.True(t, errors.As(err, &execErr))
+					assert.Equal(t, tt.exitCode, execErr.ExitCode)
+				} else {
+					var anomalyErr *AnomalyError
+					assert.True(t, errors.As(err, &anomalyErr))
+				}
+				if tt.previous != "" {
+					content, _ := os.ReadFile(filePath)
+					assert.Equal(t, tt.previous, string(content), "the previous copy is kept")
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.False(t, skipped)
+			assert.Equal(t, filePath, path)
+			assert.Equal(t, file.URL, file.ServedURL)
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func TestExecDownloader_DownloadErrors(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+	d := NewExecDownloaderWithRetries(1)
+
+	file := &c.DownloadFile{Name: "no-exec", URL: "https://portal.example/list.txt", Folder: t.TempDir()}
+	_, _, err := d.Download(context.Background(), logger, file, config.ApplicationConfig{})
+	assert.Error(t, err)
+
+	file.Exec = &c.ExecOptions{Command: "sh", Args: []string{"-c", "echo a.example", "${EXEC_TEST_UNSET_VAR}"}}
+	_, _, err = d.Download(context.Background(), logger, file, config.ApplicationConfig{})
+	assert.Error(t, err)
+
+	file.Exec = &c.ExecOptions{Command: filepath.Join(t.TempDir(), "missing-command")}
+	_, _, err = d.Download(context.Background(), logger, file, config.ApplicationConfig{})
+	var execErr *ExecError
+	require.True(t, errors.As(err, &execErr))
+	assert.Equal(t, -1, execErr.ExitCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	file.Exec = &c.ExecOptions{Command: "sh", Args: []string{"-c", "exec sleep 5"}}
+	_, _, err = d.Download(ctx, logger, file, config.ApplicationConfig{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,6 +1,7 @@
 package downloaders
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -226,7 +227,7 @@ func TestDownloadFile_ForceFlagSet(t *testing.T) {
 		t.Logf("Failed to remove test file: %v", err)
 	}
 
-	filePath, exists, err := d.Download(logger, file, true, nil, config.ApplicationConfig{})
+	filePath, exists, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.NoError(t, err)
 	assert.False(t, exists, "Should indicate file was downloaded")
 	assert.Equal(t, filepath.Join(testDir, "force_test.txt"), filePath)
@@ -259,7 +260,7 @@ func TestDownloadFile_ConnectionErrors(t *testing.T) {
 		Filename: "connection_error.txt",
 	}
 
-	_, _, err = d.Download(logger, file, false, nil, config.ApplicationConfig{})
+	_, _, err = d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 	assert.Error(t, err, "Should return error for connection failures")
 	assert.Contains(t, err.Error(), "connection", "Error should mention connection issue")
 }
@@ -296,7 +297,7 @@ func TestDownloadFile_EdgeCases(t *testing.T) {
 			Filename: "empty.txt",
 		}
 
-		filePath, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		filePath, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.NoError(t, err, "Should successfully download zero-size file")
 
 		info, err := os.Stat(filePath)
@@ -326,7 +327,7 @@ func TestDownloadFile_EdgeCases(t *testing.T) {
 			Filename: "file_in_empty_dir.txt",
 		}
 
-		filePath, _, err := d.Download(logger, file, false, nil, config.ApplicationConfig{})
+		filePath, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
 		assert.NoError(t, err, "Should download to empty directory")
 
 		content, err := os.ReadFile(filePath)
@@ -0,0 +1,184 @@
+package downloaders
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	cfg "github.com/phani-kb/dns-toolkit/internal/config"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+	"golang.org/x/crypto/blake2b"
+)
+
+// bsdChecksumLine matches a line of a checksum file in BSD format, e.g. "SHA256 (list.txt) = <hex>".
+var bsdChecksumLine = regexp.MustCompile(`^(\w+) ?\((.+)\) ?= ?([0-9a-fA-F]+)$`)
+
+// verifyIntegrity checks the staged download of file against the checksum and signature of its integrity options.
+func (d *DefaultDownloader) verifyIntegrity(
+	ctx context.Context,
+	logger *multilog.Logger,
+	file c.DownloadFile,
+	stagedPath string,
+	applicationConfig cfg.ApplicationConfig,
+) error {
+	content, err := os.ReadFile(stagedPath)
+	if err != nil {
+		return err
+	}
+	integrity := file.Integrity
+	fail := func(format string, args ...any) error {
+		return &IntegrityError{URL: file.URL, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if integrity.Checksum != "" || integrity.ChecksumURL != "" {
+		expected := integrity.Checksum
+		if integrity.ChecksumURL != "" {
+			published, err := d.fetchContent(ctx, logger, file, integrity.ChecksumURL, applicationConfig)
+			if err != nil {
+				return fail("fetching checksum file %s: %v", integrity.ChecksumURL, err)
+			}
+			expected, err = parseChecksumFile(string(published), urlFileName(file.URL), file.Filename)
+			if err != nil {
+				return fail("%v in %s", err, integrity.ChecksumURL)
+			}
+		}
+		algorithm, digest, err := c.ParseChecksum(expected)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if actual := u.CalculateChecksumFromContent(content, algorithm); actual != digest {
+			return fail("%s checksum %s does not match the expected %s", algorithm, actual, digest)
+		}
+		logger.Debugf("Verified %s checksum of %s", algorithm, file.Name)
+	}
+
+	if integrity.SignatureURL != "" {
+		signature, err := d.fetchContent(ctx, logger, file, integrity.SignatureURL, applicationConfig)
+		if err != nil {
+			return fail("fetching signature %s: %v", integrity.SignatureURL, err)
+		}
+		if err := verifySignature(content, signature, integrity.PublicKey); err != nil {
+			return fail("%v", err)
+		}
+		logger.Debugf("Verified signature of %s", file.Name)
+	}
+	return nil
+}
+
+// urlFileName returns the last path element of rawURL, or an empty string if it has none.
+func urlFileName(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Path == "" {
+		return ""
+	}
+	return path.Base(parsedURL.Path)
+}
+
+// parseChecksumFile returns the checksum listed for one of names in a published checksum file.
+// Lines are either in coreutils format, "<hex>  <name>", or in BSD format, "SHA256 (<name>) = <hex>".
+// A file with a single checksum is used as is, whatever name it lists.
+func parseChecksumFile(content string, names ...string) (string, error) {
+	var checksums []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var checksum, name string
+		if match := bsdChecksumLine.FindStringSubmatch(line); match != nil {
+			checksum, name = strings.ToLower(match[1])+":"+match[3], match[2]
+		} else {
+			fields := strings.Fields(line)
+			checksum = fields[0]
+			if len(fields) > 1 {
+				name = strings.TrimPrefix(strings.Join(fields[1:], " "), "*") // * marks binary mode
+			}
+		}
+		if name != "" && slices.Contains(names, path.Base(name)) {
+			return checksum, nil
+		}
+		checksums = append(checksums, checksum)
+	}
+	if len(checksums) == 1 {
+		return checksums[0], nil
+	}
+	return "", fmt.Errorf("no checksum found for %s", strings.Join(names, ", "))
+}
+
+// verifySignature checks a detached signature of content against publicKey.
+// The signature is either a raw or base64 ed25519 signature, or a minisign signature file.
+func verifySignature(content, signature []byte, publicKey string) error {
+	key, keyID, err := c.ParsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	text := strings.TrimSpace(string(signature))
+	if strings.HasPrefix(text, "untrusted comment:") {
+		return verifyMinisign(content, text, key, keyID)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		signature, err = base64.StdEncoding.DecodeString(text)
+		if err != nil || len(signature) != ed25519.SignatureSize {
+			return fmt.Errorf("invalid ed25519 signature")
+		}
+	}
+	if !ed25519.Verify(key, content, signature) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
+
+// verifyMinisign checks a minisign signature file, both the signature of content and the one of its trusted comment.
+// Legacy signatures (Ed) sign the content itself, prehashed ones (ED) its BLAKE2b-512 hash.
+func verifyMinisign(content []byte, text string, key ed25519.PublicKey, keyID []byte) error {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	if len(lines) < 4 {
+		return fmt.Errorf("invalid minisign signature: expected 4 lines, got %d", len(lines))
+	}
+	decoded, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(decoded) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature")
+	}
+	algorithm, signatureKeyID, signature := string(decoded[:2]), decoded[2:10], decoded[10:]
+	if keyID != nil && !bytes.Equal(signatureKeyID, keyID) {
+		return fmt.Errorf("signature was made with another key")
+	}
+	message := content
+	switch algorithm {
+	case "Ed":
+	case "ED":
+		hash := blake2b.Sum512(content)
+		message = hash[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm: %s", algorithm)
+	}
+	if !ed25519.Verify(key, message, signature) {
+		return fmt.Errorf("signature does not match")
+	}
+
+	trustedComment, found := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !found {
+		return fmt.Errorf("invalid minisign signature: missing trusted comment")
+	}
+	globalSignature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSignature) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature: invalid trusted comment signature")
+	}
+	if !ed25519.Verify(key, slices.Concat(signature, []byte(trustedComment)), globalSignature) {
+		return fmt.Errorf("trusted comment signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package downloaders
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+var testKeyID = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+// minisignPublicKey encodes public as a minisign public key with testKeyID.
+func minisignPublicKey(public ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(slices.Concat([]byte("Ed"), testKeyID, public))
+}
+
+// minisignSignature signs content like minisign does, prehashed unless legacy is set.
+func minisignSignature(private ed25519.PrivateKey, content []byte, legacy bool) string {
+	algorithm, message := "ED", content
+	if legacy {
+		algorithm = "Ed"
+	} else {
+		hash := blake2b.Sum512(content)
+		message = hash[:]
+	}
+	signature := ed25519.Sign(private, message)
+	comment := "timestamp:1760659200\tfile:list.txt"
+	global := ed25519.Sign(private, slices.Concat(signature, []byte(comment)))
+	return "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(slices.Concat([]byte(algorithm), testKeyID, signature)) + "\n" +
+		"trusted comment: " + comment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n"
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "bare", content: "abc123\n", expected: "abc123"},
+		{name: "single other name", content: "abc123  other.txt\n", expected: "abc123"},
+		{name: "coreutils", content: "abc123  other.txt\ndef456 *list.txt\n", expected: "def456"},
+		{name: "with path", content: "abc123  other.txt\ndef456  dist/list.txt\n", expected: "def456"},
+		{name: "bsd", content: "SHA256 (other.txt) = abc123\nSHA256 (list.txt) = DEF456\n", expected: "sha256:DEF456"},
+		{name: "comments", content: "# checksums\n\nabc123  list.txt\n", expected: "abc123"},
+		{name: "not listed", content: "abc123  a.txt\ndef456  b.txt\n", wantErr: true},
+		{name: "empty", content: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksum, err := parseChecksumFile(tt.content, "list.txt")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, checksum)
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	t.Parallel()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPublic, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	content := []byte("example.com\n")
+	rawKey := base64.StdEncoding.EncodeToString(public)
+	rawSignature := ed25519.Sign(private, content)
+
+	tests := []struct {
+		name      string
+		signature string
+		publicKey string
+		content   string
+		wantErr   bool
+	}{
+		{name: "raw", signature: string(rawSignature), publicKey: rawKey},
+		{name: "base64", signature: base64.StdEncoding.EncodeToString(rawSignature) + "\n", publicKey: rawKey},
+		{name: "minisign", signature: minisignSignature(private, content, false), publicKey: minisignPublicKey(public)},
+		{
+			name:      "minisign key file",
+			signature: minisignSignature(private, content, false),
+			publicKey: "untrusted comment: minisign public key 0807060504030201\n" + minisignPublicKey(public) + "\n",
+		},
+		{name: "minisign legacy", signature: minisignSignature(private, content, true), publicKey: minisignPublicKey(public)},
+		{name: "minisign raw key", signature: minisignSignature(private, content, false), publicKey: rawKey},
+		{
+			name:      "tampered content",
+			signature: minisignSignature(private, content, false),
+			publicKey: minisignPublicKey(public),
+			content:   "evil.example\n",
+			wantErr:   true,
+		},
+		{
+			name:      "other key",
+			signature: string(rawSignature),
+			publicKey: base64.StdEncoding.EncodeToString(otherPublic),
+			wantErr:   true,
+		},
+		{
+			name:      "other key id",
+			signature: minisignSignature(private, content, false),
+			publicKey: base64.StdEncoding.EncodeToString(slices.Concat([]byte("Ed"), make([]byte, 8), public)),
+			wantErr:   true,
+		},
+		{name: "invalid signature", signature: "not a signature", publicKey: rawKey, wantErr: true},
+		{name: "truncated minisign", signature: "untrusted comment: x\nabc\n", publicKey: rawKey, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := content
+			if tt.content != "" {
+				payload = []byte(tt.content)
+			}
+			err := verifySignature(payload, []byte(tt.signature), tt.publicKey)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("tampered trusted comment", func(t *testing.T) {
+		signature := minisignSignature(private, content, false)
+		tampered := strings.Replace(signature, "timestamp:", "timestamq:", 1)
+		assert.Error(t, verifySignature(content, []byte(tampered), minisignPublicKey(public)))
+	})
+}
+
+func TestDefaultDownloader_Integrity(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	served := []byte("a.example\nb.example\n")
+	sum := sha256.Sum256(served)
+	checksum := hex.EncodeToString(sum[:])
+	wrongChecksum := "sha256:" + hex.EncodeToString(make([]byte, sha256.Size))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/list.txt":
+			_, _ = w.Write(served)
+		case "/list.txt.sha256":
+			_, _ = w.Write([]byte(checksum + "  list.txt\n"))
+		case "/list.txt.minisig":
+			_, _ = w.Write([]byte(minisignSignature(private, served, false)))
+		case "/other.txt.minisig":
+			_, _ = w.Write([]byte(minisignSignature(private, []byte("other\n"), false)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := newTestDownloader(1)
+	previous := "previous.example\n"
+
+	tests := []struct {
+		integrity c.IntegrityOptions
+		name      string
+		wantErr   bool
+	}{
+		{name: "pinned checksum", integrity: c.IntegrityOptions{Checksum: "sha256:" + checksum}},
+		{name: "pinned checksum mismatch", integrity: c.IntegrityOptions{Checksum: wrongChecksum}, wantErr: true},
+		{name: "published checksum", integrity: c.IntegrityOptions{ChecksumURL: server.URL + "/list.txt.sha256"}},
+		{
+			name:      "missing checksum file",
+			integrity: c.IntegrityOptions{ChecksumURL: server.URL + "/missing.sha256"},
+			wantErr:   true,
+		},
+		{
+			name: "signature",
+			integrity: c.IntegrityOptions{
+				Checksum:     checksum,
+				SignatureURL: server.URL + "/list.txt.minisig",
+				PublicKey:    minisignPublicKey(public),
+			},
+		},
+		{
+			name: "signature of other content",
+			integrity: c.IntegrityOptions{
+				SignatureURL: server.URL + "/other.txt.minisig",
+				PublicKey:    minisignPublicKey(public),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			downloadDir := t.TempDir()
+			filePath := filepath.Join(downloadDir, "list.txt")
+			require.NoError(t, os.WriteFile(filePath, []byte(previous), 0644))
+			file := c.DownloadFile{
+				Name:      "list",
+				URL:       server.URL + "/list.txt",
+				Folder:    downloadDir,
+				Filename:  "list.txt",
+				Integrity: &tt.integrity,
+			}
+
+			_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+			content, readErr := os.ReadFile(filePath)
+			require.NoError(t, readErr)
+			entries, readErr := os.ReadDir(downloadDir)
+			require.NoError(t, readErr)
+			assert.Len(t, entries, 1, "no staged file should be left behind")
+			if tt.wantErr {
+				var integrityErr *IntegrityError
+				require.True(t, errors.As(err, &integrityErr), "expected an IntegrityError, got %v", err)
+				assert.Equal(t, file.URL, integrityErr.URL)
+				assert.Equal(t, previous, string(content), "the previous copy should be kept")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, string(served), string(content))
+		})
+	}
+
+	t.Run("mismatch on every mirror", func(t *testing.T) {
+		downloadDir := t.TempDir()
+		file := c.DownloadFile{
+			Name:      "list",
+			URL:       server.URL + "/list.txt",
+			Mirrors:   []string{server.URL + "/list.txt?mirror=1"},
+			Folder:    downloadDir,
+			Filename:  "list.txt",
+			Integrity: &c.IntegrityOptions{Checksum: wrongChecksum},
+		}
+		_, _, err := d.Download(context.Background(), logger, &file, config.ApplicationConfig{})
+		var integrityErr *IntegrityError
+		assert.True(t, errors.As(err, &integrityErr))
+		_, statErr := os.Stat(filepath.Join(downloadDir, "list.txt"))
+		assert.True(t, os.IsNotExist(statErr))
+	})
+}
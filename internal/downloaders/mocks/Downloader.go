@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	common "github.com/phani-kb/dns-toolkit/internal/common"
 	config "github.com/phani-kb/dns-toolkit/internal/config"
 
@@ -16,9 +18,9 @@ type Downloader struct {
 	mock.Mock
 }
 
-// Download provides a mock function with given fields: logger, file, skipCertVerify, skipCertHosts, applicationConfig
-func (_m *Downloader) Download(logger *multilog.Logger, file common.DownloadFile, skipCertVerify bool, skipCertHosts []string, applicationConfig config.ApplicationConfig) (string, bool, error) {
-	ret := _m.Called(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+// Download provides a mock function with given fields: ctx, logger, file, applicationConfig
+func (_m *Downloader) Download(ctx context.Context, logger *multilog.Logger, file *common.DownloadFile, applicationConfig config.ApplicationConfig) (string, bool, error) {
+	ret := _m.Called(ctx, logger, file, applicationConfig)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Download")
@@ -27,23 +29,23 @@ func (_m *Downloader) Download(logger *multilog.Logger, file common.DownloadFile
 	var r0 string
 	var r1 bool
 	var r2 error
-	if rf, ok := ret.Get(0).(func(*multilog.Logger, common.DownloadFile, bool, []string, config.ApplicationConfig) (string, bool, error)); ok {
-		return rf(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+	if rf, ok := ret.Get(0).(func(context.Context, *multilog.Logger, *common.DownloadFile, config.ApplicationConfig) (string, bool, error)); ok {
+		return rf(ctx, logger, file, applicationConfig)
 	}
-	if rf, ok := ret.Get(0).(func(*multilog.Logger, common.DownloadFile, bool, []string, config.ApplicationConfig) string); ok {
-		r0 = rf(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+	if rf, ok := ret.Get(0).(func(context.Context, *multilog.Logger, *common.DownloadFile, config.ApplicationConfig) string); ok {
+		r0 = rf(ctx, logger, file, applicationConfig)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(*multilog.Logger, common.DownloadFile, bool, []string, config.ApplicationConfig) bool); ok {
-		r1 = rf(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+	if rf, ok := ret.Get(1).(func(context.Context, *multilog.Logger, *common.DownloadFile, config.ApplicationConfig) bool); ok {
+		r1 = rf(ctx, logger, file, applicationConfig)
 	} else {
 		r1 = ret.Get(1).(bool)
 	}
 
-	if rf, ok := ret.Get(2).(func(*multilog.Logger, common.DownloadFile, bool, []string, config.ApplicationConfig) error); ok {
-		r2 = rf(logger, file, skipCertVerify, skipCertHosts, applicationConfig)
+	if rf, ok := ret.Get(2).(func(context.Context, *multilog.Logger, *common.DownloadFile, config.ApplicationConfig) error); ok {
+		r2 = rf(ctx, logger, file, applicationConfig)
 	} else {
 		r2 = ret.Error(2)
 	}
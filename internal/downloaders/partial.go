@@ -0,0 +1,311 @@
+package downloaders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+	"github.com/phani-kb/multilog"
+)
+
+// partialState is stored next to the partial file of a resumable download,
+// so that a later attempt or run can continue it with a range request validated by the ETag.
+type partialState struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag"`
+}
+
+// partialBody receives the body of a download into the partial file of the download.
+type partialBody struct {
+	part      *os.File
+	path      string
+	etag      string
+	received  int64
+	total     int64 // -1 if unknown
+	resumable bool  // the server accepts range requests validated by a strong ETag
+}
+
+// partialPath returns the path of the partial file of file, its state is stored in the same path with .json appended.
+func partialPath(file c.DownloadFile) string {
+	return filepath.Join(file.Folder, "."+file.Filename+".part")
+}
+
+// loadPartial returns the size of the partial file left by an interrupted download of file and the ETag
+// to resume it with, or 0 if there is nothing to resume. A stale partial file is removed.
+func loadPartial(logger *multilog.Logger, file c.DownloadFile) (int64, string) {
+	path := partialPath(file)
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, ""
+	}
+	var state partialState
+	content, err := os.ReadFile(path + ".json")
+	if err == nil {
+		err = json.Unmarshal(content, &state)
+	}
+	if err != nil || state.URL != file.URL || state.ETag == "" || info.Size() == 0 {
+		logger.Debugf("Discarding partial download %s", path)
+		removePartial(logger, path)
+		return 0, ""
+	}
+	return info.Size(), state.ETag
+}
+
+// removePartial removes the partial file at path and its state.
+func removePartial(logger *multilog.Logger, path string) {
+	for _, name := range []string{path, path + ".json"} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("Removing partial download %s error: %v", name, err)
+		}
+	}
+}
+
+// setRangeHeaders requests the part of a file after the first offset bytes, if it still has the given ETag.
+func setRangeHeaders(req *http.Request, offset int64, etag string) {
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	req.Header.Set("If-Range", etag)
+}
+
+// receiveBody writes the body of resp to the partial file of file, continuing the offset bytes received
+// with etag before for a 206 response. When the connection drops and the server accepts ranges validated by
+// a strong ETag, the rest of the body is requested with a range request; attempts that receive data do not
+// count towards the retries. It returns the last response, whose body is closed, and the path of the complete
+// partial file. A partial file that can be resumed is kept on failure.
+func (d *DefaultDownloader) receiveBody(
+	ctx context.Context,
+	logger *multilog.Logger,
+	client *http.Client,
+	userAgent string,
+	file c.DownloadFile,
+	resp *http.Response,
+	offset int64,
+	etag string,
+) (*http.Response, string, error) {
+	path := partialPath(file)
+	err := os.MkdirAll(file.Folder, os.ModePerm)
+	var part *os.File
+	if err == nil {
+		part, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	}
+	if err != nil {
+		logger.Errorf("Creating partial file error: %v (file: %s)", err, path)
+		u.CloseBody(logger, resp.Body)
+		return nil, "", err
+	}
+	body := &partialBody{part: part, path: path, etag: etag, received: offset}
+
+	failures := 0
+	for {
+		var written int64
+		written, err = body.receive(logger, file, resp)
+		if written > 0 {
+			failures = 0
+		}
+		if err == nil || !body.resumable || ctx.Err() != nil || isWriteError(err) {
+			break
+		}
+		resp, err = d.resumeBody(ctx, logger, client, userAgent, file, body, &failures, err)
+		if err != nil {
+			break
+		}
+	}
+
+	if closeErr := part.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if body.resumable && body.received > 0 && !isWriteError(err) {
+			logger.Warnf("Keeping %d bytes of %s to resume the download later", body.received, file.URL)
+		} else {
+			removePartial(logger, path)
+		}
+		return nil, "", err
+	}
+	return resp, path, nil
+}
+
+// resumeBody requests the rest of the body after the interruption err, until a request succeeds
+// or the retries of file are exhausted.
+func (d *DefaultDownloader) resumeBody(
+	ctx context.Context,
+	logger *multilog.Logger,
+	client *http.Client,
+	userAgent string,
+	file c.DownloadFile,
+	body *partialBody,
+	failures *int,
+	err error,
+) (*http.Response, error) {
+	maxRetries := d.maxRetries
+	if file.HTTP != nil && file.HTTP.MaxRetries > 0 {
+		maxRetries = file.HTTP.MaxRetries
+	}
+	for {
+		*failures++
+		if *failures >= maxRetries {
+			return nil, err
+		}
+		logger.Warnf("Attempt %d: Download of %s interrupted after %d bytes: %v, resuming...",
+			*failures, file.URL, body.received, err)
+		if sleepErr := sleepWithContext(ctx, d.retryDelay*time.Duration(1<<uint(*failures-1))); sleepErr != nil {
+			return nil, sleepErr
+		}
+		req, reqErr := newRequest(ctx, http.MethodGet, file, userAgent)
+		if reqErr != nil {
+			return nil, reqErr
+		}
+		setRangeHeaders(req, body.received, body.etag)
+		var resp *http.Response
+		resp, err = doRequest(ctx, client, req, file.Hosts)
+		if err == nil {
+			return resp, nil
+		}
+	}
+}
+
+// receive writes the body of resp to the partial file and closes it.
+func (p *partialBody) receive(logger *multilog.Logger, file c.DownloadFile, resp *http.Response) (int64, error) {
+	defer u.CloseBody(logger, resp.Body)
+	if err := p.accept(logger, file.URL, resp); err != nil {
+		return 0, err
+	}
+	return p.copy(logger, file.Name, resp.Body)
+}
+
+// accept prepares the partial file for the body of resp: a 206 response continues it, a 200 response replaces it.
+func (p *partialBody) accept(logger *multilog.Logger, rawURL string, resp *http.Response) error {
+	etag := resp.Header.Get("ETag")
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != p.received || etag != p.etag {
+			p.resumable = false
+			return fmt.Errorf("unexpected range %q for %s", resp.Header.Get("Content-Range"), rawURL)
+		}
+		logger.Infof("Resuming download of %s at %d bytes", rawURL, start)
+		p.total = total
+	case http.StatusOK:
+		p.received = 0
+		p.total = resp.ContentLength
+	default:
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, URL: rawURL}
+	}
+	p.etag = etag
+	if err := p.part.Truncate(p.received); err != nil {
+		return err
+	}
+	if _, err := p.part.Seek(p.received, io.SeekStart); err != nil {
+		return err
+	}
+
+	resumable := resp.Header.Get("Accept-Ranges") == "bytes" || resp.StatusCode == http.StatusPartialContent
+	p.resumable = resumable && isStrongETag(etag)
+	if !p.resumable {
+		if err := os.Remove(p.path + ".json"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	state, err := json.Marshal(partialState{URL: rawURL, ETag: etag})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.path+".json", state, 0644)
+}
+
+// copy appends body to the partial file, logging the progress of a large body.
+func (p *partialBody) copy(logger *multilog.Logger, name string, body io.Reader) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var written int64
+	lastLog := time.Now()
+	for {
+		n, readErr := body.Read(buf)
+		if n > 0 {
+			if _, err := p.part.Write(buf[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			p.received += int64(n)
+			if p.large() && time.Since(lastLog) >= constants.DownloadProgressInterval {
+				p.logProgress(logger, name)
+				lastLog = time.Now()
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			if p.total >= 0 && p.received != p.total {
+				return written, io.ErrUnexpectedEOF
+			}
+			if p.large() {
+				p.logProgress(logger, name)
+			}
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
+
+// large reports whether the progress of the body is logged.
+func (p *partialBody) large() bool {
+	return p.total >= constants.LargeDownloadSize || (p.total < 0 && p.received >= constants.LargeDownloadSize)
+}
+
+func (p *partialBody) logProgress(logger *multilog.Logger, name string) {
+	if p.total > 0 {
+		logger.Infof("Downloading %s: %.1f of %.1f MB (%d%%)", name,
+			float64(p.received)/(1<<20), float64(p.total)/(1<<20), p.received*100/p.total)
+		return
+	}
+	logger.Infof("Downloading %s: %.1f MB", name, float64(p.received)/(1<<20))
+}
+
+// isWriteError reports whether err comes from the partial file rather than the connection.
+func isWriteError(err error) bool {
+	var pathErr *os.PathError
+	return errors.As(err, &pathErr)
+}
+
+// isStrongETag reports whether etag may validate a range request, weak ETags may not.
+func isStrongETag(etag string) bool {
+	return strings.HasPrefix(etag, `"`) && len(etag) > 1
+}
+
+// parseContentRange parses a "bytes start-end/total" Content-Range header, with a total of -1 if it is unknown.
+func parseContentRange(value string) (int64, int64, bool) {
+	rangeSpec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	span, totalSpec, found := strings.Cut(rangeSpec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	startSpec, _, found := strings.Cut(span, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startSpec, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	if totalSpec == "*" {
+		return start, -1, true
+	}
+	total, err := strconv.ParseInt(totalSpec, 10, 64)
+	if err != nil || total < start {
+		return 0, 0, false
+	}
+	return start, total, true
+}
@@ -0,0 +1,235 @@
+package downloaders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeServer serves content with the given ETag and range support, returning the Range headers of its GET
+// requests. The first drops requests are cut off after half of the body.
+func rangeServer(t *testing.T, content []byte, etag string, drops int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		drop := drops > 0
+		drops--
+		mu.Unlock()
+		if drop {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), ranges...)
+	}
+}
+
+func writePartial(t *testing.T, file c.DownloadFile, content []byte, etag string) {
+	path := partialPath(file)
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	state, err := json.Marshal(partialState{URL: file.URL, ETag: etag})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+".json", state, 0644))
+}
+
+func TestDefaultDownloader_ResumeDownload(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+	content := []byte(domainList(20000))
+	half := len(content) / 2
+
+	tests := []struct {
+		name       string
+		etag       string
+		drops      int
+		maxRetries int
+		partial    []byte
+		partialTag string
+		wantRanges []string
+		wantErr    bool
+		keepsPart  bool
+	}{
+		{
+			name:       "resumes dropped connection",
+			etag:       `"v1"`,
+			drops:      1,
+			maxRetries: 2,
+			wantRanges: []string{"", "bytes=" + strconv.Itoa(half) + "-"},
+		},
+		{
+			name:       "resumes partial file of previous run",
+			etag:       `"v1"`,
+			partial:    content[:half],
+			partialTag: `"v1"`,
+			maxRetries: 1,
+			wantRanges: []string{"bytes=" + strconv.Itoa(half) + "-"},
+		},
+		{
+			name:       "restarts changed file",
+			etag:       `"v2"`,
+			partial:    []byte("stale partial content\n"),
+			partialTag: `"v1"`,
+			maxRetries: 1,
+			wantRanges: []string{"bytes=22-"},
+		},
+		{
+			name:       "restarts unsatisfiable range",
+			etag:       `"v1"`,
+			partial:    append(append([]byte{}, content...), "trailing\n"...),
+			partialTag: `"v1"`,
+			maxRetries: 1,
+			wantRanges: []string{"bytes=" + strconv.Itoa(len(content)+9) + "-", ""},
+		},
+		{
+			name:       "discards partial file of other url",
+			etag:       `"v1"`,
+			partial:    content[:half],
+			maxRetries: 1,
+			wantRanges: []string{""},
+		},
+		{
+			name:       "does not resume weak etag",
+			etag:       `W/"v1"`,
+			drops:      1,
+			maxRetries: 2,
+			wantRanges: []string{""},
+			wantErr:    true,
+		},
+		{
+			name:       "keeps partial file when retries are exhausted",
+			etag:       `"v1"`,
+			drops:      1,
+			maxRetries: 1,
+			wantRanges: []string{""},
+			wantErr:    true,
+			keepsPart:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server, ranges := rangeServer(t, content, tt.etag, tt.drops)
+			folder := t.TempDir()
+			filePath := filepath.Join(folder, "list.txt")
+			require.NoError(t, os.WriteFile(filePath, []byte("previous.example\n"), 0644))
+			file := &c.DownloadFile{Name: "list", URL: server.URL + "/list.txt", Folder: folder, Filename: "list.txt"}
+			if tt.partial != nil {
+				state := *file
+				if tt.partialTag == "" {
+					state.URL = server.URL + "/other.txt"
+				}
+				writePartial(t, state, tt.partial, tt.partialTag)
+			}
+
+			d := NewDefaultDownloaderForTesting(tt.maxRetries, time.Millisecond)
+			_, _, err := d.Download(context.Background(), logger, file, config.ApplicationConfig{})
+			assert.Equal(t, tt.wantRanges, ranges())
+			saved, readErr := os.ReadFile(filePath)
+			require.NoError(t, readErr)
+			_, partErr := os.Stat(partialPath(*file))
+			_, stateErr := os.Stat(partialPath(*file) + ".json")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, "previous.example\n", string(saved), "the previous copy is kept")
+				assert.Equal(t, tt.keepsPart, partErr == nil)
+				assert.Equal(t, tt.keepsPart, stateErr == nil)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, content, saved)
+			assert.Equal(t, tt.etag, file.ETag)
+			assert.True(t, os.IsNotExist(partErr), "the partial file is moved into place")
+			assert.True(t, os.IsNotExist(stateErr), "the partial state is removed")
+		})
+	}
+}
+
+func TestDefaultDownloader_ResumeRejected(t *testing.T) {
+	t.Parallel()
+	logger := setupTestLogger()
+	content := []byte(domainList(5))
+	server, _ := rangeServer(t, content, `"v1"`, 0)
+	folder := t.TempDir()
+	file := &c.DownloadFile{
+		Name:     "list",
+		URL:      server.URL + "/list.txt",
+		Folder:   folder,
+		Filename: "list.txt",
+		Guard:    &c.AnomalyGuard{PreviousEntries: 200},
+	}
+	writePartial(t, *file, content[:10], `"v1"`)
+
+	d := NewDefaultDownloaderForTesting(1, time.Millisecond)
+	_, _, err := d.Download(context.Background(), logger, file, config.ApplicationConfig{})
+	var anomalyErr *AnomalyError
+	assert.ErrorAs(t, err, &anomalyErr)
+	entries, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "a rejected partial file is removed")
+}
+
+func TestParseContentRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		start int64
+		total int64
+		ok    bool
+	}{
+		{value: "bytes 100-199/200", start: 100, total: 200, ok: true},
+		{value: "bytes 0-99/*", start: 0, total: -1, ok: true},
+		{value: "bytes */200"},
+		{value: "bytes 300-399/200"},
+		{value: "items 0-9/10"},
+		{value: "bytes 0-9"},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		start, total, ok := parseContentRange(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		if tt.ok {
+			assert.Equal(t, tt.start, start, tt.value)
+			assert.Equal(t, tt.total, total, tt.value)
+		}
+	}
+}
+
+func TestIsStrongETag(t *testing.T) {
+	t.Parallel()
+	assert.True(t, isStrongETag(`"abc"`))
+	assert.False(t, isStrongETag(`W/"abc"`))
+	assert.False(t, isStrongETag(""))
+	assert.False(t, isStrongETag(`"`))
+}
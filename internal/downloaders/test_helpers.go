@@ -1,6 +1,7 @@
 package downloaders
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -22,5 +23,5 @@ func (d *DefaultDownloader) CanSkipDownloadForTest(
 	localFileSize int64,
 	localModTime time.Time,
 ) bool {
-	return d.canSkipDownload(logger, client, userAgent, file, localFileSize, localModTime)
+	return d.canSkipDownload(context.Background(), logger, client, userAgent, file, localFileSize, localModTime)
 }
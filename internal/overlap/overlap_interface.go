@@ -1,6 +1,8 @@
 package overlap
 
 import (
+	"context"
+
 	"github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/multilog"
 )
@@ -9,6 +11,7 @@ import (
 type FileOverlapService interface {
 	// FindOverlap processes a collection of files of the same generic source type to identify overlaps
 	FindOverlap(
+		ctx context.Context,
 		logger *multilog.Logger,
 		genericSourceType string,
 		files []common.ProcessedFile,
@@ -16,6 +19,7 @@ type FileOverlapService interface {
 
 	// WriteCompactOverlapSummaries generates and writes overlap summaries from processed files
 	WriteCompactOverlapSummaries(
+		ctx context.Context,
 		logger *multilog.Logger,
 		processedFiles []common.ProcessedFile,
 		genericSourceTypes []string,
@@ -1,6 +1,7 @@
 package overlap
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"runtime"
@@ -35,6 +36,7 @@ func NewDefaultService(overlapDir, summaryDir string) *DefaultOverlapService {
 
 // FindOverlap processes a collection of files of the same generic source type to identify overlaps
 func (s *DefaultOverlapService) FindOverlap(
+	ctx context.Context,
 	logger *multilog.Logger,
 	genericSourceType string,
 	files []c.ProcessedFile,
@@ -67,7 +69,7 @@ func (s *DefaultOverlapService) FindOverlap(
 
 	var mu sync.Mutex
 	maxWorkers := runtime.GOMAXPROCS(0)
-	pairProcessingPool := c.NewDTWorkerPool(maxWorkers) // Worker pool for processing file pairs
+	pairProcessingPool := c.NewDTWorkerPoolWithContext(ctx, maxWorkers) // Worker pool for processing file pairs
 
 	type filePair struct {
 		file1, file2 string
@@ -200,6 +202,7 @@ func (s *DefaultOverlapService) FindOverlap(
 
 // WriteCompactOverlapSummaries generates and writes overlap summaries from processed files
 func (s *DefaultOverlapService) WriteCompactOverlapSummaries(
+	ctx context.Context,
 	logger *multilog.Logger,
 	processedFiles []c.ProcessedFile,
 	genericSourceTypes []string,
@@ -208,7 +211,7 @@ func (s *DefaultOverlapService) WriteCompactOverlapSummaries(
 	var overlapSources []c.OverlapSourceType
 	var mu sync.Mutex
 
-	workerPool := c.NewDTWorkerPool(maxWorkers)
+	workerPool := c.NewDTWorkerPoolWithContext(ctx, maxWorkers)
 
 	processedFilesMap := make(map[string][]c.ProcessedFile)
 	// group processed files by generic source type
@@ -221,6 +224,7 @@ func (s *DefaultOverlapService) WriteCompactOverlapSummaries(
 		workerPool.Submit(func() {
 			// Each call to findOverlap will manage its own string pool and memory internally
 			overlapSourceType := s.FindOverlap(
+				ctx,
 				logger,
 				currentGenericSourceType,
 				processedFilesMap[currentGenericSourceType],
@@ -233,6 +237,10 @@ func (s *DefaultOverlapService) WriteCompactOverlapSummaries(
 		})
 	}
 	workerPool.Wait()
+	if err := ctx.Err(); err != nil {
+		// Keep the previous summary file intact rather than saving a partial result
+		return nil, err
+	}
 	u.LogMemStats(logger, "After overlap calculation")
 	runtime.GC()
 	debug.FreeOSMemory()
@@ -1,6 +1,7 @@
 package overlap_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -219,7 +220,7 @@ func TestOverlapService_FindOverlap(t *testing.T) {
 
 	processedFiles, _ := createTestFiles(t, tmpDir)
 
-	result := service.FindOverlap(logger, "domain", processedFiles)
+	result := service.FindOverlap(context.Background(), logger, "domain", processedFiles)
 
 	assert.Equal(t, "domain", result.Type)
 	assert.Greater(t, result.PairsCount, 0)
@@ -261,6 +262,7 @@ func TestOverlapService_WriteCompactOverlapSummaries(t *testing.T) {
 	processedFiles, _ := createTestFiles(t, tmpDir)
 
 	summaries, err := service.WriteCompactOverlapSummaries(
+		context.Background(),
 		logger,
 		processedFiles,
 		[]string{"domain"},
@@ -286,6 +288,27 @@ func TestOverlapService_WriteCompactOverlapSummaries(t *testing.T) {
 	assert.NoError(t, err, "Summary file was not created")
 }
 
+// TestOverlapService_WriteCompactOverlapSummaries_Cancelled tests that a cancelled run does not write summaries
+func TestOverlapService_WriteCompactOverlapSummaries_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	overlapDir, summaryDir := setupTestDirs(t)
+	service := overlap.NewDefaultService(overlapDir, summaryDir)
+	logger := createTestLogger(t)
+
+	processedFiles, _ := createTestFiles(t, tmpDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summaries, err := service.WriteCompactOverlapSummaries(ctx, logger, processedFiles, []string{"domain"}, 4)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, summaries)
+
+	summaryFilePath := filepath.Join(summaryDir, constants.DefaultSummaryFiles["overlap"])
+	_, err = os.Stat(summaryFilePath)
+	assert.True(t, os.IsNotExist(err), "summary file should not be written for a cancelled run")
+}
+
 // TestOverlapService_WriteCompactOverlapSummaries_EmptySourceTypes tests WriteCompactOverlapSummaries with empty source types
 func TestOverlapService_WriteCompactOverlapSummaries_EmptySourceTypes(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -296,6 +319,7 @@ func TestOverlapService_WriteCompactOverlapSummaries_EmptySourceTypes(t *testing
 	processedFiles, _ := createTestFiles(t, tmpDir)
 
 	summaries, err := service.WriteCompactOverlapSummaries(
+		context.Background(),
 		logger,
 		processedFiles,
 		[]string{}, // Empty source types list
@@ -442,7 +466,7 @@ func TestOverlapService_FindOverlap_InvalidFiles(t *testing.T) {
 
 	processedFiles = append(processedFiles, invalidFile)
 
-	result := service.FindOverlap(logger, "domain", processedFiles)
+	result := service.FindOverlap(context.Background(), logger, "domain", processedFiles)
 
 	assert.Equal(t, "domain", result.Type)
 
@@ -478,6 +502,7 @@ func TestOverlapService_WriteCompactOverlapSummaries_ErrorCase(t *testing.T) {
 	processedFiles, _ := createTestFiles(t, tmpDir)
 
 	_, err = service.WriteCompactOverlapSummaries(
+		context.Background(),
 		logger,
 		processedFiles,
 		[]string{"domain"},
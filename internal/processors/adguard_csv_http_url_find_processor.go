@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"slices"
 	"strings"
 
@@ -21,7 +22,11 @@ func NewAdguardCsvHttpUrlFindProcessor(sourceType, listType string) *AdguardCsvH
 	return &AdguardCsvHttpUrlFindProcessor{BaseProcessor: NewBaseProcessor(sourceType, listType)}
 }
 
-func (p *AdguardCsvHttpUrlFindProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *AdguardCsvHttpUrlFindProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
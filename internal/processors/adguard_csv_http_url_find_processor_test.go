@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -22,7 +23,7 @@ C,http://192.0.2.1:8080/some,192.0.2.1,2025-10-05
 		"||192.0.2.1:8080/some$",
 	}
 
-	got, gotInv := processor.Process(logger, content)
+	got, gotInv := processor.Process(context.Background(), logger, content)
 
 	if len(gotInv) != 0 {
 		t.Fatalf("expected no invalid lines, got: %v", gotInv)
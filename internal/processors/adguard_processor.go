@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"slices"
 	"strings"
 
@@ -17,70 +18,47 @@ const (
 	sourceTypeAdguardHttpUrl = "adguard_http_url"
 )
 
-// AdGuardBlocklistProcessor handles AdGuard blocklist entries
+// AdGuardBlocklistProcessor handles AdGuard blocklist entries.
+// Exception rules and rules a DNS server does not apply, such as cosmetic rules, regex rules
+// and rules with browser-only modifiers, are invalid.
 type AdGuardBlocklistProcessor struct {
-	BaseProcessor
+	LineProcessor
 }
 
 // NewAdGuardBlocklistProcessor creates a new AdGuard blocklist processor
 func NewAdGuardBlocklistProcessor(sourceType, listType string) *AdGuardBlocklistProcessor {
 	return &AdGuardBlocklistProcessor{
-		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		LineProcessor: *NewLineProcessor(sourceType, listType, func(line string) (string, string) {
+			return adguardRuleLine(line, false)
+		}),
 	}
 }
 
-// Process processes AdGuard blocklist entries
-func (p *AdGuardBlocklistProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
-	var validEntries, invalidEntries []string
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if u.IsComment(line) {
-			continue
-		}
-
-		if !strings.HasPrefix(line, AdguardExceptionPrefix) {
-			validEntries = append(validEntries, line)
-		} else {
-			// Matching exception format
-			invalidEntries = append(invalidEntries, line)
-		}
-	}
-	return validEntries, invalidEntries
-}
-
-// AdGuardAllowlistProcessor handles AdGuard allowlist entries (different format)
+// AdGuardAllowlistProcessor handles AdGuard allowlist entries, only DNS-compatible exception rules are valid
 type AdGuardAllowlistProcessor struct {
-	BaseProcessor
+	LineProcessor
 }
 
 // NewAdGuardAllowlistProcessor creates a new AdGuard allowlist processor
 func NewAdGuardAllowlistProcessor(sourceType, listType string) *AdGuardAllowlistProcessor {
 	return &AdGuardAllowlistProcessor{
-		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		LineProcessor: *NewLineProcessor(sourceType, listType, func(line string) (string, string) {
+			return adguardRuleLine(line, true)
+		}),
 	}
 }
 
-// Process processes AdGuard allowlist entries
-func (p *AdGuardAllowlistProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
-	var validEntries, invalidEntries []string
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if u.IsComment(line) {
-			continue
-		}
-
-		if strings.HasPrefix(line, AdguardExceptionPrefix) {
-			validEntries = append(validEntries, line)
-		} else {
-			// Non-matching format for allowlist
-			invalidEntries = append(invalidEntries, line)
-		}
+// adguardRuleLine classifies an AdGuard rule, a valid rule is a DNS-compatible exception rule
+// if exception is set, or a DNS-compatible blocking rule otherwise.
+func adguardRuleLine(line string, exception bool) (string, string) {
+	rule, err := u.ParseAdguardRule(line)
+	if err != nil || rule.Exception != exception {
+		return "", line
 	}
-	return validEntries, invalidEntries
+	if compatible, _ := rule.DNSCompatible(); !compatible {
+		return "", line
+	}
+	return line, ""
 }
 
 func ExtractAllowlistDomains(logger *multilog.Logger, content string) ([]string, []string) {
@@ -156,7 +134,7 @@ func NewAdGuardHttpUrlProcessor(sourceType, listType string) *AdGuardHttpUrlProc
 }
 
 // Process converts each valid http/https URL into an AdGuard block rule
-func (p *AdGuardHttpUrlProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *AdGuardHttpUrlProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 	unique := make(map[string]struct{})
@@ -198,7 +176,11 @@ func NewAdGuardDomainBlocklistProcessor(sourceType, listType string) *AdGuardDom
 }
 
 // Process converts each valid domain into an AdGuard block rule
-func (p *AdGuardDomainBlocklistProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *AdGuardDomainBlocklistProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
@@ -231,7 +213,11 @@ func NewAdGuardDomainAllowlistProcessor(sourceType, listType string) *AdGuardDom
 }
 
 // Process converts each valid domain into an AdGuard allow rule
-func (p *AdGuardDomainAllowlistProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *AdGuardDomainAllowlistProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
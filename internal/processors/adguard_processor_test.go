@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -82,16 +83,38 @@ func TestAdGuardBlocklistProcessor_Process(t *testing.T) {
 			expectedInvalid: nil,
 		},
 		{
-			name:            "complex adguard rules",
-			content:         "||ads.example.com^\n@@||allowlist.example.com^\n! Comment\n# Another comment\n||tracking.example.com^$third-party\n@@||allowed.example.com^$important",
-			expectedValid:   []string{"||ads.example.com^", "||tracking.example.com^$third-party"},
-			expectedInvalid: []string{"@@||allowlist.example.com^", "@@||allowed.example.com^$important"},
+			name:          "complex adguard rules",
+			content:       "||ads.example.com^\n@@||allowlist.example.com^\n! Comment\n# Another comment\n||tracking.example.com^$third-party\n@@||allowed.example.com^$important",
+			expectedValid: []string{"||ads.example.com^"},
+			expectedInvalid: []string{
+				"@@||allowlist.example.com^",
+				"||tracking.example.com^$third-party",
+				"@@||allowed.example.com^$important",
+			},
+		},
+		{
+			name: "rules a DNS server does not apply",
+			content: "example.com##.banner\n/ads[0-9]+/\n||example.org^$removeparam=utm_source\n" +
+				"||cdn.example.com/ads.js\n||dns.example.com^$important,dnstype=AAAA\n||x.example.com^$~third-party,bad=\n" +
+				"$third-party,domain=example.net\n||client.example.com^$client=192.168.1.2,denyallow=a.com|b.com",
+			expectedValid: []string{
+				"||dns.example.com^$important,dnstype=AAAA",
+				"||client.example.com^$client=192.168.1.2,denyallow=a.com|b.com",
+			},
+			expectedInvalid: []string{
+				"example.com##.banner",
+				"/ads[0-9]+/",
+				"||example.org^$removeparam=utm_source",
+				"||cdn.example.com/ads.js",
+				"||x.example.com^$~third-party,bad=",
+				"$third-party,domain=example.net",
+			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -181,7 +204,7 @@ func TestAdGuardAllowlistProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -204,16 +227,16 @@ func TestAdGuardProcessors_Integration(t *testing.T) {
 example.domain.com
 @@exception.domain.com`
 
-	blocklistValid, blocklistInvalid := blocklistProcessor.Process(logger, content)
-	allowlistValid, allowlistInvalid := allowlistProcessor.Process(logger, content)
+	blocklistValid, blocklistInvalid := blocklistProcessor.Process(context.Background(), logger, content)
+	allowlistValid, allowlistInvalid := allowlistProcessor.Process(context.Background(), logger, content)
 
 	expectedBlocklistValid := []string{
 		"||ads.example.com^",
-		"||tracking.site.com^$third-party",
 		"example.domain.com",
 	}
 	expectedBlocklistInvalid := []string{
 		"@@||allowlist.example.com^",
+		"||tracking.site.com^$third-party",
 		"@@||allowed.site.com^$important",
 		"@@exception.domain.com",
 	}
@@ -358,7 +381,7 @@ func TestAdGuardDomainBlocklistProcessor_Process(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid)
 			assert.Equal(t, tt.expectedInvalid, invalid)
 		})
@@ -400,7 +423,7 @@ func TestAdGuardDomainAllowlistProcessor_Process(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid)
 			assert.Equal(t, tt.expectedInvalid, invalid)
 		})
@@ -443,7 +466,7 @@ func TestAdGuardHttpUrlProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, gotInv := processor.Process(logger, tt.input)
+			got, gotInv := processor.Process(context.Background(), logger, tt.input)
 			if !processors.EqualUnorderedAdg(got, tt.want) {
 				t.Errorf("valid entries: got %v, want %v", got, tt.want)
 			}
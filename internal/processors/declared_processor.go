@@ -0,0 +1,360 @@
+package processors
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/phani-kb/multilog"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+// declaredEntryTypes are the source types a processor may be declared for. Each one returns the canonical
+// form of a value found by the processor, and whether the value is a valid entry of the type.
+var declaredEntryTypes = map[string]func(value string) (string, bool){
+	constants.SourceTypeDomain: func(value string) (string, bool) {
+		return value, u.IsDomainEntry(value)
+	},
+	constants.SourceTypeIpv4:     canonicalIPv4,
+	constants.SourceTypeIpv6:     u.CanonicalIPv6,
+	constants.SourceTypeCidrIpv4: canonicalCIDRv4,
+	constants.SourceTypeCidrIpv6: u.CanonicalCIDRv6,
+}
+
+func canonicalIPv4(value string) (string, bool) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil || !addr.Is4() {
+		return "", false
+	}
+	return addr.String(), true
+}
+
+func canonicalCIDRv4(value string) (string, bool) {
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil || !prefix.Addr().Is4() {
+		return "", false
+	}
+	return prefix.Masked().String(), true
+}
+
+// DeclaredProcessorKey returns the source type the processor declared by a source type of a source is
+// registered under, so that it is only used for the source that declared it.
+func DeclaredProcessorKey(sourceName, sourceType string) string {
+	return sourceName + "/" + sourceType
+}
+
+// ValidateProcessorSpec checks that a processor can be created from the spec declared by a source type.
+func ValidateProcessorSpec(sourceType string, spec c.ProcessorSpec) error {
+	_, err := NewDeclaredProcessor(sourceType, constants.ListTypeBlocklist, spec)
+	return err
+}
+
+// RegisterDeclaredProcessor registers the processor declared by a source type of a source for the list types,
+// under the key returned by DeclaredProcessorKey.
+func RegisterDeclaredProcessor(sourceName, sourceType string, listTypes []string, spec c.ProcessorSpec) error {
+	for _, listType := range listTypes {
+		processor, err := NewDeclaredProcessor(sourceType, listType, spec)
+		if err != nil {
+			return err
+		}
+		Processors.RegisterProcessor(DeclaredProcessorKey(sourceName, sourceType), listType, processor)
+	}
+	return nil
+}
+
+// NewDeclaredProcessor creates the processor of a spec declared inline by a source type.
+// The values it finds are kept as entries of the source type when they are valid,
+// except for the html_selector type whose entries are of the type of its extract.
+func NewDeclaredProcessor(sourceType, listType string, spec c.ProcessorSpec) (Processor, error) {
+	if sourceType == constants.SourceTypeHtmlSelector {
+		if spec.Kind != constants.ProcessorKindHtml {
+			return nil, fmt.Errorf("type %s requires processor kind %s", sourceType, constants.ProcessorKindHtml)
+		}
+		return newHtmlSelectorProcessor(sourceType, listType, spec)
+	}
+	normalize, ok := declaredEntryTypes[sourceType]
+	if !ok {
+		return nil, fmt.Errorf("a processor cannot be declared for type %s", sourceType)
+	}
+	switch spec.Kind {
+	case constants.ProcessorKindCsv:
+		return newCsvProcessor(sourceType, listType, spec, normalize)
+	case constants.ProcessorKindRegex:
+		return newRegexProcessor(sourceType, listType, spec, normalize)
+	case constants.ProcessorKindJSON:
+		return newJSONProcessor(sourceType, listType, spec, normalize)
+	case constants.ProcessorKindHtml:
+		return nil, fmt.Errorf("processor kind %s requires type %s", spec.Kind, constants.SourceTypeHtmlSelector)
+	case "":
+		return nil, fmt.Errorf("processor kind is required")
+	default:
+		return nil, fmt.Errorf("unsupported processor kind: %s", spec.Kind)
+	}
+}
+
+// CsvProcessor keeps a column of each row of a delimited feed. Quoted fields may hold the delimiter,
+// but not a line break.
+type CsvProcessor struct {
+	normalize func(string) (string, bool)
+	BaseProcessor
+	column     int
+	delimiter  rune
+	skipHeader bool
+}
+
+func newCsvProcessor(
+	sourceType, listType string,
+	spec c.ProcessorSpec,
+	normalize func(string) (string, bool),
+) (*CsvProcessor, error) {
+	if spec.Column < 0 {
+		return nil, fmt.Errorf("invalid column: %d", spec.Column)
+	}
+	delimiter := ','
+	if spec.Delimiter != "" {
+		delimiter, _ = utf8.DecodeRuneInString(spec.Delimiter)
+		if utf8.RuneCountInString(spec.Delimiter) != 1 || delimiter == utf8.RuneError ||
+			delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return nil, fmt.Errorf("invalid delimiter: %q", spec.Delimiter)
+		}
+	}
+	return &CsvProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		normalize:     normalize,
+		column:        spec.Column,
+		delimiter:     delimiter,
+		skipHeader:    spec.SkipHeader,
+	}, nil
+}
+
+func (p *CsvProcessor) ProcessStream(ctx context.Context, _ *multilog.Logger, reader io.Reader, sink EntrySink) error {
+	header := p.skipHeader
+	return EachLine(ctx, reader, func(line string) error {
+		if header {
+			header = false
+			return nil
+		}
+		csvReader := csv.NewReader(strings.NewReader(line))
+		csvReader.Comma = p.delimiter
+		csvReader.FieldsPerRecord = -1
+		csvReader.LazyQuotes = true
+		fields, err := csvReader.Read()
+		if err != nil || p.column >= len(fields) {
+			return addInvalidReason(sink, line, constants.InvalidReasonSyntax)
+		}
+		value := strings.TrimSpace(fields[p.column])
+		if entry, ok := p.normalize(value); ok {
+			return sink.AddValid(entry)
+		}
+		return addInvalidReason(sink, line, u.InvalidEntryReason(p.GetSourceType(), value))
+	})
+}
+
+func (p *CsvProcessor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
+
+// newRegexProcessor creates a processor keeping a group of the first match of the pattern in each line.
+func newRegexProcessor(
+	sourceType, listType string,
+	spec c.ProcessorSpec,
+	normalize func(string) (string, bool),
+) (*LineProcessor, error) {
+	if spec.Pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	pattern, err := regexp.Compile(spec.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if spec.Group < 0 || spec.Group > pattern.NumSubexp() {
+		return nil, fmt.Errorf("group %d is out of range of pattern %s", spec.Group, spec.Pattern)
+	}
+	return NewLineProcessor(sourceType, listType, findLine(func(line string) (string, bool) {
+		match := pattern.FindStringSubmatch(line)
+		if match == nil {
+			return "", false
+		}
+		return normalize(strings.TrimSpace(match[spec.Group]))
+	})), nil
+}
+
+// jsonPathStep is a step of a JSONPath expression: a member name, an array index or a wildcard.
+type jsonPathStep struct {
+	key      string
+	index    int
+	wildcard bool
+}
+
+// parseJSONPath parses the JSONPath subset of the declared processors: the root $ followed by
+// .name, ['name'], [index], .* and [*] steps.
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $: %s", path)
+	}
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("empty member name in path %s", path)
+			case "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			default:
+				steps = append(steps, jsonPathStep{key: name})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed bracket in path %s", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(selector) > 2 && (selector[0] == '\'' || selector[0] == '"') &&
+				selector[len(selector)-1] == selector[0]:
+				steps = append(steps, jsonPathStep{key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid selector [%s] in path %s", selector, path)
+				}
+				steps = append(steps, jsonPathStep{index: index})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %s", rest[0], path)
+		}
+	}
+	return steps, nil
+}
+
+// selectJSONPath returns the values of a decoded JSON document selected by the steps of a path,
+// in document order. The members of an object selected by a wildcard are taken in the order of their names.
+func selectJSONPath(document any, steps []jsonPathStep) []any {
+	values := []any{document}
+	for _, step := range steps {
+		var selected []any
+		for _, value := range values {
+			switch node := value.(type) {
+			case []any:
+				if step.wildcard {
+					selected = append(selected, node...)
+				} else if step.key == "" && step.index < len(node) {
+					selected = append(selected, node[step.index])
+				}
+			case map[string]any:
+				if step.wildcard {
+					names := make([]string, 0, len(node))
+					for name := range node {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					for _, name := range names {
+						selected = append(selected, node[name])
+					}
+				} else if member, ok := node[step.key]; ok && step.key != "" {
+					selected = append(selected, member)
+				}
+			}
+		}
+		values = selected
+	}
+	return values
+}
+
+// JSONProcessor keeps the values selected by a JSONPath expression in a JSON document.
+// Selected values that are not strings are invalid entries, written as JSON.
+type JSONProcessor struct {
+	normalize func(string) (string, bool)
+	BaseProcessor
+	path []jsonPathStep
+}
+
+func newJSONProcessor(
+	sourceType, listType string,
+	spec c.ProcessorSpec,
+	normalize func(string) (string, bool),
+) (*JSONProcessor, error) {
+	if spec.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	path, err := parseJSONPath(spec.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		normalize:     normalize,
+		path:          path,
+	}, nil
+}
+
+func (p *JSONProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("decoding JSON document: %w", err)
+	}
+	for i, value := range selectJSONPath(document, p.path) {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		text, isString := value.(string)
+		reason := constants.InvalidReasonSyntax
+		if isString {
+			text = strings.TrimSpace(text)
+			if entry, ok := p.normalize(text); ok {
+				if err := sink.AddValid(entry); err != nil {
+					return err
+				}
+				continue
+			}
+			reason = u.InvalidEntryReason(p.GetSourceType(), text)
+		} else {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			text = string(encoded)
+		}
+		if text == "" {
+			continue
+		}
+		if err := addInvalidReason(sink, text, reason); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (p *JSONProcessor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
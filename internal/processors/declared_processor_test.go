@@ -0,0 +1,182 @@
+package processors_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeclaredProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		name            string
+		sourceType      string
+		spec            c.ProcessorSpec
+		content         string
+		expectedValid   []string
+		expectedInvalid []string
+	}{
+		{
+			name:       "csv column with header",
+			sourceType: "domain",
+			spec:       c.ProcessorSpec{Kind: "csv", Column: 1, SkipHeader: true},
+			content: "id,host,added\n# comment\n1,ads.example.com,2024\n2,\"tracker.example.com\",2024\n" +
+				"3,not a domain,2024\n4\n",
+			expectedValid:   []string{"ads.example.com", "tracker.example.com"},
+			expectedInvalid: []string{"3,not a domain,2024", "4"},
+		},
+		{
+			name:            "csv with a tab delimiter",
+			sourceType:      "ipv4",
+			spec:            c.ProcessorSpec{Kind: "csv", Delimiter: "\t"},
+			content:         "192.0.2.1\tscanner\n198.51.100.7 \tbotnet\n::ffff:192.0.2.2\tmapped\n",
+			expectedValid:   []string{"192.0.2.1", "198.51.100.7"},
+			expectedInvalid: []string{"::ffff:192.0.2.2\tmapped"},
+		},
+		{
+			name:            "regex group",
+			sourceType:      "ipv6",
+			spec:            c.ProcessorSpec{Kind: "regex", Pattern: `^deny from (\S+)`, Group: 1},
+			content:         "deny from 2001:0db8::0001\ndeny from example.com\nallow from 2001:db8::2\n",
+			expectedValid:   []string{"2001:db8::1"},
+			expectedInvalid: []string{"deny from example.com", "allow from 2001:db8::2"},
+		},
+		{
+			name:            "regex whole match",
+			sourceType:      "cidr_ipv4",
+			spec:            c.ProcessorSpec{Kind: "regex", Pattern: `\d+\.\d+\.\d+\.\d+/\d+`},
+			content:         "block 203.0.113.7/24 ; abuse\n999.0.0.0/8\n",
+			expectedValid:   []string{"203.0.113.0/24"},
+			expectedInvalid: []string{"999.0.0.0/8"},
+		},
+		{
+			name:       "json path",
+			sourceType: "domain",
+			spec:       c.ProcessorSpec{Kind: "json", Path: "$.data[*].host"},
+			content: `{"data": [{"host": "ads.example.com"}, {"host": " tracker.example.com "}, {"ip": "192.0.2.1"},
+				{"host": "bad host"}, {"host": 42}, {"host": ["nested.example.com"]}]}`,
+			expectedValid:   []string{"ads.example.com", "tracker.example.com"},
+			expectedInvalid: []string{"bad host", "42", `["nested.example.com"]`},
+		},
+		{
+			name:          "json wildcard members and indexes",
+			sourceType:    "cidr_ipv6",
+			spec:          c.ProcessorSpec{Kind: "json", Path: "$['prefixes'].*[0]"},
+			content:       `{"prefixes": {"b": ["2001:db8:1::/48", "x"], "a": ["2001:DB8::1/32"]}}`,
+			expectedValid: []string{"2001:db8::/32", "2001:db8:1::/48"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			processor, err := processors.NewDeclaredProcessor(tt.sourceType, "blocklist", tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.sourceType, processor.GetSourceType())
+
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
+			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
+			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
+		})
+	}
+}
+
+func TestDeclaredProcessor_MatchesBlackbookProcessor(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	content := "Domain,Malware,Reference\nads.example.com,Zeus,https://example.org\n# comment\n" +
+		"invalid_domain,Emotet,https://example.org\ntracker.example.net,Qakbot,https://example.org\n"
+
+	declared, err := processors.NewDeclaredProcessor(
+		"domain",
+		"blocklist",
+		c.ProcessorSpec{Kind: "csv", SkipHeader: true},
+	)
+	require.NoError(t, err)
+	valid, _ := declared.Process(context.Background(), logger, content)
+
+	expectedValid, _ := processors.NewDomainCustomCsvBlackbookProcessor("domain_custom_csv_blackbook", "blocklist").
+		Process(context.Background(), logger, content)
+	assert.Equal(t, expectedValid, valid)
+}
+
+func TestDeclaredProcessor_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sourceType  string
+		spec        c.ProcessorSpec
+		expectedErr string
+	}{
+		{"unsupported kind", "domain", c.ProcessorSpec{Kind: "xml"}, "unsupported processor kind: xml"},
+		{"negative column", "domain", c.ProcessorSpec{Kind: "csv", Column: -1}, "invalid column: -1"},
+		{"quote delimiter", "domain", c.ProcessorSpec{Kind: "csv", Delimiter: `"`}, "invalid delimiter"},
+		{"missing pattern", "domain", c.ProcessorSpec{Kind: "regex"}, "pattern is required"},
+		{"missing path", "domain", c.ProcessorSpec{Kind: "json"}, "path is required"},
+		{"empty member", "domain", c.ProcessorSpec{Kind: "json", Path: "$.data..host"}, "empty member name"},
+		{"unclosed bracket", "domain", c.ProcessorSpec{Kind: "json", Path: "$.data[*"}, "unclosed bracket"},
+		{"invalid index", "domain", c.ProcessorSpec{Kind: "json", Path: "$.data[-1]"}, "invalid selector [-1]"},
+		{"unexpected character", "domain", c.ProcessorSpec{Kind: "json", Path: "$data"}, `unexpected 'd'`},
+		{"unsupported type", "hostname", c.ProcessorSpec{Kind: "csv"}, "cannot be declared for type hostname"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := processors.ValidateProcessorSpec(tt.sourceType, tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestJSONProcessor_ProcessStream_InvalidDocument(t *testing.T) {
+	t.Parallel()
+
+	processor, err := processors.NewDeclaredProcessor("domain", "blocklist", c.ProcessorSpec{Kind: "json", Path: "$"})
+	require.NoError(t, err)
+	streamProcessor := processors.AsStreamProcessor(processor)
+
+	sink := &processors.SliceSink{}
+	err = streamProcessor.ProcessStream(context.Background(), multilog.NewLogger(), strings.NewReader("{"), sink)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "decoding JSON document")
+
+	sink = &processors.SliceSink{}
+	err = streamProcessor.ProcessStream(context.Background(), multilog.NewLogger(),
+		strings.NewReader(`"ads.example.com"`), sink)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ads.example.com"}, sink.Valid)
+}
+
+func TestRegisterDeclaredProcessor(t *testing.T) {
+	t.Parallel()
+
+	spec := c.ProcessorSpec{Kind: "regex", Pattern: `host=(\S+)`, Group: 1}
+	err := processors.RegisterDeclaredProcessor("declared_test_source", "domain", []string{"blocklist"}, spec)
+	require.NoError(t, err)
+
+	key := processors.DeclaredProcessorKey("declared_test_source", "domain")
+	assert.Equal(t, "declared_test_source/domain", key)
+	processor, exists := processors.Processors.GetProcessor(key, "blocklist")
+	require.True(t, exists)
+	valid, invalid := processor.Process(context.Background(), multilog.NewLogger(), "host=ads.example.com\nnothing")
+	assert.Equal(t, []string{"ads.example.com"}, valid)
+	assert.Equal(t, []string{"nothing"}, invalid)
+
+	_, exists = processors.Processors.GetProcessor(key, "allowlist")
+	assert.False(t, exists)
+	_, exists = processors.Processors.GetProcessor("domain", "blocklist")
+	assert.False(t, exists, "the declared processor should not replace the processor of the type")
+}
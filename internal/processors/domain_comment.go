@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,7 @@ func NewDomainCommentProcessor(sourceType, listType string) *DomainCommentProces
 	}
 }
 
-func (p *DomainCommentProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *DomainCommentProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -96,7 +97,7 @@ func TestDomainCommentProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
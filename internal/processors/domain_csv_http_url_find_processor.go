@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,11 @@ func NewDomainCsvHttpUrlFindProcessor(sourceType, listType string) *DomainCsvHtt
 	}
 }
 
-func (p *DomainCsvHttpUrlFindProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *DomainCsvHttpUrlFindProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
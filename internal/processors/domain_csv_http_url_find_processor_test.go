@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -57,7 +58,7 @@ func TestDomainCsvHttpUrlFindProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -73,7 +74,7 @@ Kpot,http://benten02.futbol/QU6M6L2o04P9gIbD/login.php,8.209.74.36,11-06-2019`
 
 	expectedValid := []string{"traffflo.pw", "benten02.futbol"}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected")
 	assert.Empty(t, invalid, "No invalid entries expected for this content")
@@ -105,7 +106,7 @@ func TestDomainCsvHttpUrlFindProcessor_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
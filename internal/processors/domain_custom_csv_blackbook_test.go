@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -80,7 +81,7 @@ func TestDomainCustomCsvBlackbookProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -137,7 +138,7 @@ func TestDomainCustomCsvBlackbookProcessor_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -189,7 +190,7 @@ serak.top,lokibot,2024-07-31,ViriBack`
 		"serak.top",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid domains should match expected from real-world data")
 	assert.Empty(t, invalid, "No invalid entries expected for real-world CSV data")
@@ -230,7 +231,7 @@ final.valid.domain.io,malware10,2024-09-01,Source10`
 		"domain.with.port.com:8080",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected")
 	assert.Equal(t, expectedInvalid, invalid, "Invalid entries should match expected")
@@ -276,7 +277,7 @@ australian.example.com.au,virus,2024-08-21,AustralianSource`
 		"domain_underscore.org",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected")
 	assert.Equal(t, expectedInvalid, invalid, "Invalid entries should match expected")
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"strings"
 
 	"github.com/phani-kb/multilog"
@@ -20,7 +21,11 @@ func NewDomainCustomCsvMaltrailProcessor(sourceType, listType string) *DomainCus
 	}
 }
 
-func (p *DomainCustomCsvMaltrailProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *DomainCustomCsvMaltrailProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
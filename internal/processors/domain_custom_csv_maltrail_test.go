@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -79,7 +80,7 @@ func TestDomainCustomCsvMaltrailProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -126,7 +127,7 @@ func TestDomainCustomCsvMaltrailProcessor_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -169,7 +170,7 @@ namelessserver.com,nameless c2 (malicious),(static)
 		"192.168.1.100",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected")
 	assert.Equal(t, expectedInvalid, invalid, "Invalid entries should match expected")
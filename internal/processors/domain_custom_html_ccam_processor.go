@@ -1,14 +1,23 @@
 package processors
 
 import (
+	"context"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
-	"github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
 )
 
 // sourceTypeDomainCustomHtmlCcam defines the type identifier for the Domain Custom HTML CCAM processor
 const sourceTypeDomainCustomHtmlCcam = "domain_custom_html_ccam"
 
+// ccamDomainSelection is the html_selector spec of the domains of the CCAM table cells
+var ccamDomainSelection = mustHtmlSelection(c.ProcessorSpec{
+	Kind:     constants.ProcessorKindHtml,
+	Selector: "table tr td",
+	Extract:  constants.HtmlExtractDomain,
+})
+
 // DomainCustomHtmlCcamProcessor implements a processor for extracting domain names
 // from HTML content, specifically focussing on CCAM-formatted HTML tables.
 type DomainCustomHtmlCcamProcessor struct {
@@ -36,13 +45,12 @@ func NewDomainCustomHtmlCcamProcessor(sourceType, listType string) *DomainCustom
 // Returns:
 //   - A slice of valid domain names found in the content, sorted alphabetically
 //   - A slice of invalid entries that couldn't be parsed as domains
-func (p *DomainCustomHtmlCcamProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
-	return extractUniqueFromHtmlTableCells(
-		logger,
-		content,
-		constants.SourceTypeRegexMap[constants.SourceTypeDomain],
-		utils.IsDomain,
-	)
+func (p *DomainCustomHtmlCcamProcessor) Process(
+	_ context.Context,
+	logger *multilog.Logger,
+	content string,
+) ([]string, []string) {
+	return processHtmlSelection(logger, ccamDomainSelection, content)
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -18,7 +19,7 @@ func TestDomainCustomHtmlCcamProcessor_Process(t *testing.T) {
 
 	logger := multilog.NewLogger()
 	processor := NewDomainCustomHtmlCcamProcessor("domain_custom_html_ccam", "blocklist")
-	valid, invalid := processor.Process(logger, sampleContent)
+	valid, invalid := processor.Process(context.Background(), logger, sampleContent)
 
 	expected := []string{
 		"aspmailcenter2.com",
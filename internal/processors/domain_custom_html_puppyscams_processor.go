@@ -1,17 +1,22 @@
 package processors
 
 import (
-	"bytes"
-	"sort"
-	"strings"
+	"context"
 
-	"github.com/PuerkitoBio/goquery"
-	"github.com/phani-kb/dns-toolkit/internal/utils"
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
 	"github.com/phani-kb/multilog"
 )
 
 const sourceTypeDomainCustomHtmlPuppyScams = "domain_custom_html_puppyscams"
 
+// puppyScamsSelection is the html_selector spec of the domains of the PuppyScams links
+var puppyScamsSelection = mustHtmlSelection(c.ProcessorSpec{
+	Kind:     constants.ProcessorKindHtml,
+	Selector: "a",
+	Extract:  constants.HtmlExtractDomain,
+})
+
 // DomainCustomHtmlPuppyScamsProcessor implements a processor for extracting domain names
 // from HTML content, specifically focusing on PuppyScams.
 type DomainCustomHtmlPuppyScamsProcessor struct {
@@ -37,44 +42,12 @@ func NewDomainCustomHtmlPuppyScamsProcessor(sourceType, listType string) *Domain
 // Returns:
 //   - A slice of valid domain names found in the content, sorted alphabetically
 //   - A slice of invalid entries that couldn't be parsed as domains
-func (p *DomainCustomHtmlPuppyScamsProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
-	var validEntries, invalidEntries []string
-	uniqueDomains := make(map[string]struct{})
-
-	// Parse HTML using goquery
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader([]byte(content)))
-	if err != nil {
-		logger.Errorf("Error parsing HTML content: %v", err)
-		return validEntries, invalidEntries
-	}
-
-	// Helper function to process domain text
-	processDomain := func(text string) {
-		domain := strings.ToLower(strings.TrimSpace(text))
-		if domain == "" {
-			return
-		}
-		if utils.IsDomain(domain) {
-			if _, exists := uniqueDomains[domain]; !exists {
-				uniqueDomains[domain] = struct{}{}
-			}
-		} else {
-			invalidEntries = append(invalidEntries, domain)
-		}
-	}
-
-	// Iterate over all <a> tags and process text
-	doc.Find("a").Each(func(i int, s *goquery.Selection) {
-		processDomain(s.Text())
-	})
-
-	// Convert map keys to sorted slice
-	for domain := range uniqueDomains {
-		validEntries = append(validEntries, domain)
-	}
-	sort.Strings(validEntries)
-
-	return validEntries, invalidEntries
+func (p *DomainCustomHtmlPuppyScamsProcessor) Process(
+	_ context.Context,
+	logger *multilog.Logger,
+	content string,
+) ([]string, []string) {
+	return processHtmlSelection(logger, puppyScamsSelection, content)
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -11,7 +12,7 @@ func TestDomainCustomHtmlPuppyScamsProcessor_Process(t *testing.T) {
 
 	logger := multilog.NewLogger()
 	processor := NewDomainCustomHtmlPuppyScamsProcessor("domain_custom_html_puppyscams", "blocklist")
-	valid, invalid := processor.Process(logger, sampleContent)
+	valid, invalid := processor.Process(context.Background(), logger, sampleContent)
 
 	expectedValid := []string{"example.com", "fake-pets.net"}
 	if len(valid) != len(expectedValid) {
@@ -32,7 +33,7 @@ func TestDomainCustomHtmlPuppyScamsProcessor_Process(t *testing.T) {
 func TestDomainCustomHtmlPuppyScamsProcessor_ProcessEmpty(t *testing.T) {
 	logger := multilog.NewLogger()
 	processor := NewDomainCustomHtmlPuppyScamsProcessor("domain_custom_html_puppyscams", "blocklist")
-	valid, invalid := processor.Process(logger, "")
+	valid, invalid := processor.Process(context.Background(), logger, "")
 
 	if len(valid) != 0 || len(invalid) != 0 {
 		t.Errorf("expected empty results for empty content, got valid: %v, invalid: %v", valid, invalid)
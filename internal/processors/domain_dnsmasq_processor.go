@@ -0,0 +1,110 @@
+package processors
+
+import (
+	"context"
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+const sourceTypeDomainDnsmasq = "domain_dnsmasq"
+
+// DomainDnsmasqProcessor extracts the blocked domains of dnsmasq configuration lines:
+// address=/example.com/0.0.0.0, address=/example.com/, server=/example.com/ and local=/example.com/.
+// dnsmasq applies these lines to the subdomains of the domains as well.
+type DomainDnsmasqProcessor struct {
+	BaseProcessor
+}
+
+func NewDomainDnsmasqProcessor(sourceType, listType string) *DomainDnsmasqProcessor {
+	return &DomainDnsmasqProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+	}
+}
+
+func (p *DomainDnsmasqProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	return EachLine(ctx, reader, func(line string) error {
+		domains, rejections := parseDnsmasqLine(line)
+		for _, domain := range domains {
+			if err := sink.AddValid(domain); err != nil {
+				return err
+			}
+		}
+		if len(rejections) > 0 {
+			return rejectLine(sink, line, rejections...)
+		}
+		return nil
+	})
+}
+
+func (p *DomainDnsmasqProcessor) Process(
+	ctx context.Context,
+	logger *multilog.Logger,
+	content string,
+) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
+
+func (p *DomainDnsmasqProcessor) MatchSemantics() string {
+	return constants.MatchSemanticsSubdomains
+}
+
+// parseDnsmasqLine returns the blocked domains of a dnsmasq line and why its other parts were rejected.
+func parseDnsmasqLine(line string) ([]string, []lineRejection) {
+	option, value, found := strings.Cut(line, "=")
+	if !found {
+		return nil, []lineRejection{{constants.InvalidReasonSyntax, "not a dnsmasq option"}}
+	}
+	option = strings.TrimPrefix(strings.TrimSpace(option), "--")
+	switch option {
+	case "address", "server", "local":
+	default:
+		return nil, []lineRejection{{constants.InvalidReasonUnsupported, "unsupported option " + option}}
+	}
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "/") || strings.Count(value, "/") < 2 {
+		return nil, []lineRejection{{constants.InvalidReasonSyntax, "missing /domain/"}}
+	}
+	lastSlash := strings.LastIndex(value, "/")
+	names := strings.Split(value[1:lastSlash], "/")
+	if target := value[lastSlash+1:]; target != "" {
+		if option != "address" {
+			return nil, []lineRejection{{constants.InvalidReasonNonSinkhole, "forwarded to " + target}}
+		}
+		addr, err := netip.ParseAddr(target)
+		if err != nil {
+			return nil, []lineRejection{{constants.InvalidReasonInvalidIP, "invalid address " + target}}
+		}
+		if !addr.IsUnspecified() && !addr.IsLoopback() {
+			return nil, []lineRejection{{constants.InvalidReasonNonSinkhole, "non-sinkhole address " + addr.String()}}
+		}
+	}
+
+	var domains []string
+	var rejections []lineRejection
+	for _, name := range names {
+		domain := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(name, "*"), "."))
+		if u.IsDomain(domain) {
+			domains = append(domains, domain)
+		} else {
+			rejections = append(rejections, entryRejection(constants.SourceTypeDomain, domain, "invalid domain "+name))
+		}
+	}
+	return domains, rejections
+}
+
+func init() {
+	RegisterProcessor(sourceTypeDomainDnsmasq, func(st string, lt string) Processor {
+		return NewDomainDnsmasqProcessor(st, lt)
+	})
+}
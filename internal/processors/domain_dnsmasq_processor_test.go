@@ -0,0 +1,84 @@
+package processors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDomainDnsmasqProcessor(t *testing.T) {
+	t.Parallel()
+
+	processor := processors.NewDomainDnsmasqProcessor("domain_dnsmasq", "blocklist")
+
+	assert.Equal(t, "domain_dnsmasq", processor.GetSourceType())
+	assert.Equal(t, "blocklist", processor.GetListType())
+	assert.Equal(t, constants.MatchSemanticsSubdomains, processor.MatchSemantics())
+
+	registered, exists := processors.Processors.GetProcessor("domain_dnsmasq", "blocklist")
+	assert.True(t, exists)
+	assert.IsType(t, &processors.DomainDnsmasqProcessor{}, registered)
+}
+
+func TestDomainDnsmasqProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewDomainDnsmasqProcessor("domain_dnsmasq", "blocklist")
+
+	tests := []struct {
+		name            string
+		content         string
+		expectedValid   []string
+		expectedInvalid []string
+	}{
+		{
+			name: "blocking lines",
+			content: "# dnsmasq list\naddress=/ads.example.com/0.0.0.0\naddress=/Tracker.Example.com/\n" +
+				"server=/malware.example.net/\nlocal=/phishing.example.org/\naddress=/v6.example.com/::\n" +
+				"address=/loop.example.com/127.0.0.1",
+			expectedValid: []string{
+				"ads.example.com",
+				"tracker.example.com",
+				"malware.example.net",
+				"phishing.example.org",
+				"v6.example.com",
+				"loop.example.com",
+			},
+		},
+		{
+			name:          "several domains and wildcards",
+			content:       "address=/a.example.com/b.example.com/0.0.0.0\naddress=/*.c.example.com/\nserver=/.d.example.com/",
+			expectedValid: []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"},
+		},
+		{
+			name: "rejected lines",
+			content: "server=/corp.example.com/10.0.0.1\naddress=/redirect.example.com/203.0.113.7\n" +
+				"address=/bad.example.com/not-an-ip\naddress=/#/\nno-resolv\ncache-size=1000\naddress=example.com\n" +
+				"address=/good.example.com/bad..domain/",
+			expectedValid: []string{"good.example.com"},
+			expectedInvalid: []string{
+				"server=/corp.example.com/10.0.0.1 # forwarded to 10.0.0.1",
+				"address=/redirect.example.com/203.0.113.7 # non-sinkhole address 203.0.113.7",
+				"address=/bad.example.com/not-an-ip # invalid address not-an-ip",
+				"address=/#/ # invalid domain #",
+				"no-resolv # not a dnsmasq option",
+				"cache-size=1000 # unsupported option cache-size",
+				"address=example.com # missing /domain/",
+				"address=/good.example.com/bad..domain/ # invalid domain bad..domain",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
+			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
+			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"slices"
 	"strings"
@@ -41,7 +42,7 @@ func NewDomainHttpUrlProcessor(sourceType, listType string) *DomainHttpUrlProces
 // Returns:
 //   - A slice of valid domain names found in the content
 //   - A slice of invalid entries that couldn't be parsed
-func (p *DomainHttpUrlProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *DomainHttpUrlProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
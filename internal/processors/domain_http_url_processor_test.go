@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -50,7 +51,7 @@ func TestDomainHttpUrlProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, gotInv := processor.Process(logger, tt.input)
+			got, gotInv := processor.Process(context.Background(), logger, tt.input)
 			if !equalUnordered(got, tt.want) {
 				t.Errorf("valid entries: got %v, want %v", got, tt.want)
 			}
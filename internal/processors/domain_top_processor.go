@@ -2,12 +2,8 @@ package processors
 
 import (
 	"regexp"
-	"strings"
-
-	"github.com/phani-kb/multilog"
 
 	"github.com/phani-kb/dns-toolkit/internal/constants"
-	"github.com/phani-kb/dns-toolkit/internal/utils"
 )
 
 const sourceTypeTopDomain = "domain_top"
@@ -16,36 +12,25 @@ var supportedTopDomainListTypes = []string{
 	constants.ListTypeAllowlist,
 }
 
+// topDomainRegex matches a rank,domain line of a top domains list
+var topDomainRegex = regexp.MustCompile(`^\d+,\s*(([a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,})$`)
+
 type DomainTopProcessor struct {
-	BaseProcessor
+	LineProcessor
 }
 
 func NewDomainTopProcessor(sourceType, listType string) *DomainTopProcessor {
 	return &DomainTopProcessor{
-		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		LineProcessor: *NewLineProcessor(sourceType, listType, topDomainLine),
 	}
 }
 
-func (p *DomainTopProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
-	var validEntries, invalidEntries []string
-	lines := strings.Split(content, "\n")
-
-	domainRegex := regexp.MustCompile(`^\d+,\s*(([a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,})$`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if utils.IsComment(line) {
-			continue
-		}
-
-		if match := domainRegex.FindStringSubmatch(line); len(match) > 0 {
-			validEntries = append(validEntries, match[1])
-		} else {
-			invalidEntries = append(invalidEntries, line)
-		}
+// topDomainLine returns the domain of a rank,domain line.
+func topDomainLine(line string) (string, string) {
+	if match := topDomainRegex.FindStringSubmatch(line); len(match) > 0 {
+		return match[1], ""
 	}
-
-	return validEntries, invalidEntries
+	return "", line
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -161,7 +162,7 @@ func TestDomainTopProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -195,7 +196,7 @@ invalid_entry_without_rank
 		"invalid_entry_without_rank", "10, invalid..domain",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected for realistic data")
 	assert.Equal(t, expectedInvalid, invalid, "Invalid entries should match expected for realistic data")
 }
@@ -256,7 +257,7 @@ func TestDomainTopProcessor_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected for edge case: %s", tt.name)
 			assert.Equal(
 				t,
@@ -0,0 +1,107 @@
+package processors
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+const sourceTypeDomainUnbound = "domain_unbound"
+
+// unboundBlockingZoneTypes are the local-zone types that answer the queries for the zone and its subdomains
+// without resolving them
+var unboundBlockingZoneTypes = map[string]bool{
+	"always_deny":     true,
+	"always_null":     true,
+	"always_nxdomain": true,
+	"always_refuse":   true,
+	"deny":            true,
+	"inform_deny":     true,
+	"redirect":        true,
+	"refuse":          true,
+	"static":          true,
+}
+
+// DomainUnboundProcessor extracts the blocked domains of Unbound local-zone lines,
+// such as local-zone: "example.com" always_nxdomain. A blocking local zone also covers the subdomains.
+type DomainUnboundProcessor struct {
+	BaseProcessor
+}
+
+func NewDomainUnboundProcessor(sourceType, listType string) *DomainUnboundProcessor {
+	return &DomainUnboundProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+	}
+}
+
+func (p *DomainUnboundProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	return EachLine(ctx, reader, func(line string) error {
+		domain, rejection := parseUnboundLine(line)
+		if rejection != nil {
+			return rejectLine(sink, line, *rejection)
+		}
+		if domain != "" {
+			return sink.AddValid(domain)
+		}
+		return nil
+	})
+}
+
+func (p *DomainUnboundProcessor) Process(
+	ctx context.Context,
+	logger *multilog.Logger,
+	content string,
+) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
+
+func (p *DomainUnboundProcessor) MatchSemantics() string {
+	return constants.MatchSemanticsSubdomains
+}
+
+// parseUnboundLine returns the domain of a blocking local-zone line, or why the line was rejected.
+// The server: clause header has neither.
+func parseUnboundLine(line string) (string, *lineRejection) {
+	name, value, found := strings.Cut(line, ":")
+	if !found {
+		return "", &lineRejection{constants.InvalidReasonSyntax, "not an unbound option"}
+	}
+	switch strings.TrimSpace(name) {
+	case "server":
+		return "", nil
+	case "local-zone":
+	default:
+		return "", &lineRejection{constants.InvalidReasonUnsupported, "unsupported option " + strings.TrimSpace(name)}
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return "", &lineRejection{constants.InvalidReasonSyntax, "expected a zone and a type"}
+	}
+	zoneType := strings.ToLower(fields[1])
+	if !unboundBlockingZoneTypes[zoneType] {
+		return "", &lineRejection{constants.InvalidReasonUnsupported, "non-blocking zone type " + zoneType}
+	}
+	domain := strings.ToLower(strings.TrimSuffix(strings.Trim(fields[0], `"`), "."))
+	if !u.IsDomain(domain) {
+		rejection := entryRejection(constants.SourceTypeDomain, domain, "invalid domain "+fields[0])
+		return "", &rejection
+	}
+	return domain, nil
+}
+
+func init() {
+	RegisterProcessor(sourceTypeDomainUnbound, func(st string, lt string) Processor {
+		return NewDomainUnboundProcessor(st, lt)
+	})
+}
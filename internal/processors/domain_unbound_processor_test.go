@@ -0,0 +1,66 @@
+package processors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDomainUnboundProcessor(t *testing.T) {
+	t.Parallel()
+
+	processor := processors.NewDomainUnboundProcessor("domain_unbound", "blocklist")
+
+	assert.Equal(t, "domain_unbound", processor.GetSourceType())
+	assert.Equal(t, "blocklist", processor.GetListType())
+	assert.Equal(t, constants.MatchSemanticsSubdomains, processor.MatchSemantics())
+
+	registered, exists := processors.Processors.GetProcessor("domain_unbound", "blocklist")
+	assert.True(t, exists)
+	assert.IsType(t, &processors.DomainUnboundProcessor{}, registered)
+}
+
+func TestDomainUnboundProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewDomainUnboundProcessor("domain_unbound", "blocklist")
+
+	tests := []struct {
+		name            string
+		content         string
+		expectedValid   []string
+		expectedInvalid []string
+	}{
+		{
+			name: "blocking zones",
+			content: "server:\n  local-zone: \"ads.example.com\" always_nxdomain\nlocal-zone: \"Tracker.Example.com.\" static\n" +
+				"local-zone: malware.example.net always_null\n\tlocal-zone: \"refused.example.org\" ALWAYS_REFUSE",
+			expectedValid: []string{"ads.example.com", "tracker.example.com", "malware.example.net", "refused.example.org"},
+		},
+		{
+			name: "rejected lines",
+			content: "local-zone: \"corp.example.com\" transparent\nlocal-zone: \"bad..example.com\" always_nxdomain\n" +
+				"local-zone: \"missing-type.example.com\"\nlocal-data: \"ads.example.com A 0.0.0.0\"\nnot an option",
+			expectedInvalid: []string{
+				"local-zone: \"corp.example.com\" transparent # non-blocking zone type transparent",
+				"local-zone: \"bad..example.com\" always_nxdomain # invalid domain \"bad..example.com\"",
+				"local-zone: \"missing-type.example.com\" # expected a zone and a type",
+				"local-data: \"ads.example.com A 0.0.0.0\" # unsupported option local-data",
+				"not an option # not an unbound option",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
+			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
+			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
+		})
+	}
+}
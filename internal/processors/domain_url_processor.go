@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,7 @@ func NewDomainUrlProcessor(sourceType, listType string) *DomainUrlProcessor {
 	}
 }
 
-func (p *DomainUrlProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *DomainUrlProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
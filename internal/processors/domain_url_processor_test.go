@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -71,7 +72,7 @@ beasdgadi.ga/chffud/PvfffsaqDq929BSx_A_D_M1n_a.php`,
 	processor := processors.NewDomainUrlProcessor("domain_url", "block")
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			valid, invalid := processor.Process(nil, tc.input)
+			valid, invalid := processor.Process(context.Background(), nil, tc.input)
 			if len(valid) != len(tc.expectedValid) {
 				t.Errorf("expected valid %v, got %v", tc.expectedValid, valid)
 			}
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -26,7 +27,11 @@ func NewDomainWithCommentSuffixProcessor(sourceType, listType string) *DomainWit
 	}
 }
 
-func (p *DomainWithCommentSuffixProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *DomainWithCommentSuffixProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
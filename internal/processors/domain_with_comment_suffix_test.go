@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -54,7 +55,7 @@ func TestDomainWithCommentSuffixProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -98,7 +99,7 @@ mock-tags.tiqcdn-example.com                               #Tracker     Breaks D
 		"mock-tags.tiqcdn-example.com",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid antivirus domains should match expected")
 	assert.Empty(t, invalid, "No invalid entries expected for valid antivirus format")
@@ -150,7 +151,7 @@ mock-de.software-example.de                       #Software N    German domain`
 		".invalid.start.com #Software F Invalid start (invalid)",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected")
 	assert.Equal(t, expectedInvalid, invalid, "Invalid entries should match expected")
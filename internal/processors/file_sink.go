@@ -0,0 +1,418 @@
+package processors
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+// entryFile writes the entries it receives to a file, sorted and without duplicates.
+// The entries are sorted in chunks of at most chunkSize entries, each written to a hidden temporary run
+// file next to its path, and the runs are merged into the file when it is committed. The memory used
+// is bounded by the chunk size instead of growing with the number of entries; the merge keeps one
+// buffered reader per run open, a run for every chunkSize entries received.
+type entryFile struct {
+	path      string
+	chunkSize int
+	diagnose  func(entry string) string // reason code of an invalid entry received without one
+	chunk     []sinkRecord
+	run       *os.File // run file of the current chunk
+	runs      []string // paths of the written run files, in the order they were received
+	count     int
+	reasons   map[string]int
+	samples   map[string][]string
+}
+
+// sinkRecord is an entry with the reason code it was rejected with, if any
+type sinkRecord struct {
+	entry  string
+	reason string
+}
+
+// runRecordSeparator separates an entry from its reason code in a run file.
+// Reason codes never contain it, so the last one in a line is the separator.
+const runRecordSeparator = "\x00"
+
+func newEntryFile(path string, diagnose func(entry string) string) *entryFile {
+	return &entryFile{path: path, chunkSize: constants.SinkChunkEntries, diagnose: diagnose}
+}
+
+func (f *entryFile) add(entry string) error {
+	return f.addReason(entry, "")
+}
+
+// addReason adds an entry with the reason code it was rejected with.
+// The reason of a duplicate entry is that of the entry received first.
+func (f *entryFile) addReason(entry, reason string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+	if f.run == nil {
+		if err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+			return err
+		}
+		run, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+"-run-")
+		if err != nil {
+			return err
+		}
+		f.run = run
+		f.runs = append(f.runs, run.Name())
+	}
+	f.chunk = append(f.chunk, sinkRecord{entry: entry, reason: reason})
+	if len(f.chunk) >= f.chunkSize {
+		return f.writeRun()
+	}
+	return nil
+}
+
+// writeRun writes the current chunk, sorted and without duplicates, to its run file.
+func (f *entryFile) writeRun() error {
+	if f.run == nil {
+		return nil
+	}
+	sort.SliceStable(f.chunk, func(i, j int) bool { return f.chunk[i].entry < f.chunk[j].entry })
+	writer := bufio.NewWriter(f.run)
+	var err error
+	for i, record := range f.chunk {
+		if i > 0 && record.entry == f.chunk[i-1].entry {
+			continue
+		}
+		if _, err = writer.WriteString(record.entry + runRecordSeparator + record.reason + "\n"); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := f.run.Close(); err == nil {
+		err = closeErr
+	}
+	f.run = nil
+	f.chunk = f.chunk[:0]
+	return err
+}
+
+// runReader reads the records of a run file in order
+type runReader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	record  sinkRecord
+	index   int // position of the run in the order the entries were received
+}
+
+func (r *runReader) next() bool {
+	if !r.scanner.Scan() {
+		return false
+	}
+	line := r.scanner.Text()
+	separator := strings.LastIndex(line, runRecordSeparator)
+	r.record = sinkRecord{entry: line[:separator], reason: line[separator+1:]}
+	return true
+}
+
+// runHeap orders the run readers by their current entry, then by the order the runs were received
+type runHeap []*runReader
+
+func (h runHeap) Len() int { return len(h) }
+func (h runHeap) Less(i, j int) bool {
+	if h[i].record.entry != h[j].record.entry {
+		return h[i].record.entry < h[j].record.entry
+	}
+	return h[i].index < h[j].index
+}
+func (h runHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)   { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	reader := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return reader
+}
+
+// merge merges the runs into writer, skipping duplicate entries, and records the reason codes
+// of the entries when the file diagnoses them.
+func (f *entryFile) merge(writer *bufio.Writer) error {
+	readers := make(runHeap, 0, len(f.runs))
+	defer func() {
+		for _, reader := range readers {
+			_ = reader.file.Close()
+		}
+	}()
+	for i, runPath := range f.runs {
+		file, err := os.Open(runPath)
+		if err != nil {
+			return err
+		}
+		reader := &runReader{file: file, scanner: bufio.NewScanner(file), index: i}
+		reader.scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		readers = append(readers, reader)
+	}
+	merging := make(runHeap, 0, len(readers))
+	for _, reader := range readers {
+		if reader.next() {
+			merging = append(merging, reader)
+		} else if err := reader.scanner.Err(); err != nil {
+			return err
+		}
+	}
+	heap.Init(&merging)
+
+	previous := ""
+	for merging.Len() > 0 {
+		reader := merging[0]
+		record := reader.record
+		if reader.next() {
+			heap.Fix(&merging, 0)
+		} else {
+			if err := reader.scanner.Err(); err != nil {
+				return err
+			}
+			heap.Pop(&merging)
+		}
+		if f.count > 0 && record.entry == previous {
+			continue
+		}
+		previous = record.entry
+		f.count++
+		if _, err := writer.WriteString(record.entry + "\n"); err != nil {
+			return err
+		}
+		if f.diagnose != nil {
+			f.addInvalidReason(record)
+		}
+	}
+	return nil
+}
+
+// addInvalidReason counts the reason code of an invalid entry and keeps the first entries of each code.
+func (f *entryFile) addInvalidReason(record sinkRecord) {
+	reason := record.reason
+	if reason == "" {
+		reason = f.diagnose(record.entry)
+	}
+	if f.reasons == nil {
+		f.reasons = make(map[string]int)
+		f.samples = make(map[string][]string)
+	}
+	f.reasons[reason]++
+	if len(f.samples[reason]) < constants.InvalidReasonSamples {
+		f.samples[reason] = append(f.samples[reason], record.entry)
+	}
+}
+
+// commit merges the runs into a temporary file, moves it into place and returns the number of entries
+// written. Nothing is written for a file without entries.
+func (f *entryFile) commit() (int, error) {
+	if len(f.runs) == 0 {
+		return 0, nil
+	}
+	defer f.removeRuns(nil)
+	if err := f.writeRun(); err != nil {
+		return 0, err
+	}
+	file, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+"-")
+	if err != nil {
+		return 0, err
+	}
+	tempPath := file.Name()
+	writer := bufio.NewWriter(file)
+	err = f.merge(writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, f.path)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return 0, err
+	}
+	return f.count, nil
+}
+
+// invalidReasons returns the number of entries written by reason code and the first entries of each code
+// in sorted order.
+func (f *entryFile) invalidReasons() (map[string]int, map[string][]string) {
+	return f.reasons, f.samples
+}
+
+// removeRuns removes the run files that have been written.
+func (f *entryFile) removeRuns(logger *multilog.Logger) {
+	if f.run != nil {
+		if err := f.run.Close(); err != nil && logger != nil {
+			logger.Debugf("Closing temporary file error: %v (file: %s)", err, f.run.Name())
+		}
+		f.run = nil
+	}
+	for _, runPath := range f.runs {
+		if err := os.Remove(runPath); err != nil && !os.IsNotExist(err) && logger != nil {
+			logger.Warnf("Removing temporary file error: %v (file: %s)", err, runPath)
+		}
+	}
+	f.runs = nil
+	f.chunk = nil
+}
+
+func (f *entryFile) discard(logger *multilog.Logger) {
+	f.removeRuns(logger)
+}
+
+// FileSink is an EntrySink that writes the valid and invalid entries to their processed files,
+// sorted and skipping blank and duplicate entries, without holding every entry in memory.
+// The files are only replaced when the sink is committed.
+type FileSink struct {
+	valid   *entryFile
+	invalid *entryFile
+}
+
+// NewFileSink creates a new sink writing to the valid and invalid files at the given paths.
+// The invalid entries received without a reason code are diagnosed as entries of the generic source type.
+func NewFileSink(validPath, invalidPath, genericSourceType string) *FileSink {
+	return &FileSink{
+		valid:   newEntryFile(validPath, nil),
+		invalid: newEntryFile(invalidPath, invalidEntryDiagnosis(genericSourceType)),
+	}
+}
+
+// invalidEntryDiagnosis returns the diagnosis of the invalid entries of the generic source type
+func invalidEntryDiagnosis(genericSourceType string) func(entry string) string {
+	return func(entry string) string {
+		return u.InvalidEntryReason(genericSourceType, entry)
+	}
+}
+
+func (s *FileSink) AddValid(entry string) error {
+	return s.valid.add(entry)
+}
+
+func (s *FileSink) AddInvalid(entry string) error {
+	return s.invalid.add(entry)
+}
+
+func (s *FileSink) AddInvalidReason(entry, reason string) error {
+	return s.invalid.addReason(entry, reason)
+}
+
+// Commit moves the written files into place and returns the number of valid and invalid entries.
+// A file without entries is not created.
+func (s *FileSink) Commit(logger *multilog.Logger) (int, int, error) {
+	validCount, err := s.valid.commit()
+	if err != nil {
+		s.invalid.discard(logger)
+		return 0, 0, err
+	}
+	invalidCount, err := s.invalid.commit()
+	if err != nil {
+		return validCount, 0, err
+	}
+	return validCount, invalidCount, nil
+}
+
+// InvalidReasons returns the number of committed invalid entries by reason code and the first entries
+// of each code.
+func (s *FileSink) InvalidReasons() (map[string]int, map[string][]string) {
+	return s.invalid.invalidReasons()
+}
+
+// Discard removes the written files, leaving the processed files of a previous run in place.
+func (s *FileSink) Discard(logger *multilog.Logger) {
+	s.valid.discard(logger)
+	s.invalid.discard(logger)
+}
+
+// TypedFileSink is a TypedEntrySink that writes the valid entries of each generic source type
+// to their own processed file and the invalid entries to a single file.
+// The files are only replaced when the sink is committed.
+type TypedFileSink struct {
+	valid   map[string]*entryFile
+	invalid *entryFile
+}
+
+// NewTypedFileSink creates a new sink writing the valid entries to the files at validPaths,
+// keyed by generic source type, and the invalid entries to the file at invalidPath.
+// The invalid entries received without a reason code are diagnosed as entries of the generic source type.
+func NewTypedFileSink(validPaths map[string]string, invalidPath, genericSourceType string) *TypedFileSink {
+	valid := make(map[string]*entryFile, len(validPaths))
+	for sourceType, path := range validPaths {
+		valid[sourceType] = newEntryFile(path, nil)
+	}
+	return &TypedFileSink{
+		valid:   valid,
+		invalid: newEntryFile(invalidPath, invalidEntryDiagnosis(genericSourceType)),
+	}
+}
+
+func (s *TypedFileSink) AddValid(entry string) error {
+	return fmt.Errorf("valid entry without a source type: %s", entry)
+}
+
+func (s *TypedFileSink) AddTypedValid(sourceType, entry string) error {
+	file, exists := s.valid[sourceType]
+	if !exists {
+		return fmt.Errorf("no file for %s entries: %s", sourceType, entry)
+	}
+	return file.add(entry)
+}
+
+func (s *TypedFileSink) AddInvalid(entry string) error {
+	return s.invalid.add(entry)
+}
+
+func (s *TypedFileSink) AddInvalidReason(entry, reason string) error {
+	return s.invalid.addReason(entry, reason)
+}
+
+// Commit moves the written files into place and returns the number of valid entries by source type
+// and the number of invalid entries. A file without entries is not created.
+func (s *TypedFileSink) Commit(logger *multilog.Logger) (map[string]int, int, error) {
+	sourceTypes := make([]string, 0, len(s.valid))
+	for sourceType := range s.valid {
+		sourceTypes = append(sourceTypes, sourceType)
+	}
+	sort.Strings(sourceTypes)
+
+	validCounts := make(map[string]int, len(sourceTypes))
+	for _, sourceType := range sourceTypes {
+		count, err := s.valid[sourceType].commit()
+		if err != nil {
+			s.Discard(logger)
+			return nil, 0, err
+		}
+		validCounts[sourceType] = count
+	}
+	invalidCount, err := s.invalid.commit()
+	if err != nil {
+		return validCounts, 0, err
+	}
+	return validCounts, invalidCount, nil
+}
+
+// InvalidReasons returns the number of committed invalid entries by reason code and the first entries
+// of each code.
+func (s *TypedFileSink) InvalidReasons() (map[string]int, map[string][]string) {
+	return s.invalid.invalidReasons()
+}
+
+// Discard removes the written files that have not been committed yet.
+func (s *TypedFileSink) Discard(logger *multilog.Logger) {
+	for _, file := range s.valid {
+		file.discard(logger)
+	}
+	s.invalid.discard(logger)
+}
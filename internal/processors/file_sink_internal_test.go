@@ -0,0 +1,66 @@
+package processors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryFile_BoundedChunks(t *testing.T) {
+	t.Parallel()
+
+	folder := t.TempDir()
+	path := filepath.Join(folder, "invalid.txt")
+	file := newEntryFile(path, invalidEntryDiagnosis(constants.SourceTypeDomain))
+	file.chunkSize = 3
+
+	var expected []string
+	for i := 9; i >= 0; i-- {
+		entry := fmt.Sprintf("host%d", i)
+		expected = append(expected, entry)
+		require.NoError(t, file.addReason(entry, constants.InvalidReasonSyntax))
+		require.NoError(t, file.add(entry), "a duplicate entry keeps the reason of the first one")
+		assert.Less(t, len(file.chunk), file.chunkSize, "the entries in memory are bounded by the chunk size")
+	}
+	assert.Len(t, file.runs, 7, "the entries are written to a run file for every chunk")
+
+	count, err := file.commit()
+	require.NoError(t, err)
+	assert.Equal(t, 10, count)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	sort.Strings(expected)
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", string(content), "the runs are merged in sorted order")
+
+	reasons, samples := file.invalidReasons()
+	assert.Equal(t, map[string]int{constants.InvalidReasonSyntax: 10}, reasons)
+	assert.Equal(t, expected[:constants.InvalidReasonSamples], samples[constants.InvalidReasonSyntax])
+
+	entries, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the run files are removed")
+}
+
+func TestEntryFile_Discard(t *testing.T) {
+	t.Parallel()
+
+	folder := t.TempDir()
+	file := newEntryFile(filepath.Join(folder, "valid.txt"), nil)
+	file.chunkSize = 2
+	for _, entry := range []string{"c.com", "b.com", "a.com"} {
+		require.NoError(t, file.add(entry))
+	}
+	file.discard(multilog.NewLogger())
+
+	entries, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the run files are removed")
+}
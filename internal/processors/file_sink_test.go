@@ -0,0 +1,220 @@
+package processors_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		name            string
+		valid           []string
+		invalid         []string
+		folder          string
+		expectedValid   string
+		expectedInvalid string
+	}{
+		{
+			name:            "valid and invalid entries",
+			valid:           []string{"example.com", "test.org", "sample.net"},
+			invalid:         []string{"invalid1", "invalid2"},
+			expectedValid:   "example.com\nsample.net\ntest.org\n",
+			expectedInvalid: "invalid1\ninvalid2\n",
+		},
+		{
+			name:          "only valid entries",
+			valid:         []string{"valid.com"},
+			expectedValid: "valid.com\n",
+		},
+		{
+			name: "no entries",
+		},
+		{
+			name:          "duplicate and blank entries",
+			valid:         []string{"zebra.com", "alpha.com", " ", "beta.org", "alpha.com ", ""},
+			expectedValid: "alpha.com\nbeta.org\nzebra.com\n",
+		},
+		{
+			name:          "nested folder",
+			valid:         []string{"nested.com"},
+			folder:        filepath.Join("level1", "level2"),
+			expectedValid: "nested.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			folder := filepath.Join(t.TempDir(), tt.folder)
+			validPath := filepath.Join(folder, "valid.txt")
+			invalidPath := filepath.Join(folder, "invalid.txt")
+
+			sink := processors.NewFileSink(validPath, invalidPath, constants.SourceTypeDomain)
+			for _, entry := range tt.valid {
+				require.NoError(t, sink.AddValid(entry))
+			}
+			for _, entry := range tt.invalid {
+				require.NoError(t, sink.AddInvalid(entry))
+			}
+			_, err := os.Stat(validPath)
+			assert.True(t, os.IsNotExist(err), "the file is only created when the sink is committed")
+
+			validCount, invalidCount, err := sink.Commit(logger)
+			require.NoError(t, err)
+			for _, file := range []struct {
+				path     string
+				count    int
+				expected string
+			}{
+				{path: validPath, count: validCount, expected: tt.expectedValid},
+				{path: invalidPath, count: invalidCount, expected: tt.expectedInvalid},
+			} {
+				content, err := os.ReadFile(file.path)
+				if file.expected == "" {
+					assert.Zero(t, file.count)
+					assert.True(t, os.IsNotExist(err), "a file without entries is not created")
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, file.expected, string(content))
+				assert.Equal(t, strings.Count(file.expected, "\n"), file.count)
+			}
+			entries, _ := os.ReadDir(folder)
+			assert.LessOrEqual(t, len(entries), 2, "no temporary files are left")
+		})
+	}
+}
+
+func TestFileSink_InvalidReasons(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	folder := t.TempDir()
+	sink := processors.NewFileSink(
+		filepath.Join(folder, "valid.txt"),
+		filepath.Join(folder, "invalid.txt"),
+		constants.SourceTypeDomain,
+	)
+	for _, entry := range []string{"localhost", "example.com:8080", "192.0.2.1", "localhost", "a.com # blocked"} {
+		require.NoError(t, sink.AddInvalid(entry))
+	}
+	require.NoError(t, sink.AddInvalidReason("1.2.3.4 ads.example.com # non-sinkhole", constants.InvalidReasonNonSinkhole))
+	require.NoError(t, sink.AddInvalidReason("localhost", constants.InvalidReasonSyntax))
+	_, invalidCount, err := sink.Commit(logger)
+	require.NoError(t, err)
+	assert.Equal(t, 5, invalidCount)
+	reasons, samples := sink.InvalidReasons()
+	assert.Equal(t, map[string]int{
+		constants.InvalidReasonSingleLabel:    1,
+		constants.InvalidReasonPort:           1,
+		constants.InvalidReasonIPInDomainList: 1,
+		constants.InvalidReasonProcessor:      1,
+		constants.InvalidReasonNonSinkhole:    1,
+	}, reasons, "duplicate entries are counted once, with the reason of the first one")
+	assert.Equal(t, []string{"1.2.3.4 ads.example.com # non-sinkhole"}, samples[constants.InvalidReasonNonSinkhole])
+	assert.Equal(t, []string{"localhost"}, samples[constants.InvalidReasonSingleLabel])
+}
+
+func TestFileSink_Discard(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	folder := t.TempDir()
+	validPath := filepath.Join(folder, "valid.txt")
+	require.NoError(t, os.WriteFile(validPath, []byte("previous.com\n"), 0644))
+
+	sink := processors.NewFileSink(validPath, filepath.Join(folder, "invalid.txt"), constants.SourceTypeDomain)
+	require.NoError(t, sink.AddValid("new.com"))
+	require.NoError(t, sink.AddInvalid("invalid"))
+	sink.Discard(logger)
+
+	content, err := os.ReadFile(validPath)
+	require.NoError(t, err)
+	assert.Equal(t, "previous.com\n", string(content), "the previous file is kept")
+	entries, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileSink_ReadOnlyFolder(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+	folder := filepath.Join(t.TempDir(), "readonly")
+	require.NoError(t, os.MkdirAll(folder, 0555))
+	t.Cleanup(func() { _ = os.Chmod(folder, 0755) })
+
+	sink := processors.NewFileSink(
+		filepath.Join(folder, "valid.txt"),
+		filepath.Join(folder, "invalid.txt"),
+		constants.SourceTypeDomain,
+	)
+	assert.Error(t, sink.AddValid("test.com"))
+}
+
+func TestTypedFileSink(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	folder := t.TempDir()
+	paths := map[string]string{
+		"domain": filepath.Join(folder, "domain.txt"),
+		"ipv4":   filepath.Join(folder, "ipv4.txt"),
+		"ipv6":   filepath.Join(folder, "ipv6.txt"),
+	}
+	invalidPath := filepath.Join(folder, "invalid.txt")
+
+	sink := processors.NewTypedFileSink(paths, invalidPath, constants.SourceTypeDomain)
+	require.NoError(t, sink.AddTypedValid("domain", "example.com"))
+	require.NoError(t, sink.AddTypedValid("ipv4", "192.0.2.1"))
+	require.NoError(t, sink.AddTypedValid("domain", "example.com"))
+	require.NoError(t, sink.AddTypedValid("domain", "test.org"))
+	require.NoError(t, sink.AddInvalid("invalid"))
+	assert.Error(t, sink.AddTypedValid("adguard", "||example.com^"), "a type without a file is rejected")
+	assert.Error(t, sink.AddValid("example.net"), "an entry without a type is rejected")
+
+	validCounts, invalidCount, err := sink.Commit(logger)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"domain": 2, "ipv4": 1, "ipv6": 0}, validCounts)
+	assert.Equal(t, 1, invalidCount)
+
+	content, err := os.ReadFile(paths["domain"])
+	require.NoError(t, err)
+	assert.Equal(t, "example.com\ntest.org\n", string(content))
+	content, err = os.ReadFile(paths["ipv4"])
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1\n", string(content))
+	assert.NoFileExists(t, paths["ipv6"])
+	assert.FileExists(t, invalidPath)
+}
+
+func TestTypedFileSink_Discard(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	folder := t.TempDir()
+
+	sink := processors.NewTypedFileSink(map[string]string{"domain": filepath.Join(folder, "domain.txt")},
+		filepath.Join(folder, "invalid.txt"), constants.SourceTypeDomain)
+	require.NoError(t, sink.AddTypedValid("domain", "example.com"))
+	require.NoError(t, sink.AddInvalid("invalid"))
+	sink.Discard(logger)
+
+	entries, err := os.ReadDir(folder)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -1,18 +1,33 @@
 package processors
 
 import (
+	"context"
+	"io"
+	"net/netip"
 	"strings"
 
 	"github.com/phani-kb/multilog"
 
 	"github.com/phani-kb/dns-toolkit/internal/constants"
-	"github.com/phani-kb/dns-toolkit/internal/utils"
 )
 
 const sourceTypeHostname = "hostname"
 
+// localHostnames are the entries of a default hosts file, which are skipped
+var localHostnames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"0.0.0.0":               true,
+}
+
+// HostnameProcessor extracts every hostname of the lines of a hosts file.
+// A sinkhole-only processor reports the hostnames mapped to an address other than
+// an unspecified or loopback address as invalid, with the address they are mapped to.
 type HostnameProcessor struct {
 	BaseProcessor
+	sinkholeOnly bool
 }
 
 func NewHostnameProcessor(sourceType, listType string) *HostnameProcessor {
@@ -21,67 +36,102 @@ func NewHostnameProcessor(sourceType, listType string) *HostnameProcessor {
 	}
 }
 
-func (p *HostnameProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
-	var validEntries, invalidEntries []string
-	lines := strings.Split(content, "\n")
+// NewSinkholeHostnameProcessor creates a new processor for hosts files that only map hostnames to sinkhole addresses
+func NewSinkholeHostnameProcessor(sourceType, listType string) *HostnameProcessor {
+	return &HostnameProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		sinkholeOnly:  true,
+	}
+}
+
+func (p *HostnameProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	return EachLine(ctx, reader, func(line string) error {
+		hostnames, rejections := p.parseLine(line)
+		for _, hostname := range hostnames {
+			if err := sink.AddValid(hostname); err != nil {
+				return err
+			}
+		}
+		if len(rejections) > 0 {
+			return rejectLine(sink, line, rejections...)
+		}
+		return nil
+	})
+}
+
+func (p *HostnameProcessor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
 
-	// Skip empty content
-	if len(lines) == 0 {
-		return validEntries, invalidEntries
+// parseLine returns the valid hostnames of a hosts file line and why its other hostnames were rejected.
+func (p *HostnameProcessor) parseLine(line string) ([]string, []lineRejection) {
+	fields := strings.Fields(stripInlineComment(line))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	addr, err := netip.ParseAddr(fields[0])
+	if err != nil {
+		return nil, []lineRejection{{constants.InvalidReasonInvalidIP, "invalid address " + fields[0]}}
+	}
+	if len(fields) < 2 {
+		return nil, []lineRejection{{constants.InvalidReasonSyntax, "missing hostname"}}
+	}
+	if p.sinkholeOnly && !addr.IsUnspecified() && !addr.IsLoopback() {
+		return nil, []lineRejection{{constants.InvalidReasonNonSinkhole, "non-sinkhole address " + addr.String()}}
 	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if utils.IsComment(line) {
+	var hostnames []string
+	var rejections []lineRejection
+	for _, hostname := range fields[1:] {
+		name := strings.ToLower(hostname)
+		if localHostnames[name] || strings.HasPrefix(name, "ip6-") {
 			continue
 		}
+		if constants.HostnameRegex.MatchString(hostname) && len(hostname) <= constants.MaxDomainLength {
+			hostnames = append(hostnames, hostname)
+		} else {
+			rejections = append(rejections,
+				entryRejection(constants.SourceTypeDomain, hostname, "invalid hostname "+hostname))
+		}
+	}
+	return hostnames, rejections
+}
 
-		cleanLine := line
-		minIndex := len(line)
-		for _, prefix := range constants.CommentPrefixes {
-			if prefix == "--" {
-				// only match -- if it's preceded by whitespace
-				for i := 1; i < len(line); i++ {
-					if line[i-1] == ' ' || line[i-1] == '\t' {
-						if strings.HasPrefix(line[i:], "--") {
-							if i < minIndex {
-								minIndex = i
-							}
-							break
+// stripInlineComment removes a comment after the entries of a line.
+func stripInlineComment(line string) string {
+	minIndex := len(line)
+	for _, prefix := range constants.CommentPrefixes {
+		if prefix == "--" {
+			// only match -- if it's preceded by whitespace
+			for i := 1; i < len(line); i++ {
+				if line[i-1] == ' ' || line[i-1] == '\t' {
+					if strings.HasPrefix(line[i:], "--") {
+						if i < minIndex {
+							minIndex = i
 						}
+						break
 					}
 				}
-			} else {
-				if idx := strings.Index(line, prefix); idx != -1 && idx < minIndex {
-					minIndex = idx
-				}
 			}
-		}
-		if minIndex < len(line) {
-			cleanLine = strings.TrimSpace(line[:minIndex])
-		}
-
-		// Split remaining line by whitespace
-		fields := strings.Fields(cleanLine)
-		if len(fields) >= 2 {
-			// Skip IP address (fields[0]) and get domain (fields[1])
-			domain := strings.TrimSpace(fields[1])
-
-			// Validate and store domain
-			if constants.HostnameRegex.MatchString(domain) &&
-				len(domain) <= constants.MaxDomainLength {
-				validEntries = append(validEntries, domain)
-			} else {
-				invalidEntries = append(invalidEntries, line)
+		} else {
+			if idx := strings.Index(line, prefix); idx != -1 && idx < minIndex {
+				minIndex = idx
 			}
 		}
 	}
-
-	return validEntries, invalidEntries
+	return strings.TrimSpace(line[:minIndex])
 }
 
 func init() {
 	RegisterProcessor(sourceTypeHostname, func(st string, lt string) Processor {
 		return NewHostnameProcessor(st, lt)
 	})
+	RegisterProcessor(constants.SourceTypeHostnameSinkhole, func(st string, lt string) Processor {
+		return NewSinkholeHostnameProcessor(st, lt)
+	})
 }
@@ -1,11 +1,14 @@
 package processors_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
 	"github.com/phani-kb/multilog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewHostnameProcessor(t *testing.T) {
@@ -55,7 +58,7 @@ func TestHostnameProcessor_Process(t *testing.T) {
 			name:            "entries with IPv6",
 			content:         "::1 localhost\n127.0.0.1 example.com\n0.0.0.0 bad.com",
 			expectedValid:   []string{"example.com", "bad.com"},
-			expectedInvalid: []string{"::1 localhost"},
+			expectedInvalid: nil,
 		},
 		{
 			name:            "entries with comments",
@@ -80,16 +83,19 @@ func TestHostnameProcessor_Process(t *testing.T) {
 			content:       "127.0.0.1 invalid..domain\n0.0.0.0 .invalid.domain\n127.0.0.1 invalid-.domain",
 			expectedValid: nil,
 			expectedInvalid: []string{
-				"127.0.0.1 invalid..domain",
-				"0.0.0.0 .invalid.domain",
-				"127.0.0.1 invalid-.domain",
+				"127.0.0.1 invalid..domain # invalid hostname invalid..domain",
+				"0.0.0.0 .invalid.domain # invalid hostname .invalid.domain",
+				"127.0.0.1 invalid-.domain # invalid hostname invalid-.domain",
 			},
 		},
 		{
-			name:            "mixed valid and invalid entries",
-			content:         "127.0.0.1 example.com\n0.0.0.0 invalid..domain\n127.0.0.1 good.domain.com\n0.0.0.0 .bad.domain",
-			expectedValid:   []string{"example.com", "good.domain.com"},
-			expectedInvalid: []string{"0.0.0.0 invalid..domain", "0.0.0.0 .bad.domain"},
+			name:          "mixed valid and invalid entries",
+			content:       "127.0.0.1 example.com\n0.0.0.0 invalid..domain\n127.0.0.1 good.domain.com\n0.0.0.0 .bad.domain",
+			expectedValid: []string{"example.com", "good.domain.com"},
+			expectedInvalid: []string{
+				"0.0.0.0 invalid..domain # invalid hostname invalid..domain",
+				"0.0.0.0 .bad.domain # invalid hostname .bad.domain",
+			},
 		},
 		{
 			name:            "empty lines and whitespace only",
@@ -101,13 +107,13 @@ func TestHostnameProcessor_Process(t *testing.T) {
 			name:            "lines with only IP address",
 			content:         "127.0.0.1\n0.0.0.0",
 			expectedValid:   nil,
-			expectedInvalid: nil,
+			expectedInvalid: []string{"127.0.0.1 # missing hostname", "0.0.0.0 # missing hostname"},
 		},
 		{
-			name:            "lines with extra fields",
-			content:         "127.0.0.1 example.com extra field\n0.0.0.0 malicious.com another extra field",
-			expectedValid:   []string{"example.com", "malicious.com"},
-			expectedInvalid: nil,
+			name:            "multiple hostnames per line",
+			content:         "0.0.0.0 a.example.com b.example.com c.example.com\n127.0.0.1 malicious.com bad..com",
+			expectedValid:   []string{"a.example.com", "b.example.com", "c.example.com", "malicious.com"},
+			expectedInvalid: []string{"127.0.0.1 malicious.com bad..com # invalid hostname bad..com"},
 		},
 		{
 			name:            "subdomain entries",
@@ -128,10 +134,27 @@ func TestHostnameProcessor_Process(t *testing.T) {
 			expectedInvalid: nil,
 		},
 		{
-			name:            "localhost entries",
-			content:         "127.0.0.1 localhost\n::1 localhost\n127.0.0.1 localhost.localdomain",
-			expectedValid:   []string{"localhost.localdomain"},
-			expectedInvalid: []string{"127.0.0.1 localhost", "::1 localhost"},
+			name: "localhost entries",
+			content: "127.0.0.1 localhost\n::1 localhost ip6-localhost ip6-loopback\n127.0.0.1 localhost.localdomain\n" +
+				"255.255.255.255 broadcasthost\nfe00::0 ip6-localnet\n0.0.0.0 0.0.0.0",
+			expectedValid:   nil,
+			expectedInvalid: nil,
+		},
+		{
+			name:            "IPv6 sink addresses",
+			content:         "::1 example.com\n:: malicious.com\n::ffff:0.0.0.0 tracker.com",
+			expectedValid:   []string{"example.com", "malicious.com", "tracker.com"},
+			expectedInvalid: nil,
+		},
+		{
+			name:          "invalid addresses",
+			content:       "0.0.0.0.0 example.com\n:::1 malicious.com\n256.0.0.1 tracker.com",
+			expectedValid: nil,
+			expectedInvalid: []string{
+				"0.0.0.0.0 example.com # invalid address 0.0.0.0.0",
+				":::1 malicious.com # invalid address :::1",
+				"256.0.0.1 tracker.com # invalid address 256.0.0.1",
+			},
 		},
 		{
 			name: "comprehensive hosts file format",
@@ -164,17 +187,15 @@ func TestHostnameProcessor_Process(t *testing.T) {
 				"xn--abc1.cc",
 			},
 			expectedInvalid: []string{
-				"127.0.0.1       localhost",
-				"::1             localhost",
-				"127.0.0.1       invalid..domain",
-				"0.0.0.0         .bad.start",
+				"127.0.0.1       invalid..domain # invalid hostname invalid..domain",
+				"0.0.0.0         .bad.start # invalid hostname .bad.start",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
@@ -210,14 +231,12 @@ func TestHostnameProcessor_Integration(t *testing.T) {
 	}
 
 	expectedInvalid := []string{
-		"127.0.0.1       localhost",
-		"::1             localhost",
-		"127.0.0.1       invalid..domain",
-		"0.0.0.0         .starting.with.dot",
-		"127.0.0.1       ending.with.dash-",
+		"127.0.0.1       invalid..domain # invalid hostname invalid..domain",
+		"0.0.0.0         .starting.with.dot # invalid hostname .starting.with.dot",
+		"127.0.0.1       ending.with.dash- # invalid hostname ending.with.dash-",
 	}
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, expectedValid, valid, "Valid entries should match expected")
 	assert.Equal(t, expectedInvalid, invalid, "Invalid entries should match expected")
@@ -254,10 +273,14 @@ func TestHostnameProcessor_EdgeCases(t *testing.T) {
 			expectedInvalid: nil,
 		},
 		{
-			name:            "malformed lines",
-			content:         "notanip example.com\n127.0.0.1\njustadomain.com",
-			expectedValid:   []string{"example.com"},
-			expectedInvalid: nil,
+			name:          "malformed lines",
+			content:       "notanip example.com\n127.0.0.1\njustadomain.com",
+			expectedValid: nil,
+			expectedInvalid: []string{
+				"notanip example.com # invalid address notanip",
+				"127.0.0.1 # missing hostname",
+				"justadomain.com # invalid address justadomain.com",
+			},
 		},
 		{
 			name:            "very long domain",
@@ -269,9 +292,32 @@ func TestHostnameProcessor_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.Equal(t, tt.expectedValid, valid, "Valid entries should match expected")
 			assert.Equal(t, tt.expectedInvalid, invalid, "Invalid entries should match expected")
 		})
 	}
 }
+
+func TestSinkholeHostnameProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewSinkholeHostnameProcessor("hostname_sinkhole", "blocklist")
+	assert.Equal(t, "hostname_sinkhole", processor.GetSourceType())
+
+	content := "0.0.0.0 ads.example.com\n127.0.0.2 tracker.example.com\n:: v6.example.com\n" +
+		"203.0.113.7 bank.example.com login.example.com\n2001:db8::1 v6bank.example.com"
+	valid, invalid := processor.Process(context.Background(), logger, content)
+	assert.Equal(t, []string{"ads.example.com", "tracker.example.com", "v6.example.com"}, valid)
+	assert.Equal(t, []string{
+		"203.0.113.7 bank.example.com login.example.com # non-sinkhole address 203.0.113.7",
+		"2001:db8::1 v6bank.example.com # non-sinkhole address 2001:db8::1",
+	}, invalid)
+
+	sink := &processors.SliceSink{}
+	err := processor.ProcessStream(context.Background(), logger, strings.NewReader(content), sink)
+	require.NoError(t, err)
+	assert.Equal(t, valid, sink.Valid)
+	assert.Equal(t, invalid, sink.Invalid)
+}
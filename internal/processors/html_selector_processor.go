@@ -0,0 +1,201 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/phani-kb/multilog"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+// htmlExtractor returns the entries of the value of a selected element, none if the value has no valid entry.
+type htmlExtractor func(value string) []string
+
+// htmlExtractors are the extractors of an html_selector processor, with the generic source type of their entries.
+var htmlExtractors = map[string]struct {
+	extract    htmlExtractor
+	outputType string
+}{
+	constants.HtmlExtractDomain:  {extract: extractHtmlDomain, outputType: constants.SourceTypeDomain},
+	constants.HtmlExtractIpv4:    {extract: extractHtmlIpv4, outputType: constants.SourceTypeIpv4},
+	constants.HtmlExtractURLHost: {extract: extractHtmlURLHost, outputType: constants.SourceTypeDomain},
+}
+
+func extractHtmlDomain(value string) []string {
+	domain := strings.ToLower(value)
+	if !u.IsDomain(domain) {
+		return nil
+	}
+	return []string{domain}
+}
+
+func extractHtmlIpv4(value string) []string {
+	var ips []string
+	for _, match := range constants.SourceTypeExtractorRegexMap[constants.SourceTypeIpv4].FindAllString(value, -1) {
+		if ip, ok := canonicalIPv4(match); ok {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+func extractHtmlURLHost(value string) []string {
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return nil
+	}
+	return extractHtmlDomain(strings.TrimSuffix(parsed.Hostname(), "."))
+}
+
+// htmlSelection is the compiled selection of an html spec: the elements matching the selector,
+// their attribute or text, and the extractor of their entries.
+type htmlSelection struct {
+	matcher    goquery.Matcher
+	extract    htmlExtractor
+	attribute  string
+	outputType string
+}
+
+func newHtmlSelection(spec c.ProcessorSpec) (htmlSelection, error) {
+	if spec.Selector == "" {
+		return htmlSelection{}, fmt.Errorf("selector is required")
+	}
+	matcher, err := cascadia.Compile(spec.Selector)
+	if err != nil {
+		return htmlSelection{}, fmt.Errorf("invalid selector: %w", err)
+	}
+	extractor, ok := htmlExtractors[spec.Extract]
+	if !ok {
+		return htmlSelection{}, fmt.Errorf("invalid extract: %q", spec.Extract)
+	}
+	return htmlSelection{
+		matcher:    matcher,
+		extract:    extractor.extract,
+		attribute:  spec.Attribute,
+		outputType: extractor.outputType,
+	}, nil
+}
+
+// mustHtmlSelection is like newHtmlSelection but panics if the spec is invalid,
+// for the selections of the site-specific processors.
+func mustHtmlSelection(spec c.ProcessorSpec) htmlSelection {
+	selection, err := newHtmlSelection(spec)
+	if err != nil {
+		panic(err)
+	}
+	return selection
+}
+
+// entries returns the unique entries of the selected elements of an HTML document, sorted alphabetically,
+// and the values of the selected elements without an entry in document order.
+// Elements without the attribute and blank values are skipped.
+func (s htmlSelection) entries(reader io.Reader) ([]string, []string, error) {
+	doc, err := goquery.NewDocumentFromReader(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing HTML content: %w", err)
+	}
+	unique := make(map[string]struct{})
+	var invalidEntries []string
+	doc.FindMatcher(s.matcher).Each(func(_ int, selection *goquery.Selection) {
+		value := selection.Text()
+		if s.attribute != "" {
+			var exists bool
+			if value, exists = selection.Attr(s.attribute); !exists {
+				return
+			}
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+		entries := s.extract(value)
+		if len(entries) == 0 {
+			invalidEntries = append(invalidEntries, value)
+		}
+		for _, entry := range entries {
+			unique[entry] = struct{}{}
+		}
+	})
+	validEntries := make([]string, 0, len(unique))
+	for entry := range unique {
+		validEntries = append(validEntries, entry)
+	}
+	slices.Sort(validEntries)
+	return validEntries, invalidEntries, nil
+}
+
+// processHtmlSelection returns the entries of a selection of the HTML content, for a site-specific processor.
+func processHtmlSelection(logger *multilog.Logger, selection htmlSelection, content string) ([]string, []string) {
+	validEntries, invalidEntries, err := selection.entries(strings.NewReader(content))
+	if err != nil {
+		logger.Errorf("Error parsing HTML content: %v", err)
+	}
+	return validEntries, invalidEntries
+}
+
+// HtmlSelectorProcessor keeps the entries of the elements of an HTML page selected by a CSS selector,
+// such as the cells of a table of scam sites. It is declared by the html_selector source type of a source,
+// and its entries are of the generic source type of its extract, domain for domain and url_host.
+type HtmlSelectorProcessor struct {
+	BaseProcessor
+	selection htmlSelection
+}
+
+func newHtmlSelectorProcessor(sourceType, listType string, spec c.ProcessorSpec) (*HtmlSelectorProcessor, error) {
+	selection, err := newHtmlSelection(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &HtmlSelectorProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		selection:     selection,
+	}, nil
+}
+
+func (p *HtmlSelectorProcessor) OutputTypes() []string {
+	return []string{p.selection.outputType}
+}
+
+func (p *HtmlSelectorProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	validEntries, invalidEntries, err := p.selection.entries(reader)
+	if err != nil {
+		return err
+	}
+	for _, entry := range validEntries {
+		if err := addTypedValid(sink, p.selection.outputType, entry); err != nil {
+			return err
+		}
+	}
+	for _, entry := range invalidEntries {
+		reason := u.InvalidEntryReason(p.selection.outputType, entry)
+		if err := addInvalidReason(sink, entry, reason); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (p *HtmlSelectorProcessor) Process(
+	ctx context.Context,
+	logger *multilog.Logger,
+	content string,
+) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
@@ -0,0 +1,163 @@
+package processors_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	c "github.com/phani-kb/dns-toolkit/internal/common"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readHtmlFixture(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("..", "..", "testdata", "html", name))
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestHtmlSelectorProcessor_Fixtures(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		name          string
+		fixture       string
+		spec          c.ProcessorSpec
+		custom        processors.Processor
+		expectedValid []string
+	}{
+		{
+			name:    "ccam domains",
+			fixture: "ccam.html",
+			spec:    c.ProcessorSpec{Kind: "html", Selector: "table tr td", Extract: "domain"},
+			custom:  processors.NewDomainCustomHtmlCcamProcessor("domain_custom_html_ccam", "blocklist"),
+			expectedValid: []string{
+				"aspmailcenter2.com",
+				"brausincsystem.pro",
+				"growyourownteacher.co.uk",
+				"maxidoms.com",
+				"sisr.cacsite.com",
+			},
+		},
+		{
+			name:          "ccam ipv4",
+			fixture:       "ccam.html",
+			spec:          c.ProcessorSpec{Kind: "html", Selector: "table tr td", Extract: "ipv4"},
+			custom:        processors.NewIpv4CustomHtmlCcamProcessor("ipv4_custom_html_ccam", "blocklist"),
+			expectedValid: []string{"103.208.86.48", "185.99.133.162", "69.73.130.134"},
+		},
+		{
+			name:          "puppyscams",
+			fixture:       "puppyscams.html",
+			spec:          c.ProcessorSpec{Kind: "html", Selector: "a", Extract: "domain"},
+			custom:        processors.NewDomainCustomHtmlPuppyScamsProcessor("domain_custom_html_puppyscams", "blocklist"),
+			expectedValid: []string{"example-puppies.com", "fake-pets.net", "teacupyorkies.example.org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			content := readHtmlFixture(t, tt.fixture)
+			processor, err := processors.NewDeclaredProcessor("html_selector", "blocklist", tt.spec)
+			require.NoError(t, err)
+
+			valid, invalid := processor.Process(context.Background(), logger, content)
+			assert.Equal(t, tt.expectedValid, valid)
+
+			customValid, customInvalid := tt.custom.Process(context.Background(), logger, content)
+			assert.Equal(t, customValid, valid, "the spec should express the custom processor")
+			assert.Equal(t, customInvalid, invalid, "the spec should express the custom processor")
+		})
+	}
+}
+
+func TestHtmlSelectorProcessor_ProcessStream(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		name            string
+		spec            c.ProcessorSpec
+		content         string
+		expectedTyped   map[string][]string
+		expectedInvalid []string
+	}{
+		{
+			name: "url host of an attribute",
+			spec: c.ProcessorSpec{Kind: "html", Selector: "td.site a", Attribute: "href", Extract: "url_host"},
+			content: `<table><tr><td class="site"><a href="https://Scam.Example.com/shop?id=1">shop</a></td>` +
+				`<td><a href="https://ignored.example.net/">other</a></td></tr>` +
+				`<tr><td class="site"><a href="fraud.example.org:8080/pay">pay</a><a>no href</a></td></tr>` +
+				`<tr><td class="site"><a href="mailto:someone">mail</a></td></tr></table>`,
+			expectedTyped:   map[string][]string{"domain": {"fraud.example.org", "scam.example.com"}},
+			expectedInvalid: []string{"mailto:someone"},
+		},
+		{
+			name:            "ipv4 found in the text",
+			spec:            c.ProcessorSpec{Kind: "html", Selector: "li", Extract: "ipv4"},
+			content:         "<ul><li>192.0.2.1 and 198.51.100.2</li><li>999.1.1.1</li><li> </li></ul>",
+			expectedTyped:   map[string][]string{"ipv4": {"192.0.2.1", "198.51.100.2"}},
+			expectedInvalid: []string{"999.1.1.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			processor, err := processors.NewDeclaredProcessor("html_selector", "blocklist", tt.spec)
+			require.NoError(t, err)
+			multiType, ok := processor.(processors.MultiTypeProcessor)
+			require.True(t, ok)
+			for outputType := range tt.expectedTyped {
+				assert.Equal(t, []string{outputType}, multiType.OutputTypes())
+			}
+
+			sink := &processors.SliceSink{}
+			err = processors.AsStreamProcessor(processor).
+				ProcessStream(context.Background(), logger, strings.NewReader(tt.content), sink)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTyped, sink.Typed)
+			assert.Equal(t, tt.expectedInvalid, sink.Invalid)
+		})
+	}
+}
+
+func TestHtmlSelectorProcessor_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sourceType  string
+		spec        c.ProcessorSpec
+		expectedErr string
+	}{
+		{"missing selector", "html_selector", c.ProcessorSpec{Kind: "html", Extract: "domain"}, "selector is required"},
+		{
+			"invalid selector",
+			"html_selector",
+			c.ProcessorSpec{Kind: "html", Selector: "td[", Extract: "domain"},
+			"invalid selector",
+		},
+		{"invalid extract", "html_selector", c.ProcessorSpec{Kind: "html", Selector: "td"}, `invalid extract: ""`},
+		{"other kind", "html_selector", c.ProcessorSpec{Kind: "csv"}, "type html_selector requires processor kind html"},
+		{"other type", "domain", c.ProcessorSpec{Kind: "html", Selector: "td"}, "requires type html_selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := processors.ValidateProcessorSpec(tt.sourceType, tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
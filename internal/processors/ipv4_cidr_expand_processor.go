@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,7 @@ func NewIpv4CidrProcessor(sourceType, listType string) *Ipv4CidrProcessor {
 	}
 }
 
-func (p *Ipv4CidrProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4CidrProcessor) Process(_ context.Context, logger *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
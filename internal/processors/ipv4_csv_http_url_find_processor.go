@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,11 @@ func NewIpv4CsvHttpUrlFindProcessor(sourceType, listType string) *Ipv4CsvHttpUrl
 	}
 }
 
-func (p *Ipv4CsvHttpUrlFindProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4CsvHttpUrlFindProcessor) Process(
+	_ context.Context,
+	_ *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
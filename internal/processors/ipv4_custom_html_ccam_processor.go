@@ -1,20 +1,23 @@
 package processors
 
 import (
-	"bytes"
-	"regexp"
-	"slices"
-	"strings"
+	"context"
 
-	"github.com/PuerkitoBio/goquery"
+	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
-	"github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
 )
 
 // sourceTypeIpv4CustomHtmlCcam defines the type identifier for the IPv4 Custom HTML CCAM processor
 const sourceTypeIpv4CustomHtmlCcam = "ipv4_custom_html_ccam"
 
+// ccamIpv4Selection is the html_selector spec of the IPv4 addresses of the CCAM table cells
+var ccamIpv4Selection = mustHtmlSelection(c.ProcessorSpec{
+	Kind:     constants.ProcessorKindHtml,
+	Selector: "table tr td",
+	Extract:  constants.HtmlExtractIpv4,
+})
+
 // Ipv4CustomHtmlCcamProcessor implements a processor for extracting IPv4 addresses
 // from HTML content, specifically targeting CCAM-formatted HTML tables.
 type Ipv4CustomHtmlCcamProcessor struct {
@@ -42,48 +45,12 @@ func NewIpv4CustomHtmlCcamProcessor(sourceType, listType string) *Ipv4CustomHtml
 // Returns:
 //   - A slice of valid IPv4 addresses found in the content, sorted alphabetically
 //   - A slice of invalid entries that couldn't be parsed as IPv4 addresses
-func (p *Ipv4CustomHtmlCcamProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
-	return extractUniqueFromHtmlTableCells(
-		logger,
-		content,
-		constants.SourceTypeExtractorRegexMap[constants.SourceTypeIpv4],
-		utils.IsIP,
-	)
-}
-
-// extractUniqueFromHtmlTableCells is a shared helper.
-func extractUniqueFromHtmlTableCells(
+func (p *Ipv4CustomHtmlCcamProcessor) Process(
+	_ context.Context,
 	logger *multilog.Logger,
 	content string,
-	regex *regexp.Regexp,
-	isValid func(string) bool,
-) (validEntries, invalidEntries []string) {
-	reader := bytes.NewReader([]byte(content))
-	doc, err := goquery.NewDocumentFromReader(reader)
-	if err != nil {
-		logger.Errorf("Error parsing HTML content: %v", err)
-		return nil, nil
-	}
-	unique := make(map[string]struct{})
-	doc.Find("table tr td").Each(func(i int, s *goquery.Selection) {
-		text := strings.TrimSpace(s.Text())
-		if regex.MatchString(text) {
-			matches := regex.FindAllString(text, -1)
-			for _, val := range matches {
-				if isValid(val) {
-					unique[val] = struct{}{}
-				}
-			}
-		} else if text != "" {
-			invalidEntries = append(invalidEntries, text)
-		}
-	})
-	validEntries = make([]string, 0, len(unique))
-	for val := range unique {
-		validEntries = append(validEntries, val)
-	}
-	slices.Sort(validEntries)
-	return validEntries, invalidEntries
+) ([]string, []string) {
+	return processHtmlSelection(logger, ccamIpv4Selection, content)
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestIpv4CustomHtmlCcamProcessor_Process(t *testing.T) {
 
 	logger := multilog.NewLogger()
 	processor := NewIpv4CustomHtmlCcamProcessor("ipv4_custom_html_ccam", "blocklist")
-	valid, invalid := processor.Process(logger, sampleContent)
+	valid, invalid := processor.Process(context.Background(), logger, sampleContent)
 
 	expected := []string{"103.208.86.48", "185.99.133.162", "69.73.130.134"}
 	if len(valid) != len(expected) {
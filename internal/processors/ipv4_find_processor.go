@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"strings"
 
 	"github.com/phani-kb/multilog"
@@ -21,7 +22,7 @@ func NewIpv4FindProcessor(sourceType, listType string) *Ipv4FindProcessor {
 	}
 }
 
-func (p *Ipv4FindProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4FindProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
@@ -1,6 +1,8 @@
 package processors
 
 import (
+	"context"
+
 	"github.com/phani-kb/multilog"
 
 	"github.com/phani-kb/dns-toolkit/internal/constants"
@@ -24,14 +26,18 @@ func NewIpv4FromDomainProcessor(sourceType, listType string) *Ipv4FromDomainProc
 	}
 }
 
-func (p *Ipv4FromDomainProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4FromDomainProcessor) Process(
+	ctx context.Context,
+	logger *multilog.Logger,
+	content string,
+) ([]string, []string) {
 	validEntries, invalidEntries := utils.ExtractEntriesWithRegex(
 		content,
 		constants.SourceTypeRegexMap[constants.SourceTypeDomain],
 	)
 
 	// resolve IP addresses from domains
-	ipAddresses, failedDomains := utils.ResolveDomainsToIPv4(logger, validEntries)
+	ipAddresses, failedDomains := utils.ResolveDomainsToIPv4WithContext(ctx, logger, validEntries)
 	if len(failedDomains) > 0 {
 		logger.Warnf("Failed to resolve %v domains", len(failedDomains))
 		invalidEntries = append(invalidEntries, failedDomains...)
@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -57,7 +58,7 @@ func TestIpv4FromDomainProcessorProcess(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			assert.GreaterOrEqual(t, len(valid), tt.expectedValidLen, "Valid entries count should be at least expected")
 			assert.Equal(t, tt.expectedInvalidLen, len(invalid), "Invalid entries count should match expected")
 
@@ -83,7 +84,7 @@ invalid_entry_without_rank
 invalid..domain
 # End of list`
 
-	valid, invalid := processor.Process(logger, content)
+	valid, invalid := processor.Process(context.Background(), logger, content)
 
 	require.GreaterOrEqual(t, len(valid), 1, "Should resolve at least one IP from example.com")
 
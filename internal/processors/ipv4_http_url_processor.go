@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -26,7 +27,7 @@ func isValidIPv4(ip string) bool {
 	return utils.IsIP(ip)
 }
 
-func (p *Ipv4HttpUrlProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4HttpUrlProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
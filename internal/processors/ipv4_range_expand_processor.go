@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,7 @@ func NewIpv4RangeProcessor(sourceType, listType string) *Ipv4RangeProcessor {
 	}
 }
 
-func (p *Ipv4RangeProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4RangeProcessor) Process(_ context.Context, logger *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
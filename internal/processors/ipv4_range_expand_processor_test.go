@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -23,7 +24,7 @@ func TestIpv4RangeProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.input)
+			valid, invalid := processor.Process(context.Background(), logger, tt.input)
 			if tt.wantInvalid {
 				if len(valid) != 0 {
 					t.Errorf("Expected no valid entries, got: %v", valid)
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -22,7 +23,7 @@ func NewIpv4UrlProcessor(sourceType, listType string) *Ipv4UrlProcessor {
 	}
 }
 
-func (p *Ipv4UrlProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv4UrlProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -44,7 +45,7 @@ func TestIpv4UrlProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			valid, invalid := processor.Process(logger, tt.content)
+			valid, invalid := processor.Process(context.Background(), logger, tt.content)
 			if len(valid) != len(tt.valid) {
 				t.Errorf("Expected %d valid entries, got %d", len(tt.valid), len(valid))
 			}
@@ -0,0 +1,58 @@
+package processors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+const sourceTypeIpv6Cidr = "ipv6_cidr_expand"
+
+// Ipv6CidrProcessor expands the small IPv6 networks of a list into their addresses.
+type Ipv6CidrProcessor struct {
+	BaseProcessor
+}
+
+func NewIpv6CidrProcessor(sourceType, listType string) *Ipv6CidrProcessor {
+	return &Ipv6CidrProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+	}
+}
+
+func (p *Ipv6CidrProcessor) Process(_ context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	var validEntries, invalidEntries []string
+	lines := strings.Split(content, "\n")
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if utils.IsComment(line) {
+			continue
+		}
+
+		cidr, found := utils.FindCIDRv6(line)
+		if !found {
+			invalidEntries = append(invalidEntries, line)
+			continue
+		}
+
+		ipList, err := utils.ExpandIpv6Cidr(logger, cidr)
+		if err != nil || len(ipList) == 0 {
+			logger.Errorf("Failed to expand CIDR %s: %v", cidr, err)
+			invalidEntries = append(invalidEntries, line)
+			continue
+		}
+
+		validEntries = append(validEntries, ipList...)
+	}
+
+	return validEntries, invalidEntries
+}
+
+func init() {
+	RegisterProcessor(sourceTypeIpv6Cidr, func(st string, lt string) Processor {
+		return NewIpv6CidrProcessor(st, lt)
+	})
+}
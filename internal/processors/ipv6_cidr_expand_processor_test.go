@@ -0,0 +1,65 @@
+package processors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIpv6CidrProcessor(t *testing.T) {
+	sourceType := "ipv6_cidr_expand"
+	listType := "blocklist"
+
+	processor := processors.NewIpv6CidrProcessor(sourceType, listType)
+
+	assert.NotNil(t, processor)
+	assert.Equal(t, sourceType, processor.GetSourceType())
+	assert.Equal(t, listType, processor.GetListType())
+
+	registered, exists := processors.Processors.GetProcessor(sourceType, listType)
+	assert.True(t, exists)
+	assert.IsType(t, &processors.Ipv6CidrProcessor{}, registered)
+}
+
+func TestIpv6CidrProcessor_Process(t *testing.T) {
+	logger := multilog.NewLogger()
+	processor := processors.NewIpv6CidrProcessor("ipv6_cidr_expand", "blocklist")
+
+	tests := []struct {
+		name            string
+		content         string
+		expectedValid   []string
+		expectedInvalid []string
+	}{
+		{
+			name:            "empty content",
+			content:         "",
+			expectedValid:   nil,
+			expectedInvalid: nil,
+		},
+		{
+			name:            "small networks",
+			content:         "2001:db8::/127\n# comment\n2001:DB8:0:0:0:0:0:10/128",
+			expectedValid:   []string{"2001:db8::", "2001:db8::1", "2001:db8::10"},
+			expectedInvalid: nil,
+		},
+		{
+			name:            "invalid and too large networks",
+			content:         "2001:db8::/64\n192.168.1.0/30\n2001:db8::1",
+			expectedValid:   nil,
+			expectedInvalid: []string{"2001:db8::/64", "192.168.1.0/30", "2001:db8::1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validEntries, invalidEntries := processor.Process(context.Background(), logger, tt.content)
+
+			assert.Equal(t, tt.expectedValid, validEntries, "Valid entries should match")
+			assert.Equal(t, tt.expectedInvalid, invalidEntries, "Invalid entries should match")
+		})
+	}
+}
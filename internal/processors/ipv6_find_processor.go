@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -21,7 +22,7 @@ func NewIpv6FindProcessor(sourceType, listType string) *Ipv6FindProcessor {
 	}
 }
 
-func (p *Ipv6FindProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv6FindProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
@@ -39,8 +40,8 @@ func (p *Ipv6FindProcessor) Process(_ *multilog.Logger, content string) ([]strin
 		found := false
 
 		for _, match := range matches {
-			if utils.IsIPv6(match) {
-				validEntries = append(validEntries, match)
+			if ip, ok := utils.CanonicalIPv6(match); ok {
+				validEntries = append(validEntries, ip)
 				found = true
 			}
 		}
@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -71,7 +72,7 @@ func TestIpv6FindProcessor_Process(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			validEntries, invalidEntries := processor.Process(logger, tt.content)
+			validEntries, invalidEntries := processor.Process(context.Background(), logger, tt.content)
 
 			assert.Equal(t, tt.expectedValid, validEntries, "Valid entries should match")
 			assert.Equal(t, tt.expectedInvalid, invalidEntries, "Invalid entries should match")
@@ -1,12 +1,12 @@
 package processors
 
 import (
+	"context"
 	"slices"
 	"strings"
 
 	"github.com/phani-kb/multilog"
 
-	"github.com/phani-kb/dns-toolkit/internal/constants"
 	u "github.com/phani-kb/dns-toolkit/internal/utils"
 )
 
@@ -24,7 +24,7 @@ func NewIpv6HtaccessProcessor(sourceType, listType string) *Ipv6HtaccessProcesso
 	}
 }
 
-func (p *Ipv6HtaccessProcessor) Process(_ *multilog.Logger, content string) ([]string, []string) {
+func (p *Ipv6HtaccessProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
 	var validEntries, invalidEntries []string
 	lines := strings.Split(content, "\n")
 
@@ -34,8 +34,7 @@ func (p *Ipv6HtaccessProcessor) Process(_ *multilog.Logger, content string) ([]s
 		if u.IsComment(line) {
 			continue
 		}
-		if constants.SourceTypeRegexMap[constants.SourceTypeIpv6].MatchString(line) {
-			ip := constants.SourceTypeRegexMap[constants.SourceTypeIpv6].FindString(line)
+		if ip, ok := u.FindIPv6(line); ok {
 			validEntries = append(validEntries, ip)
 		} else {
 			invalidEntries = append(invalidEntries, line)
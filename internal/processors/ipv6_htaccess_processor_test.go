@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/processors"
@@ -43,11 +44,11 @@ deny from 2a01:111:2054:15f::aff:feaf:6f06
 deny from 2402:1980:84cb:fb82::1`,
 			expectedValid: []string{
 				"2001:9b1:28fa:cf00:cfbc:17d2:84bb:722e",
+				"2402:1980:84cb:fb82::1",
+				"2a01:111:2054:15f:0:aff:feaf:6f06",
 			},
 			expectedInvalid: []string{
 				"deny from 35.206.80.53",
-				"deny from 2a01:111:2054:15f::aff:feaf:6f06",
-				"deny from 2402:1980:84cb:fb82::1",
 			},
 		},
 		{
@@ -66,7 +67,7 @@ deny from 2402:1980:84cb:fb82::1`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			validEntries, invalidEntries := processor.Process(logger, tt.content)
+			validEntries, invalidEntries := processor.Process(context.Background(), logger, tt.content)
 
 			assert.Equal(t, tt.expectedValid, validEntries, "Valid entries should match")
 			assert.Equal(t, tt.expectedInvalid, invalidEntries, "Invalid entries should match")
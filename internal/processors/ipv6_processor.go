@@ -0,0 +1,42 @@
+package processors
+
+import (
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+const (
+	sourceTypeIpv6     = "ipv6"
+	sourceTypeCidrIpv6 = "cidr_ipv6"
+)
+
+// findLine returns a LineFunc keeping the entry found by find in a line, the line is invalid otherwise.
+func findLine(find func(line string) (string, bool)) LineFunc {
+	return func(line string) (string, string) {
+		if entry, ok := find(line); ok {
+			return entry, ""
+		}
+		return "", line
+	}
+}
+
+// NewIpv6Processor creates a processor extracting the IPv6 address of each line, in any notation.
+// The addresses are kept in the canonical compressed form, such as 2001:db8::1.
+func NewIpv6Processor(sourceType, listType string) *LineProcessor {
+	return NewLineProcessor(sourceType, listType, findLine(u.FindIPv6))
+}
+
+// NewCidrIpv6Processor creates a processor extracting the IPv6 network of each line in CIDR notation.
+// The networks are kept in canonical form, such as 2001:db8::/32.
+func NewCidrIpv6Processor(sourceType, listType string) *LineProcessor {
+	return NewLineProcessor(sourceType, listType, findLine(u.FindCIDRv6))
+}
+
+func init() {
+	RegisterProcessorTypes(sourceTypeIpv6, constants.ListTypes, func(st string, lt string) Processor {
+		return NewIpv6Processor(st, lt)
+	})
+	RegisterProcessorTypes(sourceTypeCidrIpv6, constants.ListTypes, func(st string, lt string) Processor {
+		return NewCidrIpv6Processor(st, lt)
+	})
+}
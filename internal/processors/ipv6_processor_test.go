@@ -0,0 +1,49 @@
+package processors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIpv6Processors_Registration(t *testing.T) {
+	t.Parallel()
+
+	for _, sourceType := range []string{"ipv6", "cidr_ipv6"} {
+		for _, listType := range constants.ListTypes {
+			processor, exists := processors.Processors.GetProcessor(sourceType, listType)
+			assert.True(t, exists, sourceType)
+			assert.Equal(t, sourceType, processor.GetSourceType())
+			assert.Equal(t, listType, processor.GetListType())
+		}
+	}
+}
+
+func TestIpv6Processor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewIpv6Processor("ipv6", "blocklist")
+
+	valid, invalid := processor.Process(context.Background(), logger,
+		"# IPv6 list\n2001:0DB8:0000:0000:0000:0000:0000:0001\n2001:db8::2 # scanner\n::1\n"+
+			"2001:db8::/32\n::ffff:192.0.2.1\n192.0.2.1\nnot an address")
+	assert.Equal(t, []string{"2001:db8::1", "2001:db8::2", "::1"}, valid)
+	assert.Equal(t, []string{"2001:db8::/32", "::ffff:192.0.2.1", "192.0.2.1", "not an address"}, invalid)
+}
+
+func TestCidrIpv6Processor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewCidrIpv6Processor("cidr_ipv6", "blocklist")
+
+	valid, invalid := processor.Process(context.Background(), logger,
+		"2001:db8::/32\n2001:DB8:0:1::1/64\nfc00::/7 ; ULA\n2001:db8::1\n192.0.2.0/24\n2001:db8::/129")
+	assert.Equal(t, []string{"2001:db8::/32", "2001:db8:0:1::/64", "fc00::/7"}, valid)
+	assert.Equal(t, []string{"2001:db8::1", "192.0.2.0/24", "2001:db8::/129"}, invalid)
+}
@@ -0,0 +1,156 @@
+package processors
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+// mixedOutputTypes are the generic source types the lines of a mixed feed are classified into
+var mixedOutputTypes = []string{
+	constants.SourceTypeDomain,
+	constants.SourceTypeIpv4,
+	constants.SourceTypeIpv6,
+	constants.SourceTypeCidrIpv4,
+	constants.SourceTypeCidrIpv6,
+}
+
+// mixedRefanger restores the dots and colons of defanged indicators, such as example[.]com or 192.0.2[.]1
+var mixedRefanger = strings.NewReplacer(
+	"[.]", ".",
+	"(.)", ".",
+	"{.}", ".",
+	"[dot]", ".",
+	"(dot)", ".",
+	"[:]", ":",
+	"[://]", "://",
+)
+
+// mixedDefangedSchemeRegex matches the defanged schemes of URLs, such as hxxps or hXXp
+var mixedDefangedSchemeRegex = regexp.MustCompile(`(?i)^h(xx|\*\*)p(s?)(://|\[://\])`)
+
+// MixedProcessor classifies each line of a feed mixing indicators of several types, such as an IOC feed,
+// as a domain, an IPv4 or IPv6 address or CIDR, in a single pass.
+// The first field of a line may be a URL or a host:port, whose host is kept, and may be defanged.
+// Lines starting with a comment prefix are skipped, except those of a bracketed IPv6 host, such as
+// [2001:db8::1]:53.
+type MixedProcessor struct {
+	BaseProcessor
+}
+
+func NewMixedProcessor(sourceType, listType string) *MixedProcessor {
+	return &MixedProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+	}
+}
+
+func (p *MixedProcessor) OutputTypes() []string {
+	return mixedOutputTypes
+}
+
+func (p *MixedProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	return eachLineSkipping(ctx, reader, isMixedComment, func(line string) error {
+		sourceType, entry, reason := classifyMixedLine(line)
+		if reason != "" {
+			return addInvalidReason(sink, line, reason)
+		}
+		return addTypedValid(sink, sourceType, entry)
+	})
+}
+
+func (p *MixedProcessor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
+
+// isMixedComment reports whether a line of a mixed feed is a comment. A line starting with a bracketed
+// IPv6 host is an entry, although [ is a comment prefix.
+func isMixedComment(line string) bool {
+	if strings.HasPrefix(line, "[") {
+		host := strings.Fields(line)[0]
+		if splitHost, _, err := net.SplitHostPort(host); err == nil {
+			host = splitHost
+		}
+		if _, ok := u.CanonicalIPv6(strings.Trim(host, "[]")); ok {
+			return false
+		}
+	}
+	return u.IsComment(line)
+}
+
+// classifyMixedLine returns the generic source type and the canonical entry of the first field of a line,
+// or the reason code of a line without an entry.
+func classifyMixedLine(line string) (string, string, string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", "", constants.InvalidReasonSyntax
+	}
+	value := mixedDefangedSchemeRegex.ReplaceAllString(fields[0], "http$2://")
+	value = mixedRefanger.Replace(value)
+
+	if strings.Contains(value, "://") {
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return "", "", constants.InvalidReasonURL
+		}
+		return classifyMixedHost(parsed.Hostname())
+	}
+	if _, err := netip.ParsePrefix(value); err == nil {
+		if cidr, ok := canonicalCIDRv4(value); ok {
+			return constants.SourceTypeCidrIpv4, cidr, ""
+		}
+		if cidr, ok := u.CanonicalCIDRv6(value); ok {
+			return constants.SourceTypeCidrIpv6, cidr, ""
+		}
+		return "", "", constants.InvalidReasonInvalidIP
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	} else if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		// a bracketed IPv6 address without a port
+		value = strings.Trim(value, "[]")
+	} else if host, _, found := strings.Cut(value, "/"); found {
+		// a URL without a scheme, such as example.com/login
+		value = host
+	}
+	return classifyMixedHost(value)
+}
+
+// classifyMixedHost returns the generic source type and the canonical entry of a host,
+// or the reason code of a host that is not a domain or an IP address.
+func classifyMixedHost(host string) (string, string, string) {
+	if ip, ok := canonicalIPv4(host); ok {
+		return constants.SourceTypeIpv4, ip, ""
+	}
+	if ip, ok := u.CanonicalIPv6(host); ok {
+		return constants.SourceTypeIpv6, ip, ""
+	}
+	domain := strings.ToLower(strings.TrimSuffix(host, "."))
+	if u.IsDomainEntry(domain) {
+		return constants.SourceTypeDomain, domain, ""
+	}
+	return "", "", u.InvalidEntryReason(constants.SourceTypeDomain, domain)
+}
+
+func init() {
+	RegisterProcessorTypes(
+		constants.SourceTypeMixed,
+		[]string{constants.ListTypeBlocklist, constants.ListTypeAllowlist},
+		func(st string, lt string) Processor {
+			return NewMixedProcessor(st, lt)
+		},
+	)
+}
@@ -0,0 +1,98 @@
+package processors_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMixedProcessor(t *testing.T) {
+	t.Parallel()
+
+	processor := processors.NewMixedProcessor("mixed", "blocklist")
+
+	assert.Equal(t, "mixed", processor.GetSourceType())
+	assert.Equal(t, "blocklist", processor.GetListType())
+	assert.Equal(t, []string{"domain", "ipv4", "ipv6", "cidr_ipv4", "cidr_ipv6"}, processor.OutputTypes())
+
+	for _, listType := range []string{constants.ListTypeBlocklist, constants.ListTypeAllowlist} {
+		registered, exists := processors.Processors.GetProcessor("mixed", listType)
+		assert.True(t, exists)
+		assert.IsType(t, &processors.MixedProcessor{}, registered)
+	}
+}
+
+func TestMixedProcessor_ProcessStream(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		name            string
+		content         string
+		expectedTyped   map[string][]string
+		expectedInvalid []string
+	}{
+		{
+			name:    "plain indicators",
+			content: "# IOC feed\nAds.Example.com\n192.0.2.1\n2001:DB8::1\n198.51.100.7/24\n2001:db8::/32\n*.tracker.example.net\n",
+			expectedTyped: map[string][]string{
+				"domain":    {"ads.example.com", "*.tracker.example.net"},
+				"ipv4":      {"192.0.2.1"},
+				"ipv6":      {"2001:db8::1"},
+				"cidr_ipv4": {"198.51.100.0/24"},
+				"cidr_ipv6": {"2001:db8::/32"},
+			},
+		},
+		{
+			name: "urls and host:port",
+			content: "https://phish.example.com/login?id=1\nhttp://192.0.2.10:8080/payload.exe\n" +
+				"http://[2001:db8::2]/x\nc2.example.org:443\n192.0.2.11:4444\nmalware.example.net/dropper\n",
+			expectedTyped: map[string][]string{
+				"domain": {"phish.example.com", "c2.example.org", "malware.example.net"},
+				"ipv4":   {"192.0.2.10", "192.0.2.11"},
+				"ipv6":   {"2001:db8::2"},
+			},
+		},
+		{
+			name:    "defanged forms",
+			content: "example[.]com\nhxxps://bad(.)example[.]org/path\n192.0.2[.]12\nhXXp[://]evil[dot]example.net\n",
+			expectedTyped: map[string][]string{
+				"domain": {"example.com", "bad.example.org", "evil.example.net"},
+				"ipv4":   {"192.0.2.12"},
+			},
+		},
+		{
+			name:    "bracketed ipv6 hosts",
+			content: "[2001:db8::1]:53\n[2001:DB8::3]\n[Adblock Plus 2.0]\n[2001:db8::4]:8443 resolver\n",
+			expectedTyped: map[string][]string{
+				"ipv6": {"2001:db8::1", "2001:db8::3", "2001:db8::4"},
+			},
+		},
+		{
+			name:            "trailing fields and invalid lines",
+			content:         "203.0.113.5 scanner 2024-01-01\nnot_a_host\nftp://\n999.1.1.1/8\n",
+			expectedTyped:   map[string][]string{"ipv4": {"203.0.113.5"}},
+			expectedInvalid: []string{"not_a_host", "ftp://", "999.1.1.1/8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			processor := processors.NewMixedProcessor("mixed", "blocklist")
+
+			sink := &processors.SliceSink{}
+			err := processor.ProcessStream(context.Background(), logger, strings.NewReader(tt.content), sink)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTyped, sink.Typed)
+			assert.Equal(t, tt.expectedInvalid, sink.Invalid)
+		})
+	}
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	multilog "github.com/phani-kb/multilog"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -48,9 +50,9 @@ func (_m *Processor) GetSourceType() string {
 	return r0
 }
 
-// Process provides a mock function with given fields: logger, content
-func (_m *Processor) Process(logger *multilog.Logger, content string) ([]string, []string) {
-	ret := _m.Called(logger, content)
+// Process provides a mock function with given fields: ctx, logger, content
+func (_m *Processor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	ret := _m.Called(ctx, logger, content)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Process")
@@ -58,19 +60,19 @@ func (_m *Processor) Process(logger *multilog.Logger, content string) ([]string,
 
 	var r0 []string
 	var r1 []string
-	if rf, ok := ret.Get(0).(func(*multilog.Logger, string) ([]string, []string)); ok {
-		return rf(logger, content)
+	if rf, ok := ret.Get(0).(func(context.Context, *multilog.Logger, string) ([]string, []string)); ok {
+		return rf(ctx, logger, content)
 	}
-	if rf, ok := ret.Get(0).(func(*multilog.Logger, string) []string); ok {
-		r0 = rf(logger, content)
+	if rf, ok := ret.Get(0).(func(context.Context, *multilog.Logger, string) []string); ok {
+		r0 = rf(ctx, logger, content)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]string)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(*multilog.Logger, string) []string); ok {
-		r1 = rf(logger, content)
+	if rf, ok := ret.Get(1).(func(context.Context, *multilog.Logger, string) []string); ok {
+		r1 = rf(ctx, logger, content)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).([]string)
@@ -1,6 +1,8 @@
 package processors
 
 import (
+	"context"
+
 	"github.com/phani-kb/dns-toolkit/internal/constants"
 	"github.com/phani-kb/multilog"
 )
@@ -15,7 +17,7 @@ type GenericProcessor struct {
 }
 
 // Process delegates to the custom process function
-func (gp *GenericProcessor) Process(logger *multilog.Logger, content string) ([]string, []string) {
+func (gp *GenericProcessor) Process(_ context.Context, logger *multilog.Logger, content string) ([]string, []string) {
 	return gp.processFunc(logger, content)
 }
 
@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/constants"
@@ -26,7 +27,7 @@ func TestGenericProcessor(t *testing.T) {
 	assert.Equal(t, sourceType, gp.GetSourceType(), "GetSourceType should return the correct source type")
 	assert.Equal(t, listType, gp.GetListType(), "GetListType should return the correct list type")
 
-	valid, invalid := gp.Process(logger, "dummy content")
+	valid, invalid := gp.Process(context.Background(), logger, "dummy content")
 	assert.Equal(t, []string{"example.com", "example.org"}, valid, "Valid entries should match expected")
 	assert.Equal(t, []string{"invalid..domain"}, invalid, "Invalid entries should match expected")
 }
@@ -1,6 +1,7 @@
 package processors
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -12,13 +13,28 @@ import (
 // as well as report its source type.
 type Processor interface {
 	// Process parses the content and returns valid and invalid entries
-	Process(logger *multilog.Logger, content string) ([]string, []string)
+	Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string)
 	// GetSourceType returns the source type this processor handles
 	GetSourceType() string
 	// GetListType returns the list type this processor handles
 	GetListType() string
 }
 
+// MatchSemanticsProcessor is implemented by processors of formats whose entries match hostnames in a known way,
+// such as configuration files where an entry also blocks the subdomains of the domain.
+type MatchSemanticsProcessor interface {
+	// MatchSemantics returns constants.MatchSemanticsExact or constants.MatchSemanticsSubdomains
+	MatchSemantics() string
+}
+
+// MultiTypeProcessor is implemented by processors whose valid entries are of several generic source types,
+// such as zone files holding both domain and IP triggers. They send their valid entries with
+// TypedEntrySink.AddTypedValid, and the entries of each type are saved to their own processed file.
+type MultiTypeProcessor interface {
+	// OutputTypes returns the generic source types of the valid entries
+	OutputTypes() []string
+}
+
 // createRegistryKey creates a composite key from sourceType and listType
 func createRegistryKey(sourceType, listType string) string {
 	return fmt.Sprintf("%s:%s", sourceType, listType)
@@ -54,6 +70,17 @@ func (pr *ProcessorRegistry) RegisterProcessor(sourceType, listType string, proc
 	pr.customProcessorRegistry[key] = processor
 }
 
+// UnregisterProcessor removes the processor registered for a source type and list type, if any.
+//
+// Parameters:
+//   - sourceType: The source type identifier of the processor
+//   - listType: The list type identifier of the processor
+func (pr *ProcessorRegistry) UnregisterProcessor(sourceType, listType string) {
+	pr.registryMutex.Lock()
+	defer pr.registryMutex.Unlock()
+	delete(pr.customProcessorRegistry, createRegistryKey(sourceType, listType))
+}
+
 // GetProcessor retrieves a processor for a source type and list type.
 //
 // Parameters:
@@ -1,6 +1,7 @@
 package processors_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/phani-kb/dns-toolkit/internal/constants"
@@ -8,6 +9,7 @@ import (
 	"github.com/phani-kb/dns-toolkit/internal/processors/mocks"
 	"github.com/phani-kb/multilog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestProcessorRegistry(t *testing.T) {
@@ -36,6 +38,12 @@ func TestProcessorRegistry(t *testing.T) {
 	listedProcessor, exists := processorMap[key]
 	assert.True(t, exists, "Processor should exist in the map with the correct key")
 	assert.Equal(t, mockProcessor, listedProcessor, "Listed processor should match registered processor")
+
+	registry.UnregisterProcessor(sourceType, listType)
+	_, exists = registry.GetProcessor(sourceType, listType)
+	assert.False(t, exists, "Unregistered processor should not be found")
+	assert.Empty(t, registry.ListProcessors(), "Registry should be empty")
+	registry.UnregisterProcessor(sourceType, listType)
 }
 
 func TestRegisterProcessorHelper(t *testing.T) {
@@ -101,11 +109,11 @@ func TestProcessor_Process(t *testing.T) {
 	validEntries := []string{"example.com", "example.org"}
 	invalidEntries := []string{"invalid..domain"}
 
-	mockProcessor.On("Process", logger, content).Return(validEntries, invalidEntries)
+	mockProcessor.On("Process", mock.Anything, logger, content).Return(validEntries, invalidEntries)
 	mockProcessor.On("GetSourceType").Maybe().Return("test-source")
 	mockProcessor.On("GetListType").Maybe().Return("test-list")
 
-	valid, invalid := mockProcessor.Process(logger, content)
+	valid, invalid := mockProcessor.Process(context.Background(), logger, content)
 
 	assert.Equal(t, validEntries, valid, "Valid entries should match expected")
 	assert.Equal(t, invalidEntries, invalid, "Invalid entries should match expected")
@@ -0,0 +1,389 @@
+package processors
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	u "github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+const sourceTypeRpz = "rpz"
+
+// rpzOutputTypes are the generic source types of the entries of a response policy zone
+var rpzOutputTypes = []string{
+	constants.SourceTypeDomain,
+	constants.SourceTypeAdguard,
+	constants.SourceTypeIpv4,
+	constants.SourceTypeCidrIpv4,
+	constants.SourceTypeIpv6,
+	constants.SourceTypeCidrIpv6,
+}
+
+// rpzTTLRegex matches a TTL field, in seconds or with BIND units such as 1h30m
+var rpzTTLRegex = regexp.MustCompile(`^(\d+[smhdwSMHDW]?)+$`)
+
+// rpzClasses are the record classes that may precede the record type
+var rpzClasses = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+// rpzUnsupportedTriggers are the trigger suffixes that do not match the queried name or its addresses
+var rpzUnsupportedTriggers = []string{"rpz-client-ip", "rpz-nsdname", "rpz-nsip"}
+
+// RpzProcessor extracts the triggers of a response policy zone in BIND master-file format.
+// QNAME triggers go to the domain and adguard outputs, rpz-ip triggers to the ipv4, cidr_ipv4, ipv6 and cidr_ipv6
+// outputs.
+// A blocklist keeps the triggers of the NXDOMAIN (CNAME .), NODATA (CNAME *.), DROP and local data policies,
+// an allowlist keeps the triggers of the PASSTHRU policy.
+type RpzProcessor struct {
+	BaseProcessor
+}
+
+func NewRpzProcessor(sourceType, listType string) *RpzProcessor {
+	return &RpzProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+	}
+}
+
+func (p *RpzProcessor) OutputTypes() []string {
+	return rpzOutputTypes
+}
+
+func (p *RpzProcessor) ProcessStream(
+	ctx context.Context,
+	_ *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	zone := &rpzZone{allowlist: p.GetListType() == constants.ListTypeAllowlist}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if lineNumber%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		record, complete := zone.addLine(scanner.Text())
+		if !complete {
+			continue
+		}
+		if err := zone.processRecord(record, sink); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(zone.pending.fields) > 0 {
+		rejection := lineRejection{constants.InvalidReasonSyntax, "unbalanced parentheses"}
+		if err := rejectLine(sink, zone.pending.text(), rejection); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (p *RpzProcessor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
+
+// rpzRecord is a record of a zone file, which spans several lines when its fields are in parentheses.
+type rpzRecord struct {
+	fields []string
+	lines  []string
+	// inheritsOwner is set when the record starts with a blank, it then belongs to the previous owner name
+	inheritsOwner bool
+}
+
+func (r rpzRecord) text() string {
+	return strings.Join(r.lines, " ")
+}
+
+// rpzZone holds the state of a zone file while its lines are read.
+type rpzZone struct {
+	pending   rpzRecord
+	origin    string
+	owner     string
+	depth     int
+	allowlist bool
+}
+
+// addLine adds a line of the zone file to the pending record and returns the record once it is complete.
+func (z *rpzZone) addLine(line string) (rpzRecord, bool) {
+	content := stripZoneComment(line)
+	if strings.TrimSpace(content) == "" {
+		return rpzRecord{}, false
+	}
+	if z.depth == 0 {
+		z.pending = rpzRecord{inheritsOwner: content[0] == ' ' || content[0] == '\t'}
+	}
+	z.pending.lines = append(z.pending.lines, strings.TrimSpace(content))
+	for _, field := range strings.Fields(content) {
+		z.depth += strings.Count(field, "(") - strings.Count(field, ")")
+		if field = strings.Trim(field, "()"); field != "" {
+			z.pending.fields = append(z.pending.fields, field)
+		}
+	}
+	if z.depth > 0 {
+		return rpzRecord{}, false
+	}
+	z.depth = 0
+	record := z.pending
+	z.pending = rpzRecord{}
+	return record, len(record.fields) > 0
+}
+
+// processRecord applies a directive, or sends the entries of the trigger of a policy record to the sink.
+func (z *rpzZone) processRecord(record rpzRecord, sink EntrySink) error {
+	outputs, rejection := z.parseRecord(record)
+	if rejection.reason != "" {
+		return rejectLine(sink, record.text(), rejection)
+	}
+	for _, output := range outputs {
+		if err := addTypedValid(sink, output[0], output[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRecord returns the generic source type and entry pairs of a record,
+// or why the record was rejected. Directives and zone apex records have neither.
+func (z *rpzZone) parseRecord(record rpzRecord) ([][2]string, lineRejection) {
+	fields := record.fields
+	if !record.inheritsOwner && strings.HasPrefix(fields[0], "$") {
+		return nil, z.applyDirective(fields)
+	}
+
+	owner := z.owner
+	if !record.inheritsOwner {
+		owner = z.absoluteName(fields[0])
+		fields = fields[1:]
+		z.owner = owner
+	}
+	if owner == "" {
+		return nil, lineRejection{constants.InvalidReasonSyntax, "missing owner name"}
+	}
+	for len(fields) > 0 && (rpzTTLRegex.MatchString(fields[0]) || rpzClasses[strings.ToUpper(fields[0])]) {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return nil, lineRejection{constants.InvalidReasonSyntax, "missing record type"}
+	}
+	recordType := strings.ToUpper(fields[0])
+	switch recordType {
+	case "SOA":
+		if z.origin == "" {
+			z.origin = owner
+		}
+		return nil, lineRejection{}
+	case "NS":
+		return nil, lineRejection{}
+	}
+
+	trigger, inZone := z.relativeName(owner)
+	if !inZone {
+		return nil, lineRejection{constants.InvalidReasonOutsideZone, "outside the zone " + z.origin}
+	}
+	if trigger == "" {
+		return nil, lineRejection{}
+	}
+	passthru, rejection := rpzPolicy(recordType, fields[1:])
+	if rejection.reason != "" {
+		return nil, rejection
+	}
+	if passthru != z.allowlist {
+		if passthru {
+			return nil, lineRejection{constants.InvalidReasonOtherListType, "passthru policy"}
+		}
+		return nil, lineRejection{constants.InvalidReasonOtherListType, "blocking policy"}
+	}
+	return rpzTriggerEntries(trigger, z.allowlist)
+}
+
+// applyDirective applies a $ORIGIN or $TTL directive and returns why other directives are rejected.
+func (z *rpzZone) applyDirective(fields []string) lineRejection {
+	directive := strings.ToUpper(fields[0])
+	switch directive {
+	case "$ORIGIN":
+		if len(fields) < 2 {
+			return lineRejection{constants.InvalidReasonSyntax, "missing origin"}
+		}
+		z.origin = z.absoluteName(fields[1])
+		return lineRejection{}
+	case "$TTL":
+		return lineRejection{}
+	default:
+		return lineRejection{constants.InvalidReasonUnsupported, "unsupported directive " + directive}
+	}
+}
+
+// absoluteName returns a name of the zone file as a lowercase absolute name without the final dot.
+func (z *rpzZone) absoluteName(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@":
+		return z.origin
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case z.origin == "":
+		return name
+	default:
+		return name + "." + z.origin
+	}
+}
+
+// relativeName returns an absolute name relative to the origin of the zone, which is its trigger.
+func (z *rpzZone) relativeName(name string) (string, bool) {
+	switch {
+	case z.origin == "":
+		return name, true
+	case name == z.origin:
+		return "", true
+	case strings.HasSuffix(name, "."+z.origin):
+		return strings.TrimSuffix(name, "."+z.origin), true
+	default:
+		return "", false
+	}
+}
+
+// rpzPolicy reports whether a policy record is a PASSTHRU policy, or why it is not applied as a policy.
+// CNAME . (NXDOMAIN), CNAME *. (NODATA), CNAME rpz-drop. and local data all block the trigger.
+func rpzPolicy(recordType string, rdata []string) (bool, lineRejection) {
+	if recordType != "CNAME" {
+		return false, lineRejection{}
+	}
+	if len(rdata) == 0 {
+		return false, lineRejection{constants.InvalidReasonSyntax, "missing CNAME target"}
+	}
+	switch target := strings.ToLower(rdata[0]); target {
+	case "rpz-passthru.":
+		return true, lineRejection{}
+	case "rpz-tcp-only.":
+		return false, lineRejection{constants.InvalidReasonUnsupported, "tcp-only policy"}
+	default:
+		return false, lineRejection{}
+	}
+}
+
+// rpzTriggerEntries returns the generic source type and entry pairs of a trigger.
+func rpzTriggerEntries(trigger string, exception bool) ([][2]string, lineRejection) {
+	labels := strings.Split(trigger, ".")
+	suffix := labels[len(labels)-1]
+	if suffix == "rpz-ip" {
+		return rpzIPTriggerEntries(labels[:len(labels)-1])
+	}
+	for _, unsupported := range rpzUnsupportedTriggers {
+		if suffix == unsupported {
+			return nil, lineRejection{constants.InvalidReasonUnsupported, "unsupported trigger " + unsupported}
+		}
+	}
+
+	domain, wildcard := strings.CutPrefix(trigger, "*.")
+	if !u.IsDomain(domain) {
+		return nil, entryRejection(constants.SourceTypeDomain, domain, "invalid domain "+trigger)
+	}
+	prefix := ""
+	if exception {
+		prefix = "@@"
+	}
+	if wildcard {
+		// *.example.com only matches the subdomains, which a plain domain entry cannot express
+		return [][2]string{{constants.SourceTypeAdguard, prefix + "||*." + domain + "^"}}, lineRejection{}
+	}
+	return [][2]string{
+		{constants.SourceTypeDomain, domain},
+		{constants.SourceTypeAdguard, prefix + "|" + domain + "^"},
+	}, lineRejection{}
+}
+
+// rpzIPTriggerEntries returns the entry of an rpz-ip trigger, given its labels before rpz-ip:
+// the prefix length followed by the address in reverse order, where zz stands for :: in an IPv6 address.
+func rpzIPTriggerEntries(labels []string) ([][2]string, lineRejection) {
+	trigger := strings.Join(labels, ".") + ".rpz-ip"
+	if len(labels) < 2 {
+		return nil, lineRejection{constants.InvalidReasonInvalidIP, "invalid rpz-ip trigger " + trigger}
+	}
+	bits, err := strconv.Atoi(labels[0])
+	if err != nil {
+		return nil, lineRejection{constants.InvalidReasonInvalidIP, "invalid rpz-ip trigger " + trigger}
+	}
+	addressLabels := make([]string, 0, len(labels)-1)
+	for i := len(labels) - 1; i > 0; i-- {
+		addressLabels = append(addressLabels, labels[i])
+	}
+
+	var address string
+	if len(addressLabels) == 4 && !strings.Contains(trigger, "zz") {
+		address = strings.Join(addressLabels, ".")
+	} else {
+		address = rpzIPv6Address(addressLabels)
+	}
+	addr, err := netip.ParseAddr(address)
+	if err != nil || (addr.Is4() && len(addressLabels) != 4) {
+		return nil, lineRejection{constants.InvalidReasonInvalidIP, "invalid rpz-ip trigger " + trigger}
+	}
+	prefix := netip.PrefixFrom(addr, bits)
+	if !prefix.IsValid() || prefix.Masked() != prefix {
+		return nil, lineRejection{constants.InvalidReasonInvalidIP, "invalid rpz-ip trigger " + trigger}
+	}
+
+	switch {
+	case addr.Is4() && bits == 32:
+		return [][2]string{{constants.SourceTypeIpv4, addr.String()}}, lineRejection{}
+	case addr.Is4():
+		return [][2]string{{constants.SourceTypeCidrIpv4, prefix.String()}}, lineRejection{}
+	case bits == 128:
+		return [][2]string{{constants.SourceTypeIpv6, addr.String()}}, lineRejection{}
+	default:
+		return [][2]string{{constants.SourceTypeCidrIpv6, prefix.String()}}, lineRejection{}
+	}
+}
+
+// rpzIPv6Address returns the IPv6 address of the groups of an rpz-ip trigger, in address order.
+func rpzIPv6Address(groups []string) string {
+	address := strings.Join(groups, ":")
+	switch {
+	case address == "zz":
+		return "::"
+	case strings.HasPrefix(address, "zz:"):
+		return "::" + strings.TrimPrefix(address, "zz:")
+	case strings.HasSuffix(address, ":zz"):
+		return strings.TrimSuffix(address, ":zz") + "::"
+	default:
+		return strings.Replace(address, ":zz:", "::", 1)
+	}
+}
+
+// stripZoneComment removes the comment of a zone file line, a ; outside a quoted string.
+func stripZoneComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func init() {
+	RegisterProcessorTypes(
+		sourceTypeRpz,
+		[]string{constants.ListTypeBlocklist, constants.ListTypeAllowlist},
+		func(st string, lt string) Processor {
+			return NewRpzProcessor(st, lt)
+		},
+	)
+}
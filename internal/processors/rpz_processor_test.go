@@ -0,0 +1,187 @@
+package processors_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rpzZoneHeader = `$TTL 300
+$ORIGIN rpz.example.
+@ IN SOA ns1.rpz.example. hostmaster.rpz.example. (
+        2024010101 ; serial
+        3600       ; refresh
+        600        ; retry
+        86400      ; expire
+        300 )      ; minimum
+  IN NS ns1.rpz.example.
+`
+
+func TestNewRpzProcessor(t *testing.T) {
+	t.Parallel()
+
+	processor := processors.NewRpzProcessor("rpz", "blocklist")
+
+	assert.Equal(t, "rpz", processor.GetSourceType())
+	assert.Equal(t, "blocklist", processor.GetListType())
+	assert.Equal(t, []string{"domain", "adguard", "ipv4", "cidr_ipv4", "ipv6", "cidr_ipv6"}, processor.OutputTypes())
+
+	for _, listType := range []string{constants.ListTypeBlocklist, constants.ListTypeAllowlist} {
+		registered, exists := processors.Processors.GetProcessor("rpz", listType)
+		assert.True(t, exists)
+		assert.IsType(t, &processors.RpzProcessor{}, registered)
+	}
+}
+
+func TestRpzProcessor_ProcessStream(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		name            string
+		listType        string
+		content         string
+		expectedTyped   map[string][]string
+		expectedInvalid []string
+	}{
+		{
+			name:     "qname triggers",
+			listType: constants.ListTypeBlocklist,
+			content: rpzZoneHeader +
+				"ads.example.com CNAME .\n" +
+				"*.ads.example.com CNAME .\n" +
+				"Tracker.Example.com.rpz.example. 60 IN CNAME *.\n" +
+				"dropped.example.net CNAME rpz-drop.\n" +
+				"walled.example.org IN A 192.0.2.10\n" +
+				"                   IN AAAA 2001:db8::10\n" +
+				"redirect.example.org CNAME garden.example.net.\n" +
+				"@ TXT \"zone; comment\"\n",
+			expectedTyped: map[string][]string{
+				"domain": {
+					"ads.example.com",
+					"tracker.example.com",
+					"dropped.example.net",
+					"walled.example.org",
+					"walled.example.org",
+					"redirect.example.org",
+				},
+				"adguard": {
+					"|ads.example.com^",
+					"||*.ads.example.com^",
+					"|tracker.example.com^",
+					"|dropped.example.net^",
+					"|walled.example.org^",
+					"|walled.example.org^",
+					"|redirect.example.org^",
+				},
+			},
+		},
+		{
+			name:     "rpz-ip triggers",
+			listType: constants.ListTypeBlocklist,
+			content: rpzZoneHeader +
+				"32.1.2.0.192.rpz-ip CNAME .\n" +
+				"24.0.113.0.203.rpz-ip CNAME .\n" +
+				"128.1.zz.db8.2001.rpz-ip CNAME *.\n" +
+				"128.zz.1.rpz-ip CNAME .\n" +
+				"48.zz.db8.2001.rpz-ip CNAME .\n" +
+				"24.1.113.0.203.rpz-ip CNAME .\n" +
+				"33.1.2.0.192.rpz-ip CNAME .\n",
+			expectedTyped: map[string][]string{
+				"ipv4":      {"192.0.2.1"},
+				"cidr_ipv4": {"203.0.113.0/24"},
+				"ipv6":      {"2001:db8::1", "1::"},
+				"cidr_ipv6": {"2001:db8::/48"},
+			},
+			expectedInvalid: []string{
+				"24.1.113.0.203.rpz-ip CNAME . # invalid rpz-ip trigger 24.1.113.0.203.rpz-ip",
+				"33.1.2.0.192.rpz-ip CNAME . # invalid rpz-ip trigger 33.1.2.0.192.rpz-ip",
+			},
+		},
+		{
+			name:     "without origin",
+			listType: constants.ListTypeBlocklist,
+			content:  "$TTL 1h\nads.example.com 1h30m IN CNAME .\n32.4.2.0.192.rpz-ip CNAME .\n",
+			expectedTyped: map[string][]string{
+				"domain":  {"ads.example.com"},
+				"adguard": {"|ads.example.com^"},
+				"ipv4":    {"192.0.2.4"},
+			},
+		},
+		{
+			name:     "passthru triggers of a blocklist",
+			listType: constants.ListTypeBlocklist,
+			content: rpzZoneHeader +
+				"good.example.com CNAME rpz-passthru.\n" +
+				"slow.example.com CNAME rpz-tcp-only.\n" +
+				"ns.example.com.rpz-nsdname CNAME .\n" +
+				"32.1.2.0.192.rpz-client-ip CNAME .\n" +
+				"bad..example.com CNAME .\n" +
+				"outside.example.com. CNAME .\n" +
+				"$INCLUDE other.zone\n" +
+				"empty.example.com CNAME\n",
+			expectedInvalid: []string{
+				"good.example.com CNAME rpz-passthru. # passthru policy",
+				"slow.example.com CNAME rpz-tcp-only. # tcp-only policy",
+				"ns.example.com.rpz-nsdname CNAME . # unsupported trigger rpz-nsdname",
+				"32.1.2.0.192.rpz-client-ip CNAME . # unsupported trigger rpz-client-ip",
+				"bad..example.com CNAME . # invalid domain bad..example.com",
+				"outside.example.com. CNAME . # outside the zone rpz.example",
+				"$INCLUDE other.zone # unsupported directive $INCLUDE",
+				"empty.example.com CNAME # missing CNAME target",
+			},
+		},
+		{
+			name:     "passthru triggers of an allowlist",
+			listType: constants.ListTypeAllowlist,
+			content: rpzZoneHeader +
+				"good.example.com CNAME rpz-passthru.\n" +
+				"*.good.example.net CNAME rpz-passthru.\n" +
+				"32.1.2.0.192.rpz-ip CNAME rpz-passthru.\n" +
+				"ads.example.com CNAME .\n",
+			expectedTyped: map[string][]string{
+				"domain":  {"good.example.com"},
+				"adguard": {"@@|good.example.com^", "@@||*.good.example.net^"},
+				"ipv4":    {"192.0.2.1"},
+			},
+			expectedInvalid: []string{"ads.example.com CNAME . # blocking policy"},
+		},
+		{
+			name:            "unbalanced parentheses",
+			listType:        constants.ListTypeBlocklist,
+			content:         "@ SOA ns1.rpz.example. hostmaster.rpz.example. ( 1 3600\n",
+			expectedInvalid: []string{"@ SOA ns1.rpz.example. hostmaster.rpz.example. ( 1 3600 # unbalanced parentheses"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			processor := processors.NewRpzProcessor("rpz", tt.listType)
+			sink := &processors.SliceSink{}
+			err := processor.ProcessStream(context.Background(), logger, strings.NewReader(tt.content), sink)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTyped, sink.Typed, "Valid entries by type should match expected")
+			assert.Equal(t, tt.expectedInvalid, sink.Invalid, "Invalid entries should match expected")
+		})
+	}
+}
+
+func TestRpzProcessor_Process(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewRpzProcessor("rpz", "blocklist")
+
+	valid, invalid := processor.Process(context.Background(), logger,
+		rpzZoneHeader+"ads.example.com CNAME .\n32.1.2.0.192.rpz-ip CNAME .\n")
+	assert.Equal(t, []string{"ads.example.com", "|ads.example.com^", "192.0.2.1"}, valid)
+	assert.Empty(t, invalid)
+}
@@ -0,0 +1,270 @@
+package processors
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/phani-kb/multilog"
+
+	"github.com/phani-kb/dns-toolkit/internal/utils"
+)
+
+// maxLineSize is the longest line a streaming processor reads, longer lines fail the processing
+const maxLineSize = 1 << 20
+
+// ctxCheckInterval is the number of lines between two checks of the context of a streaming processor
+const ctxCheckInterval = 4096
+
+// EntrySink receives the entries found by a StreamProcessor in the order of the content.
+type EntrySink interface {
+	// AddValid receives a valid entry
+	AddValid(entry string) error
+	// AddInvalid receives a line without a valid entry
+	AddInvalid(entry string) error
+}
+
+// StreamProcessor defines the interface of processors that read their content from a reader,
+// so that a large source is never held in memory as a whole.
+type StreamProcessor interface {
+	// ProcessStream reads the content and sends its valid and invalid entries to the sink.
+	// It stops at the first error of the reader or the sink, or when ctx is cancelled, and returns it.
+	ProcessStream(ctx context.Context, logger *multilog.Logger, reader io.Reader, sink EntrySink) error
+	// GetSourceType returns the source type this processor handles
+	GetSourceType() string
+	// GetListType returns the list type this processor handles
+	GetListType() string
+}
+
+// TypedEntrySink is an EntrySink that also receives valid entries of a given generic source type,
+// for the processors whose content holds entries of several types.
+type TypedEntrySink interface {
+	EntrySink
+	// AddTypedValid receives a valid entry of the generic source type
+	AddTypedValid(sourceType, entry string) error
+}
+
+// addTypedValid sends a valid entry of a generic source type to the sink,
+// or as a plain valid entry if the sink does not keep the types apart.
+func addTypedValid(sink EntrySink, sourceType, entry string) error {
+	if typedSink, ok := sink.(TypedEntrySink); ok {
+		return typedSink.AddTypedValid(sourceType, entry)
+	}
+	return sink.AddValid(entry)
+}
+
+// ReasonEntrySink is an EntrySink that also receives the reason code of an invalid entry,
+// for the processors that know why they rejected a line.
+type ReasonEntrySink interface {
+	EntrySink
+	// AddInvalidReason receives a line without a valid entry and one of the constants.InvalidReason codes
+	AddInvalidReason(entry, reason string) error
+}
+
+// addInvalidReason sends an invalid entry and its reason code to the sink,
+// or as a plain invalid entry if the sink does not keep the reasons.
+func addInvalidReason(sink EntrySink, entry, reason string) error {
+	if reasonSink, ok := sink.(ReasonEntrySink); ok {
+		return reasonSink.AddInvalidReason(entry, reason)
+	}
+	return sink.AddInvalid(entry)
+}
+
+// lineRejection is why a processor rejected a line, or a part of it.
+type lineRejection struct {
+	reason string // one of the constants.InvalidReason codes
+	detail string // written after the line in the invalid file
+}
+
+// rejectLine sends a line rejected by a processor to the sink, with the details of its rejections
+// and the reason code of the first one.
+func rejectLine(sink EntrySink, line string, rejections ...lineRejection) error {
+	details := make([]string, 0, len(rejections))
+	for _, rejection := range rejections {
+		if rejection.detail != "" {
+			details = append(details, rejection.detail)
+		}
+	}
+	return addInvalidReason(sink, InvalidEntry(line, details...), rejections[0].reason)
+}
+
+// entryRejection is the rejection of a value that is not a valid entry of the generic source type,
+// with the reason code of its diagnosis.
+func entryRejection(genericSourceType, value, detail string) lineRejection {
+	return lineRejection{reason: utils.InvalidEntryReason(genericSourceType, value), detail: detail}
+}
+
+// SliceSink is an EntrySink that collects the entries in memory.
+// The valid entries received with a source type are also collected by type in Typed,
+// and the reason codes of the invalid entries received with one by entry in Reasons.
+type SliceSink struct {
+	Valid   []string
+	Invalid []string
+	Typed   map[string][]string
+	Reasons map[string]string
+}
+
+func (s *SliceSink) AddValid(entry string) error {
+	s.Valid = append(s.Valid, entry)
+	return nil
+}
+
+func (s *SliceSink) AddTypedValid(sourceType, entry string) error {
+	if s.Typed == nil {
+		s.Typed = make(map[string][]string)
+	}
+	s.Typed[sourceType] = append(s.Typed[sourceType], entry)
+	s.Valid = append(s.Valid, entry)
+	return nil
+}
+
+func (s *SliceSink) AddInvalid(entry string) error {
+	s.Invalid = append(s.Invalid, entry)
+	return nil
+}
+
+func (s *SliceSink) AddInvalidReason(entry, reason string) error {
+	if s.Reasons == nil {
+		s.Reasons = make(map[string]string)
+	}
+	s.Reasons[entry] = reason
+	return s.AddInvalid(entry)
+}
+
+// LineFunc classifies a trimmed line that is not a comment. It returns the entry of a valid line
+// or the invalid entry of the line; a line for which both are empty is skipped.
+type LineFunc func(line string) (valid string, invalid string)
+
+// EachLine calls fn with each trimmed line of reader that is not blank or a comment.
+// It stops at the first error of the reader or fn, or when ctx is cancelled, and returns it.
+func EachLine(ctx context.Context, reader io.Reader, fn func(line string) error) error {
+	return eachLineSkipping(ctx, reader, utils.IsComment, fn)
+}
+
+// eachLineSkipping calls fn with each trimmed line of reader for which isComment is false.
+func eachLineSkipping(
+	ctx context.Context,
+	reader io.Reader,
+	isComment func(line string) bool,
+	fn func(line string) error,
+) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if lineNumber%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if isComment(line) {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// ScanLines reads the lines of reader, skipping blank and comment lines, and sends the entries
+// classified by lineFunc to sink.
+func ScanLines(ctx context.Context, reader io.Reader, sink EntrySink, lineFunc LineFunc) error {
+	return EachLine(ctx, reader, func(line string) error {
+		valid, invalid := lineFunc(line)
+		if valid != "" {
+			return sink.AddValid(valid)
+		}
+		if invalid != "" {
+			return sink.AddInvalid(invalid)
+		}
+		return nil
+	})
+}
+
+// InvalidEntry returns the invalid entry of a rejected line, with the reasons it was rejected
+// appended as a comment.
+func InvalidEntry(line string, reasons ...string) string {
+	if len(reasons) == 0 {
+		return line
+	}
+	return line + " # " + strings.Join(reasons, "; ")
+}
+
+// LineProcessor is a StreamProcessor that classifies its content one line at a time.
+// It also implements Processor, collecting the entries of the content in memory.
+type LineProcessor struct {
+	lineFunc LineFunc
+	BaseProcessor
+}
+
+// NewLineProcessor creates a new processor classifying lines with lineFunc
+func NewLineProcessor(sourceType, listType string, lineFunc LineFunc) *LineProcessor {
+	return &LineProcessor{
+		BaseProcessor: NewBaseProcessor(sourceType, listType),
+		lineFunc:      lineFunc,
+	}
+}
+
+func (p *LineProcessor) ProcessStream(ctx context.Context, _ *multilog.Logger, reader io.Reader, sink EntrySink) error {
+	return ScanLines(ctx, reader, sink, p.lineFunc)
+}
+
+func (p *LineProcessor) Process(ctx context.Context, logger *multilog.Logger, content string) ([]string, []string) {
+	return ProcessContent(ctx, logger, p, content)
+}
+
+// ProcessContent runs a StreamProcessor on content held in memory and returns its valid and invalid entries,
+// for a streaming processor that still has to implement Process.
+func ProcessContent(
+	ctx context.Context,
+	logger *multilog.Logger,
+	processor StreamProcessor,
+	content string,
+) ([]string, []string) {
+	sink := &SliceSink{}
+	if err := processor.ProcessStream(ctx, logger, strings.NewReader(content), sink); err != nil {
+		logger.Warnf("Processing %s content error: %v", processor.GetSourceType(), err)
+	}
+	return sink.Valid, sink.Invalid
+}
+
+// processorAdapter runs a Processor that has not been migrated to streaming on the whole content of the reader.
+type processorAdapter struct {
+	Processor
+}
+
+func (a *processorAdapter) ProcessStream(
+	ctx context.Context,
+	logger *multilog.Logger,
+	reader io.Reader,
+	sink EntrySink,
+) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	validEntries, invalidEntries := a.Process(ctx, logger, string(content))
+	for _, entry := range validEntries {
+		if err := sink.AddValid(entry); err != nil {
+			return err
+		}
+	}
+	for _, entry := range invalidEntries {
+		if err := sink.AddInvalid(entry); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// AsStreamProcessor returns the processor as a StreamProcessor. A processor that only implements Process
+// is adapted by reading the whole content into memory.
+func AsStreamProcessor(processor Processor) StreamProcessor {
+	if streamProcessor, ok := processor.(StreamProcessor); ok {
+		return streamProcessor
+	}
+	return &processorAdapter{Processor: processor}
+}
@@ -0,0 +1,223 @@
+package processors_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/phani-kb/dns-toolkit/internal/processors"
+	"github.com/phani-kb/multilog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSink rejects the entries after the first limit ones
+type failingSink struct {
+	processors.SliceSink
+	limit int
+}
+
+func (s *failingSink) AddValid(entry string) error {
+	if len(s.Valid)+len(s.Invalid) >= s.limit {
+		return errors.New("sink full")
+	}
+	return s.SliceSink.AddValid(entry)
+}
+
+func (s *failingSink) AddInvalid(entry string) error {
+	if len(s.Valid)+len(s.Invalid) >= s.limit {
+		return errors.New("sink full")
+	}
+	return s.SliceSink.AddInvalid(entry)
+}
+
+func (s *failingSink) AddInvalidReason(entry, reason string) error {
+	if len(s.Valid)+len(s.Invalid) >= s.limit {
+		return errors.New("sink full")
+	}
+	return s.SliceSink.AddInvalidReason(entry, reason)
+}
+
+// contentProcessor is a Processor that only implements Process
+type contentProcessor struct {
+	processors.BaseProcessor
+}
+
+func (p *contentProcessor) Process(_ context.Context, _ *multilog.Logger, content string) ([]string, []string) {
+	fields := strings.Fields(content)
+	return fields[:1], fields[1:]
+}
+
+func upperLine(line string) (string, string) {
+	if line == "skip" {
+		return "", ""
+	}
+	if strings.HasPrefix(line, "bad") {
+		return "", line
+	}
+	return strings.ToUpper(line), ""
+}
+
+func TestScanLines(t *testing.T) {
+	t.Parallel()
+
+	sink := &processors.SliceSink{}
+	content := "# comment\n  a.example \n\nbad entry\nskip\r\nb.example"
+	err := processors.ScanLines(context.Background(), strings.NewReader(content), sink, upperLine)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A.EXAMPLE", "B.EXAMPLE"}, sink.Valid)
+	assert.Equal(t, []string{"bad entry"}, sink.Invalid)
+}
+
+func TestScanLines_Errors(t *testing.T) {
+	t.Parallel()
+
+	err := processors.ScanLines(
+		context.Background(),
+		strings.NewReader("a\nb\nc\n"),
+		&failingSink{limit: 2},
+		upperLine,
+	)
+	assert.EqualError(t, err, "sink full")
+
+	err = processors.ScanLines(
+		context.Background(),
+		strings.NewReader(strings.Repeat("a", 2<<20)),
+		&processors.SliceSink{},
+		upperLine,
+	)
+	assert.Error(t, err, "a line longer than the maximum fails")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = processors.ScanLines(ctx, strings.NewReader("a\n"), &processors.SliceSink{}, upperLine)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLineProcessor(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	processor := processors.NewLineProcessor("domain", "blocklist", upperLine)
+	assert.Equal(t, "domain", processor.GetSourceType())
+	assert.Equal(t, "blocklist", processor.GetListType())
+
+	sink := &processors.SliceSink{}
+	err := processor.ProcessStream(context.Background(), logger, strings.NewReader("a\nbad\n"), sink)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A"}, sink.Valid)
+	assert.Equal(t, []string{"bad"}, sink.Invalid)
+
+	valid, invalid := processor.Process(context.Background(), logger, "a\nbad\n")
+	assert.Equal(t, []string{"A"}, valid)
+	assert.Equal(t, []string{"bad"}, invalid)
+}
+
+func TestAsStreamProcessor(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	lineProcessor := processors.NewLineProcessor("domain", "blocklist", upperLine)
+	assert.Same(t, lineProcessor, processors.AsStreamProcessor(lineProcessor))
+
+	processor := processors.AsStreamProcessor(
+		&contentProcessor{BaseProcessor: processors.NewBaseProcessor("custom", "allowlist")},
+	)
+	assert.Equal(t, "custom", processor.GetSourceType())
+	assert.Equal(t, "allowlist", processor.GetListType())
+
+	sink := &processors.SliceSink{}
+	err := processor.ProcessStream(context.Background(), logger, strings.NewReader("a b c"), sink)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, sink.Valid)
+	assert.Equal(t, []string{"b", "c"}, sink.Invalid)
+
+	err = processor.ProcessStream(context.Background(), logger, strings.NewReader("a b c"), &failingSink{limit: 1})
+	assert.EqualError(t, err, "sink full")
+
+	valid, invalid := processors.ProcessContent(context.Background(), logger, processor, "x y")
+	assert.Equal(t, []string{"x"}, valid)
+	assert.Equal(t, []string{"y"}, invalid)
+}
+
+func TestInvalidEntry(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "bad line", processors.InvalidEntry("bad line"))
+	assert.Equal(t, "bad line # first", processors.InvalidEntry("bad line", "first"))
+	assert.Equal(t, "bad line # first; second", processors.InvalidEntry("bad line", "first", "second"))
+}
+
+func TestProcessorInvalidReasons(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+
+	tests := []struct {
+		sourceType string
+		content    string
+		expected   map[string]string // reason code by invalid entry
+	}{
+		{
+			sourceType: constants.SourceTypeHostnameSinkhole,
+			content:    "0.0.0.0 ads.example.com\n10.0.0.1 intranet.example.com\n0.0.0.0 bad_host\nexample.com\n",
+			expected: map[string]string{
+				"10.0.0.1 intranet.example.com # non-sinkhole address 10.0.0.1": constants.InvalidReasonNonSinkhole,
+				"0.0.0.0 bad_host # invalid hostname bad_host":                  constants.InvalidReasonSingleLabel,
+				"example.com # invalid address example.com":                     constants.InvalidReasonInvalidIP,
+			},
+		},
+		{
+			sourceType: constants.SourceTypeDomainDnsmasq,
+			content:    "address=/ads.example.com/0.0.0.0\nserver=/corp.example.com/10.0.0.1\ncache-size=1000\nads.example.org\n",
+			expected: map[string]string{
+				"server=/corp.example.com/10.0.0.1 # forwarded to 10.0.0.1": constants.InvalidReasonNonSinkhole,
+				"cache-size=1000 # unsupported option cache-size":           constants.InvalidReasonUnsupported,
+				"ads.example.org # not a dnsmasq option":                    constants.InvalidReasonSyntax,
+			},
+		},
+		{
+			sourceType: constants.SourceTypeDomainUnbound,
+			content:    "server:\nlocal-zone: \"ads.example.com\" always_nxdomain\nlocal-zone: \"corp.example.com\" transparent\n",
+			expected: map[string]string{
+				`local-zone: "corp.example.com" transparent # non-blocking zone type transparent`: constants.InvalidReasonUnsupported,
+			},
+		},
+		{
+			sourceType: constants.SourceTypeRpz,
+			content: "$ORIGIN rpz.example.\nads.example.com CNAME .\nok.example.com CNAME rpz-passthru.\n" +
+				"ads.example.com.rpz-nsdname CNAME .\nother.example. CNAME .\n999.1.2.0.192.rpz-ip CNAME .\n",
+			expected: map[string]string{
+				"ok.example.com CNAME rpz-passthru. # passthru policy":                       constants.InvalidReasonOtherListType,
+				"ads.example.com.rpz-nsdname CNAME . # unsupported trigger rpz-nsdname":      constants.InvalidReasonUnsupported,
+				"other.example. CNAME . # outside the zone rpz.example":                      constants.InvalidReasonOutsideZone,
+				"999.1.2.0.192.rpz-ip CNAME . # invalid rpz-ip trigger 999.1.2.0.192.rpz-ip": constants.InvalidReasonInvalidIP,
+			},
+		},
+		{
+			sourceType: constants.SourceTypeMixed,
+			content:    "ads.example.com\nintranet\nhxxp://%zz/gate\n",
+			expected: map[string]string{
+				"intranet":        constants.InvalidReasonSingleLabel,
+				"hxxp://%zz/gate": constants.InvalidReasonURL,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceType, func(t *testing.T) {
+			t.Parallel()
+			processor, exists := processors.Processors.GetProcessor(tt.sourceType, constants.ListTypeBlocklist)
+			require.True(t, exists)
+			sink := &processors.SliceSink{}
+			err := processors.AsStreamProcessor(processor).ProcessStream(
+				context.Background(), logger, strings.NewReader(tt.content), sink,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sink.Reasons)
+			assert.Len(t, sink.Invalid, len(tt.expected), "every invalid entry should have a reason code")
+		})
+	}
+}
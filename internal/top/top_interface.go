@@ -1,6 +1,8 @@
 package top
 
 import (
+	"context"
+
 	"github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/utils"
 	"github.com/phani-kb/multilog"
@@ -11,6 +13,7 @@ type EntriesService interface {
 	// FindTopEntries processes files to find the top entries that appear in at least minSources
 	// sources for a specific generic source type and list type
 	FindTopEntries(
+		ctx context.Context,
 		logger *multilog.Logger,
 		genericSourceType string,
 		listType string,
@@ -23,6 +26,7 @@ type EntriesService interface {
 	// ProcessTopEntries processes all processed files to generate top entries for different
 	// combinations of generic source types, list types and minimum sources thresholds
 	ProcessTopEntries(
+		ctx context.Context,
 		logger *multilog.Logger,
 		genericSourceTypes []string,
 		processedFiles []common.ProcessedFile,
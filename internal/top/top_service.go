@@ -3,6 +3,7 @@ package top
 import (
 	"bufio"
 	"container/heap"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -37,6 +38,7 @@ func NewDefaultService(topDir string, summaryDir string) *DefaultTopEntriesServi
 // FindTopEntries processes files to find the top entries that appear in at least minSources
 // sources for a specific generic source type and list type
 func (s *DefaultTopEntriesService) FindTopEntries(
+	ctx context.Context,
 	logger *multilog.Logger,
 	gst string,
 	listType string,
@@ -104,6 +106,9 @@ func (s *DefaultTopEntriesService) FindTopEntries(
 
 		scanner := bufio.NewScanner(file)
 		for scanner.Scan() {
+			if ctx.Err() != nil {
+				return
+			}
 			lineBytes := scanner.Bytes()
 
 			// Trim leading space
@@ -146,7 +151,7 @@ func (s *DefaultTopEntriesService) FindTopEntries(
 	}
 
 	if len(relevantFiles) > constants.MinFilesForParallelProcessing && fileProcessingWorkers > 1 {
-		filePool := common.NewDTWorkerPool(fileProcessingWorkers)
+		filePool := common.NewDTWorkerPoolWithContext(ctx, fileProcessingWorkers)
 		logger.Debugf(
 			"Processing %d files in parallel for %s (%s) using %d workers",
 			len(relevantFiles),
@@ -164,10 +169,21 @@ func (s *DefaultTopEntriesService) FindTopEntries(
 	} else {
 		logger.Debugf("Processing %d files sequentially for %s (%s)", len(relevantFiles), gst, listType)
 		for _, pf := range relevantFiles {
+			if ctx.Err() != nil {
+				break
+			}
 			processFileFunc(pf, stringPool)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return common.TopSummary{}, fmt.Errorf("finding top entry(s) for %s (%s, min %d): %w", gst,
+			listType,
+			minSources,
+			err,
+		)
+	}
+
 	logger.Debugf(
 		"Finished populating entrySources for %s (%s). Unique entries: %d. Time taken: %s",
 		gst,
@@ -227,6 +243,7 @@ func (s *DefaultTopEntriesService) FindTopEntries(
 // ProcessTopEntries processes all processed files to generate top entries for different
 // combinations of generic source types, list types and minimum sources thresholds
 func (s *DefaultTopEntriesService) ProcessTopEntries(
+	ctx context.Context,
 	logger *multilog.Logger,
 	genericSourceTypes []string,
 	processedFiles []common.ProcessedFile,
@@ -237,7 +254,7 @@ func (s *DefaultTopEntriesService) ProcessTopEntries(
 	var mu sync.Mutex
 	topSummaries := make([]common.TopSummary, 0)
 
-	workerPool := common.NewDTWorkerPool(maxWorkers)
+	workerPool := common.NewDTWorkerPoolWithContext(ctx, maxWorkers)
 
 	stringPool := utils.NewDTEntryPool()
 
@@ -247,6 +264,7 @@ func (s *DefaultTopEntriesService) ProcessTopEntries(
 				currentGst, currentListType, currentMinSrc := gst, listType, minSrc
 				workerPool.Submit(func() {
 					topSummary, err := s.FindTopEntries(
+						ctx,
 						logger,
 						currentGst,
 						currentListType,
@@ -278,6 +296,11 @@ func (s *DefaultTopEntriesService) ProcessTopEntries(
 	}
 	workerPool.Wait()
 
+	if err := ctx.Err(); err != nil {
+		// Keep the previous summary file intact rather than saving a partial result
+		return nil, err
+	}
+
 	filteredSummaries := s.FilterTopSummaries(topSummaries)
 
 	_, err := s.SaveTopSummaries(logger, filteredSummaries)
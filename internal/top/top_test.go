@@ -1,6 +1,7 @@
 package top
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -335,6 +336,7 @@ func TestFindTopEntries(t *testing.T) {
 	service := NewDefaultService(topDir, summaryDir)
 
 	summary, err := service.FindTopEntries(
+		context.Background(),
 		logger,
 		gst,
 		listType,
@@ -362,6 +364,7 @@ func TestFindTopEntries_DefaultImpl(t *testing.T) {
 	stringPool := utils.NewDTEntryPool()
 
 	summary, err := service.FindTopEntries(
+		context.Background(),
 		logger,
 		gst,
 		listType,
@@ -430,6 +433,7 @@ func TestProcessTopEntries(t *testing.T) {
 	service := NewDefaultService(topDir, summaryDir)
 
 	summaries, err := service.ProcessTopEntries(
+		context.Background(),
 		logger,
 		gsts,
 		files,
@@ -445,3 +449,23 @@ func TestProcessTopEntries(t *testing.T) {
 	_, err = os.Stat(summaryFile)
 	assert.NoError(t, err)
 }
+
+func TestProcessTopEntries_Cancelled(t *testing.T) {
+	logger, topDir, summaryDir := setup(t)
+	testDir := filepath.Dir(topDir)
+	defer cleanup(t, testDir)
+
+	files := createTestProcessedFiles(t, testDir, "domain", "blocklist", 3)
+	service := NewDefaultService(topDir, summaryDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summaries, err := service.ProcessTopEntries(ctx, logger, []string{"domain"}, files, []int{1}, 5, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, summaries)
+
+	summaryFile := filepath.Join(summaryDir, constants.DefaultSummaryFiles["top"])
+	_, err = os.Stat(summaryFile)
+	assert.True(t, os.IsNotExist(err), "summary file should not be written for a cancelled run")
+}
@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// AdguardRuleKind is the kind of an AdGuard/ABP rule
+type AdguardRuleKind int
+
+const (
+	AdguardRuleNetwork  AdguardRuleKind = iota // basic rule matching a hostname or URL pattern
+	AdguardRuleRegex                           // basic rule with a /regex/ pattern
+	AdguardRuleCosmetic                        // element hiding, CSS, scriptlet or HTML filtering rule
+)
+
+// adguardCosmeticMarkers separate the domains of a cosmetic rule from its body
+var adguardCosmeticMarkers = []string{"##", "#@#", "#?#", "#@?#", "#$#", "#@$#", "#%#", "#@%#", "$$", "$@$"}
+
+// adguardDNSModifiers are the modifiers AdGuard Home applies to DNS queries, rules with other modifiers
+// are meant for browsers
+var adguardDNSModifiers = map[string]bool{
+	"badfilter":  true,
+	"client":     true,
+	"ctag":       true,
+	"denyallow":  true,
+	"dnsrewrite": true,
+	"dnstype":    true,
+	"important":  true,
+}
+
+// adguardListModifiers take a |-separated list of values whose order does not matter
+var adguardListModifiers = map[string]bool{
+	"client":    true,
+	"ctag":      true,
+	"denyallow": true,
+	"dnstype":   true,
+}
+
+var (
+	adguardModifierNameRegex = regexp.MustCompile(`^~?[a-zA-Z][a-zA-Z0-9_-]*$`)
+	adguardHostPatternRegex  = regexp.MustCompile(`^[a-z0-9*_.-]+$`)
+)
+
+// AdguardModifier is a modifier of an AdGuard rule, such as $important or $dnstype=AAAA
+type AdguardModifier struct {
+	Name    string // lower case, without the ~ of a negated modifier
+	Value   string
+	Negated bool
+}
+
+// AdguardRule is a parsed AdGuard/ABP filtering rule
+type AdguardRule struct {
+	Raw       string
+	Pattern   string // the pattern without the exception prefix and the modifiers
+	Modifiers []AdguardModifier
+	Kind      AdguardRuleKind
+	Exception bool
+}
+
+// ParseAdguardRule parses a line of an AdGuard/ABP filter list that is not a comment.
+//
+// Parameters:
+//   - line: The rule to parse
+//
+// Returns:
+//   - The parsed rule
+//   - An error if the line is not a well-formed rule
+func ParseAdguardRule(line string) (AdguardRule, error) {
+	line = strings.TrimSpace(line)
+	rule := AdguardRule{Raw: line}
+	if line == "" {
+		return rule, fmt.Errorf("empty rule")
+	}
+	for _, marker := range adguardCosmeticMarkers {
+		if strings.Contains(line, marker) {
+			rule.Kind = AdguardRuleCosmetic
+			rule.Pattern = line
+			rule.Exception = strings.Contains(line, "#@") || strings.Contains(line, "$@$")
+			return rule, nil
+		}
+	}
+
+	rest, exception := strings.CutPrefix(line, "@@")
+	rule.Exception = exception
+	pattern, modifiers := rest, ""
+	if strings.HasPrefix(rest, "/") {
+		if idx := strings.LastIndex(rest, "/$"); idx > 0 {
+			pattern, modifiers = rest[:idx+1], rest[idx+2:]
+		}
+		if len(pattern) > 1 && strings.HasSuffix(pattern, "/") {
+			rule.Kind = AdguardRuleRegex
+		}
+	} else if idx := strings.LastIndex(rest, "$"); idx >= 0 {
+		pattern, modifiers = rest[:idx], rest[idx+1:]
+	}
+	rule.Pattern = pattern
+
+	for _, option := range splitAdguardModifiers(modifiers) {
+		name, value, _ := strings.Cut(option, "=")
+		if !adguardModifierNameRegex.MatchString(name) {
+			return rule, fmt.Errorf("invalid modifier %q", option)
+		}
+		modifier := AdguardModifier{Value: value}
+		name, modifier.Negated = strings.CutPrefix(name, "~")
+		modifier.Name = strings.ToLower(name)
+		rule.Modifiers = append(rule.Modifiers, modifier)
+	}
+	if rule.Pattern == "" && len(rule.Modifiers) == 0 {
+		return rule, fmt.Errorf("empty pattern")
+	}
+	return rule, nil
+}
+
+// splitAdguardModifiers splits the modifiers of a rule at the commas that are not escaped
+func splitAdguardModifiers(modifiers string) []string {
+	if modifiers == "" {
+		return nil
+	}
+	var options []string
+	start := 0
+	for i := 0; i < len(modifiers); i++ {
+		switch modifiers[i] {
+		case '\\':
+			i++
+		case ',':
+			options = append(options, modifiers[start:i])
+			start = i + 1
+		}
+	}
+	return append(options, modifiers[start:])
+}
+
+// HasModifier reports whether the rule has the modifier with the given name
+func (r AdguardRule) HasModifier(name string) bool {
+	return slices.ContainsFunc(r.Modifiers, func(m AdguardModifier) bool { return m.Name == name })
+}
+
+// WithoutModifier returns a copy of the rule without the modifier with the given name
+func (r AdguardRule) WithoutModifier(name string) AdguardRule {
+	r.Modifiers = slices.DeleteFunc(slices.Clone(r.Modifiers), func(m AdguardModifier) bool { return m.Name == name })
+	return r
+}
+
+// DNSCompatible reports whether a DNS server such as AdGuard Home applies the rule as written,
+// and the reason if it does not.
+func (r AdguardRule) DNSCompatible() (bool, string) {
+	switch r.Kind {
+	case AdguardRuleCosmetic:
+		return false, "cosmetic rule"
+	case AdguardRuleRegex:
+		return false, "regex rule"
+	}
+	for _, modifier := range r.Modifiers {
+		if !adguardDNSModifiers[modifier.Name] {
+			return false, "unsupported modifier " + modifier.Name
+		}
+	}
+	if r.Pattern == "" {
+		return false, "no pattern"
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(r.Pattern), "|"), "|")
+	host = strings.TrimSuffix(strings.TrimSuffix(host, "|"), "^")
+	if !adguardHostPatternRegex.MatchString(host) {
+		return false, "not a hostname pattern"
+	}
+	return true, ""
+}
+
+// String returns the canonical form of the rule: the pattern in lower case and the modifiers sorted by name,
+// with the values of list modifiers sorted. Rules that are semantically equal have the same canonical form.
+// A regex or cosmetic rule is returned as written.
+func (r AdguardRule) String() string {
+	if r.Kind == AdguardRuleCosmetic {
+		return r.Raw
+	}
+	var b strings.Builder
+	if r.Exception {
+		b.WriteString("@@")
+	}
+	if r.Kind == AdguardRuleRegex {
+		b.WriteString(r.Pattern)
+	} else {
+		b.WriteString(strings.ToLower(r.Pattern))
+	}
+
+	options := make([]string, 0, len(r.Modifiers))
+	for _, modifier := range r.Modifiers {
+		option := modifier.Name
+		if modifier.Negated {
+			option = "~" + option
+		}
+		if modifier.Value != "" {
+			option += "=" + canonicalModifierValue(modifier)
+		}
+		options = append(options, option)
+	}
+	slices.Sort(options)
+	options = slices.Compact(options)
+	if len(options) > 0 {
+		b.WriteString("$" + strings.Join(options, ","))
+	}
+	return b.String()
+}
+
+// canonicalModifierValue sorts and dedupes the values of a list modifier,
+// DNS record types and hostnames are case-insensitive.
+func canonicalModifierValue(modifier AdguardModifier) string {
+	if !adguardListModifiers[modifier.Name] {
+		return modifier.Value
+	}
+	values := strings.Split(modifier.Value, "|")
+	for i, value := range values {
+		switch modifier.Name {
+		case "dnstype":
+			values[i] = strings.ToUpper(value)
+		case "denyallow":
+			values[i] = strings.ToLower(value)
+		}
+	}
+	slices.Sort(values)
+	return strings.Join(slices.Compact(values), "|")
+}
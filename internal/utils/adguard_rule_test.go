@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdguardRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line      string
+		pattern   string
+		modifiers []AdguardModifier
+		kind      AdguardRuleKind
+		exception bool
+		wantErr   bool
+	}{
+		{line: "||example.com^", pattern: "||example.com^"},
+		{line: "example.com", pattern: "example.com"},
+		{line: "@@||example.com^", pattern: "||example.com^", exception: true},
+		{
+			line:      "@@||example.com^$important",
+			pattern:   "||example.com^",
+			modifiers: []AdguardModifier{{Name: "important"}},
+			exception: true,
+		},
+		{
+			line:    "||example.com^$dnstype=~A|AAAA,~third-party,Client='My\\, laptop'",
+			pattern: "||example.com^",
+			modifiers: []AdguardModifier{
+				{Name: "dnstype", Value: "~A|AAAA"},
+				{Name: "third-party", Negated: true},
+				{Name: "client", Value: "'My\\, laptop'"},
+			},
+		},
+		{line: "||example.com^$", pattern: "||example.com^"},
+		{line: "/ads[0-9]+\\.example/", pattern: "/ads[0-9]+\\.example/", kind: AdguardRuleRegex},
+		{
+			line:      "/^ad.*$/$important",
+			pattern:   "/^ad.*$/",
+			modifiers: []AdguardModifier{{Name: "important"}},
+			kind:      AdguardRuleRegex,
+		},
+		{line: "/banner/ads.js", pattern: "/banner/ads.js"},
+		{line: "example.com##.banner", pattern: "example.com##.banner", kind: AdguardRuleCosmetic},
+		{line: "example.com#@#.banner", pattern: "example.com#@#.banner", kind: AdguardRuleCosmetic, exception: true},
+		{line: "example.com$$script[data-ad]", pattern: "example.com$$script[data-ad]", kind: AdguardRuleCosmetic},
+		{
+			line:      "$third-party,domain=example.net",
+			modifiers: []AdguardModifier{{Name: "third-party"}, {Name: "domain", Value: "example.net"}},
+		},
+		{line: "||example.com^$bad modifier", wantErr: true},
+		{line: "@@", wantErr: true},
+		{line: "  ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseAdguardRule(tt.line)
+		if tt.wantErr {
+			assert.Error(t, err, tt.line)
+			continue
+		}
+		require.NoError(t, err, tt.line)
+		assert.Equal(t, tt.pattern, rule.Pattern, tt.line)
+		assert.Equal(t, tt.modifiers, rule.Modifiers, tt.line)
+		assert.Equal(t, tt.kind, rule.Kind, tt.line)
+		assert.Equal(t, tt.exception, rule.Exception, tt.line)
+	}
+}
+
+func TestAdguardRule_DNSCompatible(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line   string
+		reason string
+	}{
+		{line: "||example.com^"},
+		{line: "||*.ads.example.com^|"},
+		{line: "example.com"},
+		{line: "@@||example.com^$important"},
+		{line: "||example.com^$client=192.168.1.2,ctag=device_pc"},
+		{line: "||example.com^$dnstype=AAAA,denyallow=a.com|b.com"},
+		{line: "||example.com^$dnsrewrite=NOERROR;A;1.2.3.4"},
+		{line: "||example.com^$badfilter"},
+		{line: "example.com##.banner", reason: "cosmetic rule"},
+		{line: "/ads[0-9]+/", reason: "regex rule"},
+		{line: "||example.com^$third-party", reason: "unsupported modifier third-party"},
+		{line: "||example.com^$removeparam=utm_source", reason: "unsupported modifier removeparam"},
+		{line: "||example.com/ads.js", reason: "not a hostname pattern"},
+		{line: "0.0.0.0 example.com", reason: "not a hostname pattern"},
+	}
+
+	for _, tt := range tests {
+		rule, err := ParseAdguardRule(tt.line)
+		require.NoError(t, err, tt.line)
+		compatible, reason := rule.DNSCompatible()
+		assert.Equal(t, tt.reason == "", compatible, tt.line)
+		assert.Equal(t, tt.reason, reason, tt.line)
+	}
+}
+
+func TestAdguardRule_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lines     []string
+		canonical string
+	}{
+		{lines: []string{"||Example.COM^", "||example.com^$"}, canonical: "||example.com^"},
+		{
+			lines:     []string{"||example.com^$important,dnstype=AAAA|a", "||example.com^$DNSTYPE=A|aaaa,Important"},
+			canonical: "||example.com^$dnstype=A|AAAA,important",
+		},
+		{
+			lines:     []string{"@@||example.com^$denyallow=B.com|a.com", "@@||example.com^$denyallow=a.com|b.com|a.com"},
+			canonical: "@@||example.com^$denyallow=a.com|b.com",
+		},
+		{lines: []string{"/Ads[0-9]+/$important"}, canonical: "/Ads[0-9]+/$important"},
+		{lines: []string{"Example.com##.Banner"}, canonical: "Example.com##.Banner"},
+	}
+
+	for _, tt := range tests {
+		for _, line := range tt.lines {
+			rule, err := ParseAdguardRule(line)
+			require.NoError(t, err, line)
+			assert.Equal(t, tt.canonical, rule.String(), line)
+		}
+	}
+
+	assert.NotEqual(t,
+		mustParseAdguardRule(t, "||example.com^").String(),
+		mustParseAdguardRule(t, "||example.com").String(),
+		"a pattern without a separator also matches longer hostnames",
+	)
+}
+
+func TestAdguardRule_Modifiers(t *testing.T) {
+	t.Parallel()
+
+	rule := mustParseAdguardRule(t, "@@||example.com^$important,dnstype=A")
+	assert.True(t, rule.HasModifier("important"))
+	assert.False(t, rule.HasModifier("badfilter"))
+
+	stripped := rule.WithoutModifier("important")
+	assert.False(t, stripped.HasModifier("important"))
+	assert.True(t, rule.HasModifier("important"), "the rule itself is not changed")
+	assert.Equal(t, "@@||example.com^$dnstype=A", stripped.String())
+}
+
+func mustParseAdguardRule(t *testing.T, line string) AdguardRule {
+	rule, err := ParseAdguardRule(line)
+	require.NoError(t, err)
+	return rule
+}
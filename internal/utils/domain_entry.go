@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+)
+
+// ErrUnsupportedDomainFormat is returned when a domain entry is rendered to a format not in constants.DomainFormats
+var ErrUnsupportedDomainFormat = errors.New("unsupported domain format")
+
+// ParseDomainEntry returns the domain of a domain entry and how the entry matches hostnames:
+// *.example.com matches example.com and its subdomains, example.com only matches example.com.
+//
+// Parameters:
+//   - entry: The domain entry, with or without the *. prefix
+//
+// Returns:
+//   - The domain of the entry
+//   - constants.MatchSemanticsSubdomains or constants.MatchSemanticsExact
+//   - true if the domain of the entry is a valid domain name, false otherwise
+func ParseDomainEntry(entry string) (string, string, bool) {
+	entry = strings.TrimSpace(entry)
+	semantics := constants.MatchSemanticsExact
+	if domain, found := strings.CutPrefix(entry, constants.WildcardDomainPrefix); found {
+		entry = domain
+		semantics = constants.MatchSemanticsSubdomains
+	}
+	return entry, semantics, IsDomain(entry)
+}
+
+// IsDomainEntry checks if a string is a domain, or a domain with the *. prefix matching its subdomains.
+func IsDomainEntry(entry string) bool {
+	_, _, ok := ParseDomainEntry(entry)
+	return ok
+}
+
+// DomainEntry returns the entry of a domain matching hostnames with the semantics,
+// the domain itself unless the semantics is constants.MatchSemanticsSubdomains.
+func DomainEntry(domain, semantics string) string {
+	if semantics == constants.MatchSemanticsSubdomains && !strings.HasPrefix(domain, constants.WildcardDomainPrefix) {
+		return constants.WildcardDomainPrefix + domain
+	}
+	return domain
+}
+
+// SubdomainsFilepath returns the path of the file listing the domains of a domain file
+// that also match their subdomains, next to the file.
+func SubdomainsFilepath(filePath string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + constants.SubdomainsFileSuffix + ext
+}
+
+// RenderDomainRule renders a blocked domain as the rule of a format in constants.DomainFormats
+// with the matching semantics, as far as the format can express it:
+// hosts files only match the hostname itself, and dnsmasq address lines always match the subdomains.
+//
+// Parameters:
+//   - format: The format to render the domain to
+//   - domain: The domain, without the *. prefix
+//   - semantics: constants.MatchSemanticsSubdomains to match the subdomains as well, the domain only otherwise
+//
+// Returns:
+//   - The rule of the domain in the format
+//   - An error if the format is unknown or the domain is not a valid domain name
+func RenderDomainRule(format, domain, semantics string) (string, error) {
+	if !slices.Contains(constants.DomainFormats, format) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDomainFormat, format)
+	}
+	if !IsDomain(domain) {
+		return "", fmt.Errorf("invalid domain: %s", domain)
+	}
+	subdomains := semantics == constants.MatchSemanticsSubdomains
+	switch format {
+	case constants.DomainFormatDomains:
+		return DomainEntry(domain, semantics), nil
+	case constants.DomainFormatHosts:
+		return "0.0.0.0 " + domain, nil
+	case constants.DomainFormatAdguard:
+		if subdomains {
+			return "||" + domain + "^", nil
+		}
+		return "|" + domain + "^", nil
+	case constants.DomainFormatUnbound:
+		if subdomains {
+			return fmt.Sprintf("local-zone: %q always_nxdomain", domain+"."), nil
+		}
+		// the name gets no other record in the default transparent zone
+		return fmt.Sprintf("local-data: %q", domain+". A 0.0.0.0"), nil
+	case constants.DomainFormatDnsmasq:
+		if subdomains {
+			return "address=/" + domain + "/", nil
+		}
+		return "host-record=" + domain + ",0.0.0.0,::", nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedDomainFormat, format)
+	}
+}
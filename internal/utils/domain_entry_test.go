@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDomainEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		entry     string
+		domain    string
+		semantics string
+		valid     bool
+	}{
+		{"example.com", "example.com", constants.MatchSemanticsExact, true},
+		{"*.example.com", "example.com", constants.MatchSemanticsSubdomains, true},
+		{" *.ads.example.com ", "ads.example.com", constants.MatchSemanticsSubdomains, true},
+		{"*.*.example.com", "*.example.com", constants.MatchSemanticsSubdomains, false},
+		{"*example.com", "*example.com", constants.MatchSemanticsExact, false},
+		{"*.", "", constants.MatchSemanticsSubdomains, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			t.Parallel()
+			domain, semantics, valid := ParseDomainEntry(tt.entry)
+			assert.Equal(t, tt.domain, domain)
+			assert.Equal(t, tt.semantics, semantics)
+			assert.Equal(t, tt.valid, valid)
+			assert.Equal(t, tt.valid, IsDomainEntry(tt.entry))
+		})
+	}
+}
+
+func TestDomainEntry(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "example.com", DomainEntry("example.com", constants.MatchSemanticsExact))
+	assert.Equal(t, "example.com", DomainEntry("example.com", ""))
+	assert.Equal(t, "*.example.com", DomainEntry("example.com", constants.MatchSemanticsSubdomains))
+	assert.Equal(t, "*.example.com", DomainEntry("*.example.com", constants.MatchSemanticsSubdomains))
+}
+
+func TestRenderDomainRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format     string
+		exact      string
+		subdomains string
+	}{
+		{constants.DomainFormatDomains, "example.com", "*.example.com"},
+		{constants.DomainFormatHosts, "0.0.0.0 example.com", "0.0.0.0 example.com"},
+		{constants.DomainFormatAdguard, "|example.com^", "||example.com^"},
+		{constants.DomainFormatUnbound, `local-data: "example.com. A 0.0.0.0"`, `local-zone: "example.com." always_nxdomain`},
+		{constants.DomainFormatDnsmasq, "host-record=example.com,0.0.0.0,::", "address=/example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+			rule, err := RenderDomainRule(tt.format, "example.com", constants.MatchSemanticsExact)
+			require.NoError(t, err)
+			assert.Equal(t, tt.exact, rule)
+
+			rule, err = RenderDomainRule(tt.format, "example.com", constants.MatchSemanticsSubdomains)
+			require.NoError(t, err)
+			assert.Equal(t, tt.subdomains, rule)
+		})
+	}
+
+	_, err := RenderDomainRule("bind", "example.com", constants.MatchSemanticsExact)
+	assert.ErrorIs(t, err, ErrUnsupportedDomainFormat)
+
+	_, err = RenderDomainRule(constants.DomainFormatAdguard, "not a domain", constants.MatchSemanticsSubdomains)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnsupportedDomainFormat)
+
+	_, err = RenderDomainRule(constants.DomainFormatAdguard, "*.example.com", constants.MatchSemanticsExact)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnsupportedDomainFormat)
+}
+
+func TestSubdomainsFilepath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		filepath.Join("data", "consolidated", "domain_blocklist_subdomains.txt"),
+		SubdomainsFilepath(filepath.Join("data", "consolidated", "domain_blocklist.txt")),
+	)
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+)
+
+// maxLabelLength is the maximum length of a label of a domain name
+const maxLabelLength = 63
+
+// InvalidEntryReason returns the reason code of an invalid entry of a processed file,
+// one of the constants.InvalidReason codes, for an entry its processor gave no code. An entry rejected by
+// a rule of its processor carries the reason as a comment, other entries are diagnosed as entries of their
+// generic source type.
+//
+// Parameters:
+//   - genericSourceType: The generic source type of the processed file (domain, ipv4, etc.)
+//   - entry: The invalid entry
+//
+// Returns:
+//   - The reason code of the entry
+func InvalidEntryReason(genericSourceType, entry string) string {
+	if strings.Contains(entry, " # ") {
+		return constants.InvalidReasonProcessor
+	}
+	fields := strings.Fields(entry)
+	if len(fields) == 0 {
+		return constants.InvalidReasonNoMatch
+	}
+
+	var reason string
+	switch genericSourceType {
+	case constants.SourceTypeDomain:
+		reason = invalidDomainReason(fields[0])
+	case constants.SourceTypeIpv4, constants.SourceTypeIpv6, constants.SourceTypeCidrIpv4, constants.SourceTypeCidrIpv6:
+		reason = invalidIPReason(genericSourceType, fields[0])
+	default:
+		return constants.InvalidReasonNoMatch
+	}
+	if reason == "" {
+		// the first field is a valid entry of the type
+		if len(fields) > 1 {
+			return constants.InvalidReasonExtraFields
+		}
+		return constants.InvalidReasonNoMatch
+	}
+	return reason
+}
+
+// invalidDomainReason returns why a value is not a domain, empty if it is one.
+func invalidDomainReason(value string) string {
+	if IsIP(value) {
+		return constants.InvalidReasonIPInDomainList
+	}
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return constants.InvalidReasonIPInDomainList
+	}
+	if strings.Contains(value, "://") {
+		return constants.InvalidReasonURL
+	}
+	if _, port, err := net.SplitHostPort(value); err == nil && isPort(port) {
+		return constants.InvalidReasonPort
+	}
+	if strings.Contains(value, "/") {
+		return constants.InvalidReasonURL
+	}
+	if IsDomainEntry(value) {
+		return ""
+	}
+	if len(value) > constants.MaxDomainLength {
+		return constants.InvalidReasonTooLong
+	}
+
+	labels := strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, constants.WildcardDomainPrefix), "."), ".")
+	if len(labels) < 2 {
+		return constants.InvalidReasonSingleLabel
+	}
+	for _, label := range labels {
+		if len(label) > maxLabelLength {
+			return constants.InvalidReasonLabelTooLong
+		}
+	}
+	for _, label := range labels {
+		if strings.HasPrefix(strings.ToLower(label), constants.PunycodePrefix) {
+			if _, err := idna.Lookup.ToUnicode(label); err != nil {
+				return constants.InvalidReasonPunycode
+			}
+		}
+	}
+	return constants.InvalidReasonBadLabel
+}
+
+// invalidIPReason returns why a value is not an entry of an IP source type, empty if it is one.
+func invalidIPReason(sourceType, value string) string {
+	addr, addrErr := netip.ParseAddr(value)
+	prefix, prefixErr := netip.ParsePrefix(value)
+	switch {
+	case addrErr == nil:
+		if sourceType == constants.SourceTypeIpv4 && addr.Is4() ||
+			sourceType == constants.SourceTypeIpv6 && addr.Is6() && !addr.Is4In6() {
+			return ""
+		}
+		return constants.InvalidReasonOtherIPType
+	case prefixErr == nil:
+		if sourceType == constants.SourceTypeCidrIpv4 && prefix.Addr().Is4() ||
+			sourceType == constants.SourceTypeCidrIpv6 && prefix.Addr().Is6() && !prefix.Addr().Is4In6() {
+			return ""
+		}
+		return constants.InvalidReasonOtherIPType
+	}
+	if host, port, err := net.SplitHostPort(value); err == nil && isPort(port) {
+		if IsIP(host) {
+			return constants.InvalidReasonPort
+		}
+		value = host
+	}
+	if IsDomain(value) {
+		return constants.InvalidReasonDomainInIPList
+	}
+	return constants.InvalidReasonInvalidIP
+}
+
+// isPort checks if a string is a port number.
+func isPort(port string) bool {
+	number, err := strconv.Atoi(port)
+	return err == nil && number >= 0 && number <= 65535
+}
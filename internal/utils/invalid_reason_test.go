@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/phani-kb/dns-toolkit/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidEntryReason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sourceType string
+		entry      string
+		expected   string
+	}{
+		{constants.SourceTypeDomain, "ads.example.com # matches the exclude pattern", constants.InvalidReasonProcessor},
+		{constants.SourceTypeDomain, "localhost", constants.InvalidReasonSingleLabel},
+		{constants.SourceTypeDomain, "example.com:8080", constants.InvalidReasonPort},
+		{constants.SourceTypeDomain, "https://example.com/path", constants.InvalidReasonURL},
+		{constants.SourceTypeDomain, "example.com/login", constants.InvalidReasonURL},
+		{constants.SourceTypeDomain, "192.0.2.1", constants.InvalidReasonIPInDomainList},
+		{constants.SourceTypeDomain, "2001:db8::1", constants.InvalidReasonIPInDomainList},
+		{constants.SourceTypeDomain, "198.51.100.0/24", constants.InvalidReasonIPInDomainList},
+		{constants.SourceTypeDomain, "bad_label!.example.com", constants.InvalidReasonBadLabel},
+		{constants.SourceTypeDomain, "xn---.example.com", constants.InvalidReasonPunycode},
+		{constants.SourceTypeDomain, strings.Repeat("a", maxLabelLength+1) + ".example.com", constants.InvalidReasonLabelTooLong},
+		{constants.SourceTypeDomain, "example.com extra", constants.InvalidReasonExtraFields},
+		{constants.SourceTypeIpv4, "2001:db8::1", constants.InvalidReasonOtherIPType},
+		{constants.SourceTypeIpv4, "192.0.2.1:53", constants.InvalidReasonPort},
+		{constants.SourceTypeIpv4, "example.com", constants.InvalidReasonDomainInIPList},
+		{constants.SourceTypeIpv4, "192.0.2.256", constants.InvalidReasonInvalidIP},
+		{constants.SourceTypeIpv6, "192.0.2.1", constants.InvalidReasonOtherIPType},
+		{constants.SourceTypeCidrIpv4, "2001:db8::/32", constants.InvalidReasonOtherIPType},
+		{constants.SourceTypeCidrIpv6, "2001:db8::/32", constants.InvalidReasonNoMatch},
+		{constants.SourceTypeAdguard, "||example.com^$third-party", constants.InvalidReasonNoMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceType+"/"+tt.entry, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, InvalidEntryReason(tt.sourceType, tt.entry))
+		})
+	}
+}
@@ -113,13 +113,6 @@ func SaveSummaries[T any](
 		return 0
 	})
 
-	file, err := os.Create(summaryFile)
-	if err != nil {
-		logger.Errorf("Creating summary file error: %v (file: %s)", err, summaryFile)
-		return 0, err
-	}
-	defer CloseFile(logger, file)
-
 	logger.Debugf("Marshaling summaries to JSON")
 	data, err := json.Marshal(summaries)
 	if err != nil {
@@ -134,9 +127,10 @@ func SaveSummaries[T any](
 		return 0, err
 	}
 
+	// Write to a temporary file and rename it, so an interrupted run never leaves a half-written summary
 	logger.Debugf("Writing summaries to file")
-	if _, err := file.Write(buf.Bytes()); err != nil {
-		logger.Errorf("Writing summaries error: %v", err)
+	if err := WriteFileAtomic(summaryFile, buf.Bytes(), 0644); err != nil {
+		logger.Errorf("Writing summaries error: %v (file: %s)", err, summaryFile)
 		return 0, err
 	}
 
@@ -191,6 +185,59 @@ func GetLastSummary[T any](logger *multilog.Logger, summaryFile string, sourceNa
 	return zeroValue, nil
 }
 
+// CarryOverSummaries adds the summaries from the existing summary file whose names are not present
+// in the given summaries. It is used when a run is cancelled part-way, so the saved summary still
+// covers the sources that were not handled before the cancellation.
+//
+// Type Parameters:
+//   - T: The type of the summary objects
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - summaries: Summaries produced by the current run
+//   - summaryFile: Path of the existing summary file
+//   - nameFunc: Function returning the source name of a summary
+//
+// Returns:
+//   - The current summaries followed by the carried over ones
+func CarryOverSummaries[T any](
+	logger *multilog.Logger,
+	summaries []T,
+	summaryFile string,
+	nameFunc func(T) string,
+) []T {
+	content, err := os.ReadFile(summaryFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Reading previous summary file error: %v (file: %s)", err, summaryFile)
+		}
+		return summaries
+	}
+
+	var previous []T
+	if err := json.Unmarshal(content, &previous); err != nil {
+		logger.Warnf("Parsing previous summary file error: %v (file: %s)", err, summaryFile)
+		return summaries
+	}
+
+	present := make(map[string]bool, len(summaries))
+	for _, summary := range summaries {
+		present[nameFunc(summary)] = true
+	}
+
+	carried := 0
+	for _, summary := range previous {
+		if name := nameFunc(summary); !present[name] {
+			summaries = append(summaries, summary)
+			carried++
+		}
+	}
+	if carried > 0 {
+		logger.Infof("Carried over %d previous summary(s) from %s", carried, summaryFile)
+	}
+	return summaries
+}
+
 // GetSummaryFiles is a generic function that retrieves files from a summary file
 func GetSummaryFiles[T any](
 	logger *multilog.Logger,
@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/phani-kb/multilog"
@@ -211,3 +212,29 @@ func TestDetermineSummaryTypeFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestCarryOverSummaries(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	nameFunc := func(s c.DownloadSummary) string { return s.Name }
+	current := []c.DownloadSummary{{Name: "a", Checksum: "new"}}
+
+	result := CarryOverSummaries(logger, current, "/nonexistent/file", nameFunc)
+	assert.Equal(t, current, result)
+
+	summaryFile := filepath.Join(t.TempDir(), "download_summary.json")
+	previous := []c.DownloadSummary{{Name: "a", Checksum: "old"}, {Name: "b", Checksum: "old"}}
+	data, err := json.Marshal(previous)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(summaryFile, data, 0644))
+
+	result = CarryOverSummaries(logger, current, summaryFile, nameFunc)
+	require.Len(t, result, 2)
+	assert.Equal(t, "new", result[0].Checksum, "current summaries take precedence")
+	assert.Equal(t, "b", result[1].Name)
+
+	require.NoError(t, os.WriteFile(summaryFile, []byte("invalid json"), 0644))
+	result = CarryOverSummaries(logger, current, summaryFile, nameFunc)
+	assert.Equal(t, current, result)
+}
@@ -4,15 +4,20 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bufio"
+	"compress/bzip2"
 	"compress/gzip"
+	"context"
 	"crypto/md5"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
 	"hash"
 	"io"
 	"math/rand"
 	"net"
+	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -23,10 +28,12 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
 	"github.com/phani-kb/multilog"
+	"github.com/ulikunitz/xz"
 	"golang.org/x/net/idna"
 )
 
@@ -192,6 +199,87 @@ func SaveFile(logger *multilog.Logger, destFolder, fileName string, reader io.Re
 	return filePath, nil
 }
 
+// SaveVerifiedFile saves the content from the reader like SaveFile, but stages it in a temporary file
+// in the destination folder that only replaces the file once verify accepts it.
+// A rejected or failed download therefore leaves the previous copy of the file in place.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - destFolder: Target directory where the file will be saved
+//   - fileName: Name of the file to create or replace
+//   - reader: Source of content to be saved
+//   - verify: Called with the path of the staged file, may be nil
+//
+// Returns:
+//   - The absolute path of the saved file or an empty string on error
+//   - An error object if the operation or the verification failed, nil on success
+func SaveVerifiedFile(
+	logger *multilog.Logger,
+	destFolder, fileName string,
+	reader io.Reader,
+	verify func(stagedPath string) error,
+) (string, error) {
+	if err := os.MkdirAll(destFolder, os.ModePerm); err != nil {
+		logger.Errorf("Destination folder creation error: %v (folder: %s)", err, destFolder)
+		return "", err
+	}
+
+	filePath := filepath.Join(destFolder, fileName)
+	staged, err := os.CreateTemp(destFolder, "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		logger.Errorf("Creating staging file error: %v (file: %s)", err, filePath)
+		return "", err
+	}
+	stagedPath := staged.Name()
+	defer func() {
+		_ = os.Remove(stagedPath) // a no-op once the staged file was moved into place
+	}()
+
+	_, err = io.Copy(staged, reader)
+	if closeErr := staged.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Errorf("Saving file error: %v (file: %s)", err, filePath)
+		return "", err
+	}
+	if err := MoveVerifiedFile(logger, stagedPath, filePath, verify); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// MoveVerifiedFile moves a staged file over filePath once verify accepts it.
+// The staged file must be in the folder of filePath and is left in place if it is rejected.
+//
+// Parameters:
+//   - logger: Logger for recording errors
+//   - stagedPath: Path of the complete staged file
+//   - filePath: Path of the file to create or replace
+//   - verify: Called with stagedPath, may be nil
+//
+// Returns:
+//   - An error object if the verification or the move failed, nil on success
+func MoveVerifiedFile(
+	logger *multilog.Logger,
+	stagedPath, filePath string,
+	verify func(stagedPath string) error,
+) error {
+	if verify != nil {
+		if err := verify(stagedPath); err != nil {
+			return err
+		}
+	}
+	if err := os.Chmod(stagedPath, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(stagedPath, filePath); err != nil {
+		logger.Errorf("Moving file into place error: %v (file: %s)", err, filePath)
+		return err
+	}
+	return nil
+}
+
 // CloseFile safely closes the given file and logs an error if it fails.
 //
 // Parameters:
@@ -204,13 +292,57 @@ func CloseFile(logger *multilog.Logger, file *os.File) {
 	}
 }
 
+// WriteFileAtomic writes data to a temporary file in the target directory and renames it
+// over the destination, so readers never observe a partially written file.
+//
+// Parameters:
+//   - filePath: Destination path of the file
+//   - data: Content to write
+//   - perm: Permissions applied to the written file
+//
+// Returns:
+//   - An error object if the operation failed, nil on success
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
 // CalculateChecksum calculates the checksum of the specified file using the specified algorithm.
-// Supports MD5 and SHA256 algorithms. If the algorithm is empty, it defaults to MD5.
+// Supports MD5, SHA256 and SHA512 algorithms. If the algorithm is empty, it defaults to MD5.
 //
 // Parameters:
 //   - logger: Logger for recording operations and errors
 //   - filePath: Path to the file to calculate the checksum for
-//   - algo: Algorithm to use ("md5" or "sha256")
+//   - algo: Algorithm to use ("md5", "sha256" or "sha512")
 //
 // Returns:
 //   - A hex string representation of the checksum or an empty string on error
@@ -230,6 +362,8 @@ func CalculateChecksum(logger *multilog.Logger, filePath string, algo string) st
 		h = md5.New()
 	case "sha256":
 		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
 	default:
 		logger.Errorf("Unsupported algorithm: %s", algo)
 		return ""
@@ -244,11 +378,11 @@ func CalculateChecksum(logger *multilog.Logger, filePath string, algo string) st
 }
 
 // CalculateChecksumFromContent calculates the checksum of the provided content using the specified algorithm.
-// Supports MD5 and SHA256 algorithms. If the algorithm is empty, it defaults to MD5.
+// Supports MD5, SHA256 and SHA512 algorithms. If the algorithm is empty, it defaults to MD5.
 //
 // Parameters:
 //   - content: The byte slice to calculate the checksum for
-//   - algo: Algorithm to use ("md5" or "sha256")
+//   - algo: Algorithm to use ("md5", "sha256" or "sha512")
 //
 // Returns:
 //   - A hex string representation of the checksum
@@ -263,6 +397,8 @@ func CalculateChecksumFromContent(content []byte, algo string) string {
 		h = md5.New()
 	case "sha256":
 		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
 	default:
 		return ""
 	}
@@ -271,8 +407,58 @@ func CalculateChecksumFromContent(content []byte, algo string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// CountEntries counts the lines read from reader that are neither empty nor comments.
+//
+// Parameters:
+//   - reader: Source of the lines to count
+//
+// Returns:
+//   - The number of entries read
+//   - An error object if reading failed, nil on success
+func CountEntries(reader io.Reader) (int, error) {
+	count := 0
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if !IsComment(scanner.Text()) {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// CountFileEntries counts the lines of a file that are neither empty nor comments.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - filePath: Path to the file to count the entries of
+//
+// Returns:
+//   - The number of entries in the file
+//   - An error object if the file could not be read, nil on success
+func CountFileEntries(logger *multilog.Logger, filePath string) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer CloseFile(logger, file)
+	return CountEntries(file)
+}
+
+// IsHTML reports whether content starts like an HTML document, e.g. an error page served instead of a list.
+//
+// Parameters:
+//   - content: The first bytes of the content, 512 are enough
+//
+// Returns:
+//   - true if the content is detected as HTML, false otherwise
+func IsHTML(content []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(content), "text/html")
+}
+
 // IsComment determines if a line is a comment or an empty line.
-// A line is considered a comment if it's empty or starts with any of the common comment prefixes.
+// A line is considered a comment if it's empty or starts with any of the common comment prefixes,
+// except for a *.example.com domain entry.
 //
 // Parameters:
 //   - line: The string to check
@@ -285,6 +471,10 @@ func IsComment(line string) bool {
 		return true
 	}
 
+	if strings.HasPrefix(trimmedLine, constants.WildcardDomainPrefix) && IsDomainEntry(trimmedLine) {
+		return false
+	}
+
 	for _, prefix := range constants.CommentPrefixes {
 		if strings.HasPrefix(trimmedLine, prefix) {
 			return true
@@ -324,8 +514,88 @@ func IsIPv4(line string) bool {
 // Returns:
 //   - true if the string is a valid IPv6 address, false otherwise
 func IsIPv6(line string) bool {
-	ip := net.ParseIP(line)
-	return ip != nil && ip.To4() == nil
+	_, ok := CanonicalIPv6(line)
+	return ok
+}
+
+// CanonicalIPv6 parses an IPv6 address in any notation and returns it in the canonical compressed form
+// of RFC 5952, such as 2001:db8::1. IPv4-mapped addresses and addresses with a zone are rejected.
+//
+// Parameters:
+//   - s: The string to parse
+//
+// Returns:
+//   - The canonical form of the address
+//   - true if the string is a valid IPv6 address, false otherwise
+func CanonicalIPv6(s string) (string, bool) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil || !addr.Is6() || addr.Is4In6() || addr.Zone() != "" {
+		return "", false
+	}
+	return addr.String(), true
+}
+
+// CanonicalCIDRv6 parses an IPv6 network in CIDR notation and returns it in canonical form,
+// with the compressed network address and the host bits cleared, such as 2001:db8::/32.
+//
+// Parameters:
+//   - s: The string to parse
+//
+// Returns:
+//   - The canonical form of the network
+//   - true if the string is a valid IPv6 CIDR, false otherwise
+func CanonicalCIDRv6(s string) (string, bool) {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil || !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		return "", false
+	}
+	return prefix.Masked().String(), true
+}
+
+// FindIPv6 returns the canonical form of the first IPv6 address standing alone in a line,
+// skipping the addresses of CIDR networks.
+//
+// Parameters:
+//   - line: The line to search
+//
+// Returns:
+//   - The canonical form of the address
+//   - true if the line holds an IPv6 address, false otherwise
+func FindIPv6(line string) (string, bool) {
+	for _, token := range ipTokens(line) {
+		if strings.Contains(token, "/") {
+			continue
+		}
+		if ip, ok := CanonicalIPv6(token); ok {
+			return ip, true
+		}
+	}
+	return "", false
+}
+
+// FindCIDRv6 returns the canonical form of the first IPv6 CIDR network in a line.
+//
+// Parameters:
+//   - line: The line to search
+//
+// Returns:
+//   - The canonical form of the network
+//   - true if the line holds an IPv6 CIDR, false otherwise
+func FindCIDRv6(line string) (string, bool) {
+	for _, token := range ipTokens(line) {
+		if cidr, ok := CanonicalCIDRv6(token); ok {
+			return cidr, true
+		}
+	}
+	return "", false
+}
+
+// ipTokens splits a line into the candidate addresses it holds, at blanks, punctuation and brackets,
+// so that [2001:db8::1]:53 yields 2001:db8::1.
+func ipTokens(line string) []string {
+	return strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(",;|\"'()[]{}<>=", r)
+	})
 }
 
 // IsCIDR checks if a string is a valid CIDR notation address.
@@ -479,6 +749,9 @@ func isCommentFast(line string) bool {
 	if len(ch) == 0 {
 		return true
 	}
+	if strings.HasPrefix(ch, constants.WildcardDomainPrefix) && IsDomainEntry(ch) {
+		return false
+	}
 	// Single-char prefix
 	if _, ok := singleCharCommentPrefixes[ch[0]]; ok {
 		return true
@@ -911,6 +1184,38 @@ func ExpandIpv4Cidr(logger *multilog.Logger, cidr string) ([]string, error) {
 	return ips, nil
 }
 
+// ExpandIpv6Cidr expands a CIDR notation IPv6 network into its individual addresses.
+// Only networks of at most 2^16 addresses, /112 or longer, are expanded.
+//
+// Parameters:
+//   - logger: Logger for recording operations and errors
+//   - cidr: A string containing an IPv6 network in CIDR notation
+//
+// Returns:
+//   - A slice of strings with all individual addresses of the network, in canonical form
+func ExpandIpv6Cidr(logger *multilog.Logger, cidr string) ([]string, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		logger.Errorf("Invalid CIDR format: %s. Error: %v", cidr, err)
+		return nil, fmt.Errorf("invalid CIDR format: %w", err)
+	}
+	if !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		logger.Errorf("Invalid IPv6 address in CIDR (or IPv4 given): %s", cidr)
+		return nil, fmt.Errorf("invalid IPv6 address or non-IPv6 CIDR: %s", cidr)
+	}
+	hostBits := 128 - prefix.Bits()
+	if hostBits > 16 {
+		logger.Errorf("CIDR range too large to expand: %s", cidr)
+		return nil, fmt.Errorf("CIDR range too large to expand: %s", cidr)
+	}
+	ips := make([]string, 0, 1<<hostBits)
+	for addr := prefix.Masked().Addr(); prefix.Contains(addr); addr = addr.Next() {
+		ips = append(ips, addr.String())
+	}
+
+	return ips, nil
+}
+
 // IsArchive checks if a file is an archive based on its extension.
 func IsArchive(filePath string) bool {
 	for _, ext := range constants.ArchiveExtensions {
@@ -930,7 +1235,27 @@ func GetArchiveExtension(uri string) string {
 	return ""
 }
 
+// IsCompressed checks if a file is a single-file compressed payload, such as list.txt.gz, based on its extension.
+// Archives like .tar.gz are not considered compressed files.
+func IsCompressed(filePath string) bool {
+	return GetCompressedExtension(filePath) != ""
+}
+
+func GetCompressedExtension(uri string) string {
+	if IsArchive(uri) {
+		return ""
+	}
+	for _, ext := range constants.CompressedExtensions {
+		if strings.HasSuffix(uri, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
 // ExtractArchive extracts the contents of an archive file (either .tar.gz or .zip) to the specified destination folder.
+// A single-file compressed payload (.gz, .bz2 or .xz) is decompressed into the destination folder,
+// named after the compressed file without its extension.
 //
 // Parameters:
 //   - archivePath: Path to the archive file
@@ -949,9 +1274,73 @@ func ExtractArchive(logger *multilog.Logger, archivePath, destFolder string) err
 			}
 		}
 	}
+	if ext := GetCompressedExtension(archivePath); ext != "" {
+		return decompressFile(logger, archivePath, destFolder, ext)
+	}
 	return fmt.Errorf("unsupported archive format: %s", archivePath)
 }
 
+// OpenDecompressed opens a single-file gzip, bzip2 or xz payload for reading its decompressed content.
+// The extension is passed separately, so the payload may be stored under any name.
+func OpenDecompressed(logger *multilog.Logger, archivePath, ext string) (io.ReadCloser, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	switch ext {
+	case ".gz":
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			CloseFile(logger, file)
+			return nil, err
+		}
+		reader = gzipReader
+	case ".bz2":
+		reader = bzip2.NewReader(file)
+	case ".xz":
+		xzReader, err := xz.NewReader(file)
+		if err != nil {
+			CloseFile(logger, file)
+			return nil, err
+		}
+		reader = xzReader
+	default:
+		CloseFile(logger, file)
+		return nil, fmt.Errorf("unsupported compression format: %s", archivePath)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, file}, nil
+}
+
+// decompressFile decompresses a single-file gzip, bzip2 or xz payload to the specified destination folder.
+func decompressFile(logger *multilog.Logger, archivePath, destFolder, ext string) error {
+	reader, err := OpenDecompressed(logger, archivePath, ext)
+	if err != nil {
+		return err
+	}
+	defer CloseBody(logger, reader)
+
+	name := strings.TrimSuffix(filepath.Base(archivePath), ext)
+	filePath := filepath.Join(destFolder, name)
+	if err := os.MkdirAll(destFolder, os.ModePerm); err != nil {
+		return err
+	}
+	outFile, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer CloseFile(logger, outFile)
+
+	if _, err := io.Copy(outFile, reader); err != nil {
+		return fmt.Errorf("decompressing %s: %w", archivePath, err)
+	}
+	return nil
+}
+
 // extractTarGz extracts a .tar.gz archive to the specified destination folder.
 func extractTarGz(logger *multilog.Logger, archivePath, destFolder string) error {
 	file, err := os.Open(archivePath)
@@ -1142,18 +1531,63 @@ func extractZipFile(logger *multilog.Logger, f *zip.File, destFolder string) err
 	return err
 }
 
-// copySourceToTargetInternal is the internal implementation for copying files
+// IsGlobPattern reports whether a file name contains glob metacharacters.
+func IsGlobPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// ResolveTargetSources returns the source files of a target, sorted within each pattern.
+// A plain source file is returned as is; a glob pattern must match at least one file and,
+// unless the target concatenates its sources, exactly one.
+func ResolveTargetSources(target c.DownloadTarget) ([]string, error) {
+	patterns := target.SourceFiles
+	if len(patterns) == 0 {
+		patterns = []string{target.SourceFile}
+	}
+
+	var sources []string
+	for _, pattern := range patterns {
+		path := filepath.Join(target.SourceFolder, pattern)
+		if !IsGlobPattern(pattern) {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				return nil, fmt.Errorf("source file not found: %s", path)
+			}
+			sources = append(sources, path)
+			continue
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no source file matches: %s", path)
+		}
+		if len(matches) > 1 && len(target.SourceFiles) == 0 {
+			return nil, fmt.Errorf("%d source files match %s, set concatenate_files to combine them", len(matches), path)
+		}
+		sort.Strings(matches)
+		sources = append(sources, matches...)
+	}
+	return sources, nil
+}
+
+// copySourceToTargetInternal is the internal implementation for copying files.
+// Multiple source files are concatenated into the target, separated by newlines.
 func copySourceToTargetInternal(logger *multilog.Logger, target c.DownloadTarget, forceOverwrite bool) error {
-	sourceFilepath := filepath.Join(target.SourceFolder, target.SourceFile)
-	if _, err := os.Stat(sourceFilepath); os.IsNotExist(err) {
-		return fmt.Errorf("source file not found: %s", sourceFilepath)
+	sources, err := ResolveTargetSources(target)
+	if err != nil {
+		return err
 	}
+	sourceFilepath := strings.Join(sources, ", ")
 	if _, err := os.Stat(target.TargetFolder); os.IsNotExist(err) {
 		if err := os.MkdirAll(target.TargetFolder, os.ModePerm); err != nil {
 			return err
 		}
 	}
 	targetFilepath := filepath.Join(target.TargetFolder, target.TargetFile)
+	if len(sources) == 1 && filepath.Clean(sources[0]) == filepath.Clean(targetFilepath) {
+		return nil
+	}
 
 	// Check if target exists and we shouldn't overwrite
 	if !forceOverwrite {
@@ -1163,21 +1597,21 @@ func copySourceToTargetInternal(logger *multilog.Logger, target c.DownloadTarget
 		}
 	}
 
-	// copy a source file to a target file
-	sourceFile, err := os.Open(sourceFilepath)
-	if err != nil {
-		return err
-	}
-	defer CloseFile(logger, sourceFile)
-
 	targetFile, err := os.Create(targetFilepath)
 	if err != nil {
 		return err
 	}
 	defer CloseFile(logger, targetFile)
 
-	if _, err := io.Copy(targetFile, sourceFile); err != nil {
-		return err
+	for i, source := range sources {
+		if i > 0 {
+			if _, err := targetFile.WriteString("\n"); err != nil {
+				return err
+			}
+		}
+		if err := appendFile(logger, targetFile, source); err != nil {
+			return err
+		}
 	}
 
 	if forceOverwrite {
@@ -1186,6 +1620,18 @@ func copySourceToTargetInternal(logger *multilog.Logger, target c.DownloadTarget
 	return nil
 }
 
+// appendFile copies the content of the file at path to w.
+func appendFile(logger *multilog.Logger, w io.Writer, path string) error {
+	sourceFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer CloseFile(logger, sourceFile)
+
+	_, err = io.Copy(w, sourceFile)
+	return err
+}
+
 func CopySourceToTarget(logger *multilog.Logger, target c.DownloadTarget) error {
 	return copySourceToTargetInternal(logger, target, false)
 }
@@ -1200,6 +1646,26 @@ func CloseBody(logger *multilog.Logger, body io.Closer) {
 	}
 }
 
+var envPlaceholderRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnvPlaceholders replaces ${NAME} placeholders in s with the value of the environment variable NAME.
+// It returns an error if a referenced variable is not set. Other uses of $ are left untouched.
+func ExpandEnvPlaceholders(s string) (string, error) {
+	var missing []string
+	expanded := envPlaceholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := envPlaceholderRegex.FindStringSubmatch(placeholder)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable(s) not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
 func IsSkipIP(_ *multilog.Logger, ip string) bool {
 	if ip != "0.0.0.0" && !strings.HasPrefix(ip, "127.") &&
 		!strings.HasPrefix(ip, "169.254.") && !strings.HasPrefix(ip, "224.") {
@@ -1409,19 +1875,88 @@ func GetUserAgent(logger *multilog.Logger, appName string, appVersion string, ap
 	return userAgent
 }
 
+// offlineKey is the context key marking work that must not access the network.
+type offlineKey struct{}
+
+// WithOffline returns a copy of ctx in which work needing the network, such as resolving domains, is skipped.
+func WithOffline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, offlineKey{}, true)
+}
+
+// IsOffline reports whether ctx disallows network access.
+func IsOffline(ctx context.Context) bool {
+	offline, _ := ctx.Value(offlineKey{}).(bool)
+	return offline
+}
+
 func ResolveDomainsToIPv4(logger *multilog.Logger, domains []string) ([]string, []string) {
+	return ResolveDomainsToIPv4WithContext(context.Background(), logger, domains)
+}
+
+// ResolveDomainsToIPv4WithContext is like ResolveDomainsToIPv4 but stops resolving once ctx is cancelled.
+// Domains that were not resolved before the cancellation are reported as failed,
+// as are all domains when ctx is offline.
+func ResolveDomainsToIPv4WithContext(
+	ctx context.Context,
+	logger *multilog.Logger,
+	domains []string,
+) ([]string, []string) {
+	return resolveDomains(ctx, logger, domains, func(ip net.IP) bool {
+		return IsIPv4(ip.String()) && ip.String() != "0.0.0.0"
+	})
+}
+
+func ResolveDomainsToIPv6(logger *multilog.Logger, domains []string) ([]string, []string) {
+	return ResolveDomainsToIPv6WithContext(context.Background(), logger, domains)
+}
+
+// ResolveDomainsToIPv6WithContext is like ResolveDomainsToIPv4WithContext for the IPv6 addresses of the domains.
+func ResolveDomainsToIPv6WithContext(
+	ctx context.Context,
+	logger *multilog.Logger,
+	domains []string,
+) ([]string, []string) {
+	return resolveDomains(ctx, logger, domains, func(ip net.IP) bool {
+		return IsIPv6(ip.String()) && !ip.IsUnspecified()
+	})
+}
+
+// resolveDomains resolves the domains one at a time and returns the addresses accepted by keep,
+// along with the domains without any accepted address.
+func resolveDomains(
+	ctx context.Context,
+	logger *multilog.Logger,
+	domains []string,
+	keep func(net.IP) bool,
+) ([]string, []string) {
 	var ipAddresses []string
 	var failedDomains []string
 
-	for _, domain := range domains {
-		ips := resolveDomainIPv4(logger, domain)
+	if IsOffline(ctx) {
+		logger.Warnf("Offline, not resolving %d domain(s)", len(domains))
+		failedDomains = append(failedDomains, domains...)
+		sort.Strings(failedDomains)
+		return ipAddresses, failedDomains
+	}
+
+	for i, domain := range domains {
+		if ctx.Err() != nil {
+			logger.Warnf("Resolving domains cancelled: %v", ctx.Err())
+			failedDomains = append(failedDomains, domains[i:]...)
+			break
+		}
+
+		ips := resolveDomainIPs(ctx, logger, domain, keep)
 		if len(ips) == 0 {
 			failedDomains = append(failedDomains, domain)
 		} else {
 			ipAddresses = append(ipAddresses, ips...)
 		}
 
-		time.Sleep(constants.IPResolveInterval)
+		select {
+		case <-ctx.Done():
+		case <-time.After(constants.IPResolveInterval):
+		}
 	}
 
 	sort.Strings(ipAddresses)
@@ -1430,15 +1965,15 @@ func ResolveDomainsToIPv4(logger *multilog.Logger, domains []string) ([]string,
 	return ipAddresses, failedDomains
 }
 
-func resolveDomainIPv4(logger *multilog.Logger, domain string) []string {
+func resolveDomainIPs(ctx context.Context, logger *multilog.Logger, domain string, keep func(net.IP) bool) []string {
 	ipStrings := make([]string, 0)
-	ips, err := net.LookupIP(domain)
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", domain)
 	if err != nil {
 		logger.Debug("Failed to resolve domain", "domain", domain, "error", err)
 		return ipStrings
 	}
 	for _, ip := range ips {
-		if IsIPv4(ip.String()) && ip.String() != "0.0.0.0" {
+		if keep(ip) {
 			ipStrings = append(ipStrings, ip.String())
 		}
 	}
@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -16,6 +22,7 @@ import (
 	"github.com/phani-kb/multilog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
 
 	c "github.com/phani-kb/dns-toolkit/internal/common"
 	"github.com/phani-kb/dns-toolkit/internal/constants"
@@ -163,6 +170,45 @@ func TestCloseFile(t *testing.T) {
 	CloseFile(logger, tempFile)
 }
 
+func TestExpandEnvPlaceholders(t *testing.T) {
+	t.Setenv("DNS_TOOLKIT_TEST_TOKEN", "secret")
+
+	expanded, err := ExpandEnvPlaceholders("Bearer ${DNS_TOOLKIT_TEST_TOKEN}")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", expanded)
+
+	expanded, err = ExpandEnvPlaceholders("price: $5, $HOME")
+	require.NoError(t, err)
+	assert.Equal(t, "price: $5, $HOME", expanded, "only ${NAME} placeholders should be expanded")
+
+	_, err = ExpandEnvPlaceholders("${DNS_TOOLKIT_TEST_MISSING_VAR}")
+	assert.ErrorContains(t, err, "DNS_TOOLKIT_TEST_MISSING_VAR")
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "state.json")
+
+	require.NoError(t, WriteFileAtomic(filePath, []byte("first"), 0644))
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+
+	require.NoError(t, WriteFileAtomic(filePath, []byte("second"), 0644))
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should not be left behind")
+
+	err = WriteFileAtomic(filepath.Join(tempDir, "missing", "state.json"), []byte("x"), 0644)
+	assert.Error(t, err)
+}
+
 func TestCalculateChecksum(t *testing.T) {
 	t.Parallel()
 
@@ -383,6 +429,10 @@ func TestIsComment(t *testing.T) {
 	assert.True(t, IsComment("! comment"))
 	assert.True(t, IsComment("[Adblock Plus 2.0]"))
 	assert.True(t, IsComment("  [metadata]"))
+	assert.True(t, IsComment("* block comment"))
+	assert.True(t, IsComment("*.not a domain"))
+	assert.False(t, IsComment("*.example.com"))
+	assert.False(t, isCommentFast("  *.example.com"))
 
 	assert.False(t, IsComment("example.com"))
 	assert.False(t, IsComment("192.168.1.1"))
@@ -650,6 +700,76 @@ func TestSaveFileErrorCases(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSaveVerifiedFile(t *testing.T) {
+	t.Parallel()
+
+	logger := createTestLogger(t)
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "list.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("previous"), 0644))
+
+	var stagedContent string
+	savedPath, err := SaveVerifiedFile(logger, tmpDir, "list.txt", strings.NewReader("accepted"),
+		func(stagedPath string) error {
+			content, err := os.ReadFile(stagedPath)
+			stagedContent = string(content)
+			return err
+		})
+	require.NoError(t, err)
+	assert.Equal(t, filePath, savedPath)
+	assert.Equal(t, "accepted", stagedContent)
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", string(content))
+
+	_, err = SaveVerifiedFile(logger, tmpDir, "list.txt", strings.NewReader("rejected"), func(string) error {
+		return errors.New("rejected")
+	})
+	assert.Error(t, err)
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "accepted", string(content), "a rejected file should not replace the previous copy")
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no staged file should be left behind")
+
+	_, err = SaveVerifiedFile(logger, tmpDir, "", strings.NewReader("content"), nil)
+	assert.Error(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "directory"), 0755))
+	_, err = SaveVerifiedFile(logger, tmpDir, "directory", strings.NewReader("content"), nil)
+	assert.Error(t, err)
+}
+
+func TestMoveVerifiedFile(t *testing.T) {
+	t.Parallel()
+
+	logger := createTestLogger(t)
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "list.txt")
+	stagedPath := filepath.Join(tmpDir, ".list.txt.part")
+	require.NoError(t, os.WriteFile(filePath, []byte("previous"), 0644))
+	require.NoError(t, os.WriteFile(stagedPath, []byte("rejected"), 0600))
+
+	err := MoveVerifiedFile(logger, stagedPath, filePath, func(string) error { return errors.New("rejected") })
+	assert.Error(t, err)
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(content))
+	assert.FileExists(t, stagedPath, "a rejected file is left to the caller")
+
+	require.NoError(t, MoveVerifiedFile(logger, stagedPath, filePath, nil))
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "rejected", string(content))
+	assert.NoFileExists(t, stagedPath)
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	assert.Error(t, MoveVerifiedFile(logger, stagedPath, filePath, nil), "the staged file no longer exists")
+}
+
 func TestCalculateChecksumFromContentAllAlgorithms(t *testing.T) {
 	t.Parallel()
 
@@ -657,6 +777,7 @@ func TestCalculateChecksumFromContentAllAlgorithms(t *testing.T) {
 
 	md5Result := CalculateChecksumFromContent(testContent, "md5")
 	sha256Result := CalculateChecksumFromContent(testContent, "sha256")
+	sha512Result := CalculateChecksumFromContent(testContent, "sha512")
 	defaultResult := CalculateChecksumFromContent(testContent, "")
 	unsupportedResult := CalculateChecksumFromContent(testContent, "unsupported")
 
@@ -667,6 +788,7 @@ func TestCalculateChecksumFromContentAllAlgorithms(t *testing.T) {
 
 	assert.Equal(t, md5Result, defaultResult)   // Default should be MD5
 	assert.NotEqual(t, md5Result, sha256Result) // Different algorithms should give different results
+	assert.Len(t, sha512Result, 128)
 
 	emptyMD5 := CalculateChecksumFromContent([]byte{}, "md5")
 	assert.NotEmpty(t, emptyMD5) // Even empty content has a hash
@@ -992,6 +1114,126 @@ func TestExtractArchiveZip(t *testing.T) {
 	assert.Equal(t, "nested content", string(nestedExtractedContent))
 }
 
+func TestGetCompressedExtension(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ".gz", GetCompressedExtension("https://example.com/domains.txt.gz"))
+	assert.Equal(t, ".bz2", GetCompressedExtension("domains.bz2"))
+	assert.Equal(t, ".xz", GetCompressedExtension("domains.txt.xz"))
+	assert.Equal(t, "", GetCompressedExtension("domains.tar.gz"), "archives are not single-file payloads")
+	assert.Equal(t, "", GetCompressedExtension("domains.txt"))
+	assert.True(t, IsCompressed("domains.txt.gz"))
+	assert.False(t, IsCompressed("domains.zip"))
+}
+
+func TestExtractArchiveCompressed(t *testing.T) {
+	t.Parallel()
+
+	logger := createTestLogger(t)
+	content := "example.com\nexample.org\n"
+	// bzip2 has no writer in the standard library, so the payload is a precomputed fixture
+	bz2Content, err := base64.StdEncoding.DecodeString(
+		"QlpoOTFBWSZTWf+U3T0AAATRgAAQAAEqhtBAIAAxA0DQEqaGJspN0MnxQ2NDtHUfF3JFOFCQ/5TdPQ==")
+	require.NoError(t, err)
+
+	var gzBuf bytes.Buffer
+	gzWriter := gzip.NewWriter(&gzBuf)
+	_, err = gzWriter.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gzWriter.Close())
+
+	var xzBuf bytes.Buffer
+	xzWriter, err := xz.NewWriter(&xzBuf)
+	require.NoError(t, err)
+	_, err = xzWriter.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, xzWriter.Close())
+
+	payloads := map[string][]byte{
+		"list.txt.gz":  gzBuf.Bytes(),
+		"list.txt.bz2": bz2Content,
+		"list.txt.xz":  xzBuf.Bytes(),
+	}
+	for name, payload := range payloads {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			archivePath := filepath.Join(tmpDir, name)
+			require.NoError(t, os.WriteFile(archivePath, payload, 0644))
+
+			outputDir := filepath.Join(tmpDir, "output")
+			require.NoError(t, ExtractArchive(logger, archivePath, outputDir))
+
+			extracted, err := os.ReadFile(filepath.Join(outputDir, "list.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, content, string(extracted))
+		})
+	}
+
+	corruptPath := filepath.Join(t.TempDir(), "corrupt.txt.gz")
+	require.NoError(t, os.WriteFile(corruptPath, []byte("not gzip"), 0644))
+	assert.Error(t, ExtractArchive(logger, corruptPath, t.TempDir()))
+}
+
+func TestCopySourceToTargetPatterns(t *testing.T) {
+	t.Parallel()
+
+	logger := createTestLogger(t)
+	sourceDir := t.TempDir()
+	targetDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "lists"), 0755))
+	files := map[string]string{
+		"lists/domains-20260102.txt": "b.example",
+		"lists/domains-20260101.txt": "a.example\n",
+		"lists/ips-20260101.txt":     "192.0.2.1\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644))
+	}
+
+	single := c.DownloadTarget{
+		SourceFolder: sourceDir,
+		SourceFile:   "lists/ips-*.txt",
+		TargetFolder: targetDir,
+		TargetFile:   "ips.txt",
+	}
+	require.NoError(t, CopySourceToTarget(logger, single))
+	content, err := os.ReadFile(filepath.Join(targetDir, "ips.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1\n", string(content))
+
+	ambiguous := single
+	ambiguous.SourceFile = "lists/domains-*.txt"
+	ambiguous.TargetFile = "domains.txt"
+	err = CopySourceToTarget(logger, ambiguous)
+	assert.ErrorContains(t, err, "concatenate_files")
+
+	concatenated := c.DownloadTarget{
+		SourceFolder: sourceDir,
+		SourceFiles:  []string{"lists/domains-*.txt", "lists/ips-20260101.txt"},
+		TargetFolder: targetDir,
+		TargetFile:   "all.txt",
+	}
+	require.NoError(t, CopySourceToTarget(logger, concatenated))
+	content, err = os.ReadFile(filepath.Join(targetDir, "all.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a.example\n\nb.example\n192.0.2.1\n", string(content))
+
+	missing := single
+	missing.SourceFile = "lists/hosts-*.txt"
+	assert.ErrorContains(t, CopySourceToTarget(logger, missing), "no source file matches")
+
+	inPlace := c.DownloadTarget{
+		SourceFolder: sourceDir,
+		SourceFile:   "lists/ips-20260101.txt",
+		TargetFolder: filepath.Join(sourceDir, "lists"),
+		TargetFile:   "ips-20260101.txt",
+	}
+	require.NoError(t, ForceCopySourceToTarget(logger, inPlace))
+	content, err = os.ReadFile(filepath.Join(sourceDir, "lists", "ips-20260101.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1\n", string(content), "copying a file onto itself should keep its content")
+}
+
 func TestFindProjectRoot(t *testing.T) {
 	projectRoot, err := FindProjectRoot("")
 	assert.NoError(t, err)
@@ -1213,6 +1455,19 @@ func TestResolveDomainsToIPv4(t *testing.T) {
 	}
 }
 
+func TestResolveDomainsToIPv4_Offline(t *testing.T) {
+	t.Parallel()
+
+	logger := multilog.NewLogger()
+	assert.False(t, IsOffline(context.Background()))
+	ctx := WithOffline(context.Background())
+	assert.True(t, IsOffline(ctx))
+
+	ips, failedDomains := ResolveDomainsToIPv4WithContext(ctx, logger, []string{"localhost", "example.com"})
+	assert.Empty(t, ips)
+	assert.Equal(t, []string{"example.com", "localhost"}, failedDomains)
+}
+
 func TestExtractEntriesWithRegex(t *testing.T) {
 	t.Parallel()
 
@@ -1469,6 +1724,121 @@ func TestExpandIpv4Cidr(t *testing.T) {
 	}
 }
 
+func TestExpandIpv6Cidr(t *testing.T) {
+	logger := createTestLogger(t)
+
+	tests := []struct {
+		name        string
+		cidr        string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "small /126 CIDR",
+			cidr:     "2001:db8::/126",
+			expected: []string{"2001:db8::", "2001:db8::1", "2001:db8::2", "2001:db8::3"},
+		},
+		{
+			name:     "host bits set",
+			cidr:     "2001:DB8::5/127",
+			expected: []string{"2001:db8::4", "2001:db8::5"},
+		},
+		{
+			name:     "last addresses",
+			cidr:     "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127",
+			expected: []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		},
+		{
+			name:        "IPv4 CIDR",
+			cidr:        "192.168.1.0/30",
+			expectError: true,
+		},
+		{
+			name:        "too large CIDR (/64)",
+			cidr:        "2001:db8::/64",
+			expectError: true,
+		},
+		{
+			name:        "invalid CIDR format",
+			cidr:        "2001:db8::/129",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandIpv6Cidr(logger, tt.cidr)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCanonicalIPv6(t *testing.T) {
+	tests := []struct {
+		input string
+		ip    string
+		cidr  string
+	}{
+		{input: "2001:0db8:85a3:0000:0000:8a2e:0370:7334", ip: "2001:db8:85a3::8a2e:370:7334"},
+		{input: "2001:DB8::1", ip: "2001:db8::1"},
+		{input: "2001:db8:0:0:1:0:0:1", ip: "2001:db8::1:0:0:1"},
+		{input: "::", ip: "::"},
+		{input: "::ffff:192.0.2.1"},
+		{input: "fe80::1%eth0"},
+		{input: "192.0.2.1"},
+		{input: "2001:db8::/32", cidr: "2001:db8::/32"},
+		{input: "2001:db8::1/32", cidr: "2001:db8::/32"},
+		{input: "::ffff:192.0.2.0/120"},
+		{input: "192.0.2.0/24"},
+	}
+
+	for _, tt := range tests {
+		ip, ok := CanonicalIPv6(tt.input)
+		assert.Equal(t, tt.ip != "", ok, tt.input)
+		assert.Equal(t, tt.ip, ip, tt.input)
+
+		cidr, ok := CanonicalCIDRv6(tt.input)
+		assert.Equal(t, tt.cidr != "", ok, tt.input)
+		assert.Equal(t, tt.cidr, cidr, tt.input)
+	}
+}
+
+func TestFindIPv6(t *testing.T) {
+	tests := []struct {
+		line    string
+		ip      string
+		cidr    string
+		hasIP   bool
+		hasCIDR bool
+	}{
+		{line: "2001:db8::1", ip: "2001:db8::1", hasIP: true},
+		{line: "deny from 2001:DB8:0::1 # abuse", ip: "2001:db8::1", hasIP: true},
+		{line: "[2001:db8::1]:53", ip: "2001:db8::1", hasIP: true},
+		{line: "2001:db8::1,\"ftp,http\"", ip: "2001:db8::1", hasIP: true},
+		{line: "2001:db8::/32", cidr: "2001:db8::/32", hasCIDR: true},
+		{line: "route 2001:db8:1::/48 via 2001:db8::1", ip: "2001:db8::1", cidr: "2001:db8:1::/48", hasIP: true, hasCIDR: true},
+		{line: "192.0.2.1 example.com"},
+		{line: "12:30 meeting"},
+	}
+
+	for _, tt := range tests {
+		ip, ok := FindIPv6(tt.line)
+		assert.Equal(t, tt.hasIP, ok, tt.line)
+		assert.Equal(t, tt.ip, ip, tt.line)
+
+		cidr, ok := FindCIDRv6(tt.line)
+		assert.Equal(t, tt.hasCIDR, ok, tt.line)
+		assert.Equal(t, tt.cidr, cidr, tt.line)
+	}
+}
+
 func TestStringSetSize(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1625,3 +1995,55 @@ func TestIsDomain_IDNA(t *testing.T) {
 		})
 	}
 }
+
+func TestCountEntries(t *testing.T) {
+	t.Parallel()
+
+	count, err := CountEntries(strings.NewReader("# header\na.example\n\n  \nb.example\n! comment\nc.example"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	logger := multilog.NewLogger()
+	filePath := filepath.Join(t.TempDir(), "list.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("a.example\nb.example\n"), 0644))
+	count, err = CountFileEntries(logger, filePath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = CountFileEntries(logger, filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestIsHTML(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsHTML([]byte("<!DOCTYPE html><html><body>blocked</body></html>")))
+	assert.True(t, IsHTML([]byte("\n  <html>\n<head><title>Login</title></head>")))
+	assert.False(t, IsHTML([]byte("a.example\nb.example\n")))
+	assert.False(t, IsHTML([]byte("0.0.0.0 a.example\n")))
+	assert.False(t, IsHTML(nil))
+}
+
+func TestOpenDecompressed(t *testing.T) {
+	t.Parallel()
+	logger := multilog.NewLogger()
+
+	var gzBuf bytes.Buffer
+	gzWriter := gzip.NewWriter(&gzBuf)
+	_, _ = gzWriter.Write([]byte("a.example\n"))
+	require.NoError(t, gzWriter.Close())
+	archivePath := filepath.Join(t.TempDir(), "list.txt.gz")
+	require.NoError(t, os.WriteFile(archivePath, gzBuf.Bytes(), 0644))
+
+	reader, err := OpenDecompressed(logger, archivePath, ".gz")
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, "a.example\n", string(content))
+
+	_, err = OpenDecompressed(logger, archivePath, ".zip")
+	assert.Error(t, err)
+	_, err = OpenDecompressed(logger, filepath.Join(t.TempDir(), "missing.gz"), ".gz")
+	assert.Error(t, err)
+}